@@ -20,6 +20,7 @@ export const PREDICATES: Access[] = [
                 fields: [
                     { name: 'file' },
                     { name: 'content' },
+                    { name: 'symbol' },
                     {
                         name: 'commit',
                         fields: [{ name: 'after' }],
@@ -33,7 +34,7 @@ export const PREDICATES: Access[] = [
         fields: [
             {
                 name: 'contains',
-                fields: [{ name: 'content' }],
+                fields: [{ name: 'content' }, { name: 'symbol' }],
             },
         ],
     },
@@ -151,7 +151,10 @@ type GitBlobLSIFDataResolver interface {
 	Stencil(ctx context.Context) ([]RangeResolver, error)
 	Ranges(ctx context.Context, args *LSIFRangesArgs) (CodeIntelligenceRangeConnectionResolver, error)
 	Definitions(ctx context.Context, args *LSIFQueryPositionArgs) (LocationConnectionResolver, error)
+	TypeDefinitions(ctx context.Context, args *LSIFQueryPositionArgs) (LocationConnectionResolver, error)
 	References(ctx context.Context, args *LSIFPagedQueryPositionArgs) (LocationConnectionResolver, error)
+	Implementations(ctx context.Context, args *LSIFPagedQueryPositionArgs) (LocationConnectionResolver, error)
+	CallHierarchy(ctx context.Context, args *LSIFCallHierarchyArgs) (CallHierarchyEdgeConnectionResolver, error)
 	Hover(ctx context.Context, args *LSIFQueryPositionArgs) (HoverResolver, error)
 	Documentation(ctx context.Context, args *LSIFQueryPositionArgs) (DocumentationResolver, error)
 }
@@ -180,6 +183,14 @@ type LSIFPagedQueryPositionArgs struct {
 	After *string
 }
 
+type LSIFCallHierarchyArgs struct {
+	LSIFQueryPositionArgs
+	Direction string
+	Depth     *int32
+	graphqlutil.ConnectionArgs
+	After *string
+}
+
 type LSIFQueryDocumentationArgs struct {
 	PathID string
 }
@@ -211,6 +222,18 @@ type LocationConnectionResolver interface {
 	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
 }
 
+type CallHierarchyEdgeConnectionResolver interface {
+	Nodes(ctx context.Context) ([]CallHierarchyEdgeResolver, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
+
+type CallHierarchyEdgeResolver interface {
+	Depth() int32
+	Caller() LocationResolver
+	Callee() LocationResolver
+	CallSite() LocationResolver
+}
+
 type HoverResolver interface {
 	Markdown() Markdown
 	Range() RangeResolver
@@ -2,6 +2,7 @@ package graphqlbackend
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/inconshreveable/log15"
@@ -118,11 +119,17 @@ type computeTextResolver struct {
 func (c *computeTextResolver) Repository() *RepositoryResolver { return c.repository }
 
 func (c *computeTextResolver) Commit() *string {
+	if c.commit == "" {
+		return nil
+	}
 	value := c.commit
 	return &value
 }
 
 func (c *computeTextResolver) Path() *string {
+	if c.path == "" {
+		return nil
+	}
 	value := c.path
 	return &value
 }
@@ -146,7 +153,7 @@ func (r *computeResultResolver) ToComputeText() (*computeTextResolver, bool) {
 	return res, ok
 }
 
-func toComputeMatchContextResolver(fm *result.FileMatch, mc *compute.MatchContext, repository *RepositoryResolver) *computeMatchContextResolver {
+func toComputeMatchContextResolver(commit, path string, mc *compute.MatchContext, repository *RepositoryResolver) *computeMatchContextResolver {
 	var computeMatches []*computeMatchResolver
 	for _, m := range mc.Matches {
 		mCopy := m
@@ -154,27 +161,40 @@ func toComputeMatchContextResolver(fm *result.FileMatch, mc *compute.MatchContex
 	}
 	return &computeMatchContextResolver{
 		repository: repository,
-		commit:     string(fm.CommitID),
-		path:       fm.Path,
+		commit:     commit,
+		path:       path,
 		matches:    computeMatches,
 	}
 }
 
-func toComputeTextResolver(fm *result.FileMatch, result *compute.Text, repository *RepositoryResolver) *computeTextResolver {
+func toComputeTextResolver(commit, path string, result *compute.Text, repository *RepositoryResolver) *computeTextResolver {
 	return &computeTextResolver{
 		repository: repository,
-		commit:     string(fm.CommitID),
-		path:       fm.Path,
+		commit:     commit,
+		path:       path,
 		t:          result,
 	}
 }
 
-func toComputeResultResolver(fm *result.FileMatch, result compute.Result, repoResolver *RepositoryResolver) *computeResultResolver {
+// commitAndPath returns the commit and the path of the file of a file result,
+// and the commit and an empty path of a commit or diff result.
+func commitAndPath(m result.Match) (commit, path string) {
+	switch m := m.(type) {
+	case *result.FileMatch:
+		return string(m.CommitID), m.Path
+	case *result.CommitMatch:
+		return string(m.Commit.ID), ""
+	}
+	return "", ""
+}
+
+func toComputeResultResolver(m result.Match, result compute.Result, repoResolver *RepositoryResolver) *computeResultResolver {
+	commit, path := commitAndPath(m)
 	switch r := result.(type) {
 	case *compute.MatchContext:
-		return &computeResultResolver{result: toComputeMatchContextResolver(fm, r, repoResolver)}
+		return &computeResultResolver{result: toComputeMatchContextResolver(commit, path, r, repoResolver)}
 	case *compute.Text:
-		return &computeResultResolver{result: toComputeTextResolver(fm, r, repoResolver)}
+		return &computeResultResolver{result: toComputeTextResolver(commit, path, r, repoResolver)}
 	default:
 		panic(fmt.Sprintf("unsupported compute result %T", r))
 	}
@@ -196,16 +216,39 @@ func toResultResolverList(ctx context.Context, cmd compute.Command, matches []re
 		return resolver
 	}
 
+	// The histograms of count commands are merged into a single result.
+	var histogram *compute.HistogramAggregator
+
 	results := make([]*computeResultResolver, 0, len(matches))
 	for _, m := range matches {
-		if fm, ok := m.(*result.FileMatch); ok {
-			result, err := cmd.Run(ctx, fm)
-			if err != nil {
-				return nil, err
+		switch m.(type) {
+		case *result.FileMatch, *result.CommitMatch:
+		default:
+			continue
+		}
+		result, err := cmd.Run(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		if h, ok := result.(*compute.Histogram); ok {
+			if histogram == nil {
+				histogram = compute.NewHistogramAggregator()
 			}
-			repoResolver := getRepoResolver(fm.Repo, "")
-			results = append(results, toComputeResultResolver(fm, result, repoResolver))
+			histogram.Add(h)
+			continue
+		}
+		repoResolver := getRepoResolver(m.RepoName(), "")
+		results = append(results, toComputeResultResolver(m, result, repoResolver))
+	}
+
+	if histogram != nil {
+		value, err := json.Marshal(histogram.Histogram())
+		if err != nil {
+			return nil, err
 		}
+		results = append(results, &computeResultResolver{result: &computeTextResolver{
+			t: &compute.Text{Value: string(value), Kind: "count"},
+		}})
 	}
 	return results, nil
 }
@@ -9,6 +9,8 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/compute"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
 )
 
 func TestToResultResolverList(t *testing.T) {
@@ -40,3 +42,64 @@ func TestToResultResolverList(t *testing.T) {
 
 	autogold.Want("resolver copies all match results", `["a","b"]`).Equal(t, test("a|b"))
 }
+
+func TestToResultResolverList_count(t *testing.T) {
+	matches := []result.Match{
+		&result.FileMatch{
+			File:        result.File{Repo: types.RepoName{Name: "a"}, Path: "a.go"},
+			LineMatches: []*result.LineMatch{{Preview: "x1 x2"}},
+		},
+		&result.FileMatch{
+			File:        result.File{Repo: types.RepoName{Name: "b"}, Path: "b.go"},
+			LineMatches: []*result.LineMatch{{Preview: "x1"}},
+		},
+	}
+	computeQuery, err := compute.Parse(`content:count(x(\d) -> by: $1)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvers, err := toResultResolverList(context.Background(), computeQuery.Command, matches, new(dbtesting.MockDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolvers) != 1 {
+		t.Fatalf("got %d results, want a single histogram", len(resolvers))
+	}
+	text, ok := resolvers[0].ToComputeText()
+	if !ok || *text.Kind() != "count" {
+		t.Fatalf("got result %#v, want a count", resolvers[0].result)
+	}
+	autogold.Want("histogram of count results", `{"buckets":[{"value":"1","count":2,"repositories":[{"repository":"a","count":1},{"repository":"b","count":1}],"files":[{"repository":"a","path":"a.go","count":1},{"repository":"b","path":"b.go","count":1}]},{"value":"2","count":1,"repositories":[{"repository":"a","count":1}],"files":[{"repository":"a","path":"a.go","count":1}]}]}`).Equal(t, text.Value())
+}
+
+func TestToResultResolverList_commit(t *testing.T) {
+	matches := []result.Match{
+		&result.CommitMatch{
+			Repo: types.RepoName{Name: "a"},
+			Commit: gitapi.Commit{
+				ID:      "deadbeef",
+				Author:  gitapi.Signature{Name: "alice"},
+				Message: "fix: compute on commits",
+			},
+		},
+	}
+	computeQuery, err := compute.Parse(`content:output(^fix: (.*) -> $1 by $author)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvers, err := toResultResolverList(context.Background(), computeQuery.Command, matches, new(dbtesting.MockDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolvers) != 1 {
+		t.Fatalf("got %d results, want 1", len(resolvers))
+	}
+	text, ok := resolvers[0].ToComputeText()
+	if !ok {
+		t.Fatalf("got result %#v, want text", resolvers[0].result)
+	}
+	if text.Commit() == nil || *text.Commit() != "deadbeef" || text.Path() != nil {
+		t.Errorf("got commit %v and path %v, want commit deadbeef and no path", text.Commit(), text.Path())
+	}
+	autogold.Want("output of commit result", "compute on commits by alice").Equal(t, text.Value())
+}
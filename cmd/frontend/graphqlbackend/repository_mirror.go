@@ -2,6 +2,7 @@ package graphqlbackend
 
 import (
 	"context"
+	"database/sql"
 	"net/url"
 	"strings"
 	"sync"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
@@ -37,6 +39,11 @@ type repositoryMirrorInfoResolver struct {
 	repoInfoOnce     sync.Once
 	repoInfoResponse *protocol.RepoInfo
 	repoInfoErr      error
+
+	// memoize the gitserver_repos lookup
+	gitserverRepoOnce sync.Once
+	gitserverRepo     *types.GitserverRepo
+	gitserverRepoErr  error
 }
 
 func (r *repositoryMirrorInfoResolver) gitserverRepoInfo(ctx context.Context) (*protocol.RepoInfo, error) {
@@ -47,6 +54,18 @@ func (r *repositoryMirrorInfoResolver) gitserverRepoInfo(ctx context.Context) (*
 	return r.repoInfoResponse, r.repoInfoErr
 }
 
+// gitserverRepoRow returns the gitserver_repos row of the repository, or an
+// empty row if there is none yet.
+func (r *repositoryMirrorInfoResolver) gitserverRepoRow(ctx context.Context) (*types.GitserverRepo, error) {
+	r.gitserverRepoOnce.Do(func() {
+		r.gitserverRepo, r.gitserverRepoErr = database.GitserverRepos(r.db).GetByID(ctx, r.repository.IDInt32())
+		if errors.Is(r.gitserverRepoErr, sql.ErrNoRows) {
+			r.gitserverRepo, r.gitserverRepoErr = &types.GitserverRepo{RepoID: r.repository.IDInt32()}, nil
+		}
+	})
+	return r.gitserverRepo, r.gitserverRepoErr
+}
+
 func (r *repositoryMirrorInfoResolver) repoUpdateSchedulerInfo(ctx context.Context) (*repoupdaterprotocol.RepoUpdateSchedulerInfoResult, error) {
 	r.repoUpdateSchedulerInfoOnce.Do(func() {
 		args := repoupdaterprotocol.RepoUpdateSchedulerInfoArgs{
@@ -142,6 +161,53 @@ func (r *repositoryMirrorInfoResolver) UpdatedAt(ctx context.Context) (*DateTime
 	return DateTimeOrNil(info.LastFetched), nil
 }
 
+func (r *repositoryMirrorInfoResolver) CorruptionCount(ctx context.Context) (int32, error) {
+	gr, err := r.gitserverRepoRow(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int32(gr.CorruptionCount), nil
+}
+
+func (r *repositoryMirrorInfoResolver) CorruptedAt(ctx context.Context) (*DateTime, error) {
+	gr, err := r.gitserverRepoRow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if gr.CorruptedAt.IsZero() {
+		return nil, nil
+	}
+	return &DateTime{Time: gr.CorruptedAt}, nil
+}
+
+func (r *repositoryMirrorInfoResolver) LastIntegrityCheckAt(ctx context.Context) (*DateTime, error) {
+	gr, err := r.gitserverRepoRow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if gr.LastIntegrityCheckAt.IsZero() {
+		return nil, nil
+	}
+	return &DateTime{Time: gr.LastIntegrityCheckAt}, nil
+}
+
+func (r *repositoryMirrorInfoResolver) LastIntegrityCheckError(ctx context.Context) (*string, error) {
+	// 🚨 SECURITY: The output of git fsck contains paths on gitserver, so only
+	// allow site admins to see it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	gr, err := r.gitserverRepoRow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if gr.LastIntegrityCheckError == "" {
+		return nil, nil
+	}
+	return &gr.LastIntegrityCheckError, nil
+}
+
 func (r *repositoryMirrorInfoResolver) UpdateSchedule(ctx context.Context) (*updateScheduleResolver, error) {
 	info, err := r.repoUpdateSchedulerInfo(ctx)
 	if err != nil {
@@ -1087,8 +1087,11 @@ func (r *searchResolver) resultsRecursive(ctx context.Context, plan query.Plan)
 		r.invalidateRepoCache = true
 	}
 
+	// The plan determines its own limit rather than the top-level query, so
+	// that predicate subqueries are not truncated to the default count of the
+	// query that contains them.
 	wantCount := defaultMaxSearchResults
-	if count := r.Query.Count(); count != nil {
+	if count := plan.ToParseTree().Count(); count != nil {
 		wantCount = *count
 	}
 
@@ -1130,6 +1133,7 @@ func (r *searchResolver) resultsRecursive(ctx context.Context, plan query.Plan)
 			sr = union(sr, newResult)
 			if len(sr.Matches) > wantCount {
 				sr.Matches = sr.Matches[:wantCount]
+				sr.Stats.IsLimitHit = true
 				break
 			}
 		}
@@ -1142,18 +1146,30 @@ func (r *searchResolver) resultsRecursive(ctx context.Context, plan query.Plan)
 }
 
 // searchResultsToRepoNodes converts a set of search results into repository nodes
-// such that they can be used to replace a repository predicate
+// such that they can be used to replace a repository predicate. File matches,
+// such as those produced by symbol predicates, contribute their repository.
 func searchResultsToRepoNodes(matches []result.Match) ([]query.Node, error) {
 	nodes := make([]query.Node, 0, len(matches))
+	seen := make(map[api.RepoName]struct{}, len(matches))
 	for _, match := range matches {
-		repoMatch, ok := match.(*result.RepoMatch)
-		if !ok {
+		var name api.RepoName
+		switch m := match.(type) {
+		case *result.RepoMatch:
+			name = m.Name
+		case *result.FileMatch:
+			name = m.Repo.Name
+		default:
 			return nil, errors.Errorf("expected type %T, but got %T", &result.RepoMatch{}, match)
 		}
 
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
 		nodes = append(nodes, query.Parameter{
 			Field: query.FieldRepo,
-			Value: "^" + regexp.QuoteMeta(string(repoMatch.Name)) + "$",
+			Value: "^" + regexp.QuoteMeta(string(name)) + "$",
 		})
 	}
 
@@ -1190,6 +1206,69 @@ func searchResultsToFileNodes(matches []result.Match) ([]query.Node, error) {
 	return nodes, nil
 }
 
+// searchResultsToExcludedRepoNodes converts a set of search results into
+// negated repository nodes such that they can be used to replace a negated
+// repository predicate. The returned nodes are meant to be conjoined with the
+// parent query, subtracting the matched repositories from its scope.
+func searchResultsToExcludedRepoNodes(matches []result.Match) ([]query.Node, error) {
+	nodes, err := searchResultsToRepoNodes(matches)
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		p := node.(query.Parameter)
+		p.Negated = true
+		nodes[i] = p
+	}
+	return nodes, nil
+}
+
+// searchResultsToExcludedFileNodes converts a set of search results into
+// repo/file nodes that exclude the matched files such that they can replace a
+// negated file predicate. Since a file path can only be excluded within the
+// repository that matched it, the result is a disjunction: for each matched
+// repository, search that repository except for the matched paths, or search
+// any repository that did not match at all.
+func searchResultsToExcludedFileNodes(matches []result.Match) ([]query.Node, error) {
+	pathsByRepo := make(map[api.RepoName][]string)
+	for _, match := range matches {
+		fileMatch, ok := match.(*result.FileMatch)
+		if !ok {
+			return nil, errors.Errorf("expected type %T, but got %T", &result.FileMatch{}, match)
+		}
+		pathsByRepo[fileMatch.Repo.Name] = append(pathsByRepo[fileMatch.Repo.Name], fileMatch.Path)
+	}
+
+	repos := make([]api.RepoName, 0, len(pathsByRepo))
+	for repo := range pathsByRepo {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i] < repos[j] })
+
+	nodes := make([]query.Node, 0, len(repos)+1)
+	otherRepos := make([]query.Node, 0, len(repos))
+	for _, repo := range repos {
+		repoValue := "^" + regexp.QuoteMeta(string(repo)) + "$"
+		operands := []query.Node{query.Parameter{Field: query.FieldRepo, Value: repoValue}}
+		for _, path := range pathsByRepo[repo] {
+			operands = append(operands, query.Parameter{
+				Field:   query.FieldFile,
+				Value:   "^" + regexp.QuoteMeta(path) + "$",
+				Negated: true,
+			})
+		}
+		nodes = append(nodes, query.Operator{Kind: query.And, Operands: operands})
+		otherRepos = append(otherRepos, query.Parameter{Field: query.FieldRepo, Value: repoValue, Negated: true})
+	}
+
+	if len(otherRepos) == 1 {
+		nodes = append(nodes, otherRepos[0])
+	} else if len(otherRepos) > 1 {
+		nodes = append(nodes, query.Operator{Kind: query.And, Operands: otherRepos})
+	}
+	return nodes, nil
+}
+
 // resultsWithTimeoutSuggestion calls doResults, and in case of deadline
 // exceeded returns a search alert with a did-you-mean link for the same
 // query with a longer timeout.
@@ -1216,7 +1295,8 @@ func (r *searchResolver) resultsWithTimeoutSuggestion(ctx context.Context, args
 }
 
 // substitutePredicates replaces all the predicates in a query with their expanded form. The predicates
-// are expanded using the doExpand function.
+// are expanded using the doExpand function. Negated predicates expand to
+// nodes that exclude their results from the query.
 func substitutePredicates(q query.Basic, evaluate func(query.Predicate) (*SearchResults, error)) (query.Plan, error) {
 	var topErr error
 	success := false
@@ -1245,16 +1325,65 @@ func substitutePredicates(q query.Basic, evaluate func(query.Predicate) (*Search
 			return nil
 		}
 
+		var matches []result.Match
+		if srr != nil {
+			matches = srr.Matches
+		}
+
+		if neg {
+			// A negated predicate that matches nothing excludes nothing, so
+			// it is simply dropped from the query.
+			success = true
+			if len(matches) == 0 {
+				return nil
+			}
+
+			// Excluding only some of the matches would silently return
+			// results the user asked to exclude.
+			if srr.Stats.IsLimitHit {
+				topErr = &ErrPredicateLimitHit{Predicate: "-" + field + ":" + value, Limit: len(matches)}
+				return nil
+			}
+
+			var nodes []query.Node
+			switch predicate.Field() {
+			case query.FieldRepo:
+				nodes, err = searchResultsToExcludedRepoNodes(matches)
+				if err != nil {
+					topErr = err
+					return nil
+				}
+				// Every excluded repository must be excluded.
+				return query.Operator{
+					Kind:     query.And,
+					Operands: nodes,
+				}
+			case query.FieldFile:
+				nodes, err = searchResultsToExcludedFileNodes(matches)
+				if err != nil {
+					topErr = err
+					return nil
+				}
+				return query.Operator{
+					Kind:     query.Or,
+					Operands: nodes,
+				}
+			default:
+				topErr = errors.Errorf("unsupported predicate result type %q", predicate.Field())
+				return nil
+			}
+		}
+
 		var nodes []query.Node
 		switch predicate.Field() {
 		case query.FieldRepo:
-			nodes, err = searchResultsToRepoNodes(srr.Matches)
+			nodes, err = searchResultsToRepoNodes(matches)
 			if err != nil {
 				topErr = err
 				return nil
 			}
 		case query.FieldFile:
-			nodes, err = searchResultsToFileNodes(srr.Matches)
+			nodes, err = searchResultsToFileNodes(matches)
 			if err != nil {
 				topErr = err
 				return nil
@@ -1298,6 +1427,18 @@ func substitutePredicates(q query.Basic, evaluate func(query.Predicate) (*Search
 
 var ErrPredicateNoResults = errors.New("no results returned for predicate")
 
+// ErrPredicateLimitHit is returned when a negated predicate matches more
+// results than its subquery returns, such that the exclusion it expands to
+// would be incomplete.
+type ErrPredicateLimitHit struct {
+	Predicate string
+	Limit     int
+}
+
+func (e *ErrPredicateLimitHit) Error() string {
+	return fmt.Sprintf("negated predicate %s matched more than %d results, which is too many to exclude. Narrow the scope of the search with repo: filters", e.Predicate, e.Limit)
+}
+
 // longer returns a suggested longer time to wait if the given duration wasn't long enough.
 func longer(n int, dt time.Duration) time.Duration {
 	dt2 := func() time.Duration {
@@ -1098,3 +1098,85 @@ func TestIsContextError(t *testing.T) {
 		})
 	}
 }
+
+func TestSubstitutePredicates(t *testing.T) {
+	fileMatch := func(repo, path string) result.Match {
+		return &result.FileMatch{File: result.File{Repo: types.RepoName{Name: api.RepoName(repo)}, Path: path}}
+	}
+
+	test := func(input string, matches []result.Match, limitHit bool) string {
+		plan, err := query.Pipeline(query.InitLiteral(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := substitutePredicates(plan[0], func(query.Predicate) (*SearchResults, error) {
+			return &SearchResults{Matches: matches, Stats: streaming.Stats{IsLimitHit: limitHit}}, nil
+		})
+		if err != nil {
+			return err.Error()
+		}
+		out := make([]string, 0, len(got))
+		for _, basic := range got {
+			out = append(out, basic.StringHuman())
+		}
+		return strings.Join(out, "\n")
+	}
+
+	cases := []struct {
+		name     string
+		input    string
+		matches  []result.Match
+		limitHit bool
+		want     string
+	}{{
+		name:    "repo predicate",
+		input:   "repo:contains.file(CODEOWNERS) foo",
+		matches: []result.Match{&result.RepoMatch{Name: "a"}, &result.RepoMatch{Name: "b"}},
+		want:    "repo:^a$ foo\nrepo:^b$ foo",
+	}, {
+		name:    "negated repo predicate",
+		input:   "-repo:contains.file(CODEOWNERS) foo",
+		matches: []result.Match{&result.RepoMatch{Name: "a"}, &result.RepoMatch{Name: "b"}},
+		want:    "-repo:^a$ -repo:^b$ foo",
+	}, {
+		name:    "negated repo predicate without results",
+		input:   "repo:x -repo:contains.file(CODEOWNERS) foo",
+		matches: nil,
+		want:    "repo:x foo",
+	}, {
+		name:    "negated symbol repo predicate deduplicates repos",
+		input:   "-repo:contains.symbol(Server) foo",
+		matches: []result.Match{fileMatch("a", "x.go"), fileMatch("a", "y.go")},
+		want:    "-repo:^a$ foo",
+	}, {
+		name:    "negated file predicate",
+		input:   "-file:contains(Copyright) foo",
+		matches: []result.Match{fileMatch("b", "x.go"), fileMatch("a", "x.go"), fileMatch("a", "y.go")},
+		want:    "repo:^a$ -file:^x\\.go$ -file:^y\\.go$ foo\nrepo:^b$ -file:^x\\.go$ foo\n-repo:^a$ -repo:^b$ foo",
+	}, {
+		name:    "negated file predicate rejects repo matches",
+		input:   "-file:contains(Copyright) foo",
+		matches: []result.Match{&result.RepoMatch{Name: "a"}},
+		want:    "expected type *result.FileMatch, but got *result.RepoMatch",
+	}, {
+		name:     "negated predicate with incomplete results",
+		input:    "-repo:contains.file(CODEOWNERS) foo",
+		matches:  []result.Match{&result.RepoMatch{Name: "a"}},
+		limitHit: true,
+		want:     "negated predicate -repo:contains.file(CODEOWNERS) matched more than 1 results, which is too many to exclude. Narrow the scope of the search with repo: filters",
+	}, {
+		name:     "repo predicate with incomplete results",
+		input:    "repo:contains.file(CODEOWNERS) foo",
+		matches:  []result.Match{&result.RepoMatch{Name: "a"}},
+		limitHit: true,
+		want:     "repo:^a$ foo",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := test(tc.input, tc.matches, tc.limitHit); got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/compute"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// ComputeStreamHandler is an http handler which streams back the results of a
+// compute query. Results of the command of the query are sent in "results"
+// events, except for the histograms of count commands, which are merged and
+// sent in "histogram" events whenever they changed since the last flush.
+// The progress of the underlying search, including whether it hit its result
+// limit, is sent in "progress" events, and its alert in an "alert" event.
+func ComputeStreamHandler(db dbutil.DB) http.Handler {
+	return &computeStreamHandler{streamHandler{
+		db:                  db,
+		newSearchResolver:   defaultNewSearchResolver,
+		flushTickerInternal: 100 * time.Millisecond,
+	}}
+}
+
+type computeStreamHandler struct {
+	streamHandler
+}
+
+// computeEventResult is a result of a compute command in a "results" event.
+// Path is empty for the results of commit and diff matches.
+type computeEventResult struct {
+	Repository string         `json:"repository"`
+	Commit     string         `json:"commit"`
+	Path       string         `json:"path"`
+	Result     compute.Result `json:"result"`
+}
+
+func (h *computeStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		http.Error(w, "no query found", http.StatusBadRequest)
+		return
+	}
+	computeQuery, err := compute.Parse(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	searchQuery, err := computeQuery.ToSearchQuery()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tr, ctx := trace.New(ctx, "compute.ServeStream", q)
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	eventWriter, err := streamhttp.NewWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Always send a final done event so clients know the stream is shutting
+	// down.
+	defer eventWriter.Event("done", map[string]interface{}{})
+
+	events, inputs, results := h.startSearch(ctx, &args{
+		Query:       searchQuery,
+		Version:     "V2",
+		PatternType: "regexp",
+	})
+
+	// Commands run on every result of the search, so the display limit is
+	// the result limit.
+	progress := progressAggregator{
+		Start:        time.Now(),
+		Limit:        inputs.MaxResults(),
+		Trace:        trace.URL(trace.ID(ctx)),
+		DisplayLimit: inputs.MaxResults(),
+	}
+
+	resultsBuf := streamhttp.NewJSONArrayBuf(32*1024, func(data []byte) error {
+		return eventWriter.EventBytes("results", data)
+	})
+	histogram := compute.NewHistogramAggregator()
+	flush := func() {
+		if err := resultsBuf.Flush(); err != nil {
+			// EOF
+			return
+		}
+		if histogram.Dirty() {
+			_ = eventWriter.Event("histogram", histogram.Histogram())
+		}
+		if progress.Dirty {
+			_ = eventWriter.Event("progress", progress.Current())
+		}
+	}
+
+	flushTicker := time.NewTicker(h.flushTickerInternal)
+	defer flushTicker.Stop()
+
+	handleEvent := func(event streaming.SearchEvent) {
+		progress.Update(event)
+
+		repoMetadata, err := getEventRepoMetadata(ctx, h.db, event)
+		if err != nil {
+			log15.Error("failed to get repo metadata", "error", err)
+			return
+		}
+		for _, match := range event.Results {
+			// Don't compute results for matches which we cannot map to a
+			// repo the actor has access to, see streamHandler.
+			if md, ok := repoMetadata[match.RepoName().ID]; !ok || md.Name != match.RepoName().Name {
+				continue
+			}
+			var commit, path string
+			switch m := match.(type) {
+			case *result.FileMatch:
+				commit, path = string(m.CommitID), m.Path
+			case *result.CommitMatch:
+				commit = string(m.Commit.ID)
+			default:
+				continue
+			}
+			r, err := computeQuery.Command.Run(ctx, match)
+			if err != nil {
+				log15.Warn("failed to run compute command", "repo", match.RepoName().Name, "commit", commit, "path", path, "error", err)
+				continue
+			}
+			if matchHistogram, ok := r.(*compute.Histogram); ok {
+				histogram.Add(matchHistogram)
+				continue
+			}
+			_ = resultsBuf.Append(&computeEventResult{
+				Repository: string(match.RepoName().Name),
+				Commit:     commit,
+				Path:       path,
+				Result:     r,
+			})
+		}
+	}
+
+LOOP:
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				break LOOP
+			}
+			handleEvent(event)
+		case <-flushTicker.C:
+			flush()
+		}
+	}
+
+	flush()
+
+	resultsResolver, err := results()
+	if err != nil {
+		_ = eventWriter.Event("error", streamhttp.EventError{Message: err.Error()})
+		return
+	}
+
+	if eventAlert := fromAlert(resultsResolver); eventAlert != nil {
+		_ = eventWriter.Event("alert", eventAlert)
+	}
+
+	_ = eventWriter.Event("progress", progress.Final())
+}
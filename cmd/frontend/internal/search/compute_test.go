@@ -0,0 +1,137 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	api2 "github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/compute"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/run"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming/api"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestServeComputeStream_count(t *testing.T) {
+	mock := &mockSearchResolver{
+		done: make(chan struct{}),
+		// The search hits its limit with the second event.
+		inputs: &run.SearchInputs{DefaultLimit: 2},
+	}
+
+	database.Mocks.Repos.Metadata = func(ctx context.Context, ids ...api2.RepoID) (_ []*types.SearchedRepo, err error) {
+		res := make([]*types.SearchedRepo, 0, len(ids))
+		for _, id := range ids {
+			res = append(res, &types.SearchedRepo{
+				ID:   id,
+				Name: "repo",
+			})
+		}
+		return res, nil
+	}
+	defer func() { database.Mocks.Repos.Metadata = nil }()
+
+	fileMatch := func(path string, lines ...string) *result.FileMatch {
+		fm := &result.FileMatch{File: result.File{Repo: types.RepoName{ID: 1, Name: "repo"}, Path: path}}
+		for i, l := range lines {
+			fm.LineMatches = append(fm.LineMatches, &result.LineMatch{Preview: l, LineNumber: int32(i)})
+		}
+		return fm
+	}
+
+	ts := httptest.NewServer(&computeStreamHandler{streamHandler{
+		flushTickerInternal: 1 * time.Millisecond,
+		newSearchResolver: func(_ context.Context, _ dbutil.DB, args *graphqlbackend.SearchArgs) (searchResolver, error) {
+			go func() {
+				args.Stream.Send(streaming.SearchEvent{
+					Results: []result.Match{fileMatch("package.json", `"lodash": "4.17.20"`)},
+				})
+				args.Stream.Send(streaming.SearchEvent{
+					Results: []result.Match{
+						fileMatch("a/package.json", `"lodash": "4.17.21"`),
+						fileMatch("b/package.json", `"lodash": "4.17.21"`),
+					},
+				})
+				mock.Close()
+			}()
+			return mock, nil
+		}}})
+	defer ts.Close()
+
+	q := `content:count("lodash": "([\d.]+)" -> by: $1)`
+	res, err := http.Get(ts.URL + "?q=" + url.QueryEscape(q))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var histogram compute.Histogram
+	var progress *api.Progress
+	err = streamhttp.FrontendStreamDecoder{
+		OnProgress: func(p *api.Progress) {
+			progress = p
+		},
+		OnUnknown: func(event, data []byte) {
+			if string(event) == "histogram" {
+				histogram = compute.Histogram{}
+				_ = json.Unmarshal(data, &histogram)
+			}
+		},
+	}.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, b := range histogram.Buckets {
+		got = append(got, b.Value)
+		if b.Value == "4.17.21" && b.Count != 2 {
+			t.Errorf("expected a count of 2 for 4.17.21, got %d", b.Count)
+		}
+	}
+	if diff := cmp.Diff([]string{"4.17.21", "4.17.20"}, got); diff != "" {
+		t.Errorf("unexpected histogram values (-want +got):\n%s", diff)
+	}
+
+	if progress == nil || !progress.Done {
+		t.Fatalf("expected a final progress event, got %+v", progress)
+	}
+	var limitHit bool
+	for _, skipped := range progress.Skipped {
+		if skipped.Reason == api.ShardMatchLimit {
+			limitHit = true
+		}
+	}
+	if !limitHit {
+		t.Errorf("expected the final progress event to report the result limit, got %+v", progress.Skipped)
+	}
+}
+
+func TestServeComputeStream_invalid(t *testing.T) {
+	ts := httptest.NewServer(ComputeStreamHandler(nil))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "?q=" + url.QueryEscape(`content:count(a and b)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", res.StatusCode)
+	}
+}
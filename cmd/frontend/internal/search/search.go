@@ -227,21 +227,8 @@ LOOP:
 	}
 
 	alert := resultsResolver.Alert()
-	if alert != nil {
-		var pqs []streamhttp.ProposedQuery
-		if proposed := alert.ProposedQueries(); proposed != nil {
-			for _, pq := range *proposed {
-				pqs = append(pqs, streamhttp.ProposedQuery{
-					Description: fromStrPtr(pq.Description()),
-					Query:       pq.Query(),
-				})
-			}
-		}
-		_ = eventWriter.Event("alert", streamhttp.EventAlert{
-			Title:           alert.Title(),
-			Description:     fromStrPtr(alert.Description()),
-			ProposedQueries: pqs,
-		})
+	if eventAlert := fromAlert(resultsResolver); eventAlert != nil {
+		_ = eventWriter.Event("alert", eventAlert)
 	}
 
 	_ = eventWriter.Event("progress", progress.Final())
@@ -275,6 +262,30 @@ LOOP:
 	}
 }
 
+// fromAlert returns the alert event for the alert of a search, or nil if the
+// search has no alert.
+func fromAlert(resultsResolver *graphqlbackend.SearchResultsResolver) *streamhttp.EventAlert {
+	alert := resultsResolver.Alert()
+	if alert == nil {
+		return nil
+	}
+
+	var pqs []streamhttp.ProposedQuery
+	if proposed := alert.ProposedQueries(); proposed != nil {
+		for _, pq := range *proposed {
+			pqs = append(pqs, streamhttp.ProposedQuery{
+				Description: fromStrPtr(pq.Description()),
+				Query:       pq.Query(),
+			})
+		}
+	}
+	return &streamhttp.EventAlert{
+		Title:           alert.Title(),
+		Description:     fromStrPtr(alert.Description()),
+		ProposedQueries: pqs,
+	}
+}
+
 // startSearch will start a search. It returns the events channel which
 // streams out search events. Once events is closed you can call results which
 // will return the results resolver and error.
@@ -99,6 +99,11 @@ func main() {
 		log.Fatalf("failed to initialise keyring: %s", err)
 	}
 
+	repoStatsCache, err := server.NewRepoStatsCache()
+	if err != nil {
+		log.Fatalf("failed to create repository statistics cache: %s", err)
+	}
+
 	gitserver := server.Server{
 		ReposDir:           reposDir,
 		DesiredPercentFree: wantPctFree2,
@@ -178,9 +183,12 @@ func main() {
 			}
 			return &server.GitRepoSyncer{}, nil
 		},
-		Hostname:   hostname.Get(),
-		DB:         db,
-		CloneQueue: server.NewCloneQueue(list.New()),
+		Hostname:       hostname.Get(),
+		DB:             db,
+		CloneQueue:     server.NewCloneQueue(list.New()),
+		Prewarm:        prewarm,
+		IsIndexed:      isIndexed,
+		RepoStatsCache: repoStatsCache,
 	}
 	gitserver.RegisterMetrics()
 
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/zoekt/query"
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/searcher"
+	"github.com/sourcegraph/sourcegraph/internal/symbols"
+)
+
+// prewarm asks searcher and the symbols service to prepare the archive and
+// the symbols of repo at head, so that the first unindexed search of head does
+// not wait for them.
+func prewarm(ctx context.Context, repo api.RepoName, head api.CommitID) error {
+	var err error
+	if err1 := searcher.Prewarm(ctx, search.SearcherURLs(), repo, head); err1 != nil {
+		err = multierror.Append(err, err1)
+	}
+	if err1 := symbols.DefaultClient.Prewarm(ctx, repo, head); err1 != nil {
+		err = multierror.Append(err, err1)
+	}
+	return err
+}
+
+// isIndexed reports whether the default branch of repo is indexed by indexed
+// search, regardless of which commit is indexed.
+func isIndexed(ctx context.Context, repo api.RepoName) (bool, error) {
+	zoekt := search.Indexed()
+	if zoekt == nil {
+		// Indexed search is disabled.
+		return false, nil
+	}
+
+	list, err := zoekt.List(ctx, query.NewRepoSet(string(repo)), nil)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range list.Repos {
+		for _, branch := range entry.Repository.Branches {
+			if branch.Name == "HEAD" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,291 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+func (s *Server) handleBlame(w http.ResponseWriter, r *http.Request) {
+	var req protocol.BlameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.blame(w, r, &req)
+}
+
+// blame streams the hunks of git blame as "hunks" events while git produces
+// them, followed by a "done" event. Canceling the request stops git blame.
+func (s *Server) blame(w http.ResponseWriter, r *http.Request, req *protocol.BlameRequest) {
+	tr, ctx := trace.New(r.Context(), "blame", string(req.Repo))
+	tr.LogFields(
+		otlog.String("commit", string(req.Commit)),
+		otlog.String("path", req.Path),
+	)
+	defer tr.Finish()
+
+	req.Repo = protocol.NormalizeRepo(req.Repo)
+
+	eventWriter, err := streamhttp.NewWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hunksBuf := streamhttp.NewJSONArrayBuf(8*1024, func(data []byte) error {
+		return eventWriter.EventBytes("hunks", data)
+	})
+
+	g, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hunkChan := make(chan protocol.BlameHunk, 128)
+	g.Go(func() error {
+		defer close(hunkChan)
+		done := ctx.Done()
+
+		return s.blameFile(ctx, req, func(hunk protocol.BlameHunk) {
+			select {
+			case <-done:
+			case hunkChan <- hunk:
+			}
+		})
+	})
+
+	// Write hunks to the stream, flushing occasionally
+	g.Go(func() error {
+		defer cancel()
+		defer hunksBuf.Flush()
+
+		flushTicker := time.NewTicker(50 * time.Millisecond)
+		defer flushTicker.Stop()
+
+		firstHunk := true
+		for {
+			select {
+			case hunk, ok := <-hunkChan:
+				if !ok {
+					return nil
+				}
+
+				_ = hunksBuf.Append(hunk) // EOF only
+
+				// Send immediately if this is the first hunk we've seen
+				if firstHunk {
+					_ = hunksBuf.Flush() // EOF only
+					firstHunk = false
+				}
+			case <-flushTicker.C:
+				_ = hunksBuf.Flush() // EOF only
+			}
+		}
+	})
+
+	err = g.Wait()
+	if err != nil {
+		tr.SetError(err)
+	}
+	if err := eventWriter.Event("done", protocol.NewBlameEventDone(err)); err != nil {
+		log15.Warn("failed to send done event", "error", err)
+	}
+}
+
+// blameFile runs git blame --incremental for the requested file and calls
+// onHunk with each hunk as soon as git outputs it.
+func (s *Server) blameFile(ctx context.Context, req *protocol.BlameRequest, onHunk func(protocol.BlameHunk)) error {
+	commit := string(req.Commit)
+	if commit == "" {
+		commit = "HEAD"
+	}
+	if strings.HasPrefix(commit, "-") {
+		return errors.Errorf("invalid git revision spec %q (begins with '-')", commit)
+	}
+
+	dir := s.dir(req.Repo)
+	if !repoCloned(dir) {
+		return &gitdomain.RepoNotExistError{Repo: req.Repo}
+	}
+	if !conf.Get().DisableAutoGitUpdates {
+		_ = s.ensureRevision(ctx, req.Repo, commit, dir)
+	}
+
+	// git blame --incremental does not output the contents of lines, so we
+	// read the file to compute the byte offsets of hunks.
+	content, err := gitOutput(ctx, dir, "cat-file", "blob", commit+":"+req.Path)
+	if err != nil {
+		return err
+	}
+	offsets := lineOffsets(content)
+
+	args := []string{"blame", "-w", "--incremental"}
+	if req.StartLine != 0 || req.EndLine != 0 {
+		args = append(args, fmt.Sprintf("-L%d,%d", req.StartLine, req.EndLine))
+	}
+	args = append(args, commit, "--", req.Path)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	dir.Set(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	parseErr := parseIncrementalBlame(stdout, func(hunk protocol.BlameHunk) {
+		hunk.StartByte = offsets.at(hunk.StartLine)
+		hunk.EndByte = offsets.at(hunk.EndLine)
+		onHunk(hunk)
+	})
+	if parseErr != nil {
+		// Stop git blame so that Wait does not block on a full pipe.
+		_ = cmd.Process.Kill()
+	}
+	if err := cmd.Wait(); err != nil && parseErr == nil {
+		return errors.Wrapf(err, "git blame failed (stderr: %q)", stderr.String())
+	}
+	return parseErr
+}
+
+// gitOutput runs a git command in dir and returns its stdout.
+func gitOutput(ctx context.Context, dir GitDir, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	dir.Set(cmd)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "git %s failed (stderr: %q)", args[0], stderr.String())
+	}
+	return out, nil
+}
+
+// blameLineOffsets are the byte offsets at which the lines of a file start,
+// followed by the length of the file.
+type blameLineOffsets []int
+
+func lineOffsets(content []byte) blameLineOffsets {
+	offsets := blameLineOffsets{0}
+	for i, b := range content {
+		if b == '\n' && i+1 < len(content) {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return append(offsets, len(content))
+}
+
+// at returns the byte offset at which the 1-indexed line starts, or the length
+// of the file if the line is past its end.
+func (o blameLineOffsets) at(line int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(o) {
+		return o[len(o)-1]
+	}
+	return o[line-1]
+}
+
+// parseIncrementalBlame parses the output of git blame --incremental, calling
+// onHunk for each hunk. The output consists of one entry per hunk: a header line
+// "<commit> <original line> <final line> <number of lines>", followed by the
+// details of the commit the first time it is seen, and ends with a "filename"
+// line. StartByte and EndByte are left unset.
+func parseIncrementalBlame(r io.Reader, onHunk func(protocol.BlameHunk)) error {
+	// commits holds the details of the commits seen so far, which git does
+	// not repeat.
+	commits := make(map[api.CommitID]protocol.BlameHunk)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+
+	var hunk *protocol.BlameHunk
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if hunk == nil {
+			fields := strings.Split(line, " ")
+			if len(fields) != 4 {
+				return errors.Errorf("invalid blame hunk header %q", line)
+			}
+			startLine, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return errors.Errorf("invalid blame hunk header %q", line)
+			}
+			numLines, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return errors.Errorf("invalid blame hunk header %q", line)
+			}
+
+			hunk = &protocol.BlameHunk{
+				CommitID:  api.CommitID(fields[0]),
+				StartLine: startLine,
+				EndLine:   startLine + numLines,
+			}
+			if commit, ok := commits[hunk.CommitID]; ok {
+				hunk.Author = commit.Author
+				hunk.Message = commit.Message
+			}
+			continue
+		}
+
+		key, value := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			key, value = line[:i], line[i+1:]
+		}
+		switch key {
+		case "author":
+			hunk.Author.Name = value
+		case "author-mail":
+			hunk.Author.Email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			authorTime, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.Errorf("failed to parse author-time %q", value)
+			}
+			hunk.Author.Date = time.Unix(authorTime, 0).UTC()
+		case "summary":
+			hunk.Message = value
+		case "filename":
+			hunk.Filename = value
+			if _, ok := commits[hunk.CommitID]; !ok {
+				commits[hunk.CommitID] = protocol.BlameHunk{Author: hunk.Author, Message: hunk.Message}
+			}
+			onHunk(*hunk)
+			hunk = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if hunk != nil {
+		return errors.Errorf("unexpected end of blame output in hunk of commit %s", hunk.CommitID)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestParseIncrementalBlame(t *testing.T) {
+	output := `e6e8d6b0c8e5ff4c6ba0f7a9a2d4a1b8d5e0a3c1 2 2 1
+author b
+author-mail <b@b.com>
+author-time 1600000000
+author-tz +0000
+committer b
+committer-mail <b@b.com>
+committer-time 1600000000
+committer-tz +0000
+summary second
+previous 0d2e6f7a0b6c8e1b3f9a7c5e2d4b6a8c0e1f3a5b file
+filename file
+0d2e6f7a0b6c8e1b3f9a7c5e2d4b6a8c0e1f3a5b 1 1 1
+author a
+author-mail <a@a.com>
+author-time 1500000000
+author-tz +0000
+committer a
+committer-mail <a@a.com>
+committer-time 1500000000
+committer-tz +0000
+summary first
+boundary
+filename file
+0d2e6f7a0b6c8e1b3f9a7c5e2d4b6a8c0e1f3a5b 3 3 2
+filename file
+`
+
+	var hunks []protocol.BlameHunk
+	err := parseIncrementalBlame(strings.NewReader(output), func(hunk protocol.BlameHunk) {
+		hunks = append(hunks, hunk)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := protocol.Signature{Name: "a", Email: "a@a.com", Date: time.Unix(1500000000, 0).UTC()}
+	second := protocol.Signature{Name: "b", Email: "b@b.com", Date: time.Unix(1600000000, 0).UTC()}
+	want := []protocol.BlameHunk{
+		{StartLine: 2, EndLine: 3, CommitID: "e6e8d6b0c8e5ff4c6ba0f7a9a2d4a1b8d5e0a3c1", Author: second, Message: "second", Filename: "file"},
+		{StartLine: 1, EndLine: 2, CommitID: "0d2e6f7a0b6c8e1b3f9a7c5e2d4b6a8c0e1f3a5b", Author: first, Message: "first", Filename: "file"},
+		{StartLine: 3, EndLine: 5, CommitID: "0d2e6f7a0b6c8e1b3f9a7c5e2d4b6a8c0e1f3a5b", Author: first, Message: "first", Filename: "file"},
+	}
+	if diff := cmp.Diff(want, hunks); diff != "" {
+		t.Errorf("unexpected hunks (-want +got):\n%s", diff)
+	}
+
+	if err := parseIncrementalBlame(strings.NewReader("abc 1 1\n"), func(protocol.BlameHunk) {}); err == nil {
+		t.Error("expected error for invalid header")
+	}
+}
+
+func TestHandleBlame(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{DisableAutoGitUpdates: true}})
+	defer conf.Mock(nil)
+
+	reposDir := t.TempDir()
+	repoDir := filepath.Join(reposDir, "example.com/repo")
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, repoDir, name, arg...)
+	}
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd("git", "init", ".")
+	cmd("sh", "-c", "printf 'a\\nb\\n' > file")
+	cmd("git", "add", "file")
+	cmd("git", "commit", "-m", "first")
+	cmd("sh", "-c", "printf 'a\\nchanged\\nc\\n' > file")
+	cmd("git", "commit", "-am", "second")
+	head := strings.TrimSpace(cmd("git", "rev-parse", "HEAD"))
+
+	s := &Server{ReposDir: reposDir}
+
+	blame := func(t *testing.T, req string) ([]protocol.BlameHunk, error) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		s.handleBlame(w, httptest.NewRequest("POST", "/blame", strings.NewReader(req)))
+
+		var (
+			hunks []protocol.BlameHunk
+			done  protocol.BlameEventDone
+		)
+		dec := gitserver.StreamBlameDecoder{
+			OnHunks: func(e protocol.BlameEventHunks) { hunks = append(hunks, e...) },
+			OnDone:  func(e protocol.BlameEventDone) { done = e },
+		}
+		if err := dec.ReadAll(w.Body); err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(hunks, func(i, j int) bool { return hunks[i].StartLine < hunks[j].StartLine })
+		return hunks, done.Err()
+	}
+
+	t.Run("streams hunks", func(t *testing.T) {
+		hunks, err := blame(t, `{"Repo": "example.com/repo", "Path": "file"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		type hunk struct {
+			StartLine, EndLine, StartByte, EndByte int
+			Message                                string
+		}
+		var got []hunk
+		for _, h := range hunks {
+			got = append(got, hunk{h.StartLine, h.EndLine, h.StartByte, h.EndByte, h.Message})
+			if h.Message == "second" && string(h.CommitID) != head {
+				t.Errorf("got commit %s, want %s", h.CommitID, head)
+			}
+		}
+		want := []hunk{
+			{StartLine: 1, EndLine: 2, StartByte: 0, EndByte: 2, Message: "first"},
+			{StartLine: 2, EndLine: 4, StartByte: 2, EndByte: 12, Message: "second"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected hunks (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("line range", func(t *testing.T) {
+		hunks, err := blame(t, `{"Repo": "example.com/repo", "Path": "file", "StartLine": 1, "EndLine": 1}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hunks) != 1 || hunks[0].Message != "first" {
+			t.Errorf("unexpected hunks %+v", hunks)
+		}
+	})
+
+	t.Run("repo not cloned", func(t *testing.T) {
+		_, err := blame(t, `{"Repo": "example.com/missing", "Path": "file"}`)
+		if _, ok := err.(*gitdomain.RepoNotExistError); !ok {
+			t.Errorf("got error %v, want RepoNotExistError", err)
+		}
+	})
+}
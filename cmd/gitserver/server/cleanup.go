@@ -80,9 +80,11 @@ const reposStatsName = "repos-stats.json"
 // 3. Remove stale lock files.
 // 4. Ensure correct git attributes
 // 5. Scrub remote URLs
-// 6. Perform garbage collection
-// 7. Re-clone repos after a while. (simulate git gc)
-// 8. Remove repos based on disk pressure.
+// 6. Check the integrity of repos and repair corrupt repos
+// 7. Perform garbage collection
+// 8. Maintain commit-graphs, multi-pack-indexes and bitmaps
+// 9. Re-clone repos after a while. (simulate git gc)
+// 10. Remove repos based on disk pressure, see freeUpSpace.
 func (s *Server) cleanupRepos() {
 	janitorRunning.Set(1)
 	defer janitorRunning.Set(0)
@@ -94,8 +96,15 @@ func (s *Server) cleanupRepos() {
 		UpdatedAt: time.Now(),
 	}
 
+	// repoSize is the size of the repo being cleaned up, set by computeStats.
+	// repoSizes are the sizes of all repos, which freeUpSpace uses to decide
+	// which repos to evict.
+	var repoSize int64
+	repoSizes := make(map[GitDir]int64)
 	computeStats := func(dir GitDir) (done bool, err error) {
-		stats.GitDirBytes += dirSize(dir.Path("."))
+		repoSize = dirSize(dir.Path("."))
+		repoSizes[dir] = repoSize
+		stats.GitDirBytes += repoSize
 		return false, nil
 	}
 
@@ -127,6 +136,12 @@ func (s *Server) cleanupRepos() {
 		return false, nil
 	}
 
+	checkIntegrity := func(dir GitDir) (done bool, err error) {
+		// Re-cloning is the last resort to repair a corrupt repo, which we
+		// don't do if DisableAutoGitUpdates is set, see maybeReclone.
+		return s.maybeCheckIntegrity(bCtx, dir, !conf.Get().DisableAutoGitUpdates)
+	}
+
 	maybeReclone := func(dir GitDir) (done bool, err error) {
 		repoType, err := getRepositoryType(dir)
 		if err != nil {
@@ -140,12 +155,6 @@ func (s *Server) cleanupRepos() {
 
 		// Add a jitter to spread out re-cloning of repos cloned at the same time.
 		var reason string
-		const maybeCorrupt = "maybeCorrupt"
-		if maybeCorrupt, _ := gitConfigGet(dir, gitConfigMaybeCorrupt); maybeCorrupt != "" {
-			reason = maybeCorrupt
-			// unset flag to stop constantly re-cloning if it fails.
-			_ = gitConfigUnset(dir, gitConfigMaybeCorrupt)
-		}
 		if time.Since(recloneTime) > repoTTL+jitterDuration(string(dir), repoTTL/4) {
 			reason = "old"
 		}
@@ -157,8 +166,9 @@ func (s *Server) cleanupRepos() {
 
 		// We believe converting a Perforce depot to a Git repository is generally a
 		// very expensive operation, therefore we do not try to re-clone/redo the
-		// conversion only because it is old or slow to do "git gc".
-		if repoType == "perforce" && reason != maybeCorrupt {
+		// conversion only because it is old or slow to do "git gc". Corrupt repos
+		// are re-cloned by checkIntegrity.
+		if repoType == "perforce" {
 			reason = ""
 		}
 
@@ -227,6 +237,13 @@ func (s *Server) cleanupRepos() {
 		return false, gitGC(dir)
 	}
 
+	performMaintenance := func(dir GitDir) (done bool, err error) {
+		if !enableMaintenance {
+			return false, nil
+		}
+		return false, maintainRepo(bCtx, dir, repoSize)
+	}
+
 	type cleanupFn struct {
 		Name string
 		Do   func(GitDir) (bool, error)
@@ -244,12 +261,21 @@ func (s *Server) cleanupRepos() {
 		// 2021-03-01 (tomas,keegan) we used to store an authenticated remote URL on
 		// disk. We no longer need it so we can scrub it.
 		{"scrub remote URL", scrubRemoteURL},
+		// Check the integrity of repos periodically or when git reported errors
+		// indicating corruption, and repair them by refetching objects,
+		// rebuilding indexes, or re-cloning as a last resort.
+		{"check integrity", checkIntegrity},
 		// Runs a number of housekeeping tasks within the current repository, such as
 		// compressing file revisions (to reduce disk space and increase performance),
 		// removing unreachable objects which may have been created from prior
 		// invocations of git add, packing refs, pruning reflog, rerere metadata or stale
 		// working trees. May also update ancillary indexes such as the commit-graph.
 		{"garbage collect", performGC},
+		// Write the commit-graph with changed-path Bloom filters, the
+		// multi-pack-index and bitmaps, and repack geometrically, which speeds up
+		// history walks and object lookups on large repos. Each step runs at
+		// most once per interval, based on timestamps stored in the git config.
+		{"maintain", performMaintenance},
 	}
 
 	if !conf.Get().DisableAutoGitUpdates {
@@ -310,7 +336,7 @@ func (s *Server) cleanupRepos() {
 	if err != nil {
 		log15.Error("cleanup: ensuring free disk space", "error", err)
 	}
-	if err := s.freeUpSpace(b); err != nil {
+	if err := s.freeUpSpace(bCtx, b, repoSizes); err != nil {
 		log15.Error("cleanup: error freeing up space", "error", err)
 	}
 }
@@ -367,30 +393,54 @@ func (s *StatDiskSizer) DiskSizeBytes(mountPoint string) (uint64, error) {
 	return free, nil
 }
 
-// freeUpSpace removes git directories under ReposDir, in order from least
-// recently to most recently used, until it has freed howManyBytesToFree.
-func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
+// freeUpSpace removes git directories under ReposDir, in the order of the
+// configured eviction policy, until it has freed howManyBytesToFree. Pinned
+// repositories are never removed. Removed repositories are recorded as evicted,
+// so that they are not cloned again until they are requested. repoSizes are
+// the sizes of repositories computed by the janitor, which only order the
+// candidates for eviction; the sizes of repositories missing from it are
+// computed. As later janitor steps (gc, maintenance, re-clones) change the size
+// of a repository, the space freed is measured as each repository is removed.
+func (s *Server) freeUpSpace(ctx context.Context, howManyBytesToFree int64, repoSizes map[GitDir]int64) error {
 	if howManyBytesToFree <= 0 {
 		return nil
 	}
 
-	// Get the git directories and their mod times.
+	// Get the git directories and what the eviction policy needs to know
+	// about them.
 	gitDirs, err := s.findGitDirs()
 	if err != nil {
 		return errors.Wrap(err, "finding git dirs")
 	}
-	dirModTimes := make(map[GitDir]time.Time, len(gitDirs))
+	pinned := pinnedRepos()
+	now := time.Now()
+	candidates := make([]evictionCandidate, 0, len(gitDirs))
 	for _, d := range gitDirs {
+		if _, ok := pinned[s.name(d)]; ok {
+			continue
+		}
 		mt, err := gitDirModTime(d)
 		if err != nil {
 			return errors.Wrap(err, "computing mod time of git dir")
 		}
-		dirModTimes[d] = mt
+		size, ok := repoSizes[d]
+		if !ok {
+			size = dirSize(d.Path("."))
+		}
+		access := s.accesses.get(d)
+		candidates = append(candidates, evictionCandidate{
+			dir:          d,
+			size:         size,
+			lastFetched:  mt,
+			accesses:     access.scoreAt(now),
+			lastAccessed: access.last,
+		})
 	}
 
-	// Sort the repos from least to most recently used.
-	sort.Slice(gitDirs, func(i, j int) bool {
-		return dirModTimes[gitDirs[i]].Before(dirModTimes[gitDirs[j]])
+	// Sort the repos in the order they should be evicted.
+	policy := configuredEvictionPolicy()
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return policy(&candidates[i], &candidates[j])
 	})
 
 	// Remove repos until howManyBytesToFree is met or exceeded.
@@ -399,16 +449,17 @@ func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
 	if err != nil {
 		return errors.Wrap(err, "getting disk size")
 	}
-	for _, d := range gitDirs {
+	for _, c := range candidates {
 		if spaceFreed >= howManyBytesToFree {
 			return nil
 		}
-		delta := dirSize(d.Path("."))
-		if err := s.removeRepoDirectory(d); err != nil {
+		size := dirSize(c.dir.Path("."))
+		if err := s.removeRepoDirectory(c.dir); err != nil {
 			return errors.Wrap(err, "removing repo directory")
 		}
-		spaceFreed += delta
+		spaceFreed += size
 		reposRemovedDiskPressure.Inc()
+		s.setEvictedAtNonFatal(ctx, s.name(c.dir), time.Now())
 
 		// Report the new disk usage situation after removing this repo.
 		actualFreeBytes, err := s.DiskSizer.BytesFreeOnDisk(s.ReposDir)
@@ -416,9 +467,12 @@ func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
 			return errors.Wrap(err, "finding the amount of space free on disk")
 		}
 		G := float64(1024 * 1024 * 1024)
-		log15.Warn("cleanup: removed least recently used repo",
-			"repo", d,
-			"how old", time.Since(dirModTimes[d]),
+		log15.Warn("cleanup: evicted repo",
+			"repo", c.dir,
+			"how old", time.Since(c.lastFetched),
+			"last used", c.lastUsed(),
+			"accesses", c.accesses,
+			"size in GiB", float64(size)/G,
 			"free space in GiB", float64(actualFreeBytes)/G,
 			"actual percent of disk space free", float64(actualFreeBytes)/float64(diskSizeBytes)*100.0,
 			"desired percent of disk space free", float64(s.DesiredPercentFree),
@@ -698,10 +752,10 @@ func checkMaybeCorruptRepo(repo api.RepoName, dir GitDir, stderr string) {
 		return
 	}
 
-	log15.Warn("marking repo for re-cloning due to stderr output indicating repo corruption", "repo", repo, "stderr", stderr)
+	log15.Warn("marking repo for integrity check due to stderr output indicating repo corruption", "repo", repo, "stderr", stderr)
 
-	// We set a flag in the config for the cleanup janitor job to fix. The janitor
-	// runs every minute.
+	// We set a flag in the config for the cleanup janitor job to check and
+	// repair, see maybeCheckIntegrity. The janitor runs every minute.
 	err := gitConfigSet(dir, gitConfigMaybeCorrupt, strconv.FormatInt(time.Now().Unix(), 10))
 	if err != nil {
 		log15.Error("failed to set maybeCorruptRepo config", repo, "repo", "error", err)
@@ -20,10 +20,12 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 const (
@@ -235,7 +237,7 @@ func TestCleanupExpired(t *testing.T) {
 		repoOld:           2 * repoTTL,
 		repoGCOld:         2 * repoTTLGC,
 		repoBoom:          2 * repoTTL,
-		repoCorrupt:       repoTTLGC / 2, // should only trigger corrupt, not old
+		repoCorrupt:       repoTTLGC / 2, // should only trigger integrity check, not old
 		repoPerforce:      2 * repoTTL,
 		repoPerforceGCOld: 2 * repoTTLGC,
 	} {
@@ -265,7 +267,7 @@ func TestCleanupExpired(t *testing.T) {
 	repoOldTime := modTime(repoOld)
 	repoGCNewTime := modTime(repoGCNew)
 	repoGCOldTime := modTime(repoGCOld)
-	repoCorruptTime := modTime(repoBoom)
+	repoCorruptTime := modTime(repoCorrupt)
 	repoPerforceTime := modTime(repoPerforce)
 	repoPerforceGCOldTime := modTime(repoPerforceGCOld)
 	repoBoomTime := modTime(repoBoom)
@@ -294,6 +296,13 @@ func TestCleanupExpired(t *testing.T) {
 	if repoPerforceGCOldTime.Before(modTime(repoPerforceGCOld)) {
 		t.Error("expected repoPerforceGCOld to not be modified")
 	}
+	// repoCorrupt is intact, so the integrity check doesn't repair it.
+	if repoCorruptTime.Before(modTime(repoCorrupt)) {
+		t.Error("expected repoCorrupt to not be modified")
+	}
+	if maybeCorrupt, _ := gitConfigGet(GitDir(repoCorrupt), gitConfigMaybeCorrupt); maybeCorrupt != "" {
+		t.Error("expected repoCorrupt to be checked during clean up")
+	}
 
 	// repos that should be recloned
 	if !repoOldTime.Before(modTime(repoOld)) {
@@ -302,9 +311,6 @@ func TestCleanupExpired(t *testing.T) {
 	if !repoGCOldTime.Before(modTime(repoGCOld)) {
 		t.Error("expected repoGCOld to be recloned during clean up")
 	}
-	if !repoCorruptTime.Before(modTime(repoCorrupt)) {
-		t.Error("expected repoCorrupt to be recloned during clean up")
-	}
 
 	// repos that fail to clone need to have recloneTime updated
 	if repoBoomTime.Before(modTime(repoBoom)) {
@@ -719,13 +725,13 @@ func isEmptyDir(path string) (bool, error) {
 func TestFreeUpSpace(t *testing.T) {
 	t.Run("no error if no space requested and no repos", func(t *testing.T) {
 		s := &Server{DiskSizer: &fakeDiskSizer{}}
-		if err := s.freeUpSpace(0); err != nil {
+		if err := s.freeUpSpace(context.Background(), 0, nil); err != nil {
 			t.Fatal(err)
 		}
 	})
 	t.Run("error if space requested and no repos", func(t *testing.T) {
 		s := &Server{DiskSizer: &fakeDiskSizer{}}
-		if err := s.freeUpSpace(1); err == nil {
+		if err := s.freeUpSpace(context.Background(), 1, nil); err == nil {
 			t.Fatal("want error")
 		}
 	})
@@ -758,7 +764,7 @@ func TestFreeUpSpace(t *testing.T) {
 			ReposDir:  rd,
 			DiskSizer: &fakeDiskSizer{},
 		}
-		if err := s.freeUpSpace(1000); err != nil {
+		if err := s.freeUpSpace(context.Background(), 1000, nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -773,6 +779,90 @@ func TestFreeUpSpace(t *testing.T) {
 			t.Errorf("repo dir size is %d, want no more than %d", rds, wantSize)
 		}
 	})
+	t.Run("space freed is measured at eviction time", func(t *testing.T) {
+		rd := t.TempDir()
+		r1 := filepath.Join(rd, "repo1")
+		r2 := filepath.Join(rd, "repo2")
+		if err := makeFakeRepo(r1, 1000); err != nil {
+			t.Fatal(err)
+		}
+		if err := makeFakeRepo(r2, 1000); err != nil {
+			t.Fatal(err)
+		}
+		fi1, err := os.Stat(r1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(r2, time.Now(), fi1.ModTime().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+
+		s := Server{
+			ReposDir:  rd,
+			DiskSizer: &fakeDiskSizer{},
+		}
+		// The janitor computed repo1 to be large enough on its own, but it
+		// shrank afterwards (e.g. by gc), so repo2 has to be removed as well.
+		repoSizes := map[GitDir]int64{GitDir(filepath.Join(r1, ".git")): 2000}
+		if err := s.freeUpSpace(context.Background(), 1500, repoSizes); err != nil {
+			t.Fatal(err)
+		}
+
+		assertPaths(t, rd, ".tmp")
+	})
+	t.Run("least accessed repo gets removed to free up space", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+			GitEvictionPolicy: "access",
+		}})
+		defer conf.Mock(nil)
+
+		rd := t.TempDir()
+		r1 := filepath.Join(rd, "repo1")
+		r2 := filepath.Join(rd, "repo2")
+		if err := makeFakeRepo(r1, 1000); err != nil {
+			t.Fatal(err)
+		}
+		if err := makeFakeRepo(r2, 1000); err != nil {
+			t.Fatal(err)
+		}
+
+		s := Server{
+			ReposDir:  rd,
+			DiskSizer: &fakeDiskSizer{},
+		}
+		s.accesses.record(GitDir(filepath.Join(r1, ".git")), time.Now())
+		if err := s.freeUpSpace(context.Background(), 1000, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		assertPaths(t, rd,
+			".tmp",
+			"repo1/.git/HEAD",
+			"repo1/.git/space_eater")
+	})
+	t.Run("pinned repo is not removed", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+			GitEvictionPinnedRepos: []string{"repo1"},
+		}})
+		defer conf.Mock(nil)
+
+		rd := t.TempDir()
+		if err := makeFakeRepo(filepath.Join(rd, "repo1"), 1000); err != nil {
+			t.Fatal(err)
+		}
+
+		s := Server{
+			ReposDir:  rd,
+			DiskSizer: &fakeDiskSizer{},
+		}
+		if err := s.freeUpSpace(context.Background(), 1000, nil); err == nil {
+			t.Fatal("want error")
+		}
+
+		assertPaths(t, rd,
+			"repo1/.git/HEAD",
+			"repo1/.git/space_eater")
+	})
 }
 
 func makeFakeRepo(d string, sizeBytes int) error {
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// accessHalfLife is the time after which an access of a repository counts half
+// as much towards keeping the repository on disk.
+const accessHalfLife = 7 * 24 * time.Hour
+
+// repoAccess is the access history of a repository.
+type repoAccess struct {
+	// score is the number of accesses as of last, where each access is decayed
+	// by accessHalfLife since it happened.
+	score float64
+	last  time.Time
+}
+
+// scoreAt returns the score of a as of now.
+func (a repoAccess) scoreAt(now time.Time) float64 {
+	if a.last.IsZero() {
+		return 0
+	}
+	return a.score * math.Exp2(-float64(now.Sub(a.last))/float64(accessHalfLife))
+}
+
+// accessTracker records the accesses of repositories by search, archive and
+// exec requests. The history is kept in memory, so it is lost when gitserver
+// restarts. Until repositories are accessed again, eviction then falls back to
+// the order in which they were last fetched.
+type accessTracker struct {
+	mu       sync.Mutex
+	accesses map[GitDir]repoAccess
+}
+
+// record records an access of the repository in dir at now.
+func (t *accessTracker) record(dir GitDir, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.accesses == nil {
+		t.accesses = make(map[GitDir]repoAccess)
+	}
+	a := t.accesses[dir]
+	t.accesses[dir] = repoAccess{score: a.scoreAt(now) + 1, last: now}
+}
+
+// get returns the access history of the repository in dir.
+func (t *accessTracker) get(dir GitDir) repoAccess {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.accesses[dir]
+}
+
+// evictionCandidate is a repository which may be removed to free up disk
+// space.
+type evictionCandidate struct {
+	dir  GitDir
+	size int64
+	// lastFetched is the modification time of the repository, see
+	// gitDirModTime.
+	lastFetched time.Time
+	// accesses is the score of the access history of the repository at the
+	// time of eviction, see repoAccess.
+	accesses     float64
+	lastAccessed time.Time
+}
+
+// lastUsed returns the last time the repository was fetched or accessed.
+func (c *evictionCandidate) lastUsed() time.Time {
+	if c.lastAccessed.After(c.lastFetched) {
+		return c.lastAccessed
+	}
+	return c.lastFetched
+}
+
+// evictionPolicy reports whether the repository of a should be evicted before
+// the repository of b.
+type evictionPolicy func(a, b *evictionCandidate) bool
+
+// evictionPolicies are the eviction policies by the name they are configured
+// with in the gitEvictionPolicy site configuration.
+var evictionPolicies = map[string]evictionPolicy{
+	"last-fetched": evictLeastRecentlyFetched,
+	"access":       evictLeastAccessed,
+}
+
+// evictLeastAccessed evicts the repositories with the fewest accesses per byte
+// first, so that one large and rarely searched repository is evicted before
+// many small and frequently searched ones. Repositories with the same score,
+// such as those which were never accessed, are evicted from least to most
+// recently used, and larger ones first.
+func evictLeastAccessed(a, b *evictionCandidate) bool {
+	if sa, sb := accessesPerByte(a), accessesPerByte(b); sa != sb {
+		return sa < sb
+	}
+	if ua, ub := a.lastUsed(), b.lastUsed(); !ua.Equal(ub) {
+		return ua.Before(ub)
+	}
+	return a.size > b.size
+}
+
+func accessesPerByte(c *evictionCandidate) float64 {
+	if c.size <= 0 {
+		return c.accesses
+	}
+	return c.accesses / float64(c.size)
+}
+
+// evictLeastRecentlyFetched evicts the repositories from least to most
+// recently fetched.
+func evictLeastRecentlyFetched(a, b *evictionCandidate) bool {
+	return a.lastFetched.Before(b.lastFetched)
+}
+
+// configuredEvictionPolicy returns the eviction policy configured in the site
+// configuration, and evictLeastRecentlyFetched if it isn't configured. The
+// access history evictLeastAccessed relies on is lost on restarts, so it is
+// not the default.
+func configuredEvictionPolicy() evictionPolicy {
+	if policy, ok := evictionPolicies[conf.Get().GitEvictionPolicy]; ok {
+		return policy
+	}
+	return evictLeastRecentlyFetched
+}
+
+// pinnedRepos returns the set of normalized names of the repositories which
+// must never be evicted.
+func pinnedRepos() map[api.RepoName]struct{} {
+	names := conf.Get().GitEvictionPinnedRepos
+	pinned := make(map[api.RepoName]struct{}, len(names))
+	for _, name := range names {
+		pinned[protocol.NormalizeRepo(api.RepoName(name))] = struct{}{}
+	}
+	return pinned
+}
+
+// setEvictedAtNonFatal records the time repo was evicted in the DB, so that
+// repo-updater doesn't clone it again until it is requested. A zero evictedAt
+// clears it. Errors are only logged.
+func (s *Server) setEvictedAtNonFatal(ctx context.Context, repo api.RepoName, evictedAt time.Time) {
+	if s.DB == nil {
+		return
+	}
+	if err := database.GitserverRepos(s.DB).SetEvictedAt(ctx, repo, evictedAt, s.Hostname); err != nil {
+		log15.Warn("Setting evicted at in DB", "repo", repo, "error", err)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAccessTracker(t *testing.T) {
+	var tracker accessTracker
+	now := time.Now()
+
+	if got := tracker.get("a").scoreAt(now); got != 0 {
+		t.Fatalf("got score %v for a repository which was never accessed, want 0", got)
+	}
+
+	tracker.record("a", now.Add(-accessHalfLife))
+	tracker.record("a", now.Add(-accessHalfLife))
+	tracker.record("b", now)
+
+	// Accesses count half as much after accessHalfLife.
+	for dir, want := range map[GitDir]float64{"a": 1, "b": 1} {
+		if got := tracker.get(dir).scoreAt(now); math.Abs(got-want) > 1e-9 {
+			t.Errorf("got score %v for %s, want %v", got, dir, want)
+		}
+	}
+}
+
+func TestEvictionPolicies(t *testing.T) {
+	now := time.Now()
+	candidates := []evictionCandidate{
+		// Searched a lot, but very large.
+		{dir: "monorepo", size: 1 << 30, lastFetched: now.Add(-time.Minute), accesses: 100, lastAccessed: now},
+		// Searched a lot.
+		{dir: "popular", size: 1 << 20, lastFetched: now.Add(-48 * time.Hour), accesses: 100, lastAccessed: now},
+		// Never searched, but fetched recently.
+		{dir: "fetched", size: 1 << 20, lastFetched: now.Add(-time.Hour)},
+		// Never searched and stale.
+		{dir: "stale", size: 1 << 20, lastFetched: now.Add(-24 * time.Hour)},
+		// Never searched and stale, but larger.
+		{dir: "stale-large", size: 1 << 21, lastFetched: now.Add(-24 * time.Hour)},
+	}
+
+	for name, want := range map[string][]GitDir{
+		"access":       {"stale-large", "stale", "fetched", "monorepo", "popular"},
+		"last-fetched": {"popular", "stale", "stale-large", "fetched", "monorepo"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			policy := evictionPolicies[name]
+			sorted := append([]evictionCandidate(nil), candidates...)
+			sort.SliceStable(sorted, func(i, j int) bool {
+				return policy(&sorted[i], &sorted[j])
+			})
+
+			var got []GitDir
+			for _, c := range sorted {
+				got = append(got, c.dir)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("unexpected eviction order (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+)
+
+var gitVersionPattern = lazyregexp.New(`^git version (\d+\.\d+(?:\.\d+)?)`)
+
+var (
+	gitVersionOnce sync.Once
+	gitVersion     *semver.Version
+)
+
+// parseGitVersion parses the output of git version, as in "git version 2.26.3"
+// or "git version 2.37.1 (Apple Git-137.1)".
+func parseGitVersion(out string) (*semver.Version, error) {
+	m := gitVersionPattern.FindStringSubmatch(out)
+	if m == nil {
+		return nil, errors.Errorf("unexpected output of git version: %q", out)
+	}
+	return semver.NewVersion(m[1])
+}
+
+// installedGitVersion returns the version of the git binary, which is
+// determined once. It returns nil if the version could not be determined.
+func installedGitVersion() *semver.Version {
+	gitVersionOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		out, err := exec.CommandContext(ctx, "git", "version").Output()
+		if err == nil {
+			gitVersion, err = parseGitVersion(string(out))
+		}
+		if err != nil {
+			log15.Error("failed to determine git version", "error", err)
+		}
+	})
+	return gitVersion
+}
+
+// gitSupports reports whether the installed git is at least version min.
+// Features are assumed to be unsupported if the installed version could not
+// be determined.
+func gitSupports(min string) bool {
+	v := installedGitVersion()
+	return v != nil && !v.LessThan(semver.MustParse(min))
+}
@@ -0,0 +1,25 @@
+package server
+
+import "testing"
+
+func TestParseGitVersion(t *testing.T) {
+	for out, want := range map[string]string{
+		"git version 2.26.3\n":                 "2.26.3",
+		"git version 2.37.1 (Apple Git-137.1)": "2.37.1",
+		"git version 2.36.0.windows.1":         "2.36.0",
+		"git version 2.40":                     "2.40.0",
+	} {
+		v, err := parseGitVersion(out)
+		if err != nil {
+			t.Errorf("parseGitVersion(%q): %s", out, err)
+			continue
+		}
+		if v.String() != want {
+			t.Errorf("parseGitVersion(%q) = %s, want %s", out, v, want)
+		}
+	}
+
+	if _, err := parseGitVersion("hub version 2.14.2"); err == nil {
+		t.Error("expected error for unexpected output")
+	}
+}
@@ -0,0 +1,310 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+const (
+	// gitConfigLastIntegrityCheck is a key we add to git config to store the
+	// last time the integrity of a repository was checked.
+	gitConfigLastIntegrityCheck = "sourcegraph.lastIntegrityCheck"
+
+	// maxIntegrityCheckOutput is the number of bytes of the output of a failed
+	// integrity check which are kept.
+	maxIntegrityCheckOutput = 4096
+)
+
+// integrityCheckInterval is how often the janitor checks the integrity of a
+// repository. Repositories which are suspected to be corrupt, see
+// checkMaybeCorruptRepo, are checked by the next janitor run regardless.
+var integrityCheckInterval = env.MustGetDuration("SRC_REPOS_INTEGRITY_CHECK_INTERVAL", 7*24*time.Hour, "Interval at which the janitor checks the integrity of each repository with git fsck. Set to 0 to only check repositories suspected to be corrupt.")
+
+// integrityCheckTimeout bounds the time the janitor spends checking the
+// integrity of a single repository, since it cleans up repositories one at a
+// time. Repositories whose check times out are checked again after
+// integrityCheckInterval.
+var integrityCheckTimeout = env.MustGetDuration("SRC_REPOS_INTEGRITY_CHECK_TIMEOUT", 5*time.Minute, "Maximum duration of the git fsck of a single repository by the janitor.")
+
+var (
+	integrityChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_integrity_checks_total",
+		Help: "The number of repository integrity checks, by outcome (intact, corrupt or error).",
+	}, []string{"outcome"})
+	corruptionRepairs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_corruption_repairs_total",
+		Help: "The number of attempts to repair a corrupt repository, by repair step and outcome (succeeded, failed or unsupported).",
+	}, []string{"step", "outcome"})
+)
+
+// maybeCheckIntegrity checks the integrity of the repository in dir if it is
+// suspected to be corrupt or was not checked for integrityCheckInterval, and
+// tries to repair it if it is corrupt. done is true if the repository was
+// recloned or could not be repaired.
+func (s *Server) maybeCheckIntegrity(ctx context.Context, dir GitDir, allowReclone bool) (done bool, err error) {
+	maybeCorrupt, _ := gitConfigGet(dir, gitConfigMaybeCorrupt)
+
+	lastCheck, err := getLastIntegrityCheck(dir)
+	if err != nil {
+		return false, err
+	}
+	// Add a jitter to spread out checks of repos cloned at the same time.
+	due := integrityCheckInterval > 0 && time.Since(lastCheck) > integrityCheckInterval+jitterDuration(string(dir), integrityCheckInterval/4)
+	if maybeCorrupt == "" && !due {
+		return false, nil
+	}
+
+	// unset flag and update the check time up front to stop constantly
+	// checking the repo if the check or the repair fails.
+	_ = gitConfigUnset(dir, gitConfigMaybeCorrupt)
+	if err := setLastIntegrityCheck(dir, time.Now()); err != nil {
+		return false, err
+	}
+
+	repo := s.name(dir)
+	problems, err := checkIntegrityWithTimeout(ctx, dir)
+	if err != nil {
+		integrityChecks.WithLabelValues("error").Inc()
+		return false, err
+	}
+	s.setLastIntegrityCheckNonFatal(ctx, repo, problems)
+	if problems == "" {
+		integrityChecks.WithLabelValues("intact").Inc()
+		return false, nil
+	}
+
+	integrityChecks.WithLabelValues("corrupt").Inc()
+	log15.Warn("repository failed integrity check", "repo", repo, "problems", problems)
+	return s.repairRepo(ctx, repo, dir, allowReclone)
+}
+
+// repairStep is a step of the repair of a corrupt repository.
+type repairStep struct {
+	name   string
+	repair func(ctx context.Context, repo api.RepoName, dir GitDir) error
+	// minGitVersion is the git version the step requires, if any. Steps are
+	// skipped if the installed git is older.
+	minGitVersion string
+}
+
+// repairRepo tries the repair steps for the corrupt repository in dir in order
+// of increasing cost, until the repository passes the integrity check again.
+// The repository is only recloned if allowReclone is true.
+func (s *Server) repairRepo(ctx context.Context, repo api.RepoName, dir GitDir, allowReclone bool) (done bool, err error) {
+	steps := []repairStep{
+		// Missing objects are restored by fetching them again, which is much
+		// cheaper than a reclone. git fetch --refetch was added in git 2.36.
+		{name: "refetch", repair: s.refetch, minGitVersion: "2.36.0"},
+		// A corrupt commit-graph or multi-pack-index makes git report errors
+		// even if all objects are intact.
+		{name: "rebuild indexes", repair: rebuildIndexes},
+	}
+	if allowReclone {
+		steps = append(steps, repairStep{name: "reclone", repair: s.reclone})
+	}
+
+	for _, step := range steps {
+		if step.minGitVersion != "" && !gitSupports(step.minGitVersion) {
+			corruptionRepairs.WithLabelValues(step.name, "unsupported").Inc()
+			continue
+		}
+
+		problems, err := func() (string, error) {
+			repairCtx, cancel := context.WithTimeout(ctx, conf.GitLongCommandTimeout())
+			defer cancel()
+			if err := step.repair(repairCtx, repo, dir); err != nil {
+				return "", err
+			}
+			return checkIntegrityWithTimeout(ctx, dir)
+		}()
+		if err != nil || problems != "" {
+			log15.Warn("failed to repair corrupt repository", "repo", repo, "step", step.name, "problems", problems, "error", err)
+			corruptionRepairs.WithLabelValues(step.name, "failed").Inc()
+			continue
+		}
+
+		log15.Info("repaired corrupt repository", "repo", repo, "step", step.name)
+		corruptionRepairs.WithLabelValues(step.name, "succeeded").Inc()
+		s.setLastIntegrityCheckNonFatal(ctx, repo, "")
+		// A recloned repository needs no further cleanup.
+		return step.name == "reclone", nil
+	}
+	return true, errors.Errorf("failed to repair corrupt repository %s", repo)
+}
+
+// refetch fetches all objects of repo from its remote again.
+func (s *Server) refetch(ctx context.Context, repo api.RepoName, dir GitDir) error {
+	syncer, err := s.GetVCSSyncer(ctx, repo)
+	if err != nil {
+		return errors.Wrap(err, "get VCS syncer")
+	}
+	gitSyncer, ok := syncer.(*GitRepoSyncer)
+	if !ok {
+		return errors.Errorf("refetching %s repositories is not supported", syncer.Type())
+	}
+
+	remoteURL, err := s.getRemoteURL(ctx, repo)
+	if err != nil {
+		return errors.Wrap(err, "failed to determine Git remote URL")
+	}
+
+	ctx, cancel, err := s.acquireCloneLimiter(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err = s.rpsLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	defer s.cleanTmpFiles(dir)
+	return gitSyncer.Refetch(ctx, remoteURL, dir)
+}
+
+// rebuildIndexes removes the commit-graph and the multi-pack-index of the
+// repository in dir, and writes them again from its objects. The
+// multi-pack-index is only written again if the repository had one.
+func rebuildIndexes(ctx context.Context, _ api.RepoName, dir GitDir) error {
+	midx := dir.Path("objects", "pack", "multi-pack-index")
+	_, err := os.Stat(midx)
+	hadMIDX := err == nil
+
+	for _, path := range []string{
+		dir.Path("objects", "info", "commit-graph"),
+		dir.Path("objects", "info", "commit-graphs"),
+		midx,
+	} {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
+	commands := [][]string{{"commit-graph", "write", "--reachable"}}
+	if hadMIDX {
+		commands = append(commands, []string{"multi-pack-index", "write"})
+	}
+	for _, args := range commands {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		dir.Set(cmd)
+		if _, err := cmd.Output(); err != nil {
+			return errors.Wrapf(wrapCmdError(cmd, err), "failed to write %s", args[0])
+		}
+	}
+	return nil
+}
+
+// reclone replaces repo with a fresh clone.
+func (s *Server) reclone(ctx context.Context, repo api.RepoName, dir GitDir) error {
+	if _, err := s.cloneRepo(ctx, repo, &cloneOptions{Block: true, Overwrite: true}); err != nil {
+		return err
+	}
+	reposRecloned.Inc()
+	return nil
+}
+
+func checkIntegrityWithTimeout(ctx context.Context, dir GitDir) (problems string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, integrityCheckTimeout)
+	defer cancel()
+	return checkIntegrity(ctx, dir)
+}
+
+// checkIntegrity runs git fsck on the repository in dir, which also verifies
+// its commit-graph and multi-pack-index. problems is the output of git fsck if
+// it found the repository to be corrupt, and empty if the repository is
+// intact.
+func checkIntegrity(ctx context.Context, dir GitDir) (problems string, err error) {
+	cmd := exec.CommandContext(ctx, "git", "fsck", "--no-dangling", "--no-progress")
+	dir.Set(cmd)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return "", nil
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	var e *exec.ExitError
+	if !errors.As(err, &e) {
+		return "", errors.Wrap(wrapCmdError(cmd, err), "failed to check integrity")
+	}
+
+	problems = strings.TrimSpace(string(out))
+	if problems == "" {
+		problems = fmt.Sprintf("git fsck failed with exit status %d", e.ExitCode())
+	}
+	if len(problems) > maxIntegrityCheckOutput {
+		problems = problems[:maxIntegrityCheckOutput] + "\n..."
+	}
+	return problems, nil
+}
+
+// setLastIntegrityCheck sets the time the integrity of a repository was
+// checked.
+func setLastIntegrityCheck(dir GitDir, now time.Time) error {
+	err := gitConfigSet(dir, gitConfigLastIntegrityCheck, strconv.FormatInt(now.Unix(), 10))
+	if err != nil {
+		return errors.Wrap(err, "failed to update lastIntegrityCheck")
+	}
+	return nil
+}
+
+// getLastIntegrityCheck returns the time the integrity of a repository was
+// last checked. If the value is not stored in the repository, it is set to now,
+// so that existing repositories are not all checked at once.
+func getLastIntegrityCheck(dir GitDir) (time.Time, error) {
+	update := func() (time.Time, error) {
+		now := time.Now()
+		return now, setLastIntegrityCheck(dir, now)
+	}
+
+	value, err := gitConfigGet(dir, gitConfigLastIntegrityCheck)
+	if err != nil {
+		return time.Unix(0, 0), errors.Wrap(err, "failed to determine last integrity check")
+	}
+	if value == "" {
+		return update()
+	}
+
+	sec, err := strconv.ParseInt(strings.TrimSpace(value), 10, 0)
+	if err != nil {
+		// If the value is bad update it to the current time
+		now, err2 := update()
+		if err2 != nil {
+			err = err2
+		}
+		return now, err
+	}
+
+	return time.Unix(sec, 0), nil
+}
+
+// setLastIntegrityCheckNonFatal records the outcome of an integrity check of
+// repo in the DB, and only logs errors.
+func (s *Server) setLastIntegrityCheckNonFatal(ctx context.Context, repo api.RepoName, problems string) {
+	if s.DB == nil {
+		return
+	}
+	err := database.GitserverRepos(s.DB).SetLastIntegrityCheck(ctx, repo, database.GitserverIntegrityCheckData{
+		CheckedAt: time.Now(),
+		Error:     problems,
+		ShardID:   s.Hostname,
+	})
+	if err != nil {
+		log15.Warn("Setting last integrity check in DB", "repo", repo, "error", err)
+	}
+}
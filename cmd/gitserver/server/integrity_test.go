@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// makeLooseObjectsRepo creates a remote repository with a single commit, and
+// a bare copy of it in reposDir which stores its objects as loose objects. It
+// returns the remote and the GitDir of the copy.
+func makeLooseObjectsRepo(t *testing.T, reposDir string) (remote string, dir GitDir) {
+	t.Helper()
+	remote = t.TempDir()
+	makeSingleCommitRepo(func(name string, arg ...string) string {
+		return runCmd(t, remote, name, arg...)
+	})
+
+	dir = GitDir(filepath.Join(reposDir, "example.com", "repo", ".git"))
+	if err := os.MkdirAll(string(dir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runCmd(t, string(dir), "git", "init", "--bare", ".")
+	runCmd(t, string(dir), "git", "-c", "fetch.unpackLimit=1000", "fetch", remote, "+refs/heads/*:refs/heads/*")
+	return remote, dir
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	_, dir := makeLooseObjectsRepo(t, t.TempDir())
+
+	problems, err := checkIntegrity(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems != "" {
+		t.Fatalf("expected intact repository, got problems: %s", problems)
+	}
+
+	removeBlob(t, dir)
+	problems, err = checkIntegrity(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems == "" {
+		t.Fatal("expected problems with missing blob")
+	}
+}
+
+func TestMaybeCheckIntegrity_refetch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reposDir := t.TempDir()
+	remote, dir := makeLooseObjectsRepo(t, reposDir)
+	s := makeTestServer(ctx, reposDir, remote, nil)
+
+	// Intact repositories are only checked when they are due.
+	if done, err := s.maybeCheckIntegrity(ctx, dir, false); done || err != nil {
+		t.Fatalf("got done=%t err=%v for a repository which isn't due", done, err)
+	}
+
+	removeBlob(t, dir)
+	if err := gitConfigSet(dir, gitConfigMaybeCorrupt, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := s.maybeCheckIntegrity(ctx, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Error("expected refetched repository to need further cleanup")
+	}
+	if problems, err := checkIntegrity(ctx, dir); err != nil || problems != "" {
+		t.Fatalf("expected repository to be repaired, got problems %q and error %v", problems, err)
+	}
+	if maybeCorrupt, _ := gitConfigGet(dir, gitConfigMaybeCorrupt); maybeCorrupt != "" {
+		t.Error("expected maybe corrupt flag to be unset")
+	}
+}
+
+func TestMaybeCheckIntegrity_rebuildIndexes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reposDir := t.TempDir()
+	remote, dir := makeLooseObjectsRepo(t, reposDir)
+	s := makeTestServer(ctx, reposDir, remote, nil)
+	// Refetching fails, so the next step repairs the repository.
+	s.GetRemoteURLFunc = func(context.Context, api.RepoName) (string, error) {
+		return "", errors.New("boom")
+	}
+
+	runCmd(t, string(dir), "git", "commit-graph", "write", "--reachable")
+	writeFile(t, dir.Path("objects", "info", "commit-graph"), []byte("garbage"))
+	if err := gitConfigSet(dir, gitConfigMaybeCorrupt, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := s.maybeCheckIntegrity(ctx, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Error("expected repository with rebuilt indexes to need further cleanup")
+	}
+	if problems, err := checkIntegrity(ctx, dir); err != nil || problems != "" {
+		t.Fatalf("expected repository to be repaired, got problems %q and error %v", problems, err)
+	}
+	if _, err := os.Stat(dir.Path("objects", "info", "commit-graph")); err != nil {
+		t.Errorf("expected commit-graph to be written again: %v", err)
+	}
+}
+
+func TestMaybeCheckIntegrity_unrepairable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reposDir := t.TempDir()
+	remote, dir := makeLooseObjectsRepo(t, reposDir)
+	s := makeTestServer(ctx, reposDir, remote, nil)
+	s.GetRemoteURLFunc = func(context.Context, api.RepoName) (string, error) {
+		return "", errors.New("boom")
+	}
+
+	removeBlob(t, dir)
+	if err := gitConfigSet(dir, gitConfigMaybeCorrupt, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without re-cloning, the missing blob can't be restored.
+	done, err := s.maybeCheckIntegrity(ctx, dir, false)
+	if err == nil {
+		t.Fatal("expected error for unrepairable repository")
+	}
+	if !done {
+		t.Error("expected unrepairable repository to need no further cleanup")
+	}
+}
+
+// removeBlob removes the loose object of the blob of hello.txt, see
+// makeSingleCommitRepo.
+func removeBlob(t *testing.T, dir GitDir) {
+	t.Helper()
+	out, err := exec.Command("git", "--git-dir", string(dir), "rev-parse", "HEAD:hello.txt").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := strings.TrimSpace(string(out))
+	if err := os.Remove(dir.Path("objects", blob[:2], blob[2:])); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+var (
+	enableMaintenance, _ = strconv.ParseBool(env.Get("SRC_ENABLE_REPO_MAINTENANCE", "false", "Maintain commit-graphs, multi-pack-indexes and bitmaps of repositories during janitorial cleanup phases. Maintenance steps which the installed git does not support are skipped."))
+
+	// maintenanceMinRepoBytes is the size below which repositories are not
+	// maintained, since git is fast enough on them without the indexes.
+	maintenanceMinRepoBytes = int64(env.MustGetInt("SRC_REPO_MAINTENANCE_MIN_BYTES", 10*1024*1024, "Size in bytes below which repositories are not maintained by the janitor."))
+)
+
+var (
+	maintenanceSteps = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_maintenance_steps_total",
+		Help: "The number of repository maintenance steps run by the janitor, by step and outcome (succeeded, failed or unsupported).",
+	}, []string{"step", "outcome"})
+	maintenanceDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "src_gitserver_maintenance_step_duration_seconds",
+		Help:    "Duration of the repository maintenance steps run by the janitor, by step.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 4, 8),
+	}, []string{"step"})
+)
+
+// maintenanceStep is a git command which maintains a repository, which is run
+// at most once per interval for each repository.
+type maintenanceStep struct {
+	// name is used in metrics and in the git config key storing the time the
+	// step last ran.
+	name     string
+	interval time.Duration
+	args     []string
+	// minGitVersion is the git version which supports all args. The step is
+	// skipped if the installed git is older.
+	minGitVersion string
+}
+
+// repoMaintenanceSteps are run in order.
+var repoMaintenanceSteps = []maintenanceStep{
+	// Fetches add a pack each, so we roll up the packs into a geometric
+	// progression by size, which keeps the number of packs logarithmic without
+	// rewriting our largest packs. This also writes a multi-pack-index with a
+	// reachability bitmap over the remaining packs.
+	{
+		name:     "geometric-repack",
+		interval: 24 * time.Hour,
+		args:     []string{"repack", "--geometric=2", "-d", "--write-midx", "--write-bitmap-index"},
+		// git repack --write-midx was added in git 2.34.
+		minGitVersion: "2.34.0",
+	},
+	// Index the packs of fetches since the last repack, so that object lookups
+	// don't search each pack.
+	{
+		name:     "multi-pack-index",
+		interval: time.Hour,
+		args:     []string{"multi-pack-index", "write", "--bitmap"},
+		// git multi-pack-index write --bitmap was added in git 2.34.
+		minGitVersion: "2.34.0",
+	},
+	// The commit-graph speeds up history walks, as in git log and merge-base.
+	// Changed-path Bloom filters speed up history walks limited to paths. The
+	// graph is written incrementally as a chain, which git merges as it grows.
+	{
+		name:     "commit-graph",
+		interval: time.Hour,
+		args:     []string{"commit-graph", "write", "--reachable", "--split", "--changed-paths"},
+		// git commit-graph write --changed-paths was added in git 2.27.
+		minGitVersion: "2.27.0",
+	},
+}
+
+// maintainRepo runs the maintenance steps which are due for the repository in
+// dir and supported by the installed git, unless it is smaller than
+// maintenanceMinRepoBytes. size is the size of the repository in bytes.
+func maintainRepo(ctx context.Context, dir GitDir, size int64) error {
+	if size < maintenanceMinRepoBytes {
+		return nil
+	}
+
+	var err error
+	for _, step := range repoMaintenanceSteps {
+		if !gitSupports(step.minGitVersion) {
+			maintenanceSteps.WithLabelValues(step.name, "unsupported").Inc()
+			continue
+		}
+
+		lastRun, err1 := getLastMaintenance(dir, step.name)
+		if err1 != nil {
+			err = multierror.Append(err, err1)
+			continue
+		}
+		// Add a jitter to spread out maintenance of repos cloned at the same
+		// time.
+		if time.Since(lastRun) < step.interval+jitterDuration(string(dir)+step.name, step.interval/4) {
+			continue
+		}
+
+		// update the time up front so that we don't constantly retry a step
+		// which fails.
+		if err1 := setLastMaintenance(dir, step.name, time.Now()); err1 != nil {
+			err = multierror.Append(err, err1)
+			continue
+		}
+
+		if err1 := runMaintenanceStep(ctx, dir, step); err1 != nil {
+			log15.Warn("repository maintenance step failed", "repo", dir, "step", step.name, "error", err1)
+			err = multierror.Append(err, err1)
+		}
+	}
+	return err
+}
+
+func runMaintenanceStep(ctx context.Context, dir GitDir, step maintenanceStep) error {
+	ctx, cancel := context.WithTimeout(ctx, conf.GitLongCommandTimeout())
+	defer cancel()
+
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, "git", step.args...)
+	dir.Set(cmd)
+	_, err := cmd.Output()
+	maintenanceDuration.WithLabelValues(step.name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		maintenanceSteps.WithLabelValues(step.name, "failed").Inc()
+		return errors.Wrapf(wrapCmdError(cmd, err), "failed to run maintenance step %s", step.name)
+	}
+	maintenanceSteps.WithLabelValues(step.name, "succeeded").Inc()
+	return nil
+}
+
+func maintenanceConfigKey(step string) string {
+	return "sourcegraph.maintenance." + step
+}
+
+// setLastMaintenance sets the time a maintenance step last ran for a
+// repository.
+func setLastMaintenance(dir GitDir, step string, now time.Time) error {
+	err := gitConfigSet(dir, maintenanceConfigKey(step), strconv.FormatInt(now.Unix(), 10))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update last %s maintenance", step)
+	}
+	return nil
+}
+
+// getLastMaintenance returns the time a maintenance step last ran for a
+// repository, or the zero time if it never ran.
+func getLastMaintenance(dir GitDir, step string) (time.Time, error) {
+	value, err := gitConfigGet(dir, maintenanceConfigKey(step))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to determine last %s maintenance", step)
+	}
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	sec, err := strconv.ParseInt(strings.TrimSpace(value), 10, 0)
+	if err != nil {
+		// If the value is bad the step runs again, which updates it.
+		return time.Time{}, nil
+	}
+	return time.Unix(sec, 0), nil
+}
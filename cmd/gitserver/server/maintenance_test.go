@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+func TestMaintainRepo(t *testing.T) {
+	ctx := context.Background()
+	_, dir := makeLooseObjectsRepo(t, t.TempDir())
+
+	minBytes := maintenanceMinRepoBytes
+	t.Cleanup(func() { maintenanceMinRepoBytes = minBytes })
+	maintenanceMinRepoBytes = 1024
+
+	// Small repositories are not maintained.
+	if err := maintainRepo(ctx, dir, 1023); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range repoMaintenanceSteps {
+		if lastRun, err := getLastMaintenance(dir, step.name); err != nil || !lastRun.IsZero() {
+			t.Fatalf("expected %s to not run on a small repository, got last run %v and error %v", step.name, lastRun, err)
+		}
+	}
+
+	if err := maintainRepo(ctx, dir, 1024); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		dir.Path("objects", "info", "commit-graphs", "commit-graph-chain"),
+		dir.Path("objects", "pack", "multi-pack-index"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected maintenance to write %s: %v", path, err)
+		}
+	}
+	if bitmaps, _ := filepath.Glob(dir.Path("objects", "pack", "multi-pack-index-*.bitmap")); len(bitmaps) == 0 {
+		t.Error("expected maintenance to write a multi-pack-index bitmap")
+	}
+	if problems, err := checkIntegrity(ctx, dir); err != nil || problems != "" {
+		t.Fatalf("expected maintained repository to be intact, got problems %q and error %v", problems, err)
+	}
+
+	// Steps which ran recently are skipped.
+	if err := os.RemoveAll(dir.Path("objects", "info", "commit-graphs")); err != nil {
+		t.Fatal(err)
+	}
+	if err := maintainRepo(ctx, dir, 1024); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir.Path("objects", "info", "commit-graphs")); !os.IsNotExist(err) {
+		t.Errorf("expected commit-graph to not be written again: %v", err)
+	}
+
+	// Steps which are due run again.
+	if err := setLastMaintenance(dir, "commit-graph", time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := maintainRepo(ctx, dir, 1024); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir.Path("objects", "info", "commit-graphs", "commit-graph-chain")); err != nil {
+		t.Errorf("expected commit-graph to be written again: %v", err)
+	}
+}
+
+func TestMaintainRepo_unsupportedGitVersion(t *testing.T) {
+	ctx := context.Background()
+	_, dir := makeLooseObjectsRepo(t, t.TempDir())
+
+	minBytes := maintenanceMinRepoBytes
+	t.Cleanup(func() { maintenanceMinRepoBytes = minBytes })
+	maintenanceMinRepoBytes = 1024
+
+	installed := installedGitVersion()
+	t.Cleanup(func() { gitVersion = installed })
+	gitVersion = semver.MustParse("2.26.3")
+
+	if err := maintainRepo(ctx, dir, 1024); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range repoMaintenanceSteps {
+		if lastRun, err := getLastMaintenance(dir, step.name); err != nil || !lastRun.IsZero() {
+			t.Errorf("expected %s to be skipped with git 2.26, got last run %v and error %v", step.name, lastRun, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// prewarmDefaultPerMinute is the default maximum number of repositories
+// pre-warmed per minute, see the "search.prewarm" site configuration.
+const prewarmDefaultPerMinute = 60
+
+// setPrewarmConfig applies the "search.prewarm" site configuration.
+func (s *Server) setPrewarmConfig() {
+	var (
+		repos     []*regexp.Regexp
+		perMinute = prewarmDefaultPerMinute
+	)
+	if c := conf.Get().SearchPrewarm; c != nil {
+		for _, pattern := range c.Repos {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				log15.Error("ignoring invalid search.prewarm repos pattern", "pattern", pattern, "error", err)
+				continue
+			}
+			repos = append(repos, re)
+		}
+		if c.MaxPerMinute > 0 {
+			perMinute = c.MaxPerMinute
+		}
+	}
+
+	s.prewarmMu.Lock()
+	defer s.prewarmMu.Unlock()
+	s.prewarmRepos = repos
+	limit := rate.Limit(float64(perMinute) / 60)
+	if s.prewarmLimiter == nil {
+		s.prewarmLimiter = rate.NewLimiter(limit, perMinute)
+		return
+	}
+	s.prewarmLimiter.SetLimit(limit)
+	s.prewarmLimiter.SetBurst(perMinute)
+}
+
+// shouldPrewarm reports whether repo is pre-warmed after fetches, which is
+// the case if s.Prewarm is set and repo matches the "search.prewarm" site
+// configuration. The caller avoids resolving HEAD around a fetch otherwise.
+func (s *Server) shouldPrewarm(repo api.RepoName) bool {
+	if s.Prewarm == nil {
+		return false
+	}
+
+	s.prewarmMu.Lock()
+	defer s.prewarmMu.Unlock()
+
+	for _, re := range s.prewarmRepos {
+		if re.MatchString(string(repo)) {
+			return true
+		}
+	}
+	return false
+}
+
+// prewarmAllowed reports whether a pre-warm is allowed by the rate limit now.
+func (s *Server) prewarmAllowed() bool {
+	s.prewarmMu.Lock()
+	defer s.prewarmMu.Unlock()
+
+	if !s.prewarmLimiter.Allow() {
+		prewarmCounter.WithLabelValues("rate_limited").Inc()
+		return false
+	}
+	return true
+}
+
+// prewarm emits a pre-warm event for the new HEAD of repo after a fetch. The
+// event is handled by s.Prewarm in the background, unless the repository is
+// not allowed by the site configuration, the rate limit is exceeded, or the
+// default branch of the repository is indexed, in which case searches of it
+// don't read the caches which would be pre-warmed.
+func (s *Server) prewarm(repo api.RepoName, head api.CommitID) {
+	if !s.shouldPrewarm(repo) || !s.prewarmAllowed() {
+		return
+	}
+
+	ctx, cancel := s.serverContext()
+	go func() {
+		defer cancel()
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		if s.IsIndexed != nil {
+			indexed, err := s.IsIndexed(ctx, repo)
+			if err != nil {
+				log15.Warn("failed to check whether repository is indexed", "repo", repo, "error", err)
+			}
+			if indexed {
+				prewarmCounter.WithLabelValues("indexed").Inc()
+				return
+			}
+		}
+
+		if err := s.Prewarm(ctx, repo, head); err != nil {
+			log15.Warn("failed to pre-warm repository", "repo", repo, "commit", head, "error", err)
+			prewarmCounter.WithLabelValues("failed").Inc()
+			return
+		}
+		prewarmCounter.WithLabelValues("succeeded").Inc()
+	}()
+}
+
+var prewarmCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_gitserver_prewarm_total",
+	Help: "The number of pre-warm events emitted after fetches, by outcome.",
+}, []string{"outcome"})
+
+// revParseHead returns the commit HEAD points to in the repository in dir.
+func revParseHead(ctx context.Context, dir GitDir) (api.CommitID, error) {
+	out, err := gitOutput(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return api.CommitID(strings.TrimSpace(string(out))), nil
+}
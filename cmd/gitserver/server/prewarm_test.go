@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestPrewarm(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		SearchPrewarm: &schema.SearchPrewarm{
+			Repos:        []string{`^example\.com/`, `(`},
+			MaxPerMinute: 2,
+		},
+	}})
+	defer conf.Mock(nil)
+
+	var (
+		mu        sync.Mutex
+		prewarmed []string
+	)
+	s := &Server{
+		Prewarm: func(ctx context.Context, repo api.RepoName, head api.CommitID) error {
+			mu.Lock()
+			defer mu.Unlock()
+			prewarmed = append(prewarmed, string(repo)+"@"+string(head))
+			return nil
+		},
+		IsIndexed: func(ctx context.Context, repo api.RepoName) (bool, error) {
+			return repo == "example.com/indexed", nil
+		},
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	defer s.cancel()
+	s.setPrewarmConfig()
+
+	s.prewarm("other.com/repo", "a")      // not allowed
+	s.prewarm("example.com/repo", "b")    // prewarmed
+	s.prewarm("example.com/indexed", "c") // indexed
+	s.prewarm("example.com/repo", "d")    // rate limited
+	s.prewarm("example.com/unlucky", "e") // rate limited
+	s.wg.Wait()
+
+	if diff := cmp.Diff([]string{"example.com/repo@b"}, prewarmed); diff != "" {
+		t.Errorf("unexpected prewarmed repos (-want +got):\n%s", diff)
+	}
+}
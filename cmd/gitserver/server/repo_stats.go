@@ -0,0 +1,260 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-enry/go-enry/v2"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/inventory"
+)
+
+const (
+	// repoStatsMaxEntries is the maximum number of contributors and files
+	// returned in repository statistics.
+	repoStatsMaxEntries = 100
+
+	// repoStatsChurnCommits is the number of most recent commits whose
+	// changes are counted as file churn.
+	repoStatsChurnCommits = 1000
+
+	// repoStatsCacheSize is the number of repository statistics kept in
+	// memory.
+	repoStatsCacheSize = 1000
+)
+
+// NewRepoStatsCache returns a cache of repository statistics to be used as
+// Server.RepoStatsCache.
+func NewRepoStatsCache() (*lru.Cache, error) {
+	return lru.New(repoStatsCacheSize)
+}
+
+func (s *Server) handleRepoStats(w http.ResponseWriter, r *http.Request) {
+	var req protocol.RepoStatsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Repo = protocol.NormalizeRepo(req.Repo)
+	dir := s.dir(req.Repo)
+	if !repoCloned(dir) {
+		http.Error(w, "repository not found", http.StatusNotFound)
+		return
+	}
+
+	stats, err := s.repoStats(r.Context(), dir, req.Repo, req.Commit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// repoStats returns the statistics of the repository in dir at the given
+// commit, computing them if they are not cached.
+func (s *Server) repoStats(ctx context.Context, dir GitDir, repo api.RepoName, commit api.CommitID) (*protocol.RepoStats, error) {
+	rev := string(commit)
+	if rev == "" {
+		rev = "HEAD"
+	}
+	if strings.HasPrefix(rev, "-") {
+		return nil, errors.Errorf("invalid git revision spec %q (begins with '-')", rev)
+	}
+
+	out, err := gitOutput(ctx, dir, "rev-parse", "--verify", rev+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	resolved := api.CommitID(strings.TrimSpace(string(out)))
+
+	key := string(repo) + "@" + string(resolved)
+	if s.RepoStatsCache != nil {
+		if stats, ok := s.RepoStatsCache.Get(key); ok {
+			return stats.(*protocol.RepoStats), nil
+		}
+	}
+
+	stats := &protocol.RepoStats{Commit: resolved}
+
+	if out, err = gitOutput(ctx, dir, "ls-tree", "-r", "-l", "-z", string(resolved)); err != nil {
+		return nil, err
+	}
+	if stats.Languages, err = parseLanguageStats(out); err != nil {
+		return nil, err
+	}
+
+	if out, err = gitOutput(ctx, dir, "shortlog", "-sne", string(resolved), "--"); err != nil {
+		return nil, err
+	}
+	if stats.Contributors, err = parseContributorStats(out); err != nil {
+		return nil, err
+	}
+
+	if out, err = gitOutput(ctx, dir, "log", "--no-merges", "--no-renames", "--numstat", "--format=format:",
+		"-n", strconv.Itoa(repoStatsChurnCommits), string(resolved), "--"); err != nil {
+		return nil, err
+	}
+	if stats.Churn, err = parseChurnStats(out); err != nil {
+		return nil, err
+	}
+
+	if s.RepoStatsCache != nil {
+		s.RepoStatsCache.Add(key, stats)
+	}
+	return stats, nil
+}
+
+// parseLanguageStats computes the language breakdown from the output of git
+// ls-tree -r -l -z. Languages are detected from file names, like
+// inventory.GetLanguageByFilename does.
+func parseLanguageStats(out []byte) ([]protocol.RepoStatsLanguage, error) {
+	byName := make(map[string]*protocol.RepoStatsLanguage)
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		if len(entry) == 0 {
+			continue
+		}
+
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		tab := bytes.IndexByte(entry, '\t')
+		if tab < 0 {
+			return nil, errors.Errorf("invalid ls-tree entry %q", entry)
+		}
+		fields := strings.Fields(string(entry[:tab]))
+		if len(fields) != 4 {
+			return nil, errors.Errorf("invalid ls-tree entry %q", entry)
+		}
+		mode, typ, sizeField, name := fields[0], fields[1], fields[3], string(entry[tab+1:])
+		if typ != "blob" || mode == "120000" || enry.IsVendor(name) {
+			// Skip submodules, symlinks and vendored files.
+			continue
+		}
+
+		language, _ := inventory.GetLanguageByFilename(name)
+		if language == "" {
+			language, _ = enry.GetLanguageByFilename(path.Base(name))
+		}
+		if language == "" {
+			continue
+		}
+
+		size, err := strconv.ParseInt(sizeField, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid ls-tree entry %q", entry)
+		}
+
+		lang, ok := byName[language]
+		if !ok {
+			lang = &protocol.RepoStatsLanguage{Name: language}
+			byName[language] = lang
+		}
+		lang.TotalFiles++
+		lang.TotalBytes += size
+	}
+
+	languages := make([]protocol.RepoStatsLanguage, 0, len(byName))
+	for _, lang := range byName {
+		languages = append(languages, *lang)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if languages[i].TotalBytes != languages[j].TotalBytes {
+			return languages[i].TotalBytes > languages[j].TotalBytes
+		}
+		return languages[i].Name < languages[j].Name
+	})
+	return languages, nil
+}
+
+// parseContributorStats parses the output of git shortlog -sne, which is
+// already sorted by descending number of commits.
+func parseContributorStats(out []byte) ([]protocol.RepoStatsContributor, error) {
+	var contributors []protocol.RepoStatsContributor
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() && len(contributors) < repoStatsMaxEntries {
+		// "   42\tName <email>"
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid shortlog line %q", line)
+		}
+		commits, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, errors.Errorf("invalid shortlog line %q", line)
+		}
+
+		contributor := protocol.RepoStatsContributor{Name: parts[1], Commits: commits}
+		if i := strings.LastIndex(parts[1], " <"); i >= 0 && strings.HasSuffix(parts[1], ">") {
+			contributor.Name = parts[1][:i]
+			contributor.Email = parts[1][i+2 : len(parts[1])-1]
+		}
+		contributors = append(contributors, contributor)
+	}
+	return contributors, scanner.Err()
+}
+
+// parseChurnStats computes the files changed most often from the output of git
+// log --numstat --format=format:, which lists the lines added and deleted in
+// each file changed by each commit.
+func parseChurnStats(out []byte) ([]protocol.RepoStatsFileChurn, error) {
+	byPath := make(map[string]*protocol.RepoStatsFileChurn)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+	for scanner.Scan() {
+		// "<added>\t<deleted>\t<path>", with "-" counts for binary files.
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			return nil, errors.Errorf("invalid numstat line %q", line)
+		}
+
+		file, ok := byPath[parts[2]]
+		if !ok {
+			file = &protocol.RepoStatsFileChurn{Path: parts[2]}
+			byPath[parts[2]] = file
+		}
+		file.Commits++
+		added, _ := strconv.Atoi(parts[0])
+		deleted, _ := strconv.Atoi(parts[1])
+		file.LinesAdded += added
+		file.LinesDeleted += deleted
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	churn := make([]protocol.RepoStatsFileChurn, 0, len(byPath))
+	for _, file := range byPath {
+		churn = append(churn, *file)
+	}
+	sort.Slice(churn, func(i, j int) bool {
+		if churn[i].Commits != churn[j].Commits {
+			return churn[i].Commits > churn[j].Commits
+		}
+		return churn[i].Path < churn[j].Path
+	})
+	if len(churn) > repoStatsMaxEntries {
+		churn = churn[:repoStatsMaxEntries]
+	}
+	return churn, nil
+}
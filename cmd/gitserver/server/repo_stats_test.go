@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestHandleRepoStats(t *testing.T) {
+	reposDir := t.TempDir()
+	repoDir := filepath.Join(reposDir, "example.com/repo")
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, repoDir, name, arg...)
+	}
+	cmd("git", "init", ".")
+	cmd("sh", "-c", "printf 'package main\\n' > main.go && printf 'x\\ny\\n' > README.md")
+	cmd("git", "add", ".")
+	cmd("git", "commit", "-m", "first")
+	cmd("sh", "-c", "mkdir -p vendor/dep && printf 'package dep\\n' > vendor/dep/dep.go && printf 'package main\\n\\nfunc main() {}\\n' > main.go")
+	cmd("git", "add", ".")
+	cmd("git", "-c", "user.name=b", "-c", "user.email=b@b.com", "commit", "-m", "second", "--author", "b <b@b.com>")
+	first := strings.TrimSpace(cmd("git", "rev-parse", "HEAD~1"))
+
+	cache, err := NewRepoStatsCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{ReposDir: reposDir, RepoStatsCache: cache}
+
+	repoStats := func(t *testing.T, req string) (*protocol.RepoStats, int) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		s.handleRepoStats(w, httptest.NewRequest("POST", "/repo-stats", strings.NewReader(req)))
+		if w.Code != http.StatusOK {
+			return nil, w.Code
+		}
+		var stats protocol.RepoStats
+		if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+			t.Fatal(err)
+		}
+		return &stats, w.Code
+	}
+
+	t.Run("head", func(t *testing.T) {
+		stats, code := repoStats(t, `{"Repo": "example.com/repo"}`)
+		if code != http.StatusOK {
+			t.Fatalf("got status %d", code)
+		}
+
+		wantLanguages := []protocol.RepoStatsLanguage{
+			{Name: "Go", TotalFiles: 1, TotalBytes: 29},
+			{Name: "Markdown", TotalFiles: 1, TotalBytes: 4},
+		}
+		if diff := cmp.Diff(wantLanguages, stats.Languages); diff != "" {
+			t.Errorf("unexpected languages (-want +got):\n%s", diff)
+		}
+
+		wantContributors := []protocol.RepoStatsContributor{
+			{Name: "a", Email: "a@a.com", Commits: 1},
+			{Name: "b", Email: "b@b.com", Commits: 1},
+		}
+		if diff := cmp.Diff(wantContributors, stats.Contributors); diff != "" {
+			t.Errorf("unexpected contributors (-want +got):\n%s", diff)
+		}
+
+		wantChurn := []protocol.RepoStatsFileChurn{
+			{Path: "main.go", Commits: 2, LinesAdded: 3, LinesDeleted: 0},
+			{Path: "README.md", Commits: 1, LinesAdded: 2},
+			{Path: "vendor/dep/dep.go", Commits: 1, LinesAdded: 1},
+		}
+		if diff := cmp.Diff(wantChurn, stats.Churn); diff != "" {
+			t.Errorf("unexpected churn (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		stats, code := repoStats(t, `{"Repo": "example.com/repo", "Commit": "`+first+`"}`)
+		if code != http.StatusOK {
+			t.Fatalf("got status %d", code)
+		}
+		if string(stats.Commit) != first {
+			t.Errorf("got commit %s, want %s", stats.Commit, first)
+		}
+		if len(stats.Contributors) != 1 || len(stats.Churn) != 2 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("repo not cloned", func(t *testing.T) {
+		if _, code := repoStats(t, `{"Repo": "example.com/missing"}`); code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", code, http.StatusNotFound)
+		}
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -26,6 +27,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/errors"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/inconshreveable/log15"
 	"github.com/opentracing/opentracing-go/ext"
 	otlog "github.com/opentracing/opentracing-go/log"
@@ -230,6 +232,10 @@ type Server struct {
 
 	locker *RepositoryLocker
 
+	// accesses records how often repositories are accessed, which decides
+	// which repositories are evicted first when disk space runs low.
+	accesses accessTracker
+
 	// cloneLimiter and cloneableLimiter limits the number of concurrent
 	// clones and ls-remotes respectively. Use s.acquireCloneLimiter() and
 	// s.acquireClonableLimiter() instead of using these directly.
@@ -242,6 +248,25 @@ type Server struct {
 
 	repoUpdateLocksMu sync.Mutex // protects the map below and also updates to locks.once
 	repoUpdateLocks   map[api.RepoName]*locks
+
+	// Prewarm, if set, is called in the background after a fetch moved the
+	// HEAD of a repository allowed by the "search.prewarm" site
+	// configuration. It prepares the caches of the services which search
+	// the new HEAD, such as searcher and symbols.
+	Prewarm func(ctx context.Context, repo api.RepoName, head api.CommitID) error
+
+	// IsIndexed, if set, reports whether the default branch of a repository
+	// is indexed by indexed search. Such repositories are not pre-warmed.
+	IsIndexed func(ctx context.Context, repo api.RepoName) (bool, error)
+
+	// RepoStatsCache, if set, caches repository statistics by repository and
+	// commit. The statistics of a commit never change, so entries are only
+	// evicted to bound memory usage.
+	RepoStatsCache *lru.Cache
+
+	prewarmMu      sync.Mutex // protects the fields below
+	prewarmRepos   []*regexp.Regexp
+	prewarmLimiter *rate.Limiter
 }
 
 type locks struct {
@@ -337,10 +362,13 @@ func (s *Server) Handler() http.Handler {
 		setRPSLimiter()
 	})
 
+	conf.Watch(s.setPrewarmConfig)
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/archive", s.handleArchive)
 	mux.HandleFunc("/exec", s.handleExec)
 	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/blame", s.handleBlame)
 	mux.HandleFunc("/p4-exec", s.handleP4Exec)
 	mux.HandleFunc("/list", s.handleList)
 	mux.HandleFunc("/list-gitolite", s.handleListGitolite)
@@ -348,6 +376,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/is-repo-cloned", s.handleIsRepoCloned)
 	mux.HandleFunc("/repos", s.handleRepoInfo)
 	mux.HandleFunc("/repos-stats", s.handleReposStats)
+	mux.HandleFunc("/repo-stats", s.handleRepoStats)
 	mux.HandleFunc("/repo-clone-progress", s.handleRepoCloneProgress)
 	mux.HandleFunc("/delete", s.handleRepoDelete)
 	mux.HandleFunc("/repo-update", s.handleRepoUpdate)
@@ -951,6 +980,7 @@ func (s *Server) search(w http.ResponseWriter, r *http.Request, args *protocol.S
 	}
 
 	dir := s.dir(args.Repo)
+	s.accesses.record(dir, time.Now())
 	if !repoCloned(dir) {
 		if conf.Get().DisableAutoGitUpdates {
 			log15.Debug("not cloning on demand as DisableAutoGitUpdates is set")
@@ -970,6 +1000,8 @@ func (s *Server) search(w http.ResponseWriter, r *http.Request, args *protocol.S
 			return
 		}
 
+		// The repo is requested, so it is no longer evicted.
+		s.setEvictedAtNonFatal(ctx, args.Repo, time.Time{})
 		cloneProgress, err := s.cloneRepo(ctx, args.Repo, nil)
 		if err != nil {
 			log15.Debug("error starting repo clone", "repo", args.Repo, "err", err)
@@ -1208,6 +1240,7 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	}
 
 	dir := s.dir(req.Repo)
+	s.accesses.record(dir, time.Now())
 	if !repoCloned(dir) {
 		if conf.Get().DisableAutoGitUpdates {
 			log15.Debug("not cloning on demand as DisableAutoGitUpdates is set")
@@ -1228,6 +1261,8 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 			return
 		}
 
+		// The repo is requested, so it is no longer evicted.
+		s.setEvictedAtNonFatal(ctx, req.Repo, time.Time{})
 		cloneProgress, err := s.cloneRepo(ctx, req.Repo, nil)
 		if err != nil {
 			log15.Debug("error starting repo clone", "repo", req.Repo, "err", err)
@@ -1775,6 +1810,10 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 		log15.Warn("failed setting last fetch in DB", "repo", repo, "error", err)
 	}
 
+	// However the clone was requested, the repo is on disk again and must no
+	// longer be skipped by repo-updater's scheduler.
+	s.setEvictedAtNonFatal(ctx, repo, time.Time{})
+
 	log15.Info("repo cloned", "repo", repo)
 	repoClonedCounter.Inc()
 
@@ -2048,6 +2087,14 @@ func (s *Server) doBackgroundRepoUpdate(repo api.RepoName) error {
 	// when the cleanup happens, just that it does.
 	defer s.cleanTmpFiles(dir)
 
+	// HEAD is only compared before and after the fetch for repositories
+	// which are pre-warmed when it moves.
+	shouldPrewarm := s.shouldPrewarm(repo)
+	var oldHead api.CommitID
+	if shouldPrewarm {
+		oldHead, _ = revParseHead(ctx, dir)
+	}
+
 	err = syncer.Fetch(ctx, remoteURL, dir)
 	if err != nil {
 		log15.Error("Failed to fetch", "repo", repo, "error", err)
@@ -2065,6 +2112,12 @@ func (s *Server) doBackgroundRepoUpdate(repo api.RepoName) error {
 		return errors.Wrap(err, `git config set "sourcegraph.type"`)
 	}
 
+	if shouldPrewarm {
+		if head, err := revParseHead(ctx, dir); err == nil && head != oldHead {
+			s.prewarm(repo, head)
+		}
+	}
+
 	// Update the last-changed stamp.
 	if err := setLastChanged(dir); err != nil {
 		log15.Warn("Failed to update last changed time", "repo", repo, "error", err)
@@ -676,6 +676,67 @@ func TestHandleRepoUpdate(t *testing.T) {
 	}
 }
 
+func TestHandleRepoUpdateClearsEviction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	remote := t.TempDir()
+	repoName := api.RepoName("example.com/foo/bar")
+	db := dbtest.NewDB(t)
+
+	dbRepo := &types.Repo{
+		Name:        repoName,
+		Description: "Test",
+	}
+	if err := database.Repos(db).Create(ctx, dbRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, remote, name, arg...)
+	}
+	_ = makeSingleCommitRepo(cmd)
+
+	reposDir := t.TempDir()
+	s := makeTestServer(ctx, reposDir, remote, db)
+	_ = s.Handler()
+
+	// The repo was evicted by a janitor run before a user refreshed it
+	if err := database.GitserverRepos(db).SetEvictedAt(ctx, repoName, time.Now(), s.Hostname); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(protocol.RepoUpdateRequest{Repo: repoName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// This will perform a clone
+	req := httptest.NewRequest("GET", "/repo-update", bytes.NewReader(body))
+	s.handleRepoUpdate(httptest.NewRecorder(), req)
+
+	fromDB, err := database.GitserverRepos(db).GetByID(ctx, dbRepo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromDB.CloneStatus != types.CloneStatusCloned {
+		t.Fatalf("unexpected clone status. want=%q have=%q", types.CloneStatusCloned, fromDB.CloneStatus)
+	}
+	if !fromDB.EvictedAt.IsZero() {
+		t.Errorf("expected eviction to be cleared by the clone, have evicted at %s", fromDB.EvictedAt)
+	}
+
+	// The repos skipped by repo-updater's scheduler no longer include the repo
+	evicted, err := database.Repos(db).ListRepoNames(ctx, database.ReposListOptions{Names: []string{string(repoName)}, OnlyEvicted: true, NoCloned: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 0 {
+		t.Errorf("expected cloned repo to be scheduled again, have evicted repos %v", evicted)
+	}
+}
+
 func TestRemoveBadRefs(t *testing.T) {
 	dir := t.TempDir()
 	gitDir := GitDir(filepath.Join(dir, ".git"))
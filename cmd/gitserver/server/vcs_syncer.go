@@ -118,6 +118,23 @@ func (s *GitRepoSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDi
 	return nil
 }
 
+// Refetch fetches all objects reachable from the refs of a Git repository
+// again, rather than only those of refs which changed, which restores objects
+// missing from the repository. It requires git 2.36 or later, and is not
+// supported for custom fetch commands.
+func (s *GitRepoSyncer) Refetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
+	cmd, configRemoteOpts := s.fetchCommand(ctx, remoteURL)
+	if len(cmd.Args) < 2 || filepath.Base(cmd.Args[0]) != "git" || cmd.Args[1] != "fetch" {
+		return errors.Errorf("refetch is not supported for custom fetch command %q", strings.Join(cmd.Args, " "))
+	}
+	cmd.Args = append([]string{cmd.Args[0], "fetch", "--refetch"}, cmd.Args[2:]...)
+	dir.Set(cmd)
+	if output, err := runWith(ctx, cmd, configRemoteOpts, nil); err != nil {
+		return errors.Wrapf(err, "failed to refetch with output %q", newURLRedactor(remoteURL).redact(string(output)))
+	}
+	return nil
+}
+
 // RemoteShowCommand returns the command to be executed for showing remote of a Git repository.
 func (s *GitRepoSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, err error) {
 	return exec.CommandContext(ctx, "git", "remote", "show", remoteURL.String()), nil
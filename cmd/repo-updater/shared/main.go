@@ -350,6 +350,9 @@ type scheduler interface {
 	// PrioritiseUncloned ensures uncloned repos are given priority in the scheduler.
 	PrioritiseUncloned([]string)
 
+	// SetEvicted sets the repos evicted by gitserver, which the scheduler skips.
+	SetEvicted([]api.RepoID)
+
 	// ListRepos lists all the repos managed by the scheduler.
 	ListRepos() []string
 
@@ -428,9 +431,12 @@ func syncScheduler(ctx context.Context, sched scheduler, gitserverClient *gitser
 		}
 
 		// Fetch ALL indexable repos that are NOT cloned so that we can add them to the
-		// scheduler
+		// scheduler. Repos evicted by gitserver to free up disk space are left
+		// until they are requested again, so that we don't clone them straight
+		// back.
 		opts := database.ListIndexableReposOptions{
 			OnlyUncloned:   true,
+			NoEvicted:      true,
 			IncludePrivate: true,
 		}
 		if u, err := baseRepoStore.ListIndexableRepos(ctx, opts); err != nil {
@@ -442,10 +448,10 @@ func syncScheduler(ctx context.Context, sched scheduler, gitserverClient *gitser
 		}
 
 		// Next, move any repos managed by the scheduler that are uncloned to the front
-		// of the queue
+		// of the queue, except for evicted repos.
 		managed := sched.ListRepos()
 
-		uncloned, err := baseRepoStore.ListRepoNames(ctx, database.ReposListOptions{Names: managed, NoCloned: true})
+		uncloned, err := baseRepoStore.ListRepoNames(ctx, database.ReposListOptions{Names: managed, NoCloned: true, NoEvicted: true})
 		if err != nil {
 			log15.Warn("failed to fetch list of uncloned repositories", "error", err)
 			return
@@ -456,6 +462,22 @@ func syncScheduler(ctx context.Context, sched scheduler, gitserverClient *gitser
 		}
 
 		sched.PrioritiseUncloned(names)
+
+		// Finally, skip updates of evicted repos, since an update clones them
+		// again. Searches clear the eviction of the repos they request, and any
+		// successful clone clears it too. Cloned repos are never skipped, in
+		// case clearing the eviction failed.
+		evicted, err := baseRepoStore.ListRepoNames(ctx, database.ReposListOptions{Names: managed, OnlyEvicted: true, NoCloned: true})
+		if err != nil {
+			log15.Warn("failed to fetch list of evicted repositories", "error", err)
+			return
+		}
+		ids := make([]api.RepoID, len(evicted))
+		for i := range evicted {
+			ids[i] = evicted[i].ID
+		}
+
+		sched.SetEvicted(ids)
 	}
 
 	for ctx.Err() == nil {
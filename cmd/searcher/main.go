@@ -28,6 +28,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/tracer"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
 var cacheDir = env.Get("CACHE_DIR", "/tmp", "directory to store cached archives.")
@@ -62,6 +63,11 @@ func main() {
 			FetchTar: func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
 				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar"})
 			},
+			FetchTarPaths: func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar", Paths: gitserver.LiteralPathspecs(paths)})
+			},
+			Ancestors:         git.Ancestors,
+			ChangedFiles:      git.ChangedFiles,
 			FilterTar:         search.NewFilter,
 			Path:              filepath.Join(cacheDir, "searcher-archives"),
 			MaxCacheSizeBytes: cacheSizeBytes,
@@ -88,6 +94,14 @@ func main() {
 				_, _ = w.Write([]byte("ok"))
 				return
 			}
+			switch r.URL.Path {
+			case "/cached-commits":
+				service.ServeCachedCommits(w, r)
+				return
+			case "/prewarm":
+				service.ServePrewarm(w, r)
+				return
+			}
 			handler.ServeHTTP(w, r)
 		}),
 	}
@@ -221,3 +221,24 @@ type LineMatch struct {
 	// Offsets and lengths are measured in characters, not bytes.
 	OffsetAndLengths [][2]int
 }
+
+// CachedCommits is the response of the /cached-commits endpoint of searcher.
+// It lists the commits whose archives the searcher holds in its cache, so that
+// clients can send searches of those commits to a searcher which does not need
+// to fetch the archive first.
+type CachedCommits struct {
+	Commits []RepoCommit
+}
+
+// RepoCommit is a commit of a repository.
+type RepoCommit struct {
+	Repo   api.RepoName
+	Commit api.CommitID
+}
+
+// PrewarmRequest is the request of the /prewarm endpoint of searcher, which
+// prepares the archive of a commit in the background.
+type PrewarmRequest struct {
+	Repo   api.RepoName
+	Commit api.CommitID
+}
@@ -87,6 +87,47 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.streamSearch(ctx, w, p)
 }
 
+// ServeCachedCommits lists the commits whose archives are in the store, so
+// that clients can prefer this searcher for searches of those commits.
+func (s *Service) ServeCachedCommits(w http.ResponseWriter, r *http.Request) {
+	cached := s.Store.CachedCommits()
+	resp := protocol.CachedCommits{Commits: make([]protocol.RepoCommit, 0, len(cached))}
+	for _, c := range cached {
+		resp.Commits = append(resp.Commits, protocol.RepoCommit{Repo: c.Repo, Commit: c.Commit})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ServePrewarm starts preparing the archive of the requested commit in the
+// background, so that later searches of the commit don't need to fetch it.
+func (s *Service) ServePrewarm(w http.ResponseWriter, r *http.Request) {
+	var req protocol.PrewarmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "failed to decode form: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Commit) != 40 {
+		http.Error(w, fmt.Sprintf("Commit must be resolved (Commit=%q)", req.Commit), http.StatusBadRequest)
+		return
+	}
+
+	go func() {
+		// PrepareZip fetches in the background with its own timeout, so
+		// this context only bounds the wait.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		if _, err := s.Store.PrepareZip(ctx, req.Repo, req.Commit); err != nil {
+			s.Log.Warn("failed to prewarm archive", "repo", req.Repo, "commit", req.Commit, "error", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
 func (s *Service) streamSearch(ctx context.Context, w http.ResponseWriter, p protocol.Request) {
 	if p.Limit == 0 {
 		// No limit for streaming search since upstream limits
@@ -16,9 +16,11 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/comby"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/store"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 // The Sourcegraph frontend and interface only allow LineMatches (matches on a
@@ -228,6 +230,47 @@ func toMatcher(languages []string, extensionHint string) string {
 	return ".generic"
 }
 
+// lookupCustomMatcher returns the custom matcher from the
+// "search.structural.matchers" site configuration that parameterizes
+// structural search, or nil if there is none. Like toMatcher, it considers
+// the first of an explicit list of languages, or otherwise the extension hint.
+func lookupCustomMatcher(matchers []*schema.StructuralSearchMatcher, languages []string, extensionHint string) *schema.StructuralSearchMatcher {
+	for _, m := range matchers {
+		names, name := m.Extensions, extensionHint
+		if len(languages) > 0 {
+			names, name = m.Languages, languages[0]
+		}
+		for _, n := range names {
+			if name != "" && strings.EqualFold(n, name) {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// toCombySyntax converts a custom matcher from the site configuration to its
+// comby language definition. Malformed pairs are ignored, since the site
+// configuration schema validates them.
+func toCombySyntax(m *schema.StructuralSearchMatcher) *comby.Syntax {
+	pairs := func(ps [][]string) (pairs [][2]string) {
+		for _, p := range ps {
+			if len(p) == 2 {
+				pairs = append(pairs, [2]string{p[0], p[1]})
+			}
+		}
+		return pairs
+	}
+	return &comby.Syntax{
+		Delimiters:        pairs(m.Delimiters),
+		StringLiterals:    m.StringLiterals,
+		EscapeCharacter:   m.EscapeCharacter,
+		RawStringLiterals: pairs(m.RawStringLiterals),
+		LineComments:      m.LineComments,
+		BlockComments:     pairs(m.BlockComments),
+	}
+}
+
 // A variant type that represents whether to search all files in a Zip file
 // (type UniversalSet), or just a subset (type Subset).
 type filePatterns interface {
@@ -248,8 +291,6 @@ func structuralSearch(ctx context.Context, zipPath string, paths filePatterns, e
 	// Cap the number of forked processes to limit the size of zip contents being mapped to memory. Resolving #7133 could help to lift this restriction.
 	numWorkers := 4
 
-	matcher := toMatcher(languages, extensionHint)
-
 	var filePatterns []string
 	if v, ok := paths.(Subset); ok {
 		filePatterns = []string(v)
@@ -257,7 +298,6 @@ func structuralSearch(ctx context.Context, zipPath string, paths filePatterns, e
 
 	args := comby.Args{
 		Input:         comby.ZipPath(zipPath),
-		Matcher:       matcher,
 		MatchTemplate: pattern,
 		ResultKind:    comby.MatchOnly,
 		FilePatterns:  filePatterns,
@@ -265,6 +305,14 @@ func structuralSearch(ctx context.Context, zipPath string, paths filePatterns, e
 		NumWorkers:    numWorkers,
 	}
 
+	// Custom matchers take precedence over the built-in matchers of comby.
+	if m := lookupCustomMatcher(conf.Get().SearchStructuralMatchers, languages, extensionHint); m != nil {
+		requestTotalStructuralSearch.WithLabelValues("custom").Inc()
+		args.CustomMatcher = toCombySyntax(m)
+	} else {
+		args.Matcher = toMatcher(languages, extensionHint)
+	}
+
 	combyMatches, err := comby.Matches(ctx, args)
 	if err != nil {
 		return err
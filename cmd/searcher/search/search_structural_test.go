@@ -17,6 +17,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/comby"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	storetest "github.com/sourcegraph/sourcegraph/internal/store/testutil"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func TestMatcherLookupByLanguage(t *testing.T) {
@@ -295,6 +296,45 @@ func TestRecordMetrics(t *testing.T) {
 	}
 }
 
+func TestLookupCustomMatcher(t *testing.T) {
+	dsl := &schema.StructuralSearchMatcher{Languages: []string{"MyDSL"}, Extensions: []string{".dsl"}}
+	matchers := []*schema.StructuralSearchMatcher{dsl}
+
+	cases := []struct {
+		name          string
+		languages     []string
+		extensionHint string
+		want          *schema.StructuralSearchMatcher
+	}{
+		{name: "language", languages: []string{"mydsl"}, want: dsl},
+		{name: "extension", extensionHint: ".DSL", want: dsl},
+		{name: "explicit language wins", languages: []string{"go"}, extensionHint: ".dsl"},
+		{name: "built-in language", languages: []string{"go"}},
+		{name: "no hint"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lookupCustomMatcher(matchers, tt.languages, tt.extensionHint); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	got := toCombySyntax(&schema.StructuralSearchMatcher{
+		Delimiters:    [][]string{{"begin", "end"}, {"malformed"}},
+		LineComments:  []string{"#"},
+		BlockComments: [][]string{{"(*", "*)"}},
+	})
+	want := &comby.Syntax{
+		Delimiters:    [][2]string{{"begin", "end"}},
+		LineComments:  []string{"#"},
+		BlockComments: [][2]string{{"(*", "*)"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
 // Tests that includePatterns works. includePatterns serve a similar role in
 // structural search compared to regex search, but is interpreted _differently_.
 // includePatterns cannot be a regex expression (as in traditional search), but
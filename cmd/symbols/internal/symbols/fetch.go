@@ -20,7 +20,9 @@ type parseRequest struct {
 	data []byte
 }
 
-func (s *Service) fetchRepositoryArchive(ctx context.Context, repo api.RepoName, commitID api.CommitID) (<-chan parseRequest, <-chan error, error) {
+// fetchRepositoryArchive streams the files of repo@commitID as parse requests.
+// If paths is non-empty, only those paths are fetched.
+func (s *Service) fetchRepositoryArchive(ctx context.Context, repo api.RepoName, commitID api.CommitID, paths []string) (<-chan parseRequest, <-chan error, error) {
 	fetchQueueSize.Inc()
 	s.fetchSem <- 1 // acquire concurrent fetches semaphore
 	fetchQueueSize.Dec()
@@ -60,8 +62,15 @@ func (s *Service) fetchRepositoryArchive(ctx context.Context, repo api.RepoName,
 		span.Finish()
 	}
 
-	r, err := s.FetchTar(ctx, repo, commitID)
+	var r io.ReadCloser
+	var err error
+	if len(paths) > 0 {
+		r, err = s.FetchTarPaths(ctx, repo, commitID, paths)
+	} else {
+		r, err = s.FetchTar(ctx, repo, commitID)
+	}
 	if err != nil {
+		done(err)
 		return nil, nil, err
 	}
 
@@ -0,0 +1,152 @@
+package symbols
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// writeSymbolsIncrementally writes the symbols of repo@commitID to the blank
+// database file `dbFile` by copying the database of the closest ancestor commit
+// which is already in the cache, and re-parsing only the paths that changed
+// between the two commits. It returns false if there is no such ancestor or
+// incremental indexing is not configured.
+func (s *Service) writeSymbolsIncrementally(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID) (ok bool, err error) {
+	if s.ChangedFiles == nil || s.Ancestors == nil || s.FetchTarPaths == nil {
+		return false, nil
+	}
+
+	ancestors, err := s.Ancestors(ctx, repoName, commitID, s.MaxIncrementalAncestors)
+	if err != nil {
+		return false, errors.Wrap(err, "Ancestors")
+	}
+
+	var (
+		ancestor api.CommitID
+		cached   *os.File
+	)
+	for _, candidate := range ancestors {
+		if f, ok := s.cache.Peek(dbCacheKey(repoName, candidate)); ok {
+			ancestor, cached = candidate, f.File
+			break
+		}
+	}
+	if cached == nil {
+		incrementalIndexing.WithLabelValues("miss").Inc()
+		return false, nil
+	}
+	defer cached.Close()
+
+	changed, deleted, err := s.ChangedFiles(ctx, repoName, ancestor, commitID)
+	if err != nil {
+		return false, errors.Wrap(err, "ChangedFiles")
+	}
+	numChanges := len(changed) + len(deleted)
+	if numChanges > s.MaxIncrementalChanges {
+		incrementalIndexing.WithLabelValues("too_many_changes").Inc()
+		return false, nil
+	}
+
+	log15.Debug("Incrementally indexing symbols.", "repo", repoName, "commit", commitID, "ancestor", ancestor, "changes", numChanges)
+
+	if err := copyToFile(dbFile, cached); err != nil {
+		return false, err
+	}
+	if err := s.applyChanges(ctx, dbFile, repoName, commitID, changed, deleted); err != nil {
+		// Leave a blank database file behind for a full index.
+		if truncErr := os.Truncate(dbFile, 0); truncErr != nil {
+			return false, multierror.Append(err, truncErr)
+		}
+		return false, err
+	}
+
+	incrementalIndexing.WithLabelValues("hit").Inc()
+	incrementalIndexingChangedPaths.Observe(float64(numChanges))
+	return true, nil
+}
+
+// applyChanges updates the database file `dbFile` containing the symbols of an
+// ancestor of repo@commitID such that it contains the symbols of
+// repo@commitID. changed are the paths added or modified since the ancestor,
+// and deleted are the paths removed since the ancestor.
+func (s *Service) applyChanges(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID, changed, deleted []string) (err error) {
+	db, err := sqlx.Open("sqlite3_with_regexp", dbFile)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	deleteStatement, err := tx.Preparex(`DELETE FROM symbols WHERE path = ?`)
+	if err != nil {
+		return err
+	}
+	for _, paths := range [][]string{changed, deleted} {
+		for _, path := range paths {
+			if _, err := deleteStatement.ExecContext(ctx, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	insertStatement, err := prepareInsertSymbol(tx)
+	if err != nil {
+		return err
+	}
+
+	return s.parseUncached(ctx, repoName, commitID, changed, func(symbol result.Symbol) error {
+		symbolInDBValue := symbolToSymbolInDB(symbol)
+		_, err := insertStatement.Exec(&symbolInDBValue)
+		return err
+	})
+}
+
+// copyToFile overwrites the file at path with the contents of src.
+func copyToFile(path string, src io.Reader) error {
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err1 := dst.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+var (
+	incrementalIndexing = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "symbols_incremental_indexing_total",
+		Help: "The total number of incremental indexing attempts by result (hit, miss, too_many_changes, error).",
+	}, []string{"result"})
+	incrementalIndexingChangedPaths = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "symbols_incremental_indexing_changed_paths",
+		Help:    "The number of changed paths re-parsed by incremental indexing.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	})
+)
@@ -0,0 +1,80 @@
+package symbols
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sourcegraph/go-ctags"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// parserTreeSitter is the "symbols.parsers" site configuration value which
+// selects the tree-sitter parser for a language.
+const parserTreeSitter = "tree-sitter"
+
+// NewParser returns a parser which extracts symbols with universal-ctags or
+// tree-sitter, chosen per language by the "symbols.parsers" site
+// configuration.
+func NewParser() (ctags.Parser, error) {
+	return newDispatchParser(NewCtagsParser, NewTreeSitterParser, func() map[string]string {
+		return conf.Get().SymbolsParsers
+	})
+}
+
+// dispatchParser is a ctags.Parser which hands each file to the parser
+// configured for its language. Files default to universal-ctags.
+type dispatchParser struct {
+	ctags ctags.Parser
+
+	// treeSitter is created on first use, so that instances which never
+	// parse with tree-sitter do not pay for it.
+	treeSitter      ctags.Parser
+	newTreeSitter   func() (ctags.Parser, error)
+	languageParsers func() map[string]string
+}
+
+func newDispatchParser(newCtags, newTreeSitter func() (ctags.Parser, error), languageParsers func() map[string]string) (ctags.Parser, error) {
+	ctagsParser, err := newCtags()
+	if err != nil {
+		return nil, err
+	}
+	return &dispatchParser{
+		ctags:           ctagsParser,
+		newTreeSitter:   newTreeSitter,
+		languageParsers: languageParsers,
+	}, nil
+}
+
+func (p *dispatchParser) Parse(path string, content []byte) ([]*ctags.Entry, error) {
+	if !p.useTreeSitter(path) {
+		return p.ctags.Parse(path, content)
+	}
+
+	if p.treeSitter == nil {
+		treeSitter, err := p.newTreeSitter()
+		if err != nil {
+			return nil, errors.Wrap(err, "NewTreeSitterParser")
+		}
+		p.treeSitter = treeSitter
+	}
+	return p.treeSitter.Parse(path, content)
+}
+
+// useTreeSitter reports whether the file at path should be parsed with
+// tree-sitter.
+func (p *dispatchParser) useTreeSitter(path string) bool {
+	name, ok := treeSitterLanguageForPath(path)
+	if !ok {
+		return false
+	}
+	language := strings.ToLower(treeSitterLanguages[name].name)
+	return p.languageParsers()[language] == parserTreeSitter
+}
+
+func (p *dispatchParser) Close() {
+	p.ctags.Close()
+	if p.treeSitter != nil {
+		p.treeSitter.Close()
+	}
+}
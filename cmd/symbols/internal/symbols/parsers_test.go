@@ -0,0 +1,46 @@
+package symbols
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/go-ctags"
+)
+
+func TestDispatchParser(t *testing.T) {
+	treeSitterCreated := 0
+	p, err := newDispatchParser(
+		func() (ctags.Parser, error) { return mockParser{"ctags"}, nil },
+		func() (ctags.Parser, error) {
+			treeSitterCreated++
+			return mockParser{"tree-sitter"}, nil
+		},
+		func() map[string]string {
+			return map[string]string{"go": "tree-sitter", "typescript": "ctags", "kotlin": "tree-sitter"}
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	for path, want := range map[string]string{
+		"a.go":   "tree-sitter",
+		"b.GO":   "tree-sitter",
+		"c.ts":   "ctags",
+		"d.tsx":  "ctags",
+		"e.kt":   "ctags", // not supported by tree-sitter
+		"f.java": "ctags",
+	} {
+		entries, err := p.Parse(path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := entries[0].Name; got != want {
+			t.Errorf("%s: got parser %q, want %q", path, got, want)
+		}
+	}
+
+	if treeSitterCreated != 1 {
+		t.Errorf("got %d tree-sitter parsers created, want 1", treeSitterCreated)
+	}
+}
@@ -63,6 +63,29 @@ func (s *Service) handleSearch(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handlePrewarm starts building the symbols database of the repo@commit in
+// args in the background, so that later searches don't need to wait for it.
+// Only Repo and CommitID of args are used.
+func (s *Service) handlePrewarm(w http.ResponseWriter, r *http.Request) {
+	var args protocol.SearchArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	go func() {
+		// The disk cache builds the database with its own background
+		// timeout, so this context only bounds the wait.
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+		defer cancel()
+		if _, err := s.getDBFile(ctx, args); err != nil {
+			log15.Warn("Failed to prewarm symbols", "repo", args.Repo, "commit", args.CommitID, "error", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
 func (s *Service) search(ctx context.Context, args protocol.SearchArgs) (*result.Symbols, error) {
 	var err error
 	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
@@ -112,8 +135,8 @@ func (s *Service) search(ctx context.Context, args protocol.SearchArgs) (*result
 // specified in `args`. If the database doesn't already exist in the disk cache,
 // it will create a new one and write all the symbols into it.
 func (s *Service) getDBFile(ctx context.Context, args protocol.SearchArgs) (string, error) {
-	diskcacheFile, err := s.cache.OpenWithPath(ctx, fmt.Sprintf("%d-%s@%s", symbolsDBVersion, args.Repo, args.CommitID), func(fetcherCtx context.Context, tempDBFile string) error {
-		err := s.writeAllSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
+	diskcacheFile, err := s.cache.OpenWithPath(ctx, dbCacheKey(args.Repo, args.CommitID), func(fetcherCtx context.Context, tempDBFile string) error {
+		err := s.writeSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
 		if err != nil {
 			if err == context.Canceled {
 				log15.Error("Unable to parse repository symbols within the context", "repo", args.Repo, "commit", args.CommitID, "query", args.Query)
@@ -130,6 +153,11 @@ func (s *Service) getDBFile(ctx context.Context, args protocol.SearchArgs) (stri
 	return diskcacheFile.File.Name(), err
 }
 
+// dbCacheKey returns the disk cache key of the sqlite3 database for repo@commitID.
+func dbCacheKey(repo api.RepoName, commitID api.CommitID) string {
+	return fmt.Sprintf("%d-%s@%s", symbolsDBVersion, repo, commitID)
+}
+
 // isLiteralEquality checks if the given regex matches literal strings exactly.
 // Returns whether or not the regex is exact, along with the literal string if
 // so.
@@ -198,12 +226,43 @@ func filterSymbols(ctx context.Context, db *sqlx.DB, args protocol.SearchArgs) (
 		return newConditions
 	}
 
+	// makeInCondition returns a condition which matches if the lowercase
+	// value of column is one of values.
+	makeInCondition := func(column string, values []string) []*sqlf.Query {
+		if len(values) == 0 {
+			return nil
+		}
+		items := make([]*sqlf.Query, 0, len(values))
+		for _, value := range values {
+			items = append(items, sqlf.Sprintf("%s", strings.ToLower(value)))
+		}
+		return []*sqlf.Query{sqlf.Sprintf("lower("+column+") IN (%s)", sqlf.Join(items, ","))}
+	}
+
 	var conditions []*sqlf.Query
-	conditions = append(conditions, makeCondition("name", args.Query)...)
+	if args.IsExactName && args.Query != "" {
+		if args.IsCaseSensitive {
+			conditions = append(conditions, sqlf.Sprintf("name = %s", args.Query))
+		} else {
+			conditions = append(conditions, sqlf.Sprintf("namelowercase = %s", strings.ToLower(args.Query)))
+		}
+	} else {
+		conditions = append(conditions, makeCondition("name", args.Query)...)
+	}
 	for _, includePattern := range args.IncludePatterns {
 		conditions = append(conditions, makeCondition("path", includePattern)...)
 	}
 	conditions = append(conditions, negateAll(makeCondition("path", args.ExcludePattern))...)
+	conditions = append(conditions, makeInCondition("kind", args.Kinds)...)
+	conditions = append(conditions, makeInCondition("language", args.Languages)...)
+	if args.Container != "" {
+		// There is no lowercase column for parents, so always use REGEXP.
+		container := args.Container
+		if !args.IsCaseSensitive {
+			container = "(?i:" + container + ")"
+		}
+		conditions = append(conditions, sqlf.Sprintf("parent REGEXP %s", container))
+	}
 
 	var sqlQuery *sqlf.Query
 	if len(conditions) == 0 {
@@ -285,6 +344,24 @@ func symbolInDBToSymbol(symbolInDB symbolInDB) result.Symbol {
 	}
 }
 
+// writeSymbolsToNewDB writes the symbols of repo@commit to the blank database
+// file `dbFile`. It incrementally updates the database of a nearby ancestor
+// commit when possible, and otherwise parses the whole repository.
+func (s *Service) writeSymbolsToNewDB(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID) error {
+	ok, err := s.writeSymbolsIncrementally(ctx, dbFile, repoName, commitID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		log15.Warn("Failed to incrementally index symbols, falling back to a full index.", "repo", repoName, "commit", commitID, "error", err)
+		incrementalIndexing.WithLabelValues("error").Inc()
+	}
+	if ok {
+		return nil
+	}
+	return s.writeAllSymbolsToNewDB(ctx, dbFile, repoName, commitID)
+}
+
 // writeAllSymbolsToNewDB fetches the repo@commit from gitserver, parses all the
 // symbols, and writes them to the blank database file `dbFile`.
 func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID) (err error) {
@@ -307,10 +384,28 @@ func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, rep
 		err = tx.Commit()
 	}()
 
+	if err := createSymbolsTable(tx); err != nil {
+		return err
+	}
+
+	insertStatement, err := prepareInsertSymbol(tx)
+	if err != nil {
+		return err
+	}
+
+	return s.parseUncached(ctx, repoName, commitID, nil, func(symbol result.Symbol) error {
+		symbolInDBValue := symbolToSymbolInDB(symbol)
+		_, err := insertStatement.Exec(&symbolInDBValue)
+		return err
+	})
+}
+
+// createSymbolsTable creates the symbols table and its indexes.
+func createSymbolsTable(tx *sqlx.Tx) error {
 	// The column names are the lowercase version of fields in `symbolInDB`
 	// because sqlx lowercases struct fields by default. See
 	// http://jmoiron.github.io/sqlx/#query
-	_, err = tx.Exec(
+	_, err := tx.Exec(
 		`CREATE TABLE IF NOT EXISTS symbols (
 			name VARCHAR(256) NOT NULL,
 			namelowercase VARCHAR(256) NOT NULL,
@@ -346,24 +441,16 @@ func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, rep
 	}
 
 	_, err = tx.Exec(`CREATE INDEX pathlowercase_index ON symbols(pathlowercase);`)
-	if err != nil {
-		return err
-	}
+	return err
+}
 
-	insertStatement, err := tx.PrepareNamed(
+// prepareInsertSymbol returns a statement which inserts a symbolInDB.
+func prepareInsertSymbol(tx *sqlx.Tx) (*sqlx.NamedStmt, error) {
+	return tx.PrepareNamed(
 		fmt.Sprintf(
 			"INSERT INTO symbols %s VALUES %s",
 			"( name,  namelowercase,  path,  pathlowercase,  line,  kind,  language,  parent,  parentkind,  signature,  pattern,  filelimited)",
 			"(:name, :namelowercase, :path, :pathlowercase, :line, :kind, :language, :parent, :parentkind, :signature, :pattern, :filelimited)"))
-	if err != nil {
-		return err
-	}
-
-	return s.parseUncached(ctx, repoName, commitID, func(symbol result.Symbol) error {
-		symbolInDBValue := symbolToSymbolInDB(symbol)
-		_, err := insertStatement.Exec(&symbolInDBValue)
-		return err
-	})
 }
 
 // SanityCheck makes sure that go-sqlite3 was compiled with cgo by
@@ -18,7 +18,7 @@ func BenchmarkSearch(b *testing.B) {
 
 	service := Service{
 		FetchTar:  testutil.FetchTarFromGithub,
-		NewParser: NewParser,
+		NewParser: NewCtagsParser,
 		Path:      "/tmp/symbols-cache",
 	}
 	if err := service.Start(); err != nil {
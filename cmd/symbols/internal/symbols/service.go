@@ -24,10 +24,33 @@ type Service struct {
 	// determine if the error is a bad request (eg invalid repo).
 	FetchTar func(context.Context, api.RepoName, api.CommitID) (io.ReadCloser, error)
 
+	// FetchTarPaths is like FetchTar, but the archive only contains the given
+	// paths. It is used to parse the files changed since an ancestor commit.
+	FetchTarPaths func(context.Context, api.RepoName, api.CommitID, []string) (io.ReadCloser, error)
+
 	// MaxConcurrentFetchTar is the maximum number of concurrent calls allowed
 	// to FetchTar. It defaults to 15.
 	MaxConcurrentFetchTar int
 
+	// ChangedFiles returns the paths which were added or modified, and the
+	// paths which were deleted between two commits of a repository.
+	// ChangedFiles, Ancestors and FetchTarPaths must all be set to enable
+	// incremental indexing.
+	ChangedFiles func(ctx context.Context, repo api.RepoName, base, head api.CommitID) (changed, deleted []string, err error)
+
+	// Ancestors returns up to n ancestors of a commit, closest first. The
+	// commit itself is not included.
+	Ancestors func(ctx context.Context, repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error)
+
+	// MaxIncrementalAncestors is the maximum number of ancestors inspected
+	// when looking for an already indexed commit. It defaults to 100.
+	MaxIncrementalAncestors int
+
+	// MaxIncrementalChanges is the maximum number of changed paths for which
+	// an incremental index is attempted. Above it the whole repository is
+	// parsed. It defaults to 5000.
+	MaxIncrementalChanges int
+
 	NewParser func() (ctags.Parser, error)
 
 	// NumParserProcesses is the maximum number of ctags parser child processes to run.
@@ -64,6 +87,13 @@ func (s *Service) Start() error {
 	}
 	s.fetchSem = make(chan int, s.MaxConcurrentFetchTar)
 
+	if s.MaxIncrementalAncestors == 0 {
+		s.MaxIncrementalAncestors = 100
+	}
+	if s.MaxIncrementalChanges == 0 {
+		s.MaxIncrementalChanges = 5000
+	}
+
 	s.cache = &diskcache.Store{
 		Dir:               s.Path,
 		Component:         "symbols",
@@ -83,6 +113,7 @@ func (s *Service) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/prewarm", s.handlePrewarm)
 	mux.HandleFunc("/healthz", s.handleHealthCheck)
 
 	return mux
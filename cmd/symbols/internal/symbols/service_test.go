@@ -8,10 +8,12 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/sourcegraph/go-ctags"
 
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/search"
@@ -147,6 +149,163 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestServiceIncremental(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { os.RemoveAll(tmpDir) }()
+
+	commits := map[api.CommitID]map[string]string{
+		"parent": {"a.go": "a", "b.go": "b", "c.go": "c"},
+		"child":  {"a.go": "a", "b.go": "b2", "d.go": "d"},
+	}
+	var fullFetches, partialFetches []api.CommitID
+	service := Service{
+		FetchTar: func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+			fullFetches = append(fullFetches, commit)
+			return createTar(commits[commit])
+		},
+		FetchTarPaths: func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+			partialFetches = append(partialFetches, commit)
+			files := map[string]string{}
+			for _, path := range paths {
+				files[path] = commits[commit][path]
+			}
+			return createTar(files)
+		},
+		ChangedFiles: func(ctx context.Context, repo api.RepoName, base, head api.CommitID) ([]string, []string, error) {
+			if base != "parent" || head != "child" {
+				t.Fatalf("unexpected diff %s..%s", base, head)
+			}
+			return []string{"b.go", "d.go"}, []string{"c.go"}, nil
+		},
+		Ancestors: func(ctx context.Context, repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error) {
+			if commit == "child" {
+				return []api.CommitID{"parent"}, nil
+			}
+			return nil, nil
+		},
+		NewParser: func() (ctags.Parser, error) {
+			return contentParser{}, nil
+		},
+		Path: tmpDir,
+	}
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(commit api.CommitID) []string {
+		symbols, err := service.search(context.Background(), protocol.SearchArgs{Repo: "r", CommitID: commit, First: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, symbol := range *symbols {
+			names = append(names, symbol.Path+":"+symbol.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	if got, want := search("parent"), []string{"a.go:a", "b.go:b", "c.go:c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := search("child"), []string{"a.go:a", "b.go:b2", "d.go:d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if want := []api.CommitID{"parent"}; !reflect.DeepEqual(fullFetches, want) {
+		t.Errorf("got full fetches %v, want %v", fullFetches, want)
+	}
+	if want := []api.CommitID{"child"}; !reflect.DeepEqual(partialFetches, want) {
+		t.Errorf("got partial fetches %v, want %v", partialFetches, want)
+	}
+}
+
+func TestServiceStructuredFilters(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { os.RemoveAll(tmpDir) }()
+
+	entries := []*ctags.Entry{
+		{Name: "Server", Path: "server.go", Kind: "struct", Language: "Go"},
+		{Name: "Serve", Path: "server.go", Kind: "func", Language: "Go", Parent: "Server", ParentKind: "struct"},
+		{Name: "ServeHTTP", Path: "server.go", Kind: "func", Language: "Go", Parent: "Handler", ParentKind: "struct"},
+		{Name: "serve", Path: "server.ts", Kind: "function", Language: "TypeScript"},
+	}
+	service := Service{
+		FetchTar: func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+			return createTar(map[string]string{"server.go": "package main"})
+		},
+		NewParser: func() (ctags.Parser, error) {
+			return entriesParser(entries), nil
+		},
+		Path: tmpDir,
+	}
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		args protocol.SearchArgs
+		want []string
+	}{
+		"kinds": {
+			args: protocol.SearchArgs{Kinds: []string{"FUNC", "function"}},
+			want: []string{"Serve", "ServeHTTP", "serve"},
+		},
+		"languages": {
+			args: protocol.SearchArgs{Languages: []string{"typescript"}},
+			want: []string{"serve"},
+		},
+		"container": {
+			args: protocol.SearchArgs{Container: "^server$"},
+			want: []string{"Serve"},
+		},
+		"case sensitive container": {
+			args: protocol.SearchArgs{Container: "^server$", IsCaseSensitive: true},
+			want: nil,
+		},
+		"exact name": {
+			args: protocol.SearchArgs{Query: "serve", IsExactName: true},
+			want: []string{"Serve", "serve"},
+		},
+		"case sensitive exact name": {
+			args: protocol.SearchArgs{Query: "serve", IsExactName: true, IsCaseSensitive: true},
+			want: []string{"serve"},
+		},
+		"exact name is not a regexp": {
+			args: protocol.SearchArgs{Query: "Serve.*", IsExactName: true},
+			want: nil,
+		},
+		"combined": {
+			args: protocol.SearchArgs{Query: "^serve", Kinds: []string{"func"}, Languages: []string{"go"}, Container: "Server"},
+			want: []string{"Serve"},
+		},
+	}
+	for label, test := range tests {
+		t.Run(label, func(t *testing.T) {
+			test.args.Repo = "r"
+			test.args.CommitID = "c"
+			test.args.First = 10
+			symbols, err := service.search(context.Background(), test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, symbol := range *symbols {
+				names = append(names, symbol.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, test.want) {
+				t.Errorf("got %v, want %v", names, test.want)
+			}
+		})
+	}
+}
+
 func createTar(files map[string]string) (io.ReadCloser, error) {
 	buf := new(bytes.Buffer)
 	w := tar.NewWriter(buf)
@@ -182,3 +341,22 @@ func (m mockParser) Parse(name string, content []byte) ([]*ctags.Entry, error) {
 }
 
 func (mockParser) Close() {}
+
+// contentParser returns a single symbol per file named after the file's
+// contents.
+type contentParser struct{}
+
+func (contentParser) Parse(name string, content []byte) ([]*ctags.Entry, error) {
+	return []*ctags.Entry{{Name: string(content), Path: name}}, nil
+}
+
+func (contentParser) Close() {}
+
+// entriesParser returns the same entries for every file.
+type entriesParser []*ctags.Entry
+
+func (p entriesParser) Parse(name string, content []byte) ([]*ctags.Entry, error) {
+	return p, nil
+}
+
+func (entriesParser) Close() {}
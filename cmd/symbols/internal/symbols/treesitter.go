@@ -0,0 +1,314 @@
+package symbols
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+
+	"github.com/sourcegraph/go-ctags"
+)
+
+// treeSitterParseTimeout bounds the time spent parsing a single file.
+const treeSitterParseTimeout = 10 * time.Second
+
+// treeSitterLanguage describes how to extract symbols for a language from the
+// syntax trees produced by tree-sitter.
+type treeSitterLanguage struct {
+	// name is the language name reported on entries. It matches the name
+	// universal-ctags uses for the language.
+	name string
+
+	language *sitter.Language
+
+	// symbols returns the symbols defined by node, if any. container is the
+	// closest enclosing symbol. The first symbol becomes the container of
+	// symbols nested inside node.
+	symbols func(node *sitter.Node, content []byte, container *ctags.Entry) []*ctags.Entry
+
+	// descend reports whether symbols nested inside node should be
+	// extracted. Function bodies are skipped, like universal-ctags does.
+	descend func(node *sitter.Node) bool
+}
+
+// treeSitterLanguages maps a lowercase language name (as used in the
+// "symbols.parsers" site configuration) to its tree-sitter language.
+// Kotlin is not included: the go-tree-sitter release we depend on has no
+// Kotlin grammar, so Kotlin files are always parsed with universal-ctags.
+var treeSitterLanguages = map[string]*treeSitterLanguage{
+	"go": {
+		name:     "Go",
+		language: golang.GetLanguage(),
+		symbols:  goSymbols,
+		descend:  goDescend,
+	},
+	"typescript": {
+		name:     "TypeScript",
+		language: typescript.GetLanguage(),
+		symbols:  typeScriptSymbols,
+		descend:  typeScriptDescend,
+	},
+	"tsx": {
+		name:     "TypeScript",
+		language: tsx.GetLanguage(),
+		symbols:  typeScriptSymbols,
+		descend:  typeScriptDescend,
+	},
+}
+
+// treeSitterLanguageByExtension maps file extensions to a key of
+// treeSitterLanguages.
+var treeSitterLanguageByExtension = map[string]string{
+	".go":  "go",
+	".ts":  "typescript",
+	".mts": "typescript",
+	".cts": "typescript",
+	".tsx": "tsx",
+}
+
+// treeSitterLanguageForPath returns the key of treeSitterLanguages for the
+// file at path, or false if tree-sitter does not support it.
+func treeSitterLanguageForPath(filePath string) (string, bool) {
+	name, ok := treeSitterLanguageByExtension[strings.ToLower(path.Ext(filePath))]
+	return name, ok
+}
+
+// treeSitterParser is an in-process parser which extracts symbols from the
+// syntax trees produced by tree-sitter. It implements ctags.Parser so that it
+// can be used interchangeably with universal-ctags.
+type treeSitterParser struct {
+	parser *sitter.Parser
+}
+
+// NewTreeSitterParser returns a tree-sitter based parser.
+func NewTreeSitterParser() (ctags.Parser, error) {
+	return &treeSitterParser{parser: sitter.NewParser()}, nil
+}
+
+func (p *treeSitterParser) Parse(filePath string, content []byte) ([]*ctags.Entry, error) {
+	languageName, ok := treeSitterLanguageForPath(filePath)
+	if !ok {
+		return nil, errors.Errorf("tree-sitter does not support %q", filePath)
+	}
+	language := treeSitterLanguages[languageName]
+
+	ctx, cancel := context.WithTimeout(context.Background(), treeSitterParseTimeout)
+	defer cancel()
+
+	p.parser.SetLanguage(language.language)
+	tree, err := p.parser.ParseCtx(ctx, nil, content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "tree-sitter failed to parse %q", filePath)
+	}
+	defer tree.Close()
+
+	var entries []*ctags.Entry
+	var walk func(node *sitter.Node, container *ctags.Entry)
+	walk = func(node *sitter.Node, container *ctags.Entry) {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			childContainer := container
+			for i, entry := range language.symbols(child, content, container) {
+				entry.Path = filePath
+				entry.Language = language.name
+				entry.Line = int(child.StartPoint().Row) + 1
+				entry.Pattern = linePattern(content, child.StartByte())
+				entries = append(entries, entry)
+				if i == 0 {
+					childContainer = entry
+				}
+			}
+			if language.descend(child) {
+				walk(child, childContainer)
+			}
+		}
+	}
+	walk(tree.RootNode(), nil)
+
+	return entries, nil
+}
+
+func (p *treeSitterParser) Close() {
+	p.parser.Close()
+}
+
+// linePattern returns the line containing offset as a pattern of the form
+// /^ ... $/, like the patterns universal-ctags emits.
+func linePattern(content []byte, offset uint32) string {
+	start := bytes.LastIndexByte(content[:offset], '\n') + 1
+	end := bytes.IndexByte(content[offset:], '\n')
+	if end < 0 {
+		end = len(content)
+	} else {
+		end += int(offset)
+	}
+	line := bytes.TrimSuffix(content[start:end], []byte("\r"))
+	return "/^" + escapePattern(string(line)) + "$/"
+}
+
+// escapePattern escapes the characters which are special in ctags patterns.
+func escapePattern(s string) string {
+	return patternEscaper.Replace(s)
+}
+
+var patternEscaper = strings.NewReplacer(`\`, `\\`, `/`, `\/`)
+
+// fieldContents returns the contents of the named children of node with the
+// given field name.
+func fieldContents(node *sitter.Node, field string, content []byte) []string {
+	cursor := sitter.NewTreeCursor(node)
+	defer cursor.Close()
+
+	var contents []string
+	for ok := cursor.GoToFirstChild(); ok; ok = cursor.GoToNextSibling() {
+		if cursor.CurrentFieldName() == field && cursor.CurrentNode().IsNamed() {
+			contents = append(contents, cursor.CurrentNode().Content(content))
+		}
+	}
+	return contents
+}
+
+// firstDescendant returns the first node in a pre-order traversal of node
+// which has one of the given types.
+func firstDescendant(node *sitter.Node, types ...string) *sitter.Node {
+	for _, t := range types {
+		if node.Type() == t {
+			return node
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if found := firstDescendant(node.NamedChild(i), types...); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// newEntries returns entries of the given kind nested in container, one for
+// each non-empty name.
+func newEntries(names []string, kind string, container *ctags.Entry) []*ctags.Entry {
+	var entries []*ctags.Entry
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		entry := &ctags.Entry{Name: name, Kind: kind}
+		if container != nil {
+			entry.Parent = container.Name
+			entry.ParentKind = container.Kind
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// goSymbols returns the Go symbols defined by node. Kinds follow the names
+// universal-ctags uses for Go.
+func goSymbols(node *sitter.Node, content []byte, container *ctags.Entry) []*ctags.Entry {
+	names := func() []string { return fieldContents(node, "name", content) }
+
+	switch node.Type() {
+	case "package_clause":
+		if name := firstDescendant(node, "package_identifier"); name != nil {
+			return newEntries([]string{name.Content(content)}, "package", nil)
+		}
+	case "function_declaration":
+		return newEntries(names(), "func", nil)
+	case "method_declaration":
+		// The receiver type is the first identifier of the receiver's type,
+		// which also covers pointer and generic receivers.
+		var receiver *ctags.Entry
+		if r := node.ChildByFieldName("receiver"); r != nil {
+			if typ := firstDescendant(r, "parameter_declaration"); typ != nil {
+				if typ = typ.ChildByFieldName("type"); typ != nil {
+					if name := firstDescendant(typ, "type_identifier", "identifier"); name != nil {
+						receiver = &ctags.Entry{Name: name.Content(content), Kind: "struct"}
+					}
+				}
+			}
+		}
+		return newEntries(names(), "func", receiver)
+	case "type_spec":
+		kind := "type"
+		if typ := node.ChildByFieldName("type"); typ != nil {
+			switch typ.Type() {
+			case "struct_type", "interface_type":
+				kind = strings.TrimSuffix(typ.Type(), "_type")
+			}
+		}
+		return newEntries(names(), kind, nil)
+	case "field_declaration":
+		if container != nil && container.Kind == "struct" {
+			return newEntries(names(), "member", container)
+		}
+	case "method_spec":
+		if container != nil && container.Kind == "interface" {
+			return newEntries(names(), "methodSpec", container)
+		}
+	case "const_spec":
+		return newEntries(names(), "const", nil)
+	case "var_spec":
+		return newEntries(names(), "var", nil)
+	}
+	return nil
+}
+
+func goDescend(node *sitter.Node) bool {
+	switch node.Type() {
+	case "function_declaration", "method_declaration", "func_literal", "block":
+		return false
+	}
+	return true
+}
+
+// typeScriptSymbols returns the TypeScript symbols defined by node. Kinds
+// follow the names universal-ctags uses for TypeScript.
+func typeScriptSymbols(node *sitter.Node, content []byte, container *ctags.Entry) []*ctags.Entry {
+	names := func() []string { return fieldContents(node, "name", content) }
+
+	switch node.Type() {
+	case "function_declaration", "generator_function_declaration":
+		return newEntries(names(), "function", container)
+	case "class_declaration", "abstract_class_declaration", "class":
+		return newEntries(names(), "class", container)
+	case "interface_declaration":
+		return newEntries(names(), "interface", container)
+	case "enum_declaration":
+		return newEntries(names(), "enum", container)
+	case "type_alias_declaration":
+		return newEntries(names(), "alias", container)
+	case "internal_module", "module":
+		return newEntries(names(), "namespace", container)
+	case "method_definition", "method_signature", "abstract_method_signature":
+		return newEntries(names(), "method", container)
+	case "public_field_definition", "property_signature":
+		return newEntries(names(), "property", container)
+	case "variable_declarator":
+		kind := "variable"
+		if parent := node.Parent(); parent != nil && strings.HasPrefix(parent.Content(content), "const") {
+			kind = "constant"
+		}
+		return newEntries(names(), kind, container)
+	}
+	return nil
+}
+
+func typeScriptDescend(node *sitter.Node) bool {
+	switch node.Type() {
+	case "function_declaration", "generator_function_declaration", "method_definition", "arrow_function", "function":
+		return false
+	case "statement_block":
+		// Namespace bodies are statement blocks, but unlike function bodies
+		// their declarations are symbols.
+		parent := node.Parent()
+		return parent != nil && (parent.Type() == "internal_module" || parent.Type() == "module")
+	}
+	return true
+}
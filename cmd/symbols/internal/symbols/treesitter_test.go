@@ -0,0 +1,124 @@
+package symbols
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/sourcegraph/go-ctags"
+)
+
+func TestTreeSitterParser(t *testing.T) {
+	p, err := NewTreeSitterParser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	cases := []struct {
+		path string
+		data string
+		want []*ctags.Entry
+	}{{
+		path: "server/server.go",
+		data: `package server
+
+const A, B = 1, 2
+
+type Server struct {
+	Addr string
+}
+
+type Handler interface {
+	Serve() error
+}
+
+type Set[T comparable] map[T]struct{}
+
+type Pair[K comparable, V any] struct {
+	Key K
+}
+
+func (s *Server) Start() error {
+	var local int
+	return nil
+}
+
+func Map[T, U any](ts []T, f func(T) U) []U {
+	return nil
+}
+
+func (p *Pair[K, V]) Swap() {}
+`,
+		want: []*ctags.Entry{
+			{Name: "server", Kind: "package", Line: 1},
+			{Name: "A", Kind: "const", Line: 3},
+			{Name: "B", Kind: "const", Line: 3},
+			{Name: "Server", Kind: "struct", Line: 5},
+			{Name: "Addr", Kind: "member", Line: 6, Parent: "Server", ParentKind: "struct"},
+			{Name: "Handler", Kind: "interface", Line: 9},
+			{Name: "Serve", Kind: "methodSpec", Line: 10, Parent: "Handler", ParentKind: "interface"},
+			{Name: "Set", Kind: "type", Line: 13},
+			{Name: "Pair", Kind: "struct", Line: 15},
+			{Name: "Key", Kind: "member", Line: 16, Parent: "Pair", ParentKind: "struct"},
+			{Name: "Start", Kind: "func", Line: 19, Parent: "Server", ParentKind: "struct"},
+			{Name: "Map", Kind: "func", Line: 24},
+			{Name: "Swap", Kind: "func", Line: 28, Parent: "Pair", ParentKind: "struct"},
+		},
+	}, {
+		path: "src/client.ts",
+		data: `export const VERSION = 1
+let counter = 0
+
+export interface Options {
+    retries: number
+}
+
+export class Client {
+    private options: Options
+    public fetch(url: string): void {
+        const inner = 1
+    }
+}
+
+namespace Internal {
+    export function helper(): void {}
+}
+
+type ID = string
+enum Color { Red }
+`,
+		want: []*ctags.Entry{
+			{Name: "VERSION", Kind: "constant", Line: 1},
+			{Name: "counter", Kind: "variable", Line: 2},
+			{Name: "Options", Kind: "interface", Line: 4},
+			{Name: "retries", Kind: "property", Line: 5, Parent: "Options", ParentKind: "interface"},
+			{Name: "Client", Kind: "class", Line: 8},
+			{Name: "options", Kind: "property", Line: 9, Parent: "Client", ParentKind: "class"},
+			{Name: "fetch", Kind: "method", Line: 10, Parent: "Client", ParentKind: "class"},
+			{Name: "Internal", Kind: "namespace", Line: 15},
+			{Name: "helper", Kind: "function", Line: 16, Parent: "Internal", ParentKind: "namespace"},
+			{Name: "ID", Kind: "alias", Line: 19},
+			{Name: "Color", Kind: "enum", Line: 20},
+		},
+	}}
+
+	for _, tc := range cases {
+		got, err := p.Parse(tc.path, []byte(tc.data))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if d := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(ctags.Entry{}, "Path", "Language", "Pattern")); d != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", tc.path, d)
+		}
+	}
+}
+
+func TestLinePattern(t *testing.T) {
+	content := []byte("a\nfunc f(s string) { return `a/b\\c` }\nz")
+	if got, want := linePattern(content, 7), "/^func f(s string) { return `a\\/b\\\\c` }$/"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package graphql
+
+import (
+	"context"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
+)
+
+type CallHierarchyEdgeConnectionResolver struct {
+	edges            []resolvers.AdjustedCallHierarchyEdge
+	cursor           *string
+	locationResolver *CachedLocationResolver
+}
+
+func NewCallHierarchyEdgeConnectionResolver(edges []resolvers.AdjustedCallHierarchyEdge, cursor *string, locationResolver *CachedLocationResolver) gql.CallHierarchyEdgeConnectionResolver {
+	return &CallHierarchyEdgeConnectionResolver{
+		edges:            edges,
+		cursor:           cursor,
+		locationResolver: locationResolver,
+	}
+}
+
+func (r *CallHierarchyEdgeConnectionResolver) Nodes(ctx context.Context) ([]gql.CallHierarchyEdgeResolver, error) {
+	edgeResolvers := make([]gql.CallHierarchyEdgeResolver, 0, len(r.edges))
+	for i := range r.edges {
+		locations, err := resolveLocations(ctx, r.locationResolver, []resolvers.AdjustedLocation{
+			r.edges[i].Caller,
+			r.edges[i].Callee,
+			r.edges[i].CallSite,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(locations) != 3 {
+			// Skip edges with a location in a commit unknown to gitserver
+			continue
+		}
+
+		edgeResolvers = append(edgeResolvers, &CallHierarchyEdgeResolver{
+			depth:    int32(r.edges[i].Depth),
+			caller:   locations[0],
+			callee:   locations[1],
+			callSite: locations[2],
+		})
+	}
+
+	return edgeResolvers, nil
+}
+
+func (r *CallHierarchyEdgeConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	return encodeCursor(r.cursor), nil
+}
+
+type CallHierarchyEdgeResolver struct {
+	depth    int32
+	caller   gql.LocationResolver
+	callee   gql.LocationResolver
+	callSite gql.LocationResolver
+}
+
+func (r *CallHierarchyEdgeResolver) Depth() int32                   { return r.depth }
+func (r *CallHierarchyEdgeResolver) Caller() gql.LocationResolver   { return r.caller }
+func (r *CallHierarchyEdgeResolver) Callee() gql.LocationResolver   { return r.callee }
+func (r *CallHierarchyEdgeResolver) CallSite() gql.LocationResolver { return r.callSite }
@@ -15,12 +15,22 @@ const DefaultReferencesPageSize = 100
 // DefaultDiagnosticsPageSize is the diagnostic result page size when no limit is supplied.
 const DefaultDiagnosticsPageSize = 100
 
+// DefaultCallHierarchyDepth is the call hierarchy depth when no depth is supplied.
+const DefaultCallHierarchyDepth = 1
+
+// MaximumCallHierarchyDepth is the maximum call hierarchy depth that can be requested.
+const MaximumCallHierarchyDepth = 5
+
 // ErrIllegalLimit occurs when the user requests less than one object per page.
 var ErrIllegalLimit = errors.New("illegal limit")
 
 // ErrIllegalBounds occurs when a negative or zero-width bound is supplied by the user.
 var ErrIllegalBounds = errors.New("illegal bounds")
 
+// ErrIllegalDepth occurs when the user requests a call hierarchy depth less than one or greater
+// than MaximumCallHierarchyDepth.
+var ErrIllegalDepth = errors.New("illegal depth")
+
 // QueryResolver is the main interface to bundle-related operations exposed to the GraphQL API. This
 // resolver concerns itself with GraphQL/API-specific behaviors (auth, validation, marshaling, etc.).
 // All code intel-specific behavior is delegated to the underlying resolver instance, which is defined
@@ -82,6 +92,15 @@ func (r *QueryResolver) Definitions(ctx context.Context, args *gql.LSIFQueryPosi
 	return NewLocationConnectionResolver(locations, nil, r.locationResolver), nil
 }
 
+func (r *QueryResolver) TypeDefinitions(ctx context.Context, args *gql.LSIFQueryPositionArgs) (gql.LocationConnectionResolver, error) {
+	locations, err := r.resolver.TypeDefinitions(ctx, int(args.Line), int(args.Character))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocationConnectionResolver(locations, nil, r.locationResolver), nil
+}
+
 func (r *QueryResolver) References(ctx context.Context, args *gql.LSIFPagedQueryPositionArgs) (gql.LocationConnectionResolver, error) {
 	limit := derefInt32(args.First, DefaultReferencesPageSize)
 	if limit <= 0 {
@@ -100,6 +119,46 @@ func (r *QueryResolver) References(ctx context.Context, args *gql.LSIFPagedQuery
 	return NewLocationConnectionResolver(locations, strPtr(cursor), r.locationResolver), nil
 }
 
+func (r *QueryResolver) Implementations(ctx context.Context, args *gql.LSIFPagedQueryPositionArgs) (gql.LocationConnectionResolver, error) {
+	limit := derefInt32(args.First, DefaultReferencesPageSize)
+	if limit <= 0 {
+		return nil, ErrIllegalLimit
+	}
+	cursor, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, cursor, err := r.resolver.Implementations(ctx, int(args.Line), int(args.Character), limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocationConnectionResolver(locations, strPtr(cursor), r.locationResolver), nil
+}
+
+func (r *QueryResolver) CallHierarchy(ctx context.Context, args *gql.LSIFCallHierarchyArgs) (gql.CallHierarchyEdgeConnectionResolver, error) {
+	depth := derefInt32(args.Depth, DefaultCallHierarchyDepth)
+	if depth <= 0 || depth > MaximumCallHierarchyDepth {
+		return nil, ErrIllegalDepth
+	}
+	limit := derefInt32(args.First, DefaultReferencesPageSize)
+	if limit <= 0 {
+		return nil, ErrIllegalLimit
+	}
+	cursor, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	edges, cursor, err := r.resolver.CallHierarchy(ctx, int(args.Line), int(args.Character), resolvers.CallHierarchyDirection(args.Direction), depth, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCallHierarchyEdgeConnectionResolver(edges, strPtr(cursor), r.locationResolver), nil
+}
+
 func (r *QueryResolver) Hover(ctx context.Context, args *gql.LSIFQueryPositionArgs) (gql.HoverResolver, error) {
 	text, rx, exists, err := r.resolver.Hover(ctx, int(args.Line), int(args.Character))
 	if err != nil || !exists {
@@ -7,6 +7,7 @@ import (
 
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
 	resolvermocks "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers/mocks"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
@@ -56,6 +57,28 @@ func TestDefinitions(t *testing.T) {
 	}
 }
 
+func TestTypeDefinitions(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	mockResolver := resolvermocks.NewMockQueryResolver()
+	resolver := NewQueryResolver(mockResolver, NewCachedLocationResolver(db))
+
+	args := &gql.LSIFQueryPositionArgs{Line: 10, Character: 15}
+	if _, err := resolver.TypeDefinitions(context.Background(), args); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(mockResolver.TypeDefinitionsFunc.History()) != 1 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 1, len(mockResolver.TypeDefinitionsFunc.History()))
+	}
+	if val := mockResolver.TypeDefinitionsFunc.History()[0].Arg1; val != 10 {
+		t.Fatalf("unexpected line. want=%d have=%d", 10, val)
+	}
+	if val := mockResolver.TypeDefinitionsFunc.History()[0].Arg2; val != 15 {
+		t.Fatalf("unexpected character. want=%d have=%d", 15, val)
+	}
+}
+
 func TestReferences(t *testing.T) {
 	db := new(dbtesting.MockDB)
 
@@ -141,6 +164,107 @@ func TestReferencesDefaultIllegalLimit(t *testing.T) {
 	}
 }
 
+func TestImplementations(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	mockResolver := resolvermocks.NewMockQueryResolver()
+	resolver := NewQueryResolver(mockResolver, NewCachedLocationResolver(db))
+
+	offset := int32(25)
+	cursor := base64.StdEncoding.EncodeToString([]byte("test-cursor"))
+
+	args := &gql.LSIFPagedQueryPositionArgs{
+		LSIFQueryPositionArgs: gql.LSIFQueryPositionArgs{
+			Line:      10,
+			Character: 15,
+		},
+		ConnectionArgs: graphqlutil.ConnectionArgs{First: &offset},
+		After:          &cursor,
+	}
+
+	if _, err := resolver.Implementations(context.Background(), args); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(mockResolver.ImplementationsFunc.History()) != 1 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 1, len(mockResolver.ImplementationsFunc.History()))
+	}
+	if val := mockResolver.ImplementationsFunc.History()[0].Arg1; val != 10 {
+		t.Fatalf("unexpected line. want=%d have=%d", 10, val)
+	}
+	if val := mockResolver.ImplementationsFunc.History()[0].Arg2; val != 15 {
+		t.Fatalf("unexpected character. want=%d have=%d", 15, val)
+	}
+	if val := mockResolver.ImplementationsFunc.History()[0].Arg3; val != 25 {
+		t.Fatalf("unexpected limit. want=%d have=%d", 25, val)
+	}
+	if val := mockResolver.ImplementationsFunc.History()[0].Arg4; val != "test-cursor" {
+		t.Fatalf("unexpected cursor. want=%s have=%s", "test-cursor", val)
+	}
+}
+
+func TestCallHierarchy(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	mockResolver := resolvermocks.NewMockQueryResolver()
+	resolver := NewQueryResolver(mockResolver, NewCachedLocationResolver(db))
+
+	offset := int32(25)
+	depth := int32(3)
+	cursor := base64.StdEncoding.EncodeToString([]byte("test-cursor"))
+
+	args := &gql.LSIFCallHierarchyArgs{
+		LSIFQueryPositionArgs: gql.LSIFQueryPositionArgs{
+			Line:      10,
+			Character: 15,
+		},
+		Direction:      "OUTGOING",
+		Depth:          &depth,
+		ConnectionArgs: graphqlutil.ConnectionArgs{First: &offset},
+		After:          &cursor,
+	}
+
+	if _, err := resolver.CallHierarchy(context.Background(), args); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(mockResolver.CallHierarchyFunc.History()) != 1 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 1, len(mockResolver.CallHierarchyFunc.History()))
+	}
+	if val := mockResolver.CallHierarchyFunc.History()[0].Arg1; val != 10 {
+		t.Fatalf("unexpected line. want=%d have=%d", 10, val)
+	}
+	if val := mockResolver.CallHierarchyFunc.History()[0].Arg2; val != 15 {
+		t.Fatalf("unexpected character. want=%d have=%d", 15, val)
+	}
+	if val := mockResolver.CallHierarchyFunc.History()[0].Arg3; val != resolvers.OutgoingCalls {
+		t.Fatalf("unexpected direction. want=%s have=%s", resolvers.OutgoingCalls, val)
+	}
+	if val := mockResolver.CallHierarchyFunc.History()[0].Arg4; val != 3 {
+		t.Fatalf("unexpected depth. want=%d have=%d", 3, val)
+	}
+	if val := mockResolver.CallHierarchyFunc.History()[0].Arg5; val != 25 {
+		t.Fatalf("unexpected limit. want=%d have=%d", 25, val)
+	}
+	if val := mockResolver.CallHierarchyFunc.History()[0].Arg6; val != "test-cursor" {
+		t.Fatalf("unexpected cursor. want=%s have=%s", "test-cursor", val)
+	}
+}
+
+func TestCallHierarchyInvalidDepth(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	mockResolver := resolvermocks.NewMockQueryResolver()
+	resolver := NewQueryResolver(mockResolver, NewCachedLocationResolver(db))
+
+	depth := int32(MaximumCallHierarchyDepth + 1)
+	args := &gql.LSIFCallHierarchyArgs{Direction: "INCOMING", Depth: &depth}
+
+	if _, err := resolver.CallHierarchy(context.Background(), args); err != ErrIllegalDepth {
+		t.Fatalf("unexpected error. want=%q have=%q", ErrIllegalDepth, err)
+	}
+}
+
 func TestHover(t *testing.T) {
 	db := new(dbtesting.MockDB)
 
@@ -5348,6 +5348,9 @@ type MockLSIFStore struct {
 	// BulkMonikerResultsFunc is an instance of a mock function object
 	// controlling the behavior of the method BulkMonikerResults.
 	BulkMonikerResultsFunc *LSIFStoreBulkMonikerResultsFunc
+	// CallablesFunc is an instance of a mock function object controlling
+	// the behavior of the method Callables.
+	CallablesFunc *LSIFStoreCallablesFunc
 	// DefinitionsFunc is an instance of a mock function object controlling
 	// the behavior of the method Definitions.
 	DefinitionsFunc *LSIFStoreDefinitionsFunc
@@ -5372,6 +5375,9 @@ type MockLSIFStore struct {
 	// HoverFunc is an instance of a mock function object controlling the
 	// behavior of the method Hover.
 	HoverFunc *LSIFStoreHoverFunc
+	// ImplementationsFunc is an instance of a mock function object
+	// controlling the behavior of the method Implementations.
+	ImplementationsFunc *LSIFStoreImplementationsFunc
 	// MonikersByPositionFunc is an instance of a mock function object
 	// controlling the behavior of the method MonikersByPosition.
 	MonikersByPositionFunc *LSIFStoreMonikersByPositionFunc
@@ -5387,6 +5393,9 @@ type MockLSIFStore struct {
 	// StencilFunc is an instance of a mock function object controlling the
 	// behavior of the method Stencil.
 	StencilFunc *LSIFStoreStencilFunc
+	// TypeDefinitionsFunc is an instance of a mock function object
+	// controlling the behavior of the method TypeDefinitions.
+	TypeDefinitionsFunc *LSIFStoreTypeDefinitionsFunc
 }
 
 // NewMockLSIFStore creates a new mock of the LSIFStore interface. All
@@ -5398,6 +5407,11 @@ func NewMockLSIFStore() *MockLSIFStore {
 				return nil, 0, nil
 			},
 		},
+		CallablesFunc: &LSIFStoreCallablesFunc{
+			defaultHook: func(context.Context, int, string) ([]lsifstore.Callable, error) {
+				return nil, nil
+			},
+		},
 		DefinitionsFunc: &LSIFStoreDefinitionsFunc{
 			defaultHook: func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
 				return nil, 0, nil
@@ -5438,6 +5452,11 @@ func NewMockLSIFStore() *MockLSIFStore {
 				return "", lsifstore.Range{}, false, nil
 			},
 		},
+		ImplementationsFunc: &LSIFStoreImplementationsFunc{
+			defaultHook: func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
+				return nil, 0, nil
+			},
+		},
 		MonikersByPositionFunc: &LSIFStoreMonikersByPositionFunc{
 			defaultHook: func(context.Context, int, string, int, int) ([][]precise.MonikerData, error) {
 				return nil, nil
@@ -5463,6 +5482,11 @@ func NewMockLSIFStore() *MockLSIFStore {
 				return nil, nil
 			},
 		},
+		TypeDefinitionsFunc: &LSIFStoreTypeDefinitionsFunc{
+			defaultHook: func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
+				return nil, 0, nil
+			},
+		},
 	}
 }
 
@@ -5473,6 +5497,9 @@ func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
 		BulkMonikerResultsFunc: &LSIFStoreBulkMonikerResultsFunc{
 			defaultHook: i.BulkMonikerResults,
 		},
+		CallablesFunc: &LSIFStoreCallablesFunc{
+			defaultHook: i.Callables,
+		},
 		DefinitionsFunc: &LSIFStoreDefinitionsFunc{
 			defaultHook: i.Definitions,
 		},
@@ -5497,6 +5524,9 @@ func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
 		HoverFunc: &LSIFStoreHoverFunc{
 			defaultHook: i.Hover,
 		},
+		ImplementationsFunc: &LSIFStoreImplementationsFunc{
+			defaultHook: i.Implementations,
+		},
 		MonikersByPositionFunc: &LSIFStoreMonikersByPositionFunc{
 			defaultHook: i.MonikersByPosition,
 		},
@@ -5512,6 +5542,9 @@ func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
 		StencilFunc: &LSIFStoreStencilFunc{
 			defaultHook: i.Stencil,
 		},
+		TypeDefinitionsFunc: &LSIFStoreTypeDefinitionsFunc{
+			defaultHook: i.TypeDefinitions,
+		},
 	}
 }
 
@@ -5640,6 +5673,117 @@ func (c LSIFStoreBulkMonikerResultsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2}
 }
 
+// LSIFStoreCallablesFunc describes the behavior when the Callables method
+// of the parent MockLSIFStore instance is invoked.
+type LSIFStoreCallablesFunc struct {
+	defaultHook func(context.Context, int, string) ([]lsifstore.Callable, error)
+	hooks       []func(context.Context, int, string) ([]lsifstore.Callable, error)
+	history     []LSIFStoreCallablesFuncCall
+	mutex       sync.Mutex
+}
+
+// Callables delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockLSIFStore) Callables(v0 context.Context, v1 int, v2 string) ([]lsifstore.Callable, error) {
+	r0, r1 := m.CallablesFunc.nextHook()(v0, v1, v2)
+	m.CallablesFunc.appendCall(LSIFStoreCallablesFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the Callables method of
+// the parent MockLSIFStore instance is invoked and the hook queue is empty.
+func (f *LSIFStoreCallablesFunc) SetDefaultHook(hook func(context.Context, int, string) ([]lsifstore.Callable, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// Callables method of the parent MockLSIFStore instance invokes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *LSIFStoreCallablesFunc) PushHook(hook func(context.Context, int, string) ([]lsifstore.Callable, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreCallablesFunc) SetDefaultReturn(r0 []lsifstore.Callable, r1 error) {
+	f.SetDefaultHook(func(context.Context, int, string) ([]lsifstore.Callable, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreCallablesFunc) PushReturn(r0 []lsifstore.Callable, r1 error) {
+	f.PushHook(func(context.Context, int, string) ([]lsifstore.Callable, error) {
+		return r0, r1
+	})
+}
+
+func (f *LSIFStoreCallablesFunc) nextHook() func(context.Context, int, string) ([]lsifstore.Callable, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreCallablesFunc) appendCall(r0 LSIFStoreCallablesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreCallablesFuncCall objects
+// describing the invocations of this function.
+func (f *LSIFStoreCallablesFunc) History() []LSIFStoreCallablesFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreCallablesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreCallablesFuncCall is an object that describes an invocation of
+// method Callables on an instance of MockLSIFStore.
+type LSIFStoreCallablesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []lsifstore.Callable
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreCallablesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreCallablesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // LSIFStoreDefinitionsFunc describes the behavior when the Definitions
 // method of the parent MockLSIFStore instance is invoked.
 type LSIFStoreDefinitionsFunc struct {
@@ -6593,6 +6737,133 @@ func (c LSIFStoreHoverFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2, c.Result3}
 }
 
+// LSIFStoreImplementationsFunc describes the behavior when the
+// Implementations method of the parent MockLSIFStore instance is invoked.
+type LSIFStoreImplementationsFunc struct {
+	defaultHook func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error)
+	hooks       []func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error)
+	history     []LSIFStoreImplementationsFuncCall
+	mutex       sync.Mutex
+}
+
+// Implementations delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLSIFStore) Implementations(v0 context.Context, v1 int, v2 string, v3 int, v4 int, v5 int, v6 int) ([]lsifstore.Location, int, error) {
+	r0, r1, r2 := m.ImplementationsFunc.nextHook()(v0, v1, v2, v3, v4, v5, v6)
+	m.ImplementationsFunc.appendCall(LSIFStoreImplementationsFuncCall{v0, v1, v2, v3, v4, v5, v6, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the Implementations
+// method of the parent MockLSIFStore instance is invoked and the hook queue
+// is empty.
+func (f *LSIFStoreImplementationsFunc) SetDefaultHook(hook func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// Implementations method of the parent MockLSIFStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *LSIFStoreImplementationsFunc) PushHook(hook func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreImplementationsFunc) SetDefaultReturn(r0 []lsifstore.Location, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreImplementationsFunc) PushReturn(r0 []lsifstore.Location, r1 int, r2 error) {
+	f.PushHook(func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *LSIFStoreImplementationsFunc) nextHook() func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreImplementationsFunc) appendCall(r0 LSIFStoreImplementationsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreImplementationsFuncCall objects
+// describing the invocations of this function.
+func (f *LSIFStoreImplementationsFunc) History() []LSIFStoreImplementationsFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreImplementationsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreImplementationsFuncCall is an object that describes an
+// invocation of method Implementations on an instance of MockLSIFStore.
+type LSIFStoreImplementationsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 int
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Arg6 is the value of the 7th argument passed to this method
+	// invocation.
+	Arg6 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []lsifstore.Location
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreImplementationsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5, c.Arg6}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreImplementationsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // LSIFStoreMonikersByPositionFunc describes the behavior when the
 // MonikersByPosition method of the parent MockLSIFStore instance is
 // invoked.
@@ -7185,6 +7456,133 @@ func (c LSIFStoreStencilFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// LSIFStoreTypeDefinitionsFunc describes the behavior when the
+// TypeDefinitions method of the parent MockLSIFStore instance is invoked.
+type LSIFStoreTypeDefinitionsFunc struct {
+	defaultHook func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error)
+	hooks       []func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error)
+	history     []LSIFStoreTypeDefinitionsFuncCall
+	mutex       sync.Mutex
+}
+
+// TypeDefinitions delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLSIFStore) TypeDefinitions(v0 context.Context, v1 int, v2 string, v3 int, v4 int, v5 int, v6 int) ([]lsifstore.Location, int, error) {
+	r0, r1, r2 := m.TypeDefinitionsFunc.nextHook()(v0, v1, v2, v3, v4, v5, v6)
+	m.TypeDefinitionsFunc.appendCall(LSIFStoreTypeDefinitionsFuncCall{v0, v1, v2, v3, v4, v5, v6, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the TypeDefinitions
+// method of the parent MockLSIFStore instance is invoked and the hook queue
+// is empty.
+func (f *LSIFStoreTypeDefinitionsFunc) SetDefaultHook(hook func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// TypeDefinitions method of the parent MockLSIFStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *LSIFStoreTypeDefinitionsFunc) PushHook(hook func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreTypeDefinitionsFunc) SetDefaultReturn(r0 []lsifstore.Location, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreTypeDefinitionsFunc) PushReturn(r0 []lsifstore.Location, r1 int, r2 error) {
+	f.PushHook(func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *LSIFStoreTypeDefinitionsFunc) nextHook() func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreTypeDefinitionsFunc) appendCall(r0 LSIFStoreTypeDefinitionsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreTypeDefinitionsFuncCall objects
+// describing the invocations of this function.
+func (f *LSIFStoreTypeDefinitionsFunc) History() []LSIFStoreTypeDefinitionsFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreTypeDefinitionsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreTypeDefinitionsFuncCall is an object that describes an
+// invocation of method TypeDefinitions on an instance of MockLSIFStore.
+type LSIFStoreTypeDefinitionsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 int
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Arg6 is the value of the 7th argument passed to this method
+	// invocation.
+	Arg6 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []lsifstore.Location
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreTypeDefinitionsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5, c.Arg6}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreTypeDefinitionsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // MockRepoUpdaterClient is a mock implementation of the RepoUpdaterClient
 // interface (from the package
 // github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers)
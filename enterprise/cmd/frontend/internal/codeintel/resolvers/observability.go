@@ -15,6 +15,7 @@ import (
 )
 
 type operations struct {
+	callHierarchy             *observation.Operation
 	definitions               *observation.Operation
 	diagnostics               *observation.Operation
 	documentation             *observation.Operation
@@ -23,10 +24,12 @@ type operations struct {
 	documentationPathInfo     *observation.Operation
 	documentationReferences   *observation.Operation
 	hover                     *observation.Operation
+	implementations           *observation.Operation
 	queryResolver             *observation.Operation
 	ranges                    *observation.Operation
 	references                *observation.Operation
 	stencil                   *observation.Operation
+	typeDefinitions           *observation.Operation
 
 	findClosestDumps *observation.Operation
 }
@@ -57,6 +60,7 @@ func newOperations(observationContext *observation.Context) *operations {
 	}
 
 	return &operations{
+		callHierarchy:             op("CallHierarchy"),
 		definitions:               op("Definitions"),
 		diagnostics:               op("Diagnostics"),
 		documentation:             op("Documentation"),
@@ -65,10 +69,12 @@ func newOperations(observationContext *observation.Context) *operations {
 		documentationPathInfo:     op("DocumentationPathInfo"),
 		documentationReferences:   op("DocumentationReferences"),
 		hover:                     op("Hover"),
+		implementations:           op("Implementations"),
 		queryResolver:             op("QueryResolver"),
 		ranges:                    op("Ranges"),
 		references:                op("References"),
 		stencil:                   op("Stencil"),
+		typeDefinitions:           op("TypeDefinitions"),
 
 		findClosestDumps: subOp("findClosestDumps"),
 	}
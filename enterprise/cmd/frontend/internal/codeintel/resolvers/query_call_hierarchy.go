@@ -0,0 +1,650 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+const slowCallHierarchyRequestThreshold = time.Second
+
+// CallHierarchyDirection determines whether a call hierarchy is built from the callers or from the
+// callees of a callable.
+type CallHierarchyDirection string
+
+const (
+	IncomingCalls CallHierarchyDirection = "INCOMING"
+	OutgoingCalls CallHierarchyDirection = "OUTGOING"
+)
+
+// maximumCallSitesPerCallable configures the maximum number of call sites considered when expanding
+// a single callable of a call hierarchy.
+const maximumCallSitesPerCallable = 100
+
+// AdjustedCallHierarchyEdge is a call from a caller to a callee. The call site is a range within the
+// body of the caller. The depth denotes the distance from the edge to the callable at the root of the
+// call hierarchy (edges incident to the root have depth one).
+type AdjustedCallHierarchyEdge struct {
+	Depth    int
+	Caller   AdjustedLocation
+	Callee   AdjustedLocation
+	CallSite AdjustedLocation
+}
+
+// callHierarchyEdge is a call edge whose locations are relative to their indexed commit.
+type callHierarchyEdge struct {
+	depth    int
+	caller   lsifstore.Location
+	callee   lsifstore.Location
+	callSite lsifstore.Location
+}
+
+// ErrNoCallableExtents occurs when the call hierarchy of a callable is requested but none of the
+// indexes defining it record the extent of callables. Indexers that do not emit the full range of
+// definitions (such as lsif-go) record only the name of a callable, so the calls made within its
+// body are unknown. Without this error, such a call hierarchy would look like it had no edges.
+var ErrNoCallableExtents = errors.New("the code intelligence index does not record the extent of functions, so their callers and callees are unknown")
+
+// callablesCache maps a dump identifier and path pair to the callables defined in that document.
+type callablesCache map[string][]lsifstore.Callable
+
+// CallHierarchy returns the page of the call edges reachable from the callable at the given position
+// denoted by the given cursor, as well as the cursor of the next page. The position may be either the
+// name of a callable's definition or a reference to a callable.
+//
+// Edges are returned in breadth-first order, up to the given depth. Incoming edges lead from a caller
+// to the callable being expanded, and outgoing edges lead from the callable being expanded to a callee.
+// Edges to and from other repositories are found by a moniker search in the same way as References and
+// Definitions.
+func (r *queryResolver) CallHierarchy(ctx context.Context, line, character int, direction CallHierarchyDirection, depth, limit int, rawCursor string) (_ []AdjustedCallHierarchyEdge, _ string, err error) {
+	ctx, traceLog, endObservation := observeResolver(ctx, &err, "CallHierarchy", r.operations.callHierarchy, slowCallHierarchyRequestThreshold, observation.Args{
+		LogFields: []log.Field{
+			log.Int("repositoryID", r.repositoryID),
+			log.String("commit", r.commit),
+			log.String("path", r.path),
+			log.Int("numUploads", len(r.uploads)),
+			log.String("uploads", uploadIDsToString(r.uploads)),
+			log.Int("line", line),
+			log.Int("character", character),
+			log.String("direction", string(direction)),
+			log.Int("depth", depth),
+		},
+	})
+	defer endObservation()
+
+	uploadsByID := make(map[int]dbstore.Dump, len(r.uploads))
+	for i := range r.uploads {
+		uploadsByID[r.uploads[i].ID] = r.uploads[i]
+	}
+
+	cursor, err := decodeCallHierarchyCursor(rawCursor)
+	if err != nil {
+		return nil, "", errors.Wrap(err, fmt.Sprintf("invalid cursor: %q", rawCursor))
+	}
+
+	cache := callablesCache{}
+
+	if rawCursor == "" {
+		// Determine the callables at the requested position, which form the roots of the
+		// call hierarchy, and queue them for expansion.
+
+		roots, err := r.callHierarchyRoots(ctx, line, character, uploadsByID, cache)
+		if err != nil {
+			return nil, "", err
+		}
+		traceLog(log.Int("numRoots", len(roots)))
+
+		if ok, err := r.anyCallableExtents(ctx, cache, roots); err != nil {
+			return nil, "", err
+		} else if !ok {
+			return nil, "", ErrNoCallableExtents
+		}
+
+		for _, root := range roots {
+			cursor.push(root)
+		}
+	} else {
+		// Hydrate the upload records of the queued nodes, which may have been discovered
+		// via a moniker search while resolving a previous page.
+
+		ids := make([]int, 0, len(cursor.Queue)+len(cursor.BatchIDs))
+		for _, node := range cursor.Queue {
+			ids = append(ids, node.DumpID)
+		}
+		ids = append(ids, cursor.BatchIDs...)
+
+		uploads, err := r.uploadsByIDs(ctx, ids, uploadsByID)
+		if err != nil {
+			return nil, "", err
+		}
+		for i := range uploads {
+			uploadsByID[uploads[i].ID] = uploads[i]
+		}
+	}
+
+	edges, err := r.pageCallHierarchyEdges(ctx, direction, depth, uploadsByID, cache, &cursor, limit, traceLog)
+	if err != nil {
+		return nil, "", err
+	}
+	traceLog(log.Int("numEdges", len(edges)))
+
+	// Adjust the locations back to the appropriate range in the target commits. This adjusts
+	// locations within the repository the user is browsing so that it appears all locations
+	// are occurring at the same commit they are looking at.
+
+	adjustedEdges := make([]AdjustedCallHierarchyEdge, 0, len(edges))
+	for _, edge := range edges {
+		adjustedLocations, err := r.adjustLocations(ctx, uploadsByID, []lsifstore.Location{edge.caller, edge.callee, edge.callSite})
+		if err != nil {
+			return nil, "", err
+		}
+
+		adjustedEdges = append(adjustedEdges, AdjustedCallHierarchyEdge{
+			Depth:    edge.depth,
+			Caller:   adjustedLocations[0],
+			Callee:   adjustedLocations[1],
+			CallSite: adjustedLocations[2],
+		})
+	}
+
+	nextCursor := ""
+	if len(cursor.Queue) > 0 {
+		nextCursor = encodeCallHierarchyCursor(cursor)
+	}
+
+	return adjustedEdges, nextCursor, nil
+}
+
+// callHierarchyRoots returns the callables at the given position for each upload visible from the
+// current target commit. If the position is not the name of a callable definition, the definitions
+// of the symbol at the given position that are callables are returned instead.
+func (r *queryResolver) callHierarchyRoots(ctx context.Context, line, character int, uploadsByID map[int]dbstore.Dump, cache callablesCache) ([]callHierarchyNode, error) {
+	adjustedUploads, err := r.adjustUploads(ctx, line, character)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []callHierarchyNode
+	for i := range adjustedUploads {
+		callable, ok, err := r.callableAt(ctx, cache, adjustedUploads[i].Upload.ID, adjustedUploads[i].AdjustedPathInBundle, adjustedUploads[i].AdjustedPosition)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			roots = append(roots, callHierarchyNode{
+				DumpID: adjustedUploads[i].Upload.ID,
+				Path:   adjustedUploads[i].AdjustedPathInBundle,
+				Range:  callable.Range,
+			})
+			continue
+		}
+
+		locations, _, err := r.lsifStore.Definitions(
+			ctx,
+			adjustedUploads[i].Upload.ID,
+			adjustedUploads[i].AdjustedPathInBundle,
+			adjustedUploads[i].AdjustedPosition.Line,
+			adjustedUploads[i].AdjustedPosition.Character,
+			DefinitionsLimit,
+			0,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "lsifStore.Definitions")
+		}
+
+		callees, err := r.calleeLocations(ctx, adjustedUploads[i], locations, uploadsByID, cache)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, callee := range callees {
+			roots = append(roots, callHierarchyNode{
+				DumpID: callee.DumpID,
+				Path:   callee.Path,
+				Range:  callee.Range,
+			})
+		}
+	}
+
+	return roots, nil
+}
+
+// pageCallHierarchyEdges returns a slice of the call edges denoted by the given cursor. The given cursor
+// will be adjusted to reflect the offsets required to resolve the next page of results. The result set is
+// exhausted once the queue of the given cursor is empty.
+func (r *queryResolver) pageCallHierarchyEdges(
+	ctx context.Context,
+	direction CallHierarchyDirection,
+	depth int,
+	uploadsByID map[int]dbstore.Dump,
+	cache callablesCache,
+	cursor *callHierarchyCursor,
+	limit int,
+	traceLog observation.TraceLogger,
+) ([]callHierarchyEdge, error) {
+	var edges []callHierarchyEdge
+	for len(edges) < limit && len(cursor.Queue) > 0 {
+		node := cursor.Queue[0]
+
+		var nodeEdges []callHierarchyEdge
+		hasMore := false
+		if _, ok := uploadsByID[node.DumpID]; ok && node.Depth < depth {
+			var err error
+			if direction == OutgoingCalls {
+				nodeEdges, hasMore, err = r.pageOutgoingCallEdges(ctx, node, uploadsByID, cache, cursor, limit-len(edges))
+			} else {
+				nodeEdges, hasMore, err = r.pageIncomingCallEdges(ctx, node, uploadsByID, cache, cursor, limit-len(edges), traceLog)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		edges = append(edges, nodeEdges...)
+
+		// Queue the callables on the other end of each returned edge so that they're expanded
+		// on this or a subsequent page.
+		for _, edge := range nodeEdges {
+			if edge.depth >= depth {
+				break
+			}
+
+			next := edge.caller
+			if direction == OutgoingCalls {
+				next = edge.callee
+			}
+
+			cursor.push(callHierarchyNode{
+				DumpID: next.DumpID,
+				Path:   next.Path,
+				Range:  next.Range,
+				Depth:  edge.depth,
+			})
+		}
+
+		if !hasMore {
+			cursor.pop()
+		}
+	}
+
+	return edges, nil
+}
+
+// pageOutgoingCallEdges returns at most limit of the outgoing edges of the given node starting at the
+// offset of the given cursor, and whether the node has further edges.
+func (r *queryResolver) pageOutgoingCallEdges(ctx context.Context, node callHierarchyNode, uploadsByID map[int]dbstore.Dump, cache callablesCache, cursor *callHierarchyCursor, limit int) ([]callHierarchyEdge, bool, error) {
+	allEdges, err := r.outgoingCallEdges(ctx, node, uploadsByID, cache)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var edges []callHierarchyEdge
+	edges, cursor.Offset = pageEdges(allEdges, cursor.Offset, limit)
+	return edges, cursor.Offset < len(allEdges), nil
+}
+
+// pageIncomingCallEdges returns at most limit of the incoming edges of the given node starting at the
+// offsets of the given cursor, and whether the node has further edges. Edges from within the index of
+// the given node are returned first, followed by edges from other indexes.
+func (r *queryResolver) pageIncomingCallEdges(ctx context.Context, node callHierarchyNode, uploadsByID map[int]dbstore.Dump, cache callablesCache, cursor *callHierarchyCursor, limit int, traceLog observation.TraceLogger) ([]callHierarchyEdge, bool, error) {
+	var edges []callHierarchyEdge
+	if !cursor.RemotePhase {
+		localEdges, err := r.localIncomingCallEdges(ctx, node, cache)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var pageLocalEdges []callHierarchyEdge
+		pageLocalEdges, cursor.Offset = pageEdges(localEdges, cursor.Offset, limit)
+		edges = append(edges, pageLocalEdges...)
+		if cursor.Offset < len(localEdges) {
+			return edges, true, nil
+		}
+
+		cursor.RemotePhase = true
+	}
+
+	remoteEdges, hasMore, err := r.pageRemoteIncomingCallEdges(ctx, node, uploadsByID, cache, cursor, limit-len(edges), traceLog)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return append(edges, remoteEdges...), hasMore, nil
+}
+
+// localIncomingCallEdges returns an edge for each reference to the given callable within its own index
+// that is enclosed by the body of another callable.
+func (r *queryResolver) localIncomingCallEdges(ctx context.Context, node callHierarchyNode, cache callablesCache) ([]callHierarchyEdge, error) {
+	locations, _, err := r.lsifStore.References(ctx, node.DumpID, node.Path, node.Range.Start.Line, node.Range.Start.Character, maximumCallSitesPerCallable, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "lsifStore.References")
+	}
+
+	return r.incomingCallEdges(ctx, node, cache, locations)
+}
+
+// pageRemoteIncomingCallEdges returns the edges for a page of at most limit references to the given callable
+// within other indexes, found by a moniker search over the uploads that reference one of the callable's export
+// monikers. The uploads are searched in batches, as done for remote references. Fewer than limit edges may
+// be returned even if there are more, as references outside of the body of a callable are not calls.
+func (r *queryResolver) pageRemoteIncomingCallEdges(ctx context.Context, node callHierarchyNode, uploadsByID map[int]dbstore.Dump, cache callablesCache, cursor *callHierarchyCursor, limit int, traceLog observation.TraceLogger) ([]callHierarchyEdge, bool, error) {
+	if limit <= 0 {
+		return nil, true, nil
+	}
+
+	nodeUpload := adjustedUpload{
+		Upload:               uploadsByID[node.DumpID],
+		AdjustedPathInBundle: node.Path,
+		AdjustedPosition:     node.Range.Start,
+	}
+
+	orderedMonikers, err := r.orderedMonikers(ctx, []adjustedUpload{nodeUpload}, "export")
+	if err != nil {
+		return nil, false, err
+	}
+	if len(orderedMonikers) == 0 {
+		return nil, false, nil
+	}
+
+	for len(cursor.BatchIDs) == 0 {
+		if cursor.RemoteBatchOffset < 0 {
+			// All remote references have been returned
+			return nil, false, nil
+		}
+
+		referenceUploadIDs, recordsScanned, totalCount, err := r.uploadIDsWithReferences(ctx, orderedMonikers, []int{node.DumpID}, maximumIndexesPerMonikerSearch, cursor.RemoteBatchOffset, traceLog)
+		if err != nil {
+			return nil, false, err
+		}
+
+		cursor.BatchIDs = referenceUploadIDs
+		cursor.RemoteBatchOffset += recordsScanned
+		if recordsScanned == 0 || cursor.RemoteBatchOffset >= totalCount {
+			// Signal that all batches have been read
+			cursor.RemoteBatchOffset = -1
+		}
+	}
+
+	uploads, err := r.uploadsByIDs(ctx, cursor.BatchIDs, uploadsByID)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range uploads {
+		uploadsByID[uploads[i].ID] = uploads[i]
+	}
+
+	locations, totalCount, err := r.monikerLocations(ctx, uploads, orderedMonikers, "references", limit, cursor.RemoteOffset)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cursor.RemoteOffset += len(locations)
+	if len(locations) == 0 || cursor.RemoteOffset >= totalCount {
+		// Move on to the next batch
+		cursor.RemoteOffset = 0
+		cursor.BatchIDs = nil
+	}
+
+	remoteLocations := make([]lsifstore.Location, 0, len(locations))
+	for _, location := range locations {
+		if location.DumpID != node.DumpID {
+			remoteLocations = append(remoteLocations, location)
+		}
+	}
+
+	edges, err := r.incomingCallEdges(ctx, node, cache, remoteLocations)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return edges, len(cursor.BatchIDs) > 0 || cursor.RemoteBatchOffset >= 0, nil
+}
+
+// incomingCallEdges returns an edge for each of the given references to the given callable that is
+// enclosed by the body of another callable.
+func (r *queryResolver) incomingCallEdges(ctx context.Context, node callHierarchyNode, cache callablesCache, locations []lsifstore.Location) ([]callHierarchyEdge, error) {
+	callee := lsifstore.Location{DumpID: node.DumpID, Path: node.Path, Range: node.Range}
+
+	var edges []callHierarchyEdge
+	for _, location := range locations {
+		caller, ok, err := r.enclosingCallable(ctx, cache, location)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Not a call, or a call outside of the body of a callable
+			continue
+		}
+
+		edges = append(edges, callHierarchyEdge{
+			depth:    node.Depth + 1,
+			caller:   caller,
+			callee:   callee,
+			callSite: location,
+		})
+	}
+
+	return edges, nil
+}
+
+// pageEdges returns at most limit of the given edges starting at the given offset, and the offset
+// of the following page.
+func pageEdges(edges []callHierarchyEdge, offset, limit int) ([]callHierarchyEdge, int) {
+	if offset >= len(edges) {
+		return nil, offset
+	}
+
+	edges = edges[offset:]
+	if len(edges) > limit {
+		edges = edges[:limit]
+	}
+
+	return edges, offset + len(edges)
+}
+
+// outgoingCallEdges returns an edge for each range in the body of the given callable that refers to a
+// callable. Callees within the index of the given callable are found by an LSIF graph traversal, and
+// callees within other indexes are found by a moniker search over the uploads that define one of the
+// import monikers attached to the call site.
+func (r *queryResolver) outgoingCallEdges(ctx context.Context, node callHierarchyNode, uploadsByID map[int]dbstore.Dump, cache callablesCache) ([]callHierarchyEdge, error) {
+	callables, err := r.callables(ctx, cache, node.DumpID, node.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var extent lsifstore.Range
+	found := false
+	for _, callable := range callables {
+		if callable.Range == node.Range {
+			extent = callable.Extent
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	ranges, err := r.lsifStore.Ranges(ctx, node.DumpID, node.Path, extent.Start.Line, extent.End.Line+1)
+	if err != nil {
+		return nil, errors.Wrap(err, "lsifStore.Ranges")
+	}
+
+	caller := lsifstore.Location{DumpID: node.DumpID, Path: node.Path, Range: node.Range}
+
+	var edges []callHierarchyEdge
+	for _, rn := range ranges {
+		if len(edges) >= maximumCallSitesPerCallable {
+			break
+		}
+		if !rangeContainsPosition(extent, rn.Range.Start) || isCallableName(callables, rn.Range) {
+			// Outside of the body or the name of a (nested) callable definition
+			continue
+		}
+
+		callees, err := r.calleeLocations(ctx, adjustedUpload{
+			Upload:               uploadsByID[node.DumpID],
+			AdjustedPathInBundle: node.Path,
+			AdjustedPosition:     rn.Range.Start,
+		}, rn.Definitions, uploadsByID, cache)
+		if err != nil {
+			return nil, err
+		}
+
+		callSite := lsifstore.Location{DumpID: node.DumpID, Path: node.Path, Range: rn.Range}
+
+		for _, callee := range callees {
+			edges = append(edges, callHierarchyEdge{
+				depth:    node.Depth + 1,
+				caller:   caller,
+				callee:   callee,
+				callSite: callSite,
+			})
+		}
+	}
+
+	return edges, nil
+}
+
+// calleeLocations returns the name of each callable among the given local definitions of the symbol at the
+// position of the given upload. If there are no local definitions, the uploads defining one of the import
+// monikers attached to the position are searched instead.
+func (r *queryResolver) calleeLocations(ctx context.Context, upload adjustedUpload, locations []lsifstore.Location, uploadsByID map[int]dbstore.Dump, cache callablesCache) ([]lsifstore.Location, error) {
+	if len(locations) == 0 {
+		orderedMonikers, err := r.orderedMonikers(ctx, []adjustedUpload{upload}, "import")
+		if err != nil {
+			return nil, err
+		}
+		if len(orderedMonikers) == 0 {
+			return nil, nil
+		}
+
+		uploads, err := r.definitionUploads(ctx, orderedMonikers)
+		if err != nil {
+			return nil, err
+		}
+		for i := range uploads {
+			uploadsByID[uploads[i].ID] = uploads[i]
+		}
+
+		if locations, _, err = r.monikerLocations(ctx, uploads, orderedMonikers, "definitions", DefinitionsLimit, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	callees := make([]lsifstore.Location, 0, len(locations))
+	for _, location := range locations {
+		callable, ok, err := r.callableAt(ctx, cache, location.DumpID, location.Path, location.Range.Start)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			callees = append(callees, lsifstore.Location{DumpID: location.DumpID, Path: location.Path, Range: callable.Range})
+		}
+	}
+
+	return callees, nil
+}
+
+// callableAt returns the callable whose name encloses the given position.
+func (r *queryResolver) callableAt(ctx context.Context, cache callablesCache, dumpID int, path string, position lsifstore.Position) (lsifstore.Callable, bool, error) {
+	callables, err := r.callables(ctx, cache, dumpID, path)
+	if err != nil {
+		return lsifstore.Callable{}, false, err
+	}
+
+	for _, callable := range callables {
+		if rangeContainsPosition(callable.Range, position) {
+			return callable, true, nil
+		}
+	}
+
+	return lsifstore.Callable{}, false, nil
+}
+
+// anyCallableExtents returns true if there are no roots or if the index of at least one of the given
+// roots records the extent of the callable beyond its name.
+func (r *queryResolver) anyCallableExtents(ctx context.Context, cache callablesCache, roots []callHierarchyNode) (bool, error) {
+	if len(roots) == 0 {
+		return true, nil
+	}
+
+	for _, root := range roots {
+		callables, err := r.callables(ctx, cache, root.DumpID, root.Path)
+		if err != nil {
+			return false, err
+		}
+
+		for _, callable := range callables {
+			if callable.Range == root.Range && callable.Extent != callable.Range {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// enclosingCallable returns the name of the innermost callable whose body encloses the given location. If
+// the given location is itself the name of a callable, a false-valued flag is returned.
+func (r *queryResolver) enclosingCallable(ctx context.Context, cache callablesCache, location lsifstore.Location) (lsifstore.Location, bool, error) {
+	callables, err := r.callables(ctx, cache, location.DumpID, location.Path)
+	if err != nil {
+		return lsifstore.Location{}, false, err
+	}
+	if isCallableName(callables, location.Range) {
+		return lsifstore.Location{}, false, nil
+	}
+
+	found := false
+	var innermost lsifstore.Callable
+	for _, callable := range callables {
+		if !rangeContainsPosition(callable.Extent, location.Range.Start) {
+			continue
+		}
+
+		if !found || rangeContainsPosition(innermost.Extent, callable.Extent.Start) {
+			innermost = callable
+			found = true
+		}
+	}
+	if !found {
+		return lsifstore.Location{}, false, nil
+	}
+
+	return lsifstore.Location{DumpID: location.DumpID, Path: location.Path, Range: innermost.Range}, true, nil
+}
+
+// callables returns the callables defined in the given document. Results are memoized in the given cache.
+func (r *queryResolver) callables(ctx context.Context, cache callablesCache, dumpID int, path string) ([]lsifstore.Callable, error) {
+	key := fmt.Sprintf("%d:%s", dumpID, path)
+	if callables, ok := cache[key]; ok {
+		return callables, nil
+	}
+
+	callables, err := r.lsifStore.Callables(ctx, dumpID, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "lsifStore.Callables")
+	}
+
+	cache[key] = callables
+	return callables, nil
+}
+
+// isCallableName returns true if the given range is the name of one of the given callables.
+func isCallableName(callables []lsifstore.Callable, rn lsifstore.Range) bool {
+	for _, callable := range callables {
+		if callable.Range == rn {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package resolvers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+)
+
+// maximumCallHierarchyNodes configures the maximum number of callables expanded by a single call
+// hierarchy traversal over all of its pages. Callables discovered after this many were queued are
+// not expanded, which bounds both the work of a traversal and the size of its cursor.
+const maximumCallHierarchyNodes = 500
+
+// callHierarchyCursor stores (enough of) the state of a previous CallHierarchy request used to
+// resume the breadth-first traversal of the call graph in the current request. Visited holds a
+// hash of each callable queued so far rather than its location to keep the cursor small.
+//
+// The edges of the node at the head of the queue are paged in two phases. Offset is the offset
+// into the edges found within the index of the node. Incoming edges from other indexes are then
+// paged like remote references: RemoteBatchOffset is the offset into the uploads referencing one
+// of the node's export monikers (or -1 once all have been read), BatchIDs are the uploads of the
+// current batch, and RemoteOffset is the offset into the references within that batch.
+type callHierarchyCursor struct {
+	Queue             []callHierarchyNode `json:"queue"`
+	Offset            int                 `json:"offset"`
+	RemotePhase       bool                `json:"remotePhase"`
+	RemoteBatchOffset int                 `json:"remoteBatchOffset"`
+	BatchIDs          []int               `json:"batchIDs"`
+	RemoteOffset      int                 `json:"remoteOffset"`
+	Visited           []uint64            `json:"visited"`
+
+	visited map[uint64]struct{}
+}
+
+// pop removes the node at the head of the queue and resets the offsets into its edges.
+func (c *callHierarchyCursor) pop() {
+	c.Queue = c.Queue[1:]
+	c.Offset = 0
+	c.RemotePhase = false
+	c.RemoteBatchOffset = 0
+	c.BatchIDs = nil
+	c.RemoteOffset = 0
+}
+
+// callHierarchyNode is a callable whose call edges have yet to be (completely) returned. The
+// path is relative to the root of the upload, and the range is the range of the callable's name.
+type callHierarchyNode struct {
+	DumpID int             `json:"dumpID"`
+	Path   string          `json:"path"`
+	Range  lsifstore.Range `json:"range"`
+	Depth  int             `json:"depth"`
+}
+
+// push queues the given node for expansion unless it has been queued before or the maximum number
+// of nodes has been queued.
+func (c *callHierarchyCursor) push(node callHierarchyNode) {
+	if c.visited == nil {
+		c.visited = make(map[uint64]struct{}, len(c.Visited))
+		for _, key := range c.Visited {
+			c.visited[key] = struct{}{}
+		}
+	}
+
+	key := callHierarchyNodeKey(node)
+	if _, ok := c.visited[key]; ok || len(c.Visited) >= maximumCallHierarchyNodes {
+		return
+	}
+
+	c.visited[key] = struct{}{}
+	c.Visited = append(c.Visited, key)
+	c.Queue = append(c.Queue, node)
+}
+
+// callHierarchyNodeKey returns a hash of the location of the given node.
+func callHierarchyNodeKey(node callHierarchyNode) uint64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d:%s:%d:%d", node.DumpID, node.Path, node.Range.Start.Line, node.Range.Start.Character)
+	return h.Sum64()
+}
+
+// decodeCallHierarchyCursor is the inverse of encodeCallHierarchyCursor. If the given encoded
+// string is empty, then a fresh cursor is returned.
+func decodeCallHierarchyCursor(rawEncoded string) (callHierarchyCursor, error) {
+	if rawEncoded == "" {
+		return callHierarchyCursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(rawEncoded)
+	if err != nil {
+		return callHierarchyCursor{}, err
+	}
+
+	var cursor callHierarchyCursor
+	err = json.Unmarshal(raw, &cursor)
+	return cursor, err
+}
+
+// encodeCallHierarchyCursor returns an encoding of the given cursor suitable for a URL or a GraphQL token.
+func encodeCallHierarchyCursor(cursor callHierarchyCursor) string {
+	rawEncoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(rawEncoded)
+}
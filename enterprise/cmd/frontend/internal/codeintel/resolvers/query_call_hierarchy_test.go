@@ -0,0 +1,385 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/shared"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/bloomfilter"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+var (
+	fooRange    = newTestRange(1, 5, 1, 8)
+	barRange    = newTestRange(7, 5, 7, 8)
+	bazRange    = newTestRange(13, 5, 13, 8)
+	callFooSite = newTestRange(9, 1, 9, 4)
+	callBarSite = newTestRange(15, 1, 15, 4)
+
+	testCallables = []lsifstore.Callable{
+		{Range: fooRange, Extent: newTestRange(1, 0, 5, 1)},
+		{Range: barRange, Extent: newTestRange(7, 0, 11, 1)},
+		{Range: bazRange, Extent: newTestRange(13, 0, 17, 1)},
+	}
+)
+
+func TestCallHierarchyIncoming(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := identityPositionAdjuster()
+
+	mockLSIFStore.CallablesFunc.SetDefaultReturn(testCallables, nil)
+	mockLSIFStore.ReferencesFunc.SetDefaultHook(func(ctx context.Context, bundleID int, path string, line, character, limit, offset int) ([]lsifstore.Location, int, error) {
+		var locations []lsifstore.Location
+		switch line {
+		case fooRange.Start.Line:
+			locations = []lsifstore.Location{
+				{DumpID: 50, Path: "main.go", Range: fooRange},
+				{DumpID: 50, Path: "main.go", Range: callFooSite},
+				{DumpID: 50, Path: "main.go", Range: newTestRange(20, 1, 20, 4)}, // outside of a callable
+			}
+		case barRange.Start.Line:
+			locations = []lsifstore.Location{
+				{DumpID: 50, Path: "main.go", Range: barRange},
+				{DumpID: 50, Path: "main.go", Range: callBarSite},
+			}
+		}
+
+		return locations, len(locations), nil
+	})
+
+	uploads := []dbstore.Dump{
+		{ID: 50, Commit: "deadbeef", Root: "sub1/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+
+	var edges []AdjustedCallHierarchyEdge
+	for cursor, numPages := "", 0; numPages == 0 || cursor != ""; numPages++ {
+		if numPages > 5 {
+			t.Fatalf("too many pages")
+		}
+
+		pageEdges, nextCursor, err := resolver.CallHierarchy(context.Background(), 1, 6, IncomingCalls, 3, 1, cursor)
+		if err != nil {
+			t.Fatalf("unexpected error querying call hierarchy: %s", err)
+		}
+
+		edges = append(edges, pageEdges...)
+		cursor = nextCursor
+	}
+
+	location := func(r lsifstore.Range) AdjustedLocation {
+		return AdjustedLocation{Dump: uploads[0], Path: "sub1/main.go", AdjustedCommit: "deadbeef", AdjustedRange: r}
+	}
+	expectedEdges := []AdjustedCallHierarchyEdge{
+		{Depth: 1, Caller: location(barRange), Callee: location(fooRange), CallSite: location(callFooSite)},
+		{Depth: 2, Caller: location(bazRange), Callee: location(barRange), CallSite: location(callBarSite)},
+	}
+	if diff := cmp.Diff(expectedEdges, edges); diff != "" {
+		t.Errorf("unexpected edges (-want +got):\n%s", diff)
+	}
+
+	if history := mockLSIFStore.CallablesFunc.History(); len(history) != 2 {
+		// Callables are memoized within a single page
+		t.Errorf("unexpected call count for lsifstore.Callables. want=%d have=%d", 2, len(history))
+	}
+}
+
+func TestCallHierarchyNoCallableExtents(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := identityPositionAdjuster()
+
+	// The indexer did not emit full ranges, so the extent of each callable is its name
+	var callables []lsifstore.Callable
+	for _, callable := range testCallables {
+		callables = append(callables, lsifstore.Callable{Range: callable.Range, Extent: callable.Range})
+	}
+	mockLSIFStore.CallablesFunc.SetDefaultReturn(callables, nil)
+
+	uploads := []dbstore.Dump{
+		{ID: 50, Commit: "deadbeef", Root: "sub1/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+
+	for _, direction := range []CallHierarchyDirection{IncomingCalls, OutgoingCalls} {
+		if _, _, err := resolver.CallHierarchy(context.Background(), 1, 6, direction, 3, 10, ""); err != ErrNoCallableExtents {
+			t.Errorf("unexpected error for %s calls. want=%q have=%v", direction, ErrNoCallableExtents, err)
+		}
+	}
+
+	if history := mockLSIFStore.ReferencesFunc.History(); len(history) != 0 {
+		t.Errorf("unexpected call count for lsifstore.References. want=%d have=%d", 0, len(history))
+	}
+}
+
+func TestCallHierarchyOutgoingRemote(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := identityPositionAdjuster()
+	mockGitserverClient.CommitExistsFunc.SetDefaultReturn(true, nil)
+
+	definitionUploads := []dbstore.Dump{
+		{ID: 150, Commit: "deadbeef1", Root: "sub2/"},
+	}
+	mockDBStore.DefinitionDumpsFunc.PushReturn(definitionUploads, nil)
+
+	remoteCallable := lsifstore.Callable{Range: newTestRange(3, 5, 3, 9), Extent: newTestRange(3, 0, 6, 1)}
+	mockLSIFStore.CallablesFunc.SetDefaultHook(func(ctx context.Context, bundleID int, path string) ([]lsifstore.Callable, error) {
+		if bundleID == 150 {
+			return []lsifstore.Callable{remoteCallable}, nil
+		}
+		return testCallables, nil
+	})
+
+	callRemoteSite := newTestRange(16, 1, 16, 5)
+	mockLSIFStore.RangesFunc.PushReturn([]lsifstore.CodeIntelligenceRange{
+		{Range: bazRange},
+		{Range: callBarSite, Definitions: []lsifstore.Location{{DumpID: 50, Path: "main.go", Range: barRange}}},
+		{Range: newTestRange(15, 6, 15, 9), Definitions: []lsifstore.Location{{DumpID: 50, Path: "main.go", Range: newTestRange(0, 4, 0, 7)}}}, // not a callable
+		{Range: callRemoteSite},
+	}, nil)
+
+	moniker := precise.MonikerData{Kind: "import", Scheme: "gomod", Identifier: "Read", PackageInformationID: "51"}
+	mockLSIFStore.MonikersByPositionFunc.SetDefaultHook(func(ctx context.Context, bundleID int, path string, line, character int) ([][]precise.MonikerData, error) {
+		if line == callRemoteSite.Start.Line {
+			return [][]precise.MonikerData{{moniker}}, nil
+		}
+		return nil, nil
+	})
+	mockLSIFStore.PackageInformationFunc.PushReturn(precise.PackageInformationData{Name: "io", Version: "v1.0.0"}, true, nil)
+	mockLSIFStore.BulkMonikerResultsFunc.PushReturn([]lsifstore.Location{{DumpID: 150, Path: "io.go", Range: remoteCallable.Range}}, 1, nil)
+
+	uploads := []dbstore.Dump{
+		{ID: 50, Commit: "deadbeef", Root: "sub1/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+	edges, cursor, err := resolver.CallHierarchy(context.Background(), 13, 6, OutgoingCalls, 1, 10, "")
+	if err != nil {
+		t.Fatalf("unexpected error querying call hierarchy: %s", err)
+	}
+	if cursor != "" {
+		t.Errorf("unexpected cursor. want=%q have=%q", "", cursor)
+	}
+
+	location := func(r lsifstore.Range) AdjustedLocation {
+		return AdjustedLocation{Dump: uploads[0], Path: "sub1/main.go", AdjustedCommit: "deadbeef", AdjustedRange: r}
+	}
+	expectedEdges := []AdjustedCallHierarchyEdge{
+		{Depth: 1, Caller: location(bazRange), Callee: location(barRange), CallSite: location(callBarSite)},
+		{
+			Depth:    1,
+			Caller:   location(bazRange),
+			Callee:   AdjustedLocation{Dump: definitionUploads[0], Path: "sub2/io.go", AdjustedCommit: "deadbeef1", AdjustedRange: remoteCallable.Range},
+			CallSite: location(callRemoteSite),
+		},
+	}
+	if diff := cmp.Diff(expectedEdges, edges); diff != "" {
+		t.Errorf("unexpected edges (-want +got):\n%s", diff)
+	}
+
+	if history := mockLSIFStore.RangesFunc.History(); len(history) != 1 {
+		t.Fatalf("unexpected call count for lsifstore.Ranges. want=%d have=%d", 1, len(history))
+	} else if history[0].Arg3 != 13 || history[0].Arg4 != 18 {
+		t.Errorf("unexpected line span. want=[%d, %d) have=[%d, %d)", 13, 18, history[0].Arg3, history[0].Arg4)
+	}
+
+	if history := mockLSIFStore.BulkMonikerResultsFunc.History(); len(history) != 1 {
+		t.Fatalf("unexpected call count for lsifstore.BulkMonikerResults. want=%d have=%d", 1, len(history))
+	} else if history[0].Arg1 != "definitions" {
+		t.Errorf("unexpected table name. want=%q have=%q", "definitions", history[0].Arg1)
+	}
+}
+
+func identityPositionAdjuster() PositionAdjuster {
+	mockPositionAdjuster := NewMockPositionAdjuster()
+	mockPositionAdjuster.AdjustPositionFunc.SetDefaultHook(func(ctx context.Context, commit string, path string, pos lsifstore.Position, _ bool) (string, lsifstore.Position, bool, error) {
+		return path, pos, true, nil
+	})
+
+	return mockPositionAdjuster
+}
+
+func newTestRange(startLine, startCharacter, endLine, endCharacter int) lsifstore.Range {
+	return lsifstore.Range{
+		Start: lsifstore.Position{Line: startLine, Character: startCharacter},
+		End:   lsifstore.Position{Line: endLine, Character: endCharacter},
+	}
+}
+
+func TestCallHierarchyIncomingRemote(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := identityPositionAdjuster()
+	mockGitserverClient.CommitExistsFunc.SetDefaultReturn(true, nil)
+
+	remoteCallable := lsifstore.Callable{Range: newTestRange(3, 5, 3, 9), Extent: newTestRange(3, 0, 9, 1)}
+	mockLSIFStore.CallablesFunc.SetDefaultHook(func(ctx context.Context, bundleID int, path string) ([]lsifstore.Callable, error) {
+		if bundleID == 250 {
+			return []lsifstore.Callable{remoteCallable}, nil
+		}
+		return testCallables, nil
+	})
+	mockLSIFStore.ReferencesFunc.SetDefaultReturn([]lsifstore.Location{{DumpID: 50, Path: "main.go", Range: fooRange}}, 1, nil)
+
+	moniker := precise.MonikerData{Kind: "export", Scheme: "gomod", Identifier: "Foo", PackageInformationID: "51"}
+	mockLSIFStore.MonikersByPositionFunc.SetDefaultReturn([][]precise.MonikerData{{moniker}}, nil)
+	mockLSIFStore.PackageInformationFunc.SetDefaultReturn(precise.PackageInformationData{Name: "main", Version: "v1.0.0"}, true, nil)
+
+	filter, err := bloomfilter.CreateFilter([]string{"Foo"})
+	if err != nil {
+		t.Fatalf("unexpected error encoding bloom filter: %s", err)
+	}
+	mockDBStore.ReferenceIDsAndFiltersFunc.SetDefaultHook(func(ctx context.Context, repositoryID int, commit string, monikers []precise.QualifiedMonikerData, limit, offset int) (dbstore.PackageReferenceScanner, int, error) {
+		if offset > 0 {
+			return dbstore.PackageReferenceScannerFromSlice(), 1, nil
+		}
+		return dbstore.PackageReferenceScannerFromSlice(shared.PackageReference{Package: shared.Package{DumpID: 250}, Filter: filter}), 1, nil
+	})
+
+	referenceUpload := dbstore.Dump{ID: 250, Commit: "deadbeef2", Root: "sub2/"}
+	mockDBStore.GetDumpsByIDsFunc.SetDefaultHook(func(ctx context.Context, ids []int) ([]dbstore.Dump, error) {
+		var uploads []dbstore.Dump
+		for _, id := range ids {
+			if id == referenceUpload.ID {
+				uploads = append(uploads, referenceUpload)
+			}
+		}
+		return uploads, nil
+	})
+
+	callSites := []lsifstore.Location{
+		{DumpID: 250, Path: "app.go", Range: newTestRange(4, 1, 4, 4)},
+		{DumpID: 250, Path: "app.go", Range: newTestRange(7, 1, 7, 4)},
+	}
+	mockLSIFStore.BulkMonikerResultsFunc.SetDefaultHook(func(ctx context.Context, tableName string, ids []int, monikers []precise.MonikerData, limit, offset int) ([]lsifstore.Location, int, error) {
+		if offset >= len(callSites) {
+			return nil, len(callSites), nil
+		}
+		if offset+limit > len(callSites) {
+			limit = len(callSites) - offset
+		}
+		return callSites[offset : offset+limit], len(callSites), nil
+	})
+
+	uploads := []dbstore.Dump{
+		{ID: 50, Commit: "deadbeef", Root: "sub1/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+
+	var edges []AdjustedCallHierarchyEdge
+	for cursor, numPages := "", 0; numPages == 0 || cursor != ""; numPages++ {
+		if numPages > 5 {
+			t.Fatalf("too many pages")
+		}
+
+		pageEdges, nextCursor, err := resolver.CallHierarchy(context.Background(), 1, 6, IncomingCalls, 1, 1, cursor)
+		if err != nil {
+			t.Fatalf("unexpected error querying call hierarchy: %s", err)
+		}
+		if len(pageEdges) > 1 {
+			t.Fatalf("unexpected page size. want<=%d have=%d", 1, len(pageEdges))
+		}
+
+		edges = append(edges, pageEdges...)
+		cursor = nextCursor
+	}
+
+	remoteLocation := func(r lsifstore.Range) AdjustedLocation {
+		return AdjustedLocation{Dump: referenceUpload, Path: "sub2/app.go", AdjustedCommit: "deadbeef2", AdjustedRange: r}
+	}
+	callee := AdjustedLocation{Dump: uploads[0], Path: "sub1/main.go", AdjustedCommit: "deadbeef", AdjustedRange: fooRange}
+	expectedEdges := []AdjustedCallHierarchyEdge{
+		{Depth: 1, Caller: remoteLocation(remoteCallable.Range), Callee: callee, CallSite: remoteLocation(callSites[0].Range)},
+		{Depth: 1, Caller: remoteLocation(remoteCallable.Range), Callee: callee, CallSite: remoteLocation(callSites[1].Range)},
+	}
+	if diff := cmp.Diff(expectedEdges, edges); diff != "" {
+		t.Errorf("unexpected edges (-want +got):\n%s", diff)
+	}
+
+	if history := mockLSIFStore.BulkMonikerResultsFunc.History(); len(history) < 2 {
+		t.Fatalf("unexpected call count for lsifstore.BulkMonikerResults. want>=%d have=%d", 2, len(history))
+	} else if history[0].Arg5 != 0 || history[1].Arg5 != 1 {
+		t.Errorf("unexpected offsets. want=[%d, %d] have=[%d, %d]", 0, 1, history[0].Arg5, history[1].Arg5)
+	}
+}
+
+func TestCallHierarchyCursorPush(t *testing.T) {
+	node := func(line int) callHierarchyNode {
+		return callHierarchyNode{DumpID: 42, Path: "main.go", Range: lsifstore.Range{Start: lsifstore.Position{Line: line}}}
+	}
+
+	var cursor callHierarchyCursor
+	cursor.push(node(0))
+	cursor.push(node(0))
+	if len(cursor.Queue) != 1 {
+		t.Fatalf("unexpected queue length. want=%d have=%d", 1, len(cursor.Queue))
+	}
+
+	// Visited nodes are remembered across pages
+	cursor, err := decodeCallHierarchyCursor(encodeCallHierarchyCursor(cursor))
+	if err != nil {
+		t.Fatalf("unexpected error decoding cursor: %s", err)
+	}
+	cursor.Queue = nil
+	cursor.push(node(0))
+	if len(cursor.Queue) != 0 {
+		t.Fatalf("unexpected queue length. want=%d have=%d", 0, len(cursor.Queue))
+	}
+
+	for i := 1; i < 2*maximumCallHierarchyNodes; i++ {
+		cursor.push(node(i))
+	}
+	if len(cursor.Visited) != maximumCallHierarchyNodes {
+		t.Errorf("unexpected number of visited nodes. want=%d have=%d", maximumCallHierarchyNodes, len(cursor.Visited))
+	}
+}
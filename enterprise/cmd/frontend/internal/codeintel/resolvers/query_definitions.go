@@ -31,6 +31,18 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 	})
 	defer endObservation()
 
+	return r.definitionLocations(ctx, line, character, locationsQuery{
+		local:     r.lsifStore.Definitions,
+		name:      "lsifStore.Definitions",
+		tableName: "definitions",
+	}, traceLog)
+}
+
+// definitionLocations returns the list of source locations resolved by the given query for the
+// symbol at the given position. Results from a local LSIF graph traversal are preferred; the given
+// query's moniker table is searched in the uploads defining the import monikers at the position
+// only when no upload has a local result.
+func (r *queryResolver) definitionLocations(ctx context.Context, line, character int, query locationsQuery, traceLog observation.TraceLogger) ([]AdjustedLocation, error) {
 	// Adjust the path and position for each visible upload based on its git difference to
 	// the target commit.
 
@@ -46,7 +58,7 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 	for i := range adjustedUploads {
 		traceLog(log.Int("uploadID", adjustedUploads[i].Upload.ID))
 
-		locations, _, err := r.lsifStore.Definitions(
+		locations, _, err := query.local(
 			ctx,
 			adjustedUploads[i].Upload.ID,
 			adjustedUploads[i].AdjustedPathInBundle,
@@ -56,7 +68,7 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 			0,
 		)
 		if err != nil {
-			return nil, errors.Wrap(err, "lsifStore.Definitions")
+			return nil, errors.Wrap(err, query.name)
 		}
 		if len(locations) > 0 {
 			uploadsByID := map[int]dbstore.Dump{
@@ -91,7 +103,7 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 	)
 
 	// Perform the moniker search
-	locations, _, err := r.monikerLocations(ctx, uploads, orderedMonikers, "definitions", DefinitionsLimit, 0)
+	locations, _, err := r.monikerLocations(ctx, uploads, orderedMonikers, query.tableName, DefinitionsLimit, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +35,29 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	})
 	defer endObservation()
 
+	return r.pageLocationsFromCursor(ctx, line, character, limit, rawCursor, locationsQuery{
+		local:     r.lsifStore.References,
+		name:      "lsifstore.References",
+		tableName: "references",
+	}, traceLog)
+}
+
+// locationsQuery describes how to resolve the locations related to the symbol at a position, such
+// as its references or implementations.
+type locationsQuery struct {
+	// local returns the locations within a single upload reachable via LSIF graph traversal.
+	local func(ctx context.Context, bundleID int, path string, line, character, limit, offset int) ([]lsifstore.Location, int, error)
+	// name identifies local in error messages.
+	name string
+	// tableName is the moniker table searched for locations in other uploads (see BulkMonikerResults).
+	tableName string
+}
+
+// pageLocationsFromCursor returns the page of the result set of the given query denoted by the given
+// cursor, as well as the cursor of the next page. The locations within each upload visible from the
+// target commit are returned first, followed by the locations found by a moniker search over uploads
+// that define or reference one of the monikers attached to the target position.
+func (r *queryResolver) pageLocationsFromCursor(ctx context.Context, line, character, limit int, rawCursor string, query locationsQuery, traceLog observation.TraceLogger) ([]AdjustedLocation, string, error) {
 	// Maintain a map from identifers to hydrated upload records from the database. We use
 	// this map as a quick lookup when constructing the resulting location set. Any additional
 	// upload records pulled back from the database while processing this page will be added
@@ -96,8 +119,9 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	}
 
 	// Query a single page of location results
-	locations, hasMore, err := r.pageReferences(
+	locations, hasMore, err := r.pageLocations(
 		ctx,
+		query,
 		adjustedUploads,
 		orderedMonikers,
 		definitionUploadIDs,
@@ -112,7 +136,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	traceLog(log.Int("numLocations", len(locations)))
 
 	// Adjust the locations back to the appropriate range in the target commits. This adjusts
-	// locations within the repository the user is browsing so that it appears all references
+	// locations within the repository the user is browsing so that it appears all locations
 	// are occurring at the same commit they are looking at.
 
 	adjustedLocations, err := r.adjustLocations(ctx, uploadsByID, locations)
@@ -243,11 +267,12 @@ func (r *queryResolver) definitionUploadIDsFromCursor(ctx context.Context, adjus
 	return definitionUploadIDs, definitionUploads, nil
 }
 
-// pageReferences returns a slice of the result set denoted by the given cursor. The given cursor will be
-// adjusted to reflect the offsets required to resolve the next page of results. If there are no more pages
-// left in the result set, a false-valued flag is returned.
-func (r *queryResolver) pageReferences(
+// pageLocations returns a slice of the result set of the given query denoted by the given cursor. The given
+// cursor will be adjusted to reflect the offsets required to resolve the next page of results. If there are
+// no more pages left in the result set, a false-valued flag is returned.
+func (r *queryResolver) pageLocations(
 	ctx context.Context,
+	query locationsQuery,
 	adjustedUploads []adjustedUpload,
 	orderedMonikers []precise.QualifiedMonikerData,
 	definitionUploadIDs []int,
@@ -264,8 +289,9 @@ func (r *queryResolver) pageReferences(
 
 	if !cursor.RemotePhase {
 		for len(locations) < limit {
-			localLocations, hasMore, err := r.pageLocalReferences(
+			localLocations, hasMore, err := r.pageLocalLocations(
 				ctx,
+				query,
 				adjustedUploads,
 				cursor,
 				limit-len(locations),
@@ -274,7 +300,7 @@ func (r *queryResolver) pageReferences(
 			if err != nil {
 				return nil, false, err
 			}
-			traceLog(log.Int("pageReferences.numLocalLocations", len(localLocations)))
+			traceLog(log.Int("pageLocations.numLocalLocations", len(localLocations)))
 			locations = append(locations, localLocations...)
 
 			if !hasMore {
@@ -291,8 +317,9 @@ func (r *queryResolver) pageReferences(
 
 	if cursor.RemotePhase {
 		for len(locations) < limit {
-			remoteLocations, hasMore, err := r.pageRemoteReferences(
+			remoteLocations, hasMore, err := r.pageRemoteLocations(
 				ctx,
+				query,
 				adjustedUploads,
 				orderedMonikers,
 				definitionUploadIDs,
@@ -304,7 +331,7 @@ func (r *queryResolver) pageReferences(
 			if err != nil {
 				return nil, false, err
 			}
-			traceLog(log.Int("pageReferences.numRemoteLocations", len(remoteLocations)))
+			traceLog(log.Int("pageLocations.numRemoteLocations", len(remoteLocations)))
 			locations = append(locations, remoteLocations...)
 
 			if !hasMore {
@@ -316,12 +343,13 @@ func (r *queryResolver) pageReferences(
 	return locations, true, nil
 }
 
-// pageLocalReferences returns a slice of the (local) result set denoted by the given cursor fulfilled by
+// pageLocalLocations returns a slice of the (local) result set denoted by the given cursor fulfilled by
 // traversing the LSIF graph. The given cursor will be adjusted to reflect the offsets required to resolve
 // the next page of results. If there are no more pages left in the result set, a false-valued flag is
 // returned.
-func (r *queryResolver) pageLocalReferences(
+func (r *queryResolver) pageLocalLocations(
 	ctx context.Context,
+	query locationsQuery,
 	adjustedUploads []adjustedUpload,
 	cursor *referencesCursor,
 	limit int,
@@ -338,7 +366,7 @@ func (r *queryResolver) pageLocalReferences(
 			continue
 		}
 
-		locations, totalCount, err := r.lsifStore.References(
+		locations, totalCount, err := query.local(
 			ctx,
 			adjustedUploads[i].Upload.ID,
 			adjustedUploads[i].AdjustedPathInBundle,
@@ -348,11 +376,11 @@ func (r *queryResolver) pageLocalReferences(
 			cursor.LocalOffset,
 		)
 		if err != nil {
-			return nil, false, errors.Wrap(err, "lsifstore.References")
+			return nil, false, errors.Wrap(err, query.name)
 		}
 
 		numLocations := len(locations)
-		traceLog(log.Int("pageLocalReferences.numLocations", numLocations))
+		traceLog(log.Int("pageLocalLocations.numLocations", numLocations))
 		cursor.LocalOffset += numLocations
 
 		if cursor.LocalOffset >= totalCount {
@@ -371,12 +399,13 @@ func (r *queryResolver) pageLocalReferences(
 // that can be passed to a single moniker search query.
 const maximumIndexesPerMonikerSearch = 50
 
-// pageRemoteReferences returns a slice of the (remote) result set denoted by the given cursor fulfilled by
+// pageRemoteLocations returns a slice of the (remote) result set denoted by the given cursor fulfilled by
 // performing a moniker search over a group of indexes. The given cursor will be adjusted to reflect the
 // offsets required to resolve the next page of results. If there are no more pages left in the result set,
 // a false-valued flag is returned.
-func (r *queryResolver) pageRemoteReferences(
+func (r *queryResolver) pageRemoteLocations(
 	ctx context.Context,
+	query locationsQuery,
 	adjustedUploads []adjustedUpload,
 	orderedMonikers []precise.QualifiedMonikerData,
 	definitionUploadIDs []int,
@@ -423,13 +452,13 @@ func (r *queryResolver) pageRemoteReferences(
 	}
 
 	// Perform the moniker search
-	locations, totalCount, err := r.monikerLocations(ctx, monikerSearchUploads, orderedMonikers, "references", limit, cursor.RemoteOffset)
+	locations, totalCount, err := r.monikerLocations(ctx, monikerSearchUploads, orderedMonikers, query.tableName, limit, cursor.RemoteOffset)
 	if err != nil {
 		return nil, false, err
 	}
 
 	numLocations := len(locations)
-	traceLog(log.Int("pageLocalReferences.pageRemoteReferences", numLocations))
+	traceLog(log.Int("pageRemoteLocations.numLocations", numLocations))
 	cursor.RemoteOffset += numLocations
 
 	if cursor.RemoteOffset >= totalCount {
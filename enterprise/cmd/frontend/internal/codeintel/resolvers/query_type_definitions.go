@@ -0,0 +1,36 @@
+package resolvers
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+const slowTypeDefinitionsRequestThreshold = time.Second
+
+// TypeDefinitions returns the list of source locations that define the type of the symbol at the given
+// position. Type definitions in other repositories are found by a moniker search over the uploads which
+// define the symbol, in the same way as Definitions.
+func (r *queryResolver) TypeDefinitions(ctx context.Context, line, character int) (_ []AdjustedLocation, err error) {
+	ctx, traceLog, endObservation := observeResolver(ctx, &err, "TypeDefinitions", r.operations.typeDefinitions, slowTypeDefinitionsRequestThreshold, observation.Args{
+		LogFields: []log.Field{
+			log.Int("repositoryID", r.repositoryID),
+			log.String("commit", r.commit),
+			log.String("path", r.path),
+			log.Int("numUploads", len(r.uploads)),
+			log.String("uploads", uploadIDsToString(r.uploads)),
+			log.Int("line", line),
+			log.Int("character", character),
+		},
+	})
+	defer endObservation()
+
+	return r.definitionLocations(ctx, line, character, locationsQuery{
+		local:     r.lsifStore.TypeDefinitions,
+		name:      "lsifStore.TypeDefinitions",
+		tableName: "type_definitions",
+	}, traceLog)
+}
@@ -0,0 +1,120 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+func TestTypeDefinitions(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := noopPositionAdjuster()
+
+	locations := []lsifstore.Location{
+		{DumpID: 51, Path: "a.go", Range: testRange1},
+		{DumpID: 51, Path: "b.go", Range: testRange2},
+	}
+	mockLSIFStore.TypeDefinitionsFunc.PushReturn(nil, 0, nil)
+	mockLSIFStore.TypeDefinitionsFunc.PushReturn(locations, len(locations), nil)
+
+	uploads := []dbstore.Dump{
+		{ID: 50, Commit: "deadbeef", Root: "sub1/"},
+		{ID: 51, Commit: "deadbeef", Root: "sub2/"},
+		{ID: 52, Commit: "deadbeef", Root: "sub3/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"s1/main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+	adjustedLocations, err := resolver.TypeDefinitions(context.Background(), 10, 20)
+	if err != nil {
+		t.Fatalf("unexpected error querying type definitions: %s", err)
+	}
+
+	expectedLocations := []AdjustedLocation{
+		{Dump: uploads[1], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: testRange1},
+		{Dump: uploads[1], Path: "sub2/b.go", AdjustedCommit: "deadbeef", AdjustedRange: testRange2},
+	}
+	if diff := cmp.Diff(expectedLocations, adjustedLocations); diff != "" {
+		t.Errorf("unexpected locations (-want +got):\n%s", diff)
+	}
+
+	if history := mockLSIFStore.DefinitionsFunc.History(); len(history) != 0 {
+		t.Errorf("unexpected call count for lsifstore.Definitions. want=%d have=%d", 0, len(history))
+	}
+}
+
+func TestTypeDefinitionsRemote(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := noopPositionAdjuster()
+	mockGitserverClient.CommitExistsFunc.SetDefaultReturn(true, nil)
+
+	remoteUploads := []dbstore.Dump{
+		{ID: 150, Commit: "deadbeef1", Root: "sub1/"},
+	}
+	mockDBStore.DefinitionDumpsFunc.PushReturn(remoteUploads, nil)
+
+	moniker := precise.MonikerData{Kind: "import", Scheme: "gomod", Identifier: "Reader", PackageInformationID: "51"}
+	mockLSIFStore.MonikersByPositionFunc.PushReturn([][]precise.MonikerData{{moniker}}, nil)
+	packageInformation := precise.PackageInformationData{Name: "io", Version: "v1.0.0"}
+	mockLSIFStore.PackageInformationFunc.PushReturn(packageInformation, true, nil)
+
+	locations := []lsifstore.Location{
+		{DumpID: 150, Path: "a.go", Range: testRange1},
+	}
+	mockLSIFStore.BulkMonikerResultsFunc.PushReturn(locations, len(locations), nil)
+
+	uploads := []dbstore.Dump{
+		{ID: 50, Commit: "deadbeef", Root: "sub1/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"s1/main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+	adjustedLocations, err := resolver.TypeDefinitions(context.Background(), 10, 20)
+	if err != nil {
+		t.Fatalf("unexpected error querying type definitions: %s", err)
+	}
+
+	expectedLocations := []AdjustedLocation{
+		{Dump: remoteUploads[0], Path: "sub1/a.go", AdjustedCommit: "deadbeef1", AdjustedRange: testRange1},
+	}
+	if diff := cmp.Diff(expectedLocations, adjustedLocations); diff != "" {
+		t.Errorf("unexpected locations (-want +got):\n%s", diff)
+	}
+
+	if history := mockLSIFStore.BulkMonikerResultsFunc.History(); len(history) != 1 {
+		t.Fatalf("unexpected call count for lsifstore.BulkMonikerResults. want=%d have=%d", 1, len(history))
+	} else {
+		if history[0].Arg1 != "type_definitions" {
+			t.Errorf("unexpected table name. want=%q have=%q", "type_definitions", history[0].Arg1)
+		}
+		if diff := cmp.Diff([]int{150}, history[0].Arg2); diff != "" {
+			t.Errorf("unexpected ids (-want +got):\n%s", diff)
+		}
+	}
+}
@@ -14,6 +14,8 @@ type Config struct {
 	WorkerPollInterval time.Duration
 	WorkerConcurrency  int
 	WorkerBudget       int64
+
+	HoverSpillThreshold int64
 }
 
 func (c *Config) Load() {
@@ -24,4 +26,5 @@ func (c *Config) Load() {
 	c.WorkerPollInterval = c.GetInterval("PRECISE_CODE_INTEL_WORKER_POLL_INTERVAL", "1s", "Interval between queries to the upload queue.")
 	c.WorkerConcurrency = c.GetInt("PRECISE_CODE_INTEL_WORKER_CONCURRENCY", "1", "The maximum number of indexes that can be processed concurrently.")
 	c.WorkerBudget = int64(c.GetInt("PRECISE_CODE_INTEL_WORKER_BUDGET", "0", "The amount of compressed input data (in bytes) a worker can process concurrently. Zero acts as an infinite budget."))
+	c.HoverSpillThreshold = int64(c.GetInt("PRECISE_CODE_INTEL_HOVER_SPILL_THRESHOLD", "0", "The heap size (in bytes) above which hover text of the upload being processed is spilled to disk. This does not limit memory usage, as only hover text is spilled. Zero disables spilling."))
 }
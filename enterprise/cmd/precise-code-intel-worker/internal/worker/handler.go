@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"bufio"
 	"compress/gzip"
 	"context"
 	"fmt"
@@ -26,17 +27,20 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/workerutil"
 	dbworkerstore "github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker/store"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsiftyped"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/pathexistence"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
 )
 
 type handler struct {
-	dbStore         DBStore
-	workerStore     dbworkerstore.Store
-	lsifStore       LSIFStore
-	uploadStore     uploadstore.Store
-	gitserverClient GitserverClient
-	enableBudget    bool
-	budgetRemaining int64
+	dbStore             DBStore
+	workerStore         dbworkerstore.Store
+	lsifStore           LSIFStore
+	uploadStore         uploadstore.Store
+	gitserverClient     GitserverClient
+	enableBudget        bool
+	budgetRemaining     int64
+	hoverSpillThreshold int64
 }
 
 var (
@@ -115,10 +119,17 @@ func (h *handler) handle(ctx context.Context, upload store.Upload) (requeued boo
 		return directoryChildren, nil
 	}
 
-	return false, withUploadData(ctx, h.uploadStore, upload.ID, func(r io.Reader) (err error) {
-		groupedBundleData, err := conversion.Correlate(ctx, r, upload.Root, getChildren)
+	progress := startProgressReporter(ctx, h.workerStore, upload)
+	defer func() { progress.stop(ctx, err) }()
+
+	options := conversion.CorrelateOptions{
+		HoverSpillThreshold: h.hoverSpillThreshold,
+	}
+
+	return false, withUploadData(ctx, h.uploadStore, upload.ID, progress.Reader, func(r io.Reader) (err error) {
+		groupedBundleData, err := correlateUploadData(ctx, r, upload.Root, getChildren, options)
 		if err != nil {
-			return errors.Wrap(err, "conversion.Correlate")
+			return err
 		}
 
 		// Note: this is writing to a different database than the block below, so we need to use a
@@ -233,10 +244,38 @@ func requeueIfCloning(ctx context.Context, workerStore dbworkerstore.Store, uplo
 	return true, nil
 }
 
+// correlateUploadData converts the given raw upload data into the format written to the codeintel
+// database. Uploads are either newline-delimited LSIF JSON or a protobuf-encoded typed index; the
+// format is determined by the first byte of the upload.
+func correlateUploadData(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc, options conversion.CorrelateOptions) (*precise.GroupedBundleDataChans, error) {
+	br := bufio.NewReader(r)
+	prefix, err := br.Peek(1)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "reading upload data")
+	}
+
+	if lsiftyped.IsIndex(prefix) {
+		groupedBundleData, err := conversion.CorrelateTypedWithOptions(ctx, br, root, getChildren, options)
+		if err != nil {
+			return nil, errors.Wrap(err, "conversion.CorrelateTypedWithOptions")
+		}
+
+		return groupedBundleData, nil
+	}
+
+	groupedBundleData, err := conversion.CorrelateWithOptions(ctx, br, root, getChildren, options)
+	if err != nil {
+		return nil, errors.Wrap(err, "conversion.CorrelateWithOptions")
+	}
+
+	return groupedBundleData, nil
+}
+
 // withUploadData will invoke the given function with a reader of the upload's raw data. The
-// consumer should expect raw newline-delimited JSON content. If the function returns without
-// an error, the upload file will be deleted.
-func withUploadData(ctx context.Context, uploadStore uploadstore.Store, id int, fn func(r io.Reader) error) error {
+// consumer should expect either raw newline-delimited JSON content or a protobuf-encoded typed
+// index. The compressed upload data is passed through the given wrap function before being
+// decompressed. If the function returns without an error, the upload file will be deleted.
+func withUploadData(ctx context.Context, uploadStore uploadstore.Store, id int, wrap func(r io.Reader) io.Reader, fn func(r io.Reader) error) error {
 	uploadFilename := fmt.Sprintf("upload-%d.lsif.gz", id)
 
 	// Pull raw uploaded data from bucket
@@ -246,7 +285,7 @@ func withUploadData(ctx context.Context, uploadStore uploadstore.Store, id int,
 	}
 	defer rc.Close()
 
-	rc, err = gzip.NewReader(rc)
+	rc, err = gzip.NewReader(wrap(rc))
 	if err != nil {
 		return errors.Wrap(err, "gzip.NewReader")
 	}
@@ -284,15 +323,27 @@ func writeData(ctx context.Context, lsifStore LSIFStore, upload dbstore.Upload,
 	if err := tx.WriteDocuments(ctx, upload.ID, groupedBundleData.Documents); err != nil {
 		return errors.Wrap(err, "store.WriteDocuments")
 	}
+	if groupedBundleData.Err != nil {
+		// Documents are no longer sent once one cannot be serialized
+		if err := groupedBundleData.Err(); err != nil {
+			return errors.Wrap(err, "serializing documents")
+		}
+	}
 	if err := tx.WriteResultChunks(ctx, upload.ID, groupedBundleData.ResultChunks); err != nil {
 		return errors.Wrap(err, "store.WriteResultChunks")
 	}
 	if err := tx.WriteDefinitions(ctx, upload.ID, groupedBundleData.Definitions); err != nil {
 		return errors.Wrap(err, "store.WriteDefinitions")
 	}
+	if err := tx.WriteTypeDefinitions(ctx, upload.ID, groupedBundleData.TypeDefinitions); err != nil {
+		return errors.Wrap(err, "store.WriteTypeDefinitions")
+	}
 	if err := tx.WriteReferences(ctx, upload.ID, groupedBundleData.References); err != nil {
 		return errors.Wrap(err, "store.WriteReferences")
 	}
+	if err := tx.WriteImplementations(ctx, upload.ID, groupedBundleData.Implementations); err != nil {
+		return errors.Wrap(err, "store.WriteImplementations")
+	}
 	if err := tx.WriteDocumentationPages(ctx, upload, repo, isDefaultBranch, groupedBundleData.DocumentationPages, repositoryNameID, languageNameID); err != nil {
 		return errors.Wrap(err, "store.WriteDocumentationPages")
 	}
@@ -1,6 +1,8 @@
 package worker
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"io"
 	"os"
@@ -18,6 +20,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/bloomfilter"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsiftyped"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
 )
 
@@ -163,6 +166,82 @@ func TestHandle(t *testing.T) {
 	}
 }
 
+func TestHandleTypedIndex(t *testing.T) {
+	setupRepoMocks(t)
+
+	upload := dbstore.Upload{
+		ID:           42,
+		Root:         "root/",
+		Commit:       "deadbeef",
+		RepositoryID: 50,
+		Indexer:      "lsif-test",
+	}
+
+	mockWorkerStore := NewMockWorkerStore()
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockUploadStore := uploadstoremocks.NewMockStore()
+	gitserverClient := NewMockGitserverClient()
+
+	// Set default transaction behavior
+	mockDBStore.TransactFunc.SetDefaultReturn(mockDBStore, nil)
+	mockDBStore.DoneFunc.SetDefaultHook(func(err error) error { return err })
+	mockLSIFStore.TransactFunc.SetDefaultReturn(mockLSIFStore, nil)
+
+	// Give correlation package a valid typed index
+	mockUploadStore.GetFunc.SetDefaultHook(copyTestTypedIndex)
+
+	// Allowlist all files in index
+	gitserverClient.DirectoryChildrenFunc.SetDefaultReturn(map[string][]string{
+		"root": {"root/foo.go"},
+	}, nil)
+	gitserverClient.CommitDateFunc.SetDefaultReturn("deadbeef", time.Now(), true, nil)
+
+	var paths []string
+	mockLSIFStore.WriteDocumentsFunc.SetDefaultHook(func(ctx context.Context, bundleID int, documents chan precise.KeyedDocumentData) error {
+		for document := range documents {
+			paths = append(paths, document.Path)
+		}
+		return nil
+	})
+
+	handler := &handler{
+		dbStore:         mockDBStore,
+		workerStore:     mockWorkerStore,
+		lsifStore:       mockLSIFStore,
+		uploadStore:     mockUploadStore,
+		gitserverClient: gitserverClient,
+	}
+
+	requeued, err := handler.handle(context.Background(), upload)
+	if err != nil {
+		t.Fatalf("unexpected error handling upload: %s", err)
+	} else if requeued {
+		t.Errorf("unexpected requeue")
+	}
+
+	if diff := cmp.Diff([]string{"foo.go"}, paths); diff != "" {
+		t.Errorf("unexpected document paths (-want +got):\n%s", diff)
+	}
+
+	expectedPackages := []precise.Package{
+		{
+			Scheme:  "gomod",
+			Name:    "example.com/foo",
+			Version: "v1.0.0",
+		},
+	}
+	if len(mockDBStore.UpdatePackagesFunc.History()) != 1 {
+		t.Errorf("unexpected number of UpdatePackages calls. want=%d have=%d", 1, len(mockDBStore.UpdatePackagesFunc.History()))
+	} else if diff := cmp.Diff(expectedPackages, mockDBStore.UpdatePackagesFunc.History()[0].Arg2); diff != "" {
+		t.Errorf("unexpected UpdatePackagesFunc args (-want +got):\n%s", diff)
+	}
+
+	if len(mockUploadStore.DeleteFunc.History()) != 1 {
+		t.Errorf("unexpected number of Delete calls. want=%d have=%d", 1, len(mockUploadStore.DeleteFunc.History()))
+	}
+}
+
 func TestHandleError(t *testing.T) {
 	setupRepoMocks(t)
 
@@ -279,6 +358,34 @@ func copyTestDump(ctx context.Context, key string) (io.ReadCloser, error) {
 	return os.Open("../../testdata/dump1.lsif.gz")
 }
 
+func copyTestTypedIndex(ctx context.Context, key string) (io.ReadCloser, error) {
+	index := lsiftyped.Index{
+		Metadata: lsiftyped.Metadata{ToolInfo: lsiftyped.ToolInfo{Name: "lsif-test"}},
+		Documents: []lsiftyped.Document{
+			{
+				RelativePath: "foo.go",
+				Occurrences: []lsiftyped.Occurrence{
+					{Range: []int32{1, 5, 8}, Symbol: "gomod . example.com/foo v1.0.0 foo/Foo().", SymbolRoles: lsiftyped.SymbolRoleDefinition},
+				},
+			},
+			{
+				RelativePath: "vendor/bar.go",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	if _, err := gzipWriter.Write(lsiftyped.MarshalIndex(index)); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(&buf), nil
+}
+
 func setupRepoMocks(t *testing.T) {
 	t.Cleanup(func() {
 		backend.Mocks.Repos.Get = nil
@@ -2145,6 +2145,9 @@ type MockLSIFStore struct {
 	// WriteDocumentsFunc is an instance of a mock function object
 	// controlling the behavior of the method WriteDocuments.
 	WriteDocumentsFunc *LSIFStoreWriteDocumentsFunc
+	// WriteImplementationsFunc is an instance of a mock function object
+	// controlling the behavior of the method WriteImplementations.
+	WriteImplementationsFunc *LSIFStoreWriteImplementationsFunc
 	// WriteMetaFunc is an instance of a mock function object controlling
 	// the behavior of the method WriteMeta.
 	WriteMetaFunc *LSIFStoreWriteMetaFunc
@@ -2154,6 +2157,9 @@ type MockLSIFStore struct {
 	// WriteResultChunksFunc is an instance of a mock function object
 	// controlling the behavior of the method WriteResultChunks.
 	WriteResultChunksFunc *LSIFStoreWriteResultChunksFunc
+	// WriteTypeDefinitionsFunc is an instance of a mock function object
+	// controlling the behavior of the method WriteTypeDefinitions.
+	WriteTypeDefinitionsFunc *LSIFStoreWriteTypeDefinitionsFunc
 }
 
 // NewMockLSIFStore creates a new mock of the LSIFStore interface. All
@@ -2200,6 +2206,11 @@ func NewMockLSIFStore() *MockLSIFStore {
 				return nil
 			},
 		},
+		WriteImplementationsFunc: &LSIFStoreWriteImplementationsFunc{
+			defaultHook: func(context.Context, int, chan precise.MonikerLocations) error {
+				return nil
+			},
+		},
 		WriteMetaFunc: &LSIFStoreWriteMetaFunc{
 			defaultHook: func(context.Context, int, precise.MetaData) error {
 				return nil
@@ -2215,6 +2226,11 @@ func NewMockLSIFStore() *MockLSIFStore {
 				return nil
 			},
 		},
+		WriteTypeDefinitionsFunc: &LSIFStoreWriteTypeDefinitionsFunc{
+			defaultHook: func(context.Context, int, chan precise.MonikerLocations) error {
+				return nil
+			},
+		},
 	}
 }
 
@@ -2246,6 +2262,9 @@ func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
 		WriteDocumentsFunc: &LSIFStoreWriteDocumentsFunc{
 			defaultHook: i.WriteDocuments,
 		},
+		WriteImplementationsFunc: &LSIFStoreWriteImplementationsFunc{
+			defaultHook: i.WriteImplementations,
+		},
 		WriteMetaFunc: &LSIFStoreWriteMetaFunc{
 			defaultHook: i.WriteMeta,
 		},
@@ -2255,6 +2274,9 @@ func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
 		WriteResultChunksFunc: &LSIFStoreWriteResultChunksFunc{
 			defaultHook: i.WriteResultChunks,
 		},
+		WriteTypeDefinitionsFunc: &LSIFStoreWriteTypeDefinitionsFunc{
+			defaultHook: i.WriteTypeDefinitions,
+		},
 	}
 }
 
@@ -3153,6 +3175,117 @@ func (c LSIFStoreWriteDocumentsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// LSIFStoreWriteImplementationsFunc describes the behavior when the
+// WriteImplementations method of the parent MockLSIFStore instance is
+// invoked.
+type LSIFStoreWriteImplementationsFunc struct {
+	defaultHook func(context.Context, int, chan precise.MonikerLocations) error
+	hooks       []func(context.Context, int, chan precise.MonikerLocations) error
+	history     []LSIFStoreWriteImplementationsFuncCall
+	mutex       sync.Mutex
+}
+
+// WriteImplementations delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLSIFStore) WriteImplementations(v0 context.Context, v1 int, v2 chan precise.MonikerLocations) error {
+	r0 := m.WriteImplementationsFunc.nextHook()(v0, v1, v2)
+	m.WriteImplementationsFunc.appendCall(LSIFStoreWriteImplementationsFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the WriteImplementations
+// method of the parent MockLSIFStore instance is invoked and the hook queue
+// is empty.
+func (f *LSIFStoreWriteImplementationsFunc) SetDefaultHook(hook func(context.Context, int, chan precise.MonikerLocations) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// WriteImplementations method of the parent MockLSIFStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *LSIFStoreWriteImplementationsFunc) PushHook(hook func(context.Context, int, chan precise.MonikerLocations) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreWriteImplementationsFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, chan precise.MonikerLocations) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreWriteImplementationsFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, chan precise.MonikerLocations) error {
+		return r0
+	})
+}
+
+func (f *LSIFStoreWriteImplementationsFunc) nextHook() func(context.Context, int, chan precise.MonikerLocations) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreWriteImplementationsFunc) appendCall(r0 LSIFStoreWriteImplementationsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreWriteImplementationsFuncCall
+// objects describing the invocations of this function.
+func (f *LSIFStoreWriteImplementationsFunc) History() []LSIFStoreWriteImplementationsFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreWriteImplementationsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreWriteImplementationsFuncCall is an object that describes an
+// invocation of method WriteImplementations on an instance of
+// MockLSIFStore.
+type LSIFStoreWriteImplementationsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 chan precise.MonikerLocations
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreWriteImplementationsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreWriteImplementationsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
 // LSIFStoreWriteMetaFunc describes the behavior when the WriteMeta method
 // of the parent MockLSIFStore instance is invoked.
 type LSIFStoreWriteMetaFunc struct {
@@ -3478,3 +3611,114 @@ func (c LSIFStoreWriteResultChunksFuncCall) Args() []interface{} {
 func (c LSIFStoreWriteResultChunksFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
+
+// LSIFStoreWriteTypeDefinitionsFunc describes the behavior when the
+// WriteTypeDefinitions method of the parent MockLSIFStore instance is
+// invoked.
+type LSIFStoreWriteTypeDefinitionsFunc struct {
+	defaultHook func(context.Context, int, chan precise.MonikerLocations) error
+	hooks       []func(context.Context, int, chan precise.MonikerLocations) error
+	history     []LSIFStoreWriteTypeDefinitionsFuncCall
+	mutex       sync.Mutex
+}
+
+// WriteTypeDefinitions delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLSIFStore) WriteTypeDefinitions(v0 context.Context, v1 int, v2 chan precise.MonikerLocations) error {
+	r0 := m.WriteTypeDefinitionsFunc.nextHook()(v0, v1, v2)
+	m.WriteTypeDefinitionsFunc.appendCall(LSIFStoreWriteTypeDefinitionsFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the WriteTypeDefinitions
+// method of the parent MockLSIFStore instance is invoked and the hook queue
+// is empty.
+func (f *LSIFStoreWriteTypeDefinitionsFunc) SetDefaultHook(hook func(context.Context, int, chan precise.MonikerLocations) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// WriteTypeDefinitions method of the parent MockLSIFStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *LSIFStoreWriteTypeDefinitionsFunc) PushHook(hook func(context.Context, int, chan precise.MonikerLocations) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreWriteTypeDefinitionsFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, chan precise.MonikerLocations) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreWriteTypeDefinitionsFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, chan precise.MonikerLocations) error {
+		return r0
+	})
+}
+
+func (f *LSIFStoreWriteTypeDefinitionsFunc) nextHook() func(context.Context, int, chan precise.MonikerLocations) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreWriteTypeDefinitionsFunc) appendCall(r0 LSIFStoreWriteTypeDefinitionsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreWriteTypeDefinitionsFuncCall
+// objects describing the invocations of this function.
+func (f *LSIFStoreWriteTypeDefinitionsFunc) History() []LSIFStoreWriteTypeDefinitionsFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreWriteTypeDefinitionsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreWriteTypeDefinitionsFuncCall is an object that describes an
+// invocation of method WriteTypeDefinitions on an instance of
+// MockLSIFStore.
+type LSIFStoreWriteTypeDefinitionsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 chan precise.MonikerLocations
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreWriteTypeDefinitionsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreWriteTypeDefinitionsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+	dbworkerstore "github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker/store"
+)
+
+// progressReportInterval is the duration between progress updates to the upload job records.
+const progressReportInterval = 10 * time.Second
+
+// progressReporter periodically records the number of compressed bytes of an upload that have
+// been read by the correlator into an execution log entry of the upload record. This allows the
+// progress of processing very large uploads to be observed.
+type progressReporter struct {
+	workerStore dbworkerstore.Store
+	upload      store.Upload
+	startTime   time.Time
+	entryID     int
+	bytesRead   int64 // accessed atomically
+	done        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// startProgressReporter adds a progress entry to the execution logs of the given upload and
+// begins periodically updating it. The caller must call stop once processing has completed.
+func startProgressReporter(ctx context.Context, workerStore dbworkerstore.Store, upload store.Upload) *progressReporter {
+	r := &progressReporter{
+		workerStore: workerStore,
+		upload:      upload,
+		startTime:   time.Now(),
+		done:        make(chan struct{}),
+	}
+
+	entryID, err := workerStore.AddExecutionLogEntry(ctx, upload.ID, r.entry(nil), dbworkerstore.ExecutionLogEntryOptions{})
+	if err != nil {
+		// Progress is informational only; do not fail the upload
+		log15.Warn("Failed to add progress entry to upload record", "id", upload.ID, "err", err)
+		return r
+	}
+	r.entryID = entryID
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(progressReportInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.update(ctx, nil)
+			case <-r.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return r
+}
+
+// Reader wraps the given reader of the compressed upload so that the bytes read from it are
+// counted towards the progress of the upload.
+func (r *progressReporter) Reader(rc io.Reader) io.Reader {
+	return &countingReader{Reader: rc, n: &r.bytesRead}
+}
+
+// stop halts periodic updates and records the final result of processing the upload.
+func (r *progressReporter) stop(ctx context.Context, err error) {
+	close(r.done)
+	r.wg.Wait()
+
+	if r.entryID != 0 {
+		exitCode := 0
+		if err != nil {
+			exitCode = 1
+		}
+
+		r.update(ctx, &exitCode)
+	}
+}
+
+func (r *progressReporter) update(ctx context.Context, exitCode *int) {
+	if err := r.workerStore.UpdateExecutionLogEntry(ctx, r.upload.ID, r.entryID, r.entry(exitCode), dbworkerstore.ExecutionLogEntryOptions{}); err != nil {
+		log15.Warn("Failed to update progress of upload record", "id", r.upload.ID, "err", err)
+	}
+}
+
+// entry returns the execution log entry describing the current progress. Entries of completed
+// processing attempts have a non-nil exit code.
+func (r *progressReporter) entry(exitCode *int) workerutil.ExecutionLogEntry {
+	entry := workerutil.ExecutionLogEntry{
+		Key:       "upload.process",
+		Command:   []string{"correlate", fmt.Sprintf("upload-%d.lsif.gz", r.upload.ID)},
+		StartTime: r.startTime,
+		ExitCode:  exitCode,
+		Out:       r.progress(),
+	}
+
+	if exitCode != nil {
+		durationMs := int(time.Since(r.startTime) / time.Millisecond)
+		entry.DurationMs = &durationMs
+	}
+
+	return entry
+}
+
+// progress returns a human-readable description of the bytes read so far.
+func (r *progressReporter) progress() string {
+	bytesRead := atomic.LoadInt64(&r.bytesRead)
+	if r.upload.UploadSize == nil || *r.upload.UploadSize <= 0 {
+		return fmt.Sprintf("read %d bytes\n", bytesRead)
+	}
+
+	percent := float64(bytesRead) / float64(*r.upload.UploadSize) * 100
+	return fmt.Sprintf("read %d of %d bytes (%.1f%%)\n", bytesRead, *r.upload.UploadSize, percent)
+}
+
+// countingReader counts the bytes read from the wrapped reader.
+type countingReader struct {
+	io.Reader
+	n *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	atomic.AddInt64(r.n, int64(n))
+	return n, err
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+)
+
+func TestProgressReporter(t *testing.T) {
+	mockWorkerStore := NewMockWorkerStore()
+	mockWorkerStore.AddExecutionLogEntryFunc.SetDefaultReturn(3, nil)
+
+	uploadSize := int64(20)
+	upload := dbstore.Upload{ID: 42, UploadSize: &uploadSize}
+
+	progress := startProgressReporter(context.Background(), mockWorkerStore, upload)
+	if _, err := io.Copy(io.Discard, progress.Reader(strings.NewReader("0123456789"))); err != nil {
+		t.Fatalf("unexpected error reading: %s", err)
+	}
+	progress.stop(context.Background(), errors.New("uh-oh"))
+
+	if history := mockWorkerStore.AddExecutionLogEntryFunc.History(); len(history) != 1 {
+		t.Fatalf("unexpected number of AddExecutionLogEntry calls. want=%d have=%d", 1, len(history))
+	} else if history[0].Arg1 != 42 {
+		t.Errorf("unexpected upload id. want=%d have=%d", 42, history[0].Arg1)
+	} else if history[0].Arg2.ExitCode != nil {
+		t.Errorf("unexpected exit code for in-progress entry")
+	}
+
+	history := mockWorkerStore.UpdateExecutionLogEntryFunc.History()
+	if len(history) == 0 {
+		t.Fatalf("expected UpdateExecutionLogEntry to be called")
+	}
+
+	call := history[len(history)-1]
+	if call.Arg1 != 42 || call.Arg2 != 3 {
+		t.Errorf("unexpected upload or entry id. want=(%d, %d) have=(%d, %d)", 42, 3, call.Arg1, call.Arg2)
+	}
+	if expected := "read 10 of 20 bytes (50.0%)\n"; call.Arg3.Out != expected {
+		t.Errorf("unexpected progress. want=%q have=%q", expected, call.Arg3.Out)
+	}
+	if call.Arg3.ExitCode == nil || *call.Arg3.ExitCode != 1 {
+		t.Errorf("unexpected exit code. want=%d have=%v", 1, call.Arg3.ExitCode)
+	}
+	if call.Arg3.DurationMs == nil {
+		t.Errorf("expected duration to be set")
+	}
+}
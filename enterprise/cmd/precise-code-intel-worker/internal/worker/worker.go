@@ -23,18 +23,20 @@ func NewWorker(
 	pollInterval time.Duration,
 	numProcessorRoutines int,
 	budgetMax int64,
+	hoverSpillThreshold int64,
 	workerMetrics workerutil.WorkerMetrics,
 ) *workerutil.Worker {
 	rootContext := actor.WithActor(context.Background(), &actor.Actor{Internal: true})
 
 	handler := &handler{
-		dbStore:         dbStore,
-		workerStore:     workerStore,
-		lsifStore:       lsifStore,
-		uploadStore:     uploadStore,
-		gitserverClient: gitserverClient,
-		enableBudget:    budgetMax > 0,
-		budgetRemaining: budgetMax,
+		dbStore:             dbStore,
+		workerStore:         workerStore,
+		lsifStore:           lsifStore,
+		uploadStore:         uploadStore,
+		gitserverClient:     gitserverClient,
+		enableBudget:        budgetMax > 0,
+		budgetRemaining:     budgetMax,
+		hoverSpillThreshold: hoverSpillThreshold,
 	}
 
 	return dbworker.NewWorker(rootContext, workerStore, handler, workerutil.WorkerOptions{
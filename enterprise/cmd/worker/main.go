@@ -17,6 +17,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/versions"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/globalindex"
 )
 
 func main() {
@@ -34,6 +35,7 @@ func main() {
 		"codehost-version-syncing": versions.NewSyncingJob(),
 		"insights-job":             insights.NewInsightsJob(),
 		"batches-janitor":          batches.NewJanitorJob(),
+		"symbols-global-index":     globalindex.NewIndexingJob(),
 	})
 }
 
@@ -0,0 +1,46 @@
+package lsifstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+// Callables returns the functions, methods, and constructors defined within a single document.
+func (s *Store) Callables(ctx context.Context, bundleID int, path string) (_ []Callable, err error) {
+	ctx, traceLog, endObservation := s.operations.callables.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("bundleID", bundleID),
+		log.String("path", path),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	documentData, exists, err := s.scanFirstDocumentData(s.Store.Query(ctx, sqlf.Sprintf(rangesDocumentQuery, bundleID, path)))
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	traceLog(log.Int("numRanges", len(documentData.Document.Ranges)))
+
+	var callables []Callable
+	for _, r := range documentData.Document.Ranges {
+		if r.Callable == nil {
+			continue
+		}
+
+		callables = append(callables, Callable{
+			Range:  newRange(r.StartLine, r.StartCharacter, r.EndLine, r.EndCharacter),
+			Extent: newRange(r.Callable.StartLine, r.Callable.StartCharacter, r.Callable.EndLine, r.Callable.EndCharacter),
+		})
+	}
+	traceLog(log.Int("numCallables", len(callables)))
+
+	sort.Slice(callables, func(i, j int) bool {
+		return compareBundleRanges(callables[i].Range, callables[j].Range)
+	})
+
+	return callables, nil
+}
@@ -21,6 +21,13 @@ func (s *Store) Definitions(ctx context.Context, bundleID int, path string, line
 	return s.definitionsReferences(ctx, extractor, operation, bundleID, path, line, character, limit, offset)
 }
 
+// TypeDefinitions returns the set of locations defining the type of the symbol at the given position.
+func (s *Store) TypeDefinitions(ctx context.Context, bundleID int, path string, line, character, limit, offset int) (_ []Location, _ int, err error) {
+	extractor := func(r precise.RangeData) precise.ID { return r.TypeDefinitionResultID }
+	operation := s.operations.typeDefinitions
+	return s.definitionsReferences(ctx, extractor, operation, bundleID, path, line, character, limit, offset)
+}
+
 // References returns the set of locations referencing the symbol at the given position.
 func (s *Store) References(ctx context.Context, bundleID int, path string, line, character, limit, offset int) (_ []Location, _ int, err error) {
 	extractor := func(r precise.RangeData) precise.ID { return r.ReferenceResultID }
@@ -28,6 +35,13 @@ func (s *Store) References(ctx context.Context, bundleID int, path string, line,
 	return s.definitionsReferences(ctx, extractor, operation, bundleID, path, line, character, limit, offset)
 }
 
+// Implementations returns the set of locations implementing the symbol at the given position.
+func (s *Store) Implementations(ctx context.Context, bundleID int, path string, line, character, limit, offset int) (_ []Location, _ int, err error) {
+	extractor := func(r precise.RangeData) precise.ID { return r.ImplementationResultID }
+	operation := s.operations.implementations
+	return s.definitionsReferences(ctx, extractor, operation, bundleID, path, line, character, limit, offset)
+}
+
 func (s *Store) definitionsReferences(ctx context.Context, extractor func(r precise.RangeData) precise.ID, operation *observation.Operation, bundleID int, path string, line, character, limit, offset int) (_ []Location, _ int, err error) {
 	ctx, traceLog, endObservation := operation.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
 		log.Int("bundleID", bundleID),
@@ -457,7 +471,7 @@ func extractResultIDs(ranges []precise.RangeData, fn func(r precise.RangeData) p
 }
 
 const locationsDocumentQuery = `
--- source: enterprise/internal/codeintel/stores/lsifstore/locations.go:{Definitions,References}
+-- source: enterprise/internal/codeintel/stores/lsifstore/locations.go:{Definitions,TypeDefinitions,References,Implementations}
 SELECT
 	dump_id,
 	path,
@@ -9,6 +9,7 @@ import (
 
 type operations struct {
 	bulkMonikerResults              *observation.Operation
+	callables                       *observation.Operation
 	clear                           *observation.Operation
 	definitions                     *observation.Operation
 	deleteOldSearchRecords          *observation.Operation
@@ -23,12 +24,14 @@ type operations struct {
 	documentationReferences         *observation.Operation
 	exists                          *observation.Operation
 	hover                           *observation.Operation
+	implementations                 *observation.Operation
 	monikerResults                  *observation.Operation
 	monikersByPosition              *observation.Operation
 	packageInformation              *observation.Operation
 	ranges                          *observation.Operation
 	references                      *observation.Operation
 	stencil                         *observation.Operation
+	typeDefinitions                 *observation.Operation
 	writeDefinitions                *observation.Operation
 	writeDocumentationMappings      *observation.Operation
 	writeDocumentationPages         *observation.Operation
@@ -36,9 +39,11 @@ type operations struct {
 	writeDocumentationSearch        *observation.Operation
 	writeDocumentationSearchPrework *observation.Operation
 	writeDocuments                  *observation.Operation
+	writeImplementations            *observation.Operation
 	writeMeta                       *observation.Operation
 	writeReferences                 *observation.Operation
 	writeResultChunks               *observation.Operation
+	writeTypeDefinitions            *observation.Operation
 
 	locations           *observation.Operation
 	locationsWithinFile *observation.Operation
@@ -71,6 +76,7 @@ func newOperations(observationContext *observation.Context) *operations {
 
 	return &operations{
 		bulkMonikerResults:              op("BulkMonikerResults"),
+		callables:                       op("Callables"),
 		clear:                           op("Clear"),
 		definitions:                     op("Definitions"),
 		deleteOldSearchRecords:          op("DeleteOldSearchRecords"),
@@ -85,12 +91,14 @@ func newOperations(observationContext *observation.Context) *operations {
 		documentationReferences:         op("DocumentationReferences"),
 		exists:                          op("Exists"),
 		hover:                           op("Hover"),
+		implementations:                 op("Implementations"),
 		monikerResults:                  op("MonikerResults"),
 		monikersByPosition:              op("MonikersByPosition"),
 		packageInformation:              op("PackageInformation"),
 		ranges:                          op("Ranges"),
 		references:                      op("References"),
 		stencil:                         op("Stencil"),
+		typeDefinitions:                 op("TypeDefinitions"),
 		writeDefinitions:                op("WriteDefinitions"),
 		writeDocumentationMappings:      op("WriteDocumentationMappings"),
 		writeDocumentationPages:         op("WriteDocumentationPages"),
@@ -98,9 +106,11 @@ func newOperations(observationContext *observation.Context) *operations {
 		writeDocumentationSearch:        op("WriteDocumentationSearch"),
 		writeDocumentationSearchPrework: op("WriteDocumentationSearchPrework"),
 		writeDocuments:                  op("WriteDocuments"),
+		writeImplementations:            op("WriteImplementations"),
 		writeMeta:                       op("WriteMeta"),
 		writeReferences:                 op("WriteReferences"),
 		writeResultChunks:               op("WriteResultChunks"),
+		writeTypeDefinitions:            op("WriteTypeDefinitions"),
 
 		locations:           subOp("locations"),
 		locationsWithinFile: subOp("locationsWithinFile"),
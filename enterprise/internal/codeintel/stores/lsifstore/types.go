@@ -21,6 +21,13 @@ type Position struct {
 	Character int
 }
 
+// Callable is the range of the name of a function, method, or constructor paired with the extent of
+// its declaration.
+type Callable struct {
+	Range  Range
+	Extent Range
+}
+
 // Diagnostic describes diagnostic information attached to a location within a
 // particular dump.
 type Diagnostic struct {
@@ -34,7 +34,9 @@ func (args Args) String() string {
 		s = append(s, "-jobs", strconv.Itoa(args.NumWorkers))
 	}
 
-	if args.Matcher != "" {
+	if args.CustomMatcher != nil {
+		s = append(s, "-custom-matcher <language definition>")
+	} else if args.Matcher != "" {
 		s = append(s, "-matcher", args.Matcher)
 	}
 
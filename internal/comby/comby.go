@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -53,7 +54,8 @@ func rawArgs(args Args) (rawArgs []string) {
 		rawArgs = append(rawArgs, "-jobs", strconv.Itoa(args.NumWorkers))
 	}
 
-	if args.Matcher != "" {
+	// A custom matcher is passed as a file by PipeTo.
+	if args.CustomMatcher == nil && args.Matcher != "" {
 		rawArgs = append(rawArgs, "-matcher", args.Matcher)
 	}
 
@@ -124,6 +126,14 @@ func PipeTo(ctx context.Context, args Args, w io.Writer) (err error) {
 	defer cancel()
 
 	rawArgs := rawArgs(args)
+	if args.CustomMatcher != nil {
+		path, err := writeCustomMatcher(args.CustomMatcher)
+		if err != nil {
+			return errors.Wrap(err, "failed to write comby custom matcher")
+		}
+		defer os.Remove(path)
+		rawArgs = append(rawArgs, "-custom-matcher", path)
+	}
 	log15.Info("running comby", "args", args.String())
 
 	cmd := exec.Command(combyPath, rawArgs...)
@@ -170,6 +180,30 @@ func PipeTo(ctx context.Context, args Args, w io.Writer) (err error) {
 	return nil
 }
 
+// writeCustomMatcher writes syntax to a temporary file for -custom-matcher and
+// returns its path. The caller removes the file.
+func writeCustomMatcher(syntax *Syntax) (string, error) {
+	b, err := json.Marshal(syntax)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", "comby-matcher-*.json")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
 type unmarshaller func([]byte) Result
 
 func toFileMatch(b []byte) Result {
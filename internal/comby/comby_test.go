@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"testing"
 
@@ -205,3 +206,58 @@ func TestReplacements(t *testing.T) {
 		}
 	}
 }
+
+func TestSyntaxMarshalJSON(t *testing.T) {
+	test := func(s *Syntax) string {
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	autogold.Want("empty", `{"user_defined_delimiters":[],"escapable_string_literals":{"delimiters":[],"escape_character":"\\"},"raw_string_literals":[],"comments":[]}`).
+		Equal(t, test(&Syntax{}))
+
+	autogold.Want("full", `{"user_defined_delimiters":[["begin","end"]],"escapable_string_literals":{"delimiters":["\""],"escape_character":"'"},"raw_string_literals":[["{|","|}"]],"comments":[["Multiline","(*","*)"],["Until_newline","#"]]}`).
+		Equal(t, test(&Syntax{
+			Delimiters:        [][2]string{{"begin", "end"}},
+			StringLiterals:    []string{`"`},
+			EscapeCharacter:   "'",
+			RawStringLiterals: [][2]string{{"{|", "|}"}},
+			LineComments:      []string{"#"},
+			BlockComments:     [][2]string{{"(*", "*)"}},
+		}))
+}
+
+func TestCustomMatcher(t *testing.T) {
+	args := Args{
+		Input:         FileContent("begin x end"),
+		MatchTemplate: "begin :[x] end",
+		Matcher:       ".go",
+		CustomMatcher: &Syntax{Delimiters: [][2]string{{"begin", "end"}}},
+	}
+	for _, arg := range rawArgs(args) {
+		if arg == "-matcher" {
+			t.Fatalf("got -matcher with a custom matcher: %v", rawArgs(args))
+		}
+	}
+
+	// If we are not on CI skip the test if comby is not installed.
+	if os.Getenv("CI") == "" && !Exists() {
+		t.Skip("comby is not installed on the PATH. Try running 'bash <(curl -sL get.comby.dev)'.")
+	}
+
+	// The hole only matches balanced begin/end delimiters.
+	args.Input = FileContent("begin a begin b end end")
+	matches, err := Matches(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || len(matches[0].Matches) != 1 {
+		t.Fatalf("got %v, want one match", matches)
+	}
+	if got, want := matches[0].Matches[0].Matched, "begin a begin b end end"; got != want {
+		t.Fatalf("got match %q, want %q", got, want)
+	}
+}
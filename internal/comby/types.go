@@ -1,5 +1,7 @@
 package comby
 
+import "encoding/json"
+
 type Input interface {
 	input()
 }
@@ -39,6 +41,10 @@ type Args struct {
 	// Matcher is a file extension (e.g., '.go') which denotes which language parser to use
 	Matcher string
 
+	// CustomMatcher is a language definition to use instead of Matcher, for
+	// languages which comby does not support out of the box
+	CustomMatcher *Syntax
+
 	ResultKind resultKind
 
 	// FilePatterns is a list of file patterns (suffixes) to filter and process
@@ -48,6 +54,78 @@ type Args struct {
 	NumWorkers int
 }
 
+// Syntax is a custom language definition for comby, which describes the
+// delimiters, comments and string literals of a language.
+type Syntax struct {
+	// Delimiters are pairs of opening and closing delimiters which holes must
+	// balance
+	Delimiters [][2]string
+
+	// StringLiterals are the delimiters of string literals in which
+	// EscapeCharacter escapes the delimiter
+	StringLiterals []string
+
+	// EscapeCharacter escapes delimiters in StringLiterals
+	EscapeCharacter string
+
+	// RawStringLiterals are pairs of strings which start and end a string
+	// literal without escapes
+	RawStringLiterals [][2]string
+
+	// LineComments are strings which start a comment that extends to the end
+	// of the line
+	LineComments []string
+
+	// BlockComments are pairs of strings which start and end a multiline
+	// comment
+	BlockComments [][2]string
+}
+
+// MarshalJSON encodes s in the format comby expects for -custom-matcher.
+func (s *Syntax) MarshalJSON() ([]byte, error) {
+	type escapable struct {
+		Delimiters      []string `json:"delimiters"`
+		EscapeCharacter string   `json:"escape_character"`
+	}
+
+	comments := make([][]string, 0, len(s.BlockComments)+len(s.LineComments))
+	for _, c := range s.BlockComments {
+		comments = append(comments, []string{"Multiline", c[0], c[1]})
+	}
+	for _, c := range s.LineComments {
+		comments = append(comments, []string{"Until_newline", c})
+	}
+
+	escapeCharacter := s.EscapeCharacter
+	if escapeCharacter == "" {
+		escapeCharacter = `\`
+	}
+
+	// Comby rejects null in place of empty lists.
+	nonNil := func(pairs [][2]string) [][2]string {
+		if pairs == nil {
+			return [][2]string{}
+		}
+		return pairs
+	}
+	stringLiterals := s.StringLiterals
+	if stringLiterals == nil {
+		stringLiterals = []string{}
+	}
+
+	return json.Marshal(struct {
+		Delimiters        [][2]string `json:"user_defined_delimiters"`
+		StringLiterals    escapable   `json:"escapable_string_literals"`
+		RawStringLiterals [][2]string `json:"raw_string_literals"`
+		Comments          [][]string  `json:"comments"`
+	}{
+		Delimiters:        nonNil(s.Delimiters),
+		StringLiterals:    escapable{Delimiters: stringLiterals, EscapeCharacter: escapeCharacter},
+		RawStringLiterals: nonNil(s.RawStringLiterals),
+		Comments:          comments,
+	})
+}
+
 // Location is the location in a file
 type Location struct {
 	Offset int `json:"offset"`
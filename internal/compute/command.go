@@ -8,7 +8,7 @@ import (
 
 type Command interface {
 	command()
-	Run(context.Context, *result.FileMatch) (Result, error)
+	Run(context.Context, result.Match) (Result, error)
 	String() string
 }
 
@@ -16,8 +16,10 @@ var (
 	_ Command = (*MatchOnly)(nil)
 	_ Command = (*Replace)(nil)
 	_ Command = (*Output)(nil)
+	_ Command = (*Count)(nil)
 )
 
 func (MatchOnly) command() {}
 func (Replace) command()   {}
 func (Output) command()    {}
+func (Count) command()     {}
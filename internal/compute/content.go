@@ -0,0 +1,74 @@
+package compute
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// line is a line of a search result which commands run on.
+type line struct {
+	value  string
+	number int
+}
+
+// commitContent returns the text of a commit result which commands run on:
+// the diff of a diff result, and the message of a commit result.
+func commitContent(cm *result.CommitMatch) string {
+	if cm.DiffPreview != nil {
+		return cm.DiffPreview.Value
+	}
+	return string(cm.Commit.Message)
+}
+
+// resultLines returns the lines of r which commands run on: the matched lines
+// of a file result, and all lines of the message or diff of a commit result.
+func resultLines(r result.Match) ([]line, error) {
+	switch m := r.(type) {
+	case *result.FileMatch:
+		lines := make([]line, 0, len(m.LineMatches))
+		for _, l := range m.LineMatches {
+			lines = append(lines, line{value: l.Preview, number: int(l.LineNumber)})
+		}
+		return lines, nil
+	case *result.CommitMatch:
+		values := strings.Split(commitContent(m), "\n")
+		lines := make([]line, 0, len(values))
+		for i, v := range values {
+			lines = append(lines, line{value: v, number: i})
+		}
+		return lines, nil
+	default:
+		return nil, errors.Errorf("compute commands do not support %T results", r)
+	}
+}
+
+// resultPath returns the path of the file of a file result, and the empty
+// string for other results.
+func resultPath(r result.Match) string {
+	if fm, ok := r.(*result.FileMatch); ok {
+		return fm.Path
+	}
+	return ""
+}
+
+// metaEnvironment returns the template variables which describe r, rather
+// than a match in it: $repo and $commit, $path for file results, and $author,
+// $email and $date for commit results. Captures of a match pattern with the
+// same names take precedence.
+func metaEnvironment(r result.Match) Environment {
+	env := Environment{"repo": {Value: string(r.RepoName().Name)}}
+	switch m := r.(type) {
+	case *result.FileMatch:
+		env["commit"] = Data{Value: string(m.CommitID)}
+		env["path"] = Data{Value: m.Path}
+	case *result.CommitMatch:
+		env["commit"] = Data{Value: string(m.Commit.ID)}
+		env["author"] = Data{Value: m.Commit.Author.Name}
+		env["email"] = Data{Value: m.Commit.Author.Email}
+		env["date"] = Data{Value: m.Commit.Author.Date.Format("2006-01-02")}
+	}
+	return env
+}
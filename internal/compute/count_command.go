@@ -0,0 +1,79 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// Count counts the values of the GroupBy template over the matches of
+// MatchPattern. Its result for a single search result is a Histogram, and the
+// histograms of all search results are merged with a HistogramAggregator.
+type Count struct {
+	MatchPattern MatchPattern
+
+	// GroupBy is an output template, as in "$1". The matched value is
+	// counted if it is empty.
+	GroupBy string
+}
+
+func (c *Count) String() string {
+	if c.GroupBy == "" {
+		return fmt.Sprintf("Count: (%s)", c.MatchPattern.String())
+	}
+	return fmt.Sprintf("Count: (%s) by: (%s)", c.MatchPattern.String(), c.GroupBy)
+}
+
+// groupByValues returns the value of groupBy for each match of matchPattern on
+// the lines of r. Values are empty if a template variable has no value.
+func groupByValues(r result.Match, matchPattern MatchPattern, groupBy string) ([]string, error) {
+	lines, err := resultLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := metaEnvironment(r)
+	var values []string
+	switch p := matchPattern.(type) {
+	case *Regexp:
+		// Each match on a line is counted separately.
+		for _, l := range lines {
+			for _, m := range p.Value.FindAllStringSubmatchIndex(l.value, -1) {
+				match := fromRegexpMatches([][]int{m}, p.Value.SubexpNames(), l.value, l.number, "")
+				if groupBy == "" {
+					values = append(values, match.Value)
+				} else {
+					values = append(values, substitute(groupBy, mergeEnvironments(meta, match.Environment)))
+				}
+			}
+		}
+	case *Operator:
+		for _, env := range environments(p, leafMatches(lines, p)) {
+			values = append(values, substitute(groupBy, mergeEnvironments(meta, env)))
+		}
+	default:
+		return nil, errors.Errorf("unsupported count operation for match pattern %T", p)
+	}
+	return values, nil
+}
+
+func count(r result.Match, matchPattern MatchPattern, groupBy string) (*Histogram, error) {
+	values, err := groupByValues(r, matchPattern, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	a := NewHistogramAggregator()
+	for _, v := range values {
+		if v != "" {
+			a.add(v, string(r.RepoName().Name), resultPath(r), 1)
+		}
+	}
+	return a.Histogram(), nil
+}
+
+func (c *Count) Run(_ context.Context, r result.Match) (Result, error) {
+	return count(r, c.MatchPattern, c.GroupBy)
+}
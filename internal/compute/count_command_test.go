@@ -0,0 +1,77 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/hexops/autogold"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func Test_count(t *testing.T) {
+	fileMatch := func(repo, path string, lines ...string) *result.FileMatch {
+		fm := &result.FileMatch{File: result.File{Repo: types.RepoName{Name: api.RepoName("github.com/" + repo)}, Path: path}}
+		for i, l := range lines {
+			fm.LineMatches = append(fm.LineMatches, &result.LineMatch{Preview: l, LineNumber: int32(i)})
+		}
+		return fm
+	}
+	data := []*result.FileMatch{
+		fileMatch("a", "package.json", `"lodash": "4.17.20", "left-pad": "1.3.0"`),
+		fileMatch("a", "web/package.json", `"lodash": "4.17.21"`),
+		fileMatch("b", "package.json", `"lodash": "4.17.21"`),
+	}
+
+	test := func(input string) string {
+		q, err := Parse(input)
+		if err != nil {
+			return err.Error()
+		}
+		a := NewHistogramAggregator()
+		for _, fm := range data {
+			r, err := q.Command.Run(context.Background(), fm)
+			if err != nil {
+				return err.Error()
+			}
+			a.Add(r.(*Histogram))
+		}
+		v, _ := json.Marshal(a.Histogram())
+		return string(v)
+	}
+
+	autogold.Want("count by capture", `{"buckets":[{"value":"4.17.21","count":2,"repositories":[{"repository":"github.com/a","count":1},{"repository":"github.com/b","count":1}],"files":[{"repository":"github.com/a","path":"web/package.json","count":1},{"repository":"github.com/b","path":"package.json","count":1}]},{"value":"4.17.20","count":1,"repositories":[{"repository":"github.com/a","count":1}],"files":[{"repository":"github.com/a","path":"package.json","count":1}]}]}`).
+		Equal(t, test(`content:count("lodash": "([\d.]+)" -> by: $1)`))
+
+	autogold.Want("count matches", `{"buckets":[{"value":"\"left-pad\": \"1.3.0\"","count":1,"repositories":[{"repository":"github.com/a","count":1}],"files":[{"repository":"github.com/a","path":"package.json","count":1}]}]}`).
+		Equal(t, test(`content:count("left-pad": "[\d.]+")`))
+
+	autogold.Want("count each match on a line", `{"buckets":[{"value":"package","count":4,"repositories":[{"repository":"github.com/a","count":3},{"repository":"github.com/b","count":1}],"files":[{"repository":"github.com/a","path":"package.json","count":2},{"repository":"github.com/a","path":"web/package.json","count":1},{"repository":"github.com/b","path":"package.json","count":1}]}]}`).
+		Equal(t, test(`content:count("(lodash|left-pad)" -> by: package)`))
+
+	autogold.Want("count and expression without group-by",
+		"count command with 'and' or 'or' expressions expects a group-by template, as in count(a(b) and c(d) -> by: $1.1 $2.1)").
+		Equal(t, test(`content:'count(a and b)'`))
+}
+
+func Test_histogramLimit(t *testing.T) {
+	a := NewHistogramAggregator()
+	for i := 0; i <= maxBuckets; i++ {
+		// Value i occurs i+1 times, so value 0 has the fewest occurrences.
+		a.add(strconv.Itoa(i), "github.com/a", "file", i+1)
+	}
+
+	h := a.Histogram()
+	if !h.LimitHit {
+		t.Errorf("expected the histogram to hit its bucket limit")
+	}
+	if len(h.Buckets) != maxBuckets {
+		t.Fatalf("expected %d buckets, got %d", maxBuckets, len(h.Buckets))
+	}
+	if last := h.Buckets[len(h.Buckets)-1].Value; last != "1" {
+		t.Errorf("expected the value with the fewest occurrences to be omitted, last bucket is %q", last)
+	}
+}
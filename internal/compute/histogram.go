@@ -0,0 +1,159 @@
+package compute
+
+import (
+	"sort"
+)
+
+// maxBucketFiles is the number of files listed in a bucket of a histogram, those
+// with the most occurrences of its value.
+const maxBucketFiles = 100
+
+// maxBuckets is the number of buckets of an aggregated histogram, those of the
+// values with the most occurrences.
+const maxBuckets = 500
+
+// Histogram is the result of a count command, the number of occurrences of
+// each value of its group-by template.
+type Histogram struct {
+	Buckets []*Bucket `json:"buckets"`
+	// LimitHit is true if buckets of values with fewer occurrences were
+	// omitted.
+	LimitHit bool `json:"limitHit,omitempty"`
+}
+
+// Bucket is the number of occurrences of a value, in total and per repository
+// and file.
+type Bucket struct {
+	Value        string             `json:"value"`
+	Count        int                `json:"count"`
+	Repositories []*RepositoryCount `json:"repositories"`
+	Files        []*FileCount       `json:"files"`
+}
+
+type RepositoryCount struct {
+	Repository string `json:"repository"`
+	Count      int    `json:"count"`
+}
+
+type FileCount struct {
+	Repository string `json:"repository"`
+	// Path is empty for the counts of commit and diff results.
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+type fileKey struct {
+	repository string
+	path       string
+}
+
+// bucketCounts is the number of occurrences of a value, in total and per file.
+type bucketCounts struct {
+	count int
+	files map[fileKey]int
+}
+
+// HistogramAggregator merges the histograms of a count command over search
+// results, which are streamed, into a histogram of all results seen so far.
+type HistogramAggregator struct {
+	buckets map[string]*bucketCounts
+	dirty   bool
+}
+
+func NewHistogramAggregator() *HistogramAggregator {
+	return &HistogramAggregator{buckets: make(map[string]*bucketCounts)}
+}
+
+func (a *HistogramAggregator) add(value, repository, path string, count int) {
+	b, ok := a.buckets[value]
+	if !ok {
+		b = &bucketCounts{files: make(map[fileKey]int)}
+		a.buckets[value] = b
+	}
+	b.count += count
+	b.files[fileKey{repository: repository, path: path}] += count
+	a.dirty = true
+}
+
+// Add merges h into the aggregated histogram. h must list all files of its
+// buckets, which is the case for the histogram of a single search result.
+func (a *HistogramAggregator) Add(h *Histogram) {
+	for _, b := range h.Buckets {
+		for _, f := range b.Files {
+			a.add(b.Value, f.Repository, f.Path, f.Count)
+		}
+	}
+}
+
+// Dirty reports whether the aggregated histogram changed since it was last
+// returned by Histogram.
+func (a *HistogramAggregator) Dirty() bool {
+	return a.dirty
+}
+
+// Histogram returns the aggregated histogram, limited to the maxBuckets values
+// with the most occurrences. Buckets, and the repositories and files of each
+// bucket, are sorted by descending count.
+func (a *HistogramAggregator) Histogram() *Histogram {
+	a.dirty = false
+
+	values := make([]string, 0, len(a.buckets))
+	for value := range a.buckets {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		x, y := a.buckets[values[i]].count, a.buckets[values[j]].count
+		if x != y {
+			return x > y
+		}
+		return values[i] < values[j]
+	})
+
+	h := &Histogram{}
+	if len(values) > maxBuckets {
+		values = values[:maxBuckets]
+		h.LimitHit = true
+	}
+
+	// Only the files of the buckets which are returned are sorted.
+	h.Buckets = make([]*Bucket, 0, len(values))
+	for _, value := range values {
+		h.Buckets = append(h.Buckets, a.bucket(value))
+	}
+	return h
+}
+
+func (a *HistogramAggregator) bucket(value string) *Bucket {
+	counts := a.buckets[value]
+	b := &Bucket{Value: value, Count: counts.count}
+	repositories := make(map[string]int)
+	for k, count := range counts.files {
+		repositories[k.repository] += count
+		b.Files = append(b.Files, &FileCount{Repository: k.repository, Path: k.path, Count: count})
+	}
+	for repository, count := range repositories {
+		b.Repositories = append(b.Repositories, &RepositoryCount{Repository: repository, Count: count})
+	}
+
+	sort.Slice(b.Repositories, func(i, j int) bool {
+		x, y := b.Repositories[i], b.Repositories[j]
+		if x.Count != y.Count {
+			return x.Count > y.Count
+		}
+		return x.Repository < y.Repository
+	})
+	sort.Slice(b.Files, func(i, j int) bool {
+		x, y := b.Files[i], b.Files[j]
+		if x.Count != y.Count {
+			return x.Count > y.Count
+		}
+		if x.Repository != y.Repository {
+			return x.Repository < y.Repository
+		}
+		return x.Path < y.Path
+	})
+	if len(b.Files) > maxBucketFiles {
+		b.Files = b.Files[:maxBucketFiles]
+	}
+	return b
+}
@@ -6,6 +6,8 @@ import (
 	"regexp"
 	"strconv"
 
+	"github.com/cockroachdb/errors"
+
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 )
 
@@ -17,7 +19,10 @@ func (c *MatchOnly) String() string {
 	return fmt.Sprintf("Match only: %s", c.MatchPattern.String())
 }
 
-func fromRegexpMatches(matches [][]int, namedGroups []string, lineValue string, lineNumber int) Match {
+// fromRegexpMatches converts the matches of a regexp on a line to a Match.
+// Unnamed capture groups are added to the environment by number, prefixed
+// with prefix.
+func fromRegexpMatches(matches [][]int, namedGroups []string, lineValue string, lineNumber int, prefix string) Match {
 	env := make(Environment)
 	var firstValue string
 	var firstRange Range
@@ -45,7 +50,7 @@ func fromRegexpMatches(matches [][]int, namedGroups []string, lineValue string,
 
 			var v string
 			if namedGroups[j/2] == "" {
-				v = strconv.Itoa(j / 2)
+				v = prefix + strconv.Itoa(j/2)
 			} else {
 				v = namedGroups[j/2]
 			}
@@ -55,17 +60,50 @@ func fromRegexpMatches(matches [][]int, namedGroups []string, lineValue string,
 	return Match{Value: firstValue, Range: firstRange, Environment: env}
 }
 
-func matchOnly(fm *result.FileMatch, r *regexp.Regexp) *MatchContext {
-	matches := make([]Match, 0, len(fm.LineMatches))
-	for _, l := range fm.LineMatches {
-		regexpMatches := r.FindAllStringSubmatchIndex(l.Preview, -1)
+// regexpMatches returns the matches of r on lines.
+func regexpMatches(lines []line, r *regexp.Regexp, prefix string) []Match {
+	matches := make([]Match, 0, len(lines))
+	for _, l := range lines {
+		regexpMatches := r.FindAllStringSubmatchIndex(l.value, -1)
 		if len(regexpMatches) > 0 {
-			matches = append(matches, fromRegexpMatches(regexpMatches, r.SubexpNames(), l.Preview, int(l.LineNumber)))
+			matches = append(matches, fromRegexpMatches(regexpMatches, r.SubexpNames(), l.value, l.number, prefix))
+		}
+	}
+	return matches
+}
+
+func matchOnly(r result.Match, re *regexp.Regexp) (*MatchContext, error) {
+	lines, err := resultLines(r)
+	if err != nil {
+		return nil, err
+	}
+	return &MatchContext{Matches: regexpMatches(lines, re, ""), Path: resultPath(r)}, nil
+}
+
+// matchOnlyOperator returns the matches of all regexps in p on the lines of
+// r if the matches satisfy p, and no matches otherwise.
+func matchOnlyOperator(r result.Match, p *Operator) (*MatchContext, error) {
+	lines, err := resultLines(r)
+	if err != nil {
+		return nil, err
+	}
+	leaves := leafMatches(lines, p)
+	matches := []Match{}
+	if satisfied(p, leaves) {
+		for _, r := range p.leaves() {
+			matches = append(matches, leaves[r]...)
 		}
 	}
-	return &MatchContext{Matches: matches, Path: fm.Path}
+	return &MatchContext{Matches: matches, Path: resultPath(r)}, nil
 }
 
-func (c *MatchOnly) Run(_ context.Context, fm *result.FileMatch) (Result, error) {
-	return matchOnly(fm, c.MatchPattern.(*Regexp).Value), nil
+func (c *MatchOnly) Run(_ context.Context, r result.Match) (Result, error) {
+	switch p := c.MatchPattern.(type) {
+	case *Regexp:
+		return matchOnly(r, p.Value)
+	case *Operator:
+		return matchOnlyOperator(r, p)
+	default:
+		return nil, errors.Errorf("unsupported match only operation for match pattern %T", p)
+	}
 }
@@ -38,7 +38,7 @@ func Test_matchOnly(t *testing.T) {
 
 	test := func(input string, serialize serializer) string {
 		r, _ := regexp.Compile(input)
-		result := matchOnly(data, r)
+		result, _ := matchOnly(data, r)
 		v, _ := json.MarshalIndent(serialize(result), "", "  ")
 		return string(v)
 	}
@@ -138,3 +138,35 @@ func Test_matchOnly(t *testing.T) {
   "path": "bedge"
 }`).Equal(t, test("a(b(c))(de)f(g)h", match))
 }
+
+func Test_matchOnlyOperator(t *testing.T) {
+	data := &result.FileMatch{
+		File: result.File{Path: "bedge"},
+		LineMatches: []*result.LineMatch{
+			{Preview: "abcdefgh", LineNumber: 1},
+			{Preview: "ijklmnop", LineNumber: 2},
+		},
+	}
+
+	test := func(input string) string {
+		q, err := Parse(input)
+		if err != nil {
+			return err.Error()
+		}
+		result, _ := matchOnlyOperator(data, q.Command.(*MatchOnly).MatchPattern.(*Operator))
+		v, _ := json.MarshalIndent(environment(result), "", "  ")
+		return string(v)
+	}
+
+	autogold.Want("compute and expression environment", `{
+  "1.1": "b",
+  "2.1": "j",
+  "named": "k"
+}`).Equal(t, test("a(b) and i(j)(?P<named>k)"))
+
+	autogold.Want("compute unsatisfied and expression", "{}").Equal(t, test("a(b) and nothing"))
+
+	autogold.Want("compute or expression environment", `{
+  "1.1": "b"
+}`).Equal(t, test("a(b) or nothing(x)"))
+}
@@ -0,0 +1,183 @@
+package compute
+
+import (
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+)
+
+// maxEnvironments caps the number of combinations of matches considered for
+// an 'and' operator in a single file.
+const maxEnvironments = 1000
+
+// leaves returns the regexps in p, in the order in which they appear in the
+// query.
+func (p *Operator) leaves() []*Regexp {
+	var leaves []*Regexp
+	for _, operand := range p.Operands {
+		switch o := operand.(type) {
+		case *Regexp:
+			leaves = append(leaves, o)
+		case *Operator:
+			leaves = append(leaves, o.leaves()...)
+		}
+	}
+	return leaves
+}
+
+// leafMatches returns the matches of each regexp in p on lines.
+// The unnamed capture groups of the i-th regexp in p are prefixed with "i.",
+// so that the captures of each regexp are addressable separately.
+func leafMatches(lines []line, p *Operator) map[*Regexp][]Match {
+	leaves := p.leaves()
+	matches := make(map[*Regexp][]Match, len(leaves))
+	for i, r := range leaves {
+		matches[r] = regexpMatches(lines, r.Value, strconv.Itoa(i+1)+".")
+	}
+	return matches
+}
+
+// satisfied reports whether matches satisfy p: an 'or' operator is satisfied
+// if any operand is, and an 'and' operator if all operands are.
+func satisfied(p MatchPattern, matches map[*Regexp][]Match) bool {
+	switch p := p.(type) {
+	case *Regexp:
+		return len(matches[p]) > 0
+	case *Operator:
+		for _, operand := range p.Operands {
+			ok := satisfied(operand, matches)
+			if p.Kind == Or && ok {
+				return true
+			}
+			if p.Kind == And && !ok {
+				return false
+			}
+		}
+		return p.Kind == And
+	}
+	return false
+}
+
+// environments returns the environments of the combinations of matches which
+// satisfy p. An 'or' operator yields the environments of each of its operands,
+// and an 'and' operator the merged environments of one combination of each of
+// its operands.
+func environments(p MatchPattern, matches map[*Regexp][]Match) []Environment {
+	switch p := p.(type) {
+	case *Regexp:
+		envs := make([]Environment, 0, len(matches[p]))
+		for _, m := range matches[p] {
+			envs = append(envs, m.Environment)
+		}
+		return envs
+	case *Operator:
+		if p.Kind == Or {
+			var envs []Environment
+			for _, operand := range p.Operands {
+				envs = append(envs, environments(operand, matches)...)
+			}
+			return envs
+		}
+
+		envs := []Environment{{}}
+		for _, operand := range p.Operands {
+			operandEnvs := environments(operand, matches)
+			product := make([]Environment, 0, len(envs)*len(operandEnvs))
+		loop:
+			for _, env := range envs {
+				for _, operandEnv := range operandEnvs {
+					if len(product) == maxEnvironments {
+						break loop
+					}
+					product = append(product, mergeEnvironments(env, operandEnv))
+				}
+			}
+			envs = product
+		}
+		return envs
+	}
+	return nil
+}
+
+func mergeEnvironments(a, b Environment) Environment {
+	env := make(Environment, len(a)+len(b))
+	for k, v := range a {
+		env[k] = v
+	}
+	for k, v := range b {
+		env[k] = v
+	}
+	return env
+}
+
+// toMatchPattern converts an 'and' or 'or' expression of search patterns to a
+// match pattern, converting the operands of the expression which are not
+// themselves 'and' or 'or' expressions with leaf.
+func toMatchPattern(node query.Node, leaf func(query.Node) (MatchPattern, error)) (MatchPattern, error) {
+	operator, ok := node.(query.Operator)
+	if !ok || (operator.Kind != query.And && operator.Kind != query.Or) {
+		return leaf(node)
+	}
+
+	kind := And
+	if operator.Kind == query.Or {
+		kind = Or
+	}
+	operands := make([]MatchPattern, 0, len(operator.Operands))
+	for _, operand := range operator.Operands {
+		p, err := toMatchPattern(operand, leaf)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, p)
+	}
+	return &Operator{Kind: kind, Operands: operands}, nil
+}
+
+// toRegexpLeaf converts a search pattern in an 'and' or 'or' expression to a
+// regexp.
+func toRegexpLeaf(node query.Node) (MatchPattern, error) {
+	pattern, ok := node.(query.Pattern)
+	if !ok {
+		return nil, errors.Errorf("compute endpoint expects only search patterns in 'and' or 'or' expressions, got %s", node.String())
+	}
+	if pattern.Negated {
+		return nil, errors.New("compute endpoint expects a nonnegated pattern")
+	}
+	return toRegexpPattern(pattern.Value)
+}
+
+// span returns the offsets in the input of the query parser at which node
+// starts and ends.
+func span(node query.Node) (start, end int) {
+	switch n := node.(type) {
+	case query.Pattern:
+		return n.Annotation.Range.Start.Column, n.Annotation.Range.End.Column
+	case query.Operator:
+		if len(n.Operands) > 0 {
+			start, _ = span(n.Operands[0])
+			_, end = span(n.Operands[len(n.Operands)-1])
+		}
+	}
+	return start, end
+}
+
+// toNode converts a match pattern to the search pattern which finds the files
+// it applies to.
+func toNode(p MatchPattern) query.Node {
+	o, ok := p.(*Operator)
+	if !ok {
+		return query.Pattern{Value: p.String()}
+	}
+	operands := make([]query.Node, 0, len(o.Operands))
+	for _, operand := range o.Operands {
+		operands = append(operands, toNode(operand))
+	}
+	kind := query.And
+	if o.Kind == Or {
+		kind = query.Or
+	}
+	return query.Operator{Kind: kind, Operands: operands}
+}
@@ -3,7 +3,11 @@ package compute
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 )
 
@@ -17,6 +21,48 @@ func (c *Output) String() string {
 	return fmt.Sprintf("Output with separator: (%s) -> (%s) separator: %s", c.MatchPattern.String(), c.OutputPattern, c.Separator)
 }
 
-func (c *Output) Run(context.Context, *result.FileMatch) (Result, error) {
-	return nil, nil
+// templateVariable matches variables in output templates, which are written
+// $name or ${name}. Names of the captures of a regexp in an 'and' or 'or'
+// expression contain a dot, as in $2.1.
+var templateVariable = lazyregexp.New(`\$(?:\{([\w.]+)\}|(\w+(?:\.\d+)?))`)
+
+// substitute replaces the variables in template with their values in env.
+// Variables which are not in env are replaced with the empty string.
+func substitute(template string, env Environment) string {
+	return templateVariable.ReplaceAllStringFunc(template, func(variable string) string {
+		name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(variable, "$"), "{"), "}")
+		return env[name].Value
+	})
+}
+
+// output substitutes the captures of each match of matchPattern in r, and the
+// variables describing r, in outputPattern.
+func output(r result.Match, matchPattern MatchPattern, outputPattern, separator string) (*Text, error) {
+	lines, err := resultLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var envs []Environment
+	switch p := matchPattern.(type) {
+	case *Regexp:
+		for _, m := range regexpMatches(lines, p.Value, "") {
+			envs = append(envs, m.Environment)
+		}
+	case *Operator:
+		envs = environments(p, leafMatches(lines, p))
+	default:
+		return nil, errors.Errorf("unsupported output operation for match pattern %T", p)
+	}
+
+	meta := metaEnvironment(r)
+	values := make([]string, 0, len(envs))
+	for _, env := range envs {
+		values = append(values, substitute(outputPattern, mergeEnvironments(meta, env)))
+	}
+	return &Text{Value: strings.Join(values, separator), Kind: "output"}, nil
+}
+
+func (c *Output) Run(_ context.Context, r result.Match) (Result, error) {
+	return output(r, c.MatchPattern, c.OutputPattern, c.Separator)
 }
@@ -0,0 +1,91 @@
+package compute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hexops/autogold"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
+)
+
+func Test_output(t *testing.T) {
+	data := &result.FileMatch{
+		File: result.File{Path: "main.go"},
+		LineMatches: []*result.LineMatch{
+			{Preview: `import "fmt"`, LineNumber: 2},
+			{Preview: `func hello() { fmt.Println("hello") }`, LineNumber: 4},
+			{Preview: `func bye() { fmt.Println("bye") }`, LineNumber: 5},
+		},
+	}
+
+	test := func(input string) string {
+		q, err := Parse(input)
+		if err != nil {
+			return err.Error()
+		}
+		c := q.Command.(*Output)
+		result, err := output(data, c.MatchPattern, c.OutputPattern, c.Separator)
+		if err != nil {
+			return err.Error()
+		}
+		return result.Value
+	}
+
+	autogold.Want("output regexp captures", "hello\nbye").
+		Equal(t, test(`content:output(func (\w+) -> $1)`))
+
+	autogold.Want("output named captures with braces", "hello!\nbye!").
+		Equal(t, test(`content:output(func (?P<name>\w+) -> ${name}!)`))
+
+	autogold.Want("output and expression", "fmt used in hello\nfmt used in bye").
+		Equal(t, test(`content:'output(import "(?P<pkg>\\w+)" and func (\\w+) -> $pkg used in $2.1)'`))
+
+	autogold.Want("output or expression", "fmt\nhello\nbye").
+		Equal(t, test(`content:'output(import "(\\w+)" or func (\\w+) -> $1.1$2.1)'`))
+
+	autogold.Want("output unsatisfied and expression", "").
+		Equal(t, test(`content:'output(import and nothing -> $1.1)'`))
+}
+
+func Test_outputCommit(t *testing.T) {
+	commit := &result.CommitMatch{
+		Repo: types.RepoName{Name: "github.com/sourcegraph/sourcegraph"},
+		Commit: gitapi.Commit{
+			ID:      "deadbeef",
+			Author:  gitapi.Signature{Name: "alice", Email: "alice@example.com", Date: time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)},
+			Message: "search: fix streaming\n\nfeat: add compute\n",
+		},
+	}
+	diff := &result.CommitMatch{
+		Repo:   commit.Repo,
+		Commit: commit.Commit,
+		DiffPreview: &result.HighlightedString{
+			Value: "main.go main.go\n@@ -1,1 +1,1 @@\n-func hello() {}\n+func bye() {}\n",
+		},
+	}
+
+	test := func(input string, r result.Match) string {
+		q, err := Parse(input)
+		if err != nil {
+			return err.Error()
+		}
+		c := q.Command.(*Output)
+		result, err := output(r, c.MatchPattern, c.OutputPattern, c.Separator)
+		if err != nil {
+			return err.Error()
+		}
+		return result.Value
+	}
+
+	autogold.Want("output commit message", "* search: fix streaming (alice, 2021-10-01, deadbeef)\n* feat: add compute (alice, 2021-10-01, deadbeef)").
+		Equal(t, test(`content:output(^(\w+): (.*) -> * $1: $2 ($author, $date, $commit))`, commit))
+
+	autogold.Want("output diff", "github.com/sourcegraph/sourcegraph: bye by alice@example.com").
+		Equal(t, test(`content:output(^\+func (\w+) -> $repo: $1 by $email)`, diff))
+
+	autogold.Want("captures take precedence over commit variables", "search\nfeat").
+		Equal(t, test(`content:output(^(?P<author>\w+): -> $author)`, commit))
+}
@@ -3,6 +3,7 @@ package compute
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
@@ -24,20 +25,22 @@ func (q Query) String() string {
 }
 
 func (q Query) ToSearchQuery() (string, error) {
-	var searchPattern string
+	var matchPattern MatchPattern
 	switch c := q.Command.(type) {
 	case *MatchOnly:
-		searchPattern = c.MatchPattern.String()
+		matchPattern = c.MatchPattern
 	case *Replace:
-		searchPattern = c.MatchPattern.String()
+		matchPattern = c.MatchPattern
 	case *Output:
-		searchPattern = c.MatchPattern.String()
+		matchPattern = c.MatchPattern
+	case *Count:
+		matchPattern = c.MatchPattern
 	default:
 		return "", errors.Errorf("unsupported query conversion for compute command %T", c)
 	}
 	basic := query.Basic{
 		Parameters: q.Parameters,
-		Pattern:    query.Pattern{Value: searchPattern},
+		Pattern:    toNode(matchPattern),
 	}
 	return basic.StringHuman(), nil
 }
@@ -47,8 +50,9 @@ type MatchPattern interface {
 	String() string
 }
 
-func (Regexp) pattern() {}
-func (Comby) pattern()  {}
+func (Regexp) pattern()   {}
+func (Comby) pattern()    {}
+func (Operator) pattern() {}
 
 type Regexp struct {
 	Value *regexp.Regexp
@@ -58,6 +62,21 @@ type Comby struct {
 	Value string
 }
 
+// Operator is an 'and' or 'or' expression of match patterns, as in
+// "(a or b) and c". The unnamed capture groups of the i-th regexp in the
+// expression are addressable as "i.1", "i.2", and so on.
+type Operator struct {
+	Kind     OperatorKind
+	Operands []MatchPattern
+}
+
+type OperatorKind int
+
+const (
+	And OperatorKind = iota
+	Or
+)
+
 func (p Regexp) String() string {
 	return p.Value.String()
 }
@@ -66,32 +85,56 @@ func (p Comby) String() string {
 	return p.Value
 }
 
-func extractPattern(basic query.Basic) (*query.Pattern, error) {
+func (p Operator) String() string {
+	operands := make([]string, 0, len(p.Operands))
+	for _, o := range p.Operands {
+		operands = append(operands, o.String())
+	}
+	separator := " and "
+	if p.Kind == Or {
+		separator = " or "
+	}
+	return "(" + strings.Join(operands, separator) + ")"
+}
+
+func extractPattern(basic query.Basic) (query.Node, error) {
 	if basic.Pattern == nil {
 		return nil, errors.New("compute endpoint expects nonempty pattern")
 	}
 	var err error
-	var pattern *query.Pattern
-	seen := false
 	query.VisitPattern([]query.Node{basic.Pattern}, func(value string, negated bool, annotation query.Annotation) {
-		if err != nil {
-			return
-		}
 		if negated {
 			err = errors.New("compute endpoint expects a nonnegated pattern")
-			return
-		}
-		if seen {
-			err = errors.New("compute endpoint only supports one search pattern currently ('and' or 'or' operators are not supported yet)")
-			return
 		}
-		pattern = &query.Pattern{Value: value, Annotation: annotation}
-		seen = true
 	})
 	if err != nil {
 		return nil, err
 	}
-	return pattern, nil
+	return basic.Pattern, nil
+}
+
+// parseMatchPattern parses the match pattern of a command, which may be an
+// 'and' or 'or' expression like the pattern of a search query. Patterns without
+// such operators, and the operands of the expression, are converted with leaf
+// as written, unlike search patterns whose whitespace is interpreted.
+func parseMatchPattern(value string, leaf func(value string) (MatchPattern, error)) (MatchPattern, error) {
+	nodes, err := query.Parse(value, query.SearchTypeRegex)
+	if err != nil || len(nodes) != 1 {
+		return leaf(value)
+	}
+	if operator, ok := nodes[0].(query.Operator); !ok || (operator.Kind != query.And && operator.Kind != query.Or) {
+		return leaf(value)
+	}
+	return toMatchPattern(nodes[0], func(node query.Node) (MatchPattern, error) {
+		if p, ok := node.(query.Pattern); ok && p.Negated {
+			return nil, errors.New("compute endpoint expects a nonnegated pattern")
+		}
+		start, end := span(node)
+		if start < 0 || end > len(value) || start > end {
+			return nil, errors.Errorf("invalid pattern %s", node.String())
+		}
+		return leaf(value[start:end])
+	})
 }
 
 func toRegexpPattern(value string) (MatchPattern, error) {
@@ -108,6 +151,8 @@ var ComputePredicateRegistry = query.PredicateRegistry{
 		"replace.regexp":     func() query.Predicate { return query.EmptyPredicate{} },
 		"replace.structural": func() query.Predicate { return query.EmptyPredicate{} },
 		"output":             func() query.Predicate { return query.EmptyPredicate{} },
+		"output.regexp":      func() query.Predicate { return query.EmptyPredicate{} },
+		"count":              func() query.Predicate { return query.EmptyPredicate{} },
 	},
 }
 
@@ -123,6 +168,9 @@ func parseReplace(pattern *query.Pattern) (Command, bool, error) {
 		return nil, false, nil
 	}
 	name, args := query.ParseAsPredicate(value)
+	if name != "replace" && name != "replace.regexp" && name != "replace.structural" {
+		return nil, false, nil
+	}
 	parts := arrowSyntax.Split(args, 2)
 	if len(parts) != 2 {
 		return nil, false, errors.New("invalid replace statement, no left and right hand sides of `->`")
@@ -132,7 +180,7 @@ func parseReplace(pattern *query.Pattern) (Command, bool, error) {
 	switch name {
 	case "replace", "replace.regexp":
 		var err error
-		matchPattern, err = toRegexpPattern(parts[0])
+		matchPattern, err = parseMatchPattern(parts[0], toRegexpPattern)
 		if err != nil {
 			return nil, false, errors.Wrap(err, "replace command")
 		}
@@ -145,7 +193,61 @@ func parseReplace(pattern *query.Pattern) (Command, bool, error) {
 }
 
 func parseOutput(pattern *query.Pattern) (Command, bool, error) {
-	return nil, false, nil
+	if !pattern.Annotation.Labels.IsSet(query.IsAlias) {
+		// pattern is not set via `content:`, so it cannot be an output command.
+		return nil, false, nil
+	}
+	value, _, ok := query.ScanPredicate("content", []byte(pattern.Value), ComputePredicateRegistry)
+	if !ok {
+		return nil, false, nil
+	}
+	name, args := query.ParseAsPredicate(value)
+	if name != "output" && name != "output.regexp" {
+		return nil, false, nil
+	}
+	parts := arrowSyntax.Split(args, 2)
+	if len(parts) != 2 {
+		return nil, false, errors.New("invalid output statement, no left and right hand sides of `->`")
+	}
+
+	matchPattern, err := parseMatchPattern(parts[0], toRegexpPattern)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "output command")
+	}
+	return &Output{MatchPattern: matchPattern, OutputPattern: parts[1], Separator: "\n"}, true, nil
+}
+
+var groupBySyntax = lazyregexp.New(`^by:\s*`)
+
+func parseCount(pattern *query.Pattern) (Command, bool, error) {
+	if !pattern.Annotation.Labels.IsSet(query.IsAlias) {
+		// pattern is not set via `content:`, so it cannot be a count command.
+		return nil, false, nil
+	}
+	value, _, ok := query.ScanPredicate("content", []byte(pattern.Value), ComputePredicateRegistry)
+	if !ok {
+		return nil, false, nil
+	}
+	name, args := query.ParseAsPredicate(value)
+	if name != "count" {
+		return nil, false, nil
+	}
+
+	// The group-by template is optional, as in count(a(b) -> by: $1).
+	var groupBy string
+	parts := arrowSyntax.Split(args, 2)
+	if len(parts) == 2 {
+		groupBy = groupBySyntax.ReplaceAllString(parts[1], "")
+	}
+
+	matchPattern, err := parseMatchPattern(parts[0], toRegexpPattern)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "count command")
+	}
+	if _, ok := matchPattern.(*Operator); ok && groupBy == "" {
+		return nil, false, errors.New("count command with 'and' or 'or' expressions expects a group-by template, as in count(a(b) and c(d) -> by: $1.1 $2.1)")
+	}
+	return &Count{MatchPattern: matchPattern, GroupBy: groupBy}, true, nil
 }
 
 func parseMatchOnly(pattern *query.Pattern) (Command, bool, error) {
@@ -177,31 +279,74 @@ func first(parsers ...commandParser) commandParser {
 var parseCommand = first(
 	parseReplace,
 	parseOutput,
+	parseCount,
 	parseMatchOnly,
 )
 
-func toComputeQuery(plan query.Plan) (*Query, error) {
-	if len(plan) != 1 {
-		return nil, errors.New("compute endpoint only supports one search pattern currently ('and' or 'or' operators are not supported yet)")
+// isCommand reports whether pattern is a command predicate such as
+// content:replace(...), rather than a pattern to match. A command containing
+// 'and' or 'or' which is not quoted is split by the query parser, leaving only
+// the name of the command in pattern.
+func isCommand(pattern query.Pattern) bool {
+	if !pattern.Annotation.Labels.IsSet(query.IsAlias) {
+		return false
 	}
-	pattern, err := extractPattern(plan[0])
-	if err != nil {
-		return nil, err
+	if _, ok := ComputePredicateRegistry[query.FieldContent][pattern.Value]; ok {
+		return true
 	}
-	command, _, err := parseCommand(pattern)
+	_, _, ok := query.ScanPredicate("content", []byte(pattern.Value), ComputePredicateRegistry)
+	return ok
+}
+
+func toComputeQuery(basic query.Basic) (*Query, error) {
+	node, err := extractPattern(basic)
 	if err != nil {
 		return nil, err
 	}
+
+	var command Command
+	if pattern, ok := node.(query.Pattern); ok {
+		command, _, err = parseCommand(&pattern)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// An 'and' or 'or' expression of patterns to match.
+		var commandErr error
+		query.VisitPattern([]query.Node{node}, func(value string, negated bool, annotation query.Annotation) {
+			if isCommand(query.Pattern{Value: value, Annotation: annotation}) {
+				commandErr = errors.New("compute commands like content:replace(...) cannot be combined with other patterns, quote the command to use 'and' or 'or' inside it, as in content:'output((a) or (b) -> $1.1 $2.1)'")
+			}
+		})
+		if commandErr != nil {
+			return nil, commandErr
+		}
+		matchPattern, err := toMatchPattern(node, toRegexpLeaf)
+		if err != nil {
+			return nil, err
+		}
+		command = &MatchOnly{MatchPattern: matchPattern}
+	}
+
 	return &Query{
-		Parameters: plan[0].Parameters,
+		Parameters: basic.Parameters,
 		Command:    command,
 	}, nil
 }
 
 func Parse(q string) (*Query, error) {
-	plan, err := query.Pipeline(query.Init(q, query.SearchTypeRegex))
+	nodes, err := query.Run(query.Init(q, query.SearchTypeRegex))
 	if err != nil {
 		return nil, err
 	}
-	return toComputeQuery(plan)
+	if err := query.Validate(query.Dnf(nodes)); err != nil {
+		return nil, err
+	}
+	// Unlike search, compute evaluates 'and' and 'or' expressions of patterns
+	// itself, so the query is not split into one basic query per disjunct.
+	basic, err := query.ToBasicQuery(nodes)
+	if err != nil {
+		return nil, errors.New("compute endpoint only supports 'and' and 'or' operators between search patterns, not between parameters like repo:")
+	}
+	return toComputeQuery(query.ConcatRevFilters(basic))
 }
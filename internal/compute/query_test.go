@@ -27,10 +27,42 @@ func TestParse(t *testing.T) {
 		"compute endpoint expects nonempty pattern").
 		Equal(t, test("repo:cool"))
 
-	autogold.Want("unsupported operators",
-		"compute endpoint only supports one search pattern currently ('and' or 'or' operators are not supported yet)").
+	autogold.Want("or operator",
+		"Command: `Match only: (a or b)`").
 		Equal(t, test("a or b"))
 
+	autogold.Want("nested operators",
+		"Command: `Match only: ((a or b) and c)`").
+		Equal(t, test("(a or b) and c"))
+
+	autogold.Want("operators between parameters",
+		"compute endpoint only supports 'and' and 'or' operators between search patterns, not between parameters like repo:").
+		Equal(t, test("(repo:a x) or (repo:b y)"))
+
+	autogold.Want("command combined with pattern",
+		"compute commands like content:replace(...) cannot be combined with other patterns, quote the command to use 'and' or 'or' inside it, as in content:'output((a) or (b) -> $1.1 $2.1)'").
+		Equal(t, test("content:replace(a -> b) or c"))
+
+	autogold.Want("unquoted command with operators",
+		"compute commands like content:replace(...) cannot be combined with other patterns, quote the command to use 'and' or 'or' inside it, as in content:'output((a) or (b) -> $1.1 $2.1)'").
+		Equal(t, test("content:output(a or b -> $1)"))
+
+	autogold.Want("output",
+		"Command: `Output with separator: (a(\\w+)) -> ($1) separator: \n`").
+		Equal(t, test("content:output(a(\\w+) -> $1)"))
+
+	autogold.Want("output with operators",
+		"Command: `Output with separator: (((a) and (b))) -> ($1.1 $2.1) separator: \n`").
+		Equal(t, test("content:'output((a) and (b) -> $1.1 $2.1)'"))
+
+	autogold.Want("count",
+		"Command: `Count: ((\\w+)@(\\S+)) by: ($1)`").
+		Equal(t, test("content:count((\\w+)@(\\S+) -> by: $1)"))
+
+	autogold.Want("replace with operators",
+		"Command: `Replace in place: ((a or b)) -> (c)`").
+		Equal(t, test("content:'replace(a or b -> c)'"))
+
 	autogold.Want("replace",
 		"Command: `Replace in place: (sourcegraph) -> (smorgasboard)`").
 		Equal(t, test("content:replace(sourcegraph -> smorgasboard)"))
@@ -65,4 +97,26 @@ func TestToSearchQuery(t *testing.T) {
 	autogold.Want("convert replace-in-place to search query",
 		"repo:foo file:bar colarado").
 		Equal(t, test("content:replace(colarado -> colorodo) repo:foo file:bar"))
+
+	autogold.Want("convert or expression to search query",
+		"repo:foo (a or b)").
+		Equal(t, test("repo:foo a or b"))
+
+	autogold.Want("convert output with operators to search query",
+		"repo:foo (import and func)").
+		Equal(t, test("content:'output(import and func -> $1)' repo:foo"))
+}
+
+func TestParseMatchPattern(t *testing.T) {
+	test := func(input string) string {
+		p, err := parseMatchPattern(input, toRegexpPattern)
+		if err != nil {
+			return err.Error()
+		}
+		return p.String()
+	}
+
+	autogold.Want("verbatim pattern", "(foo bar)").Equal(t, test("(foo bar)"))
+	autogold.Want("verbatim operands", `(import "(\w+)" or (func (\w+)))`).Equal(t, test(`import "(\w+)" or (func (\w+))`))
+	autogold.Want("nested operands", "((a b or c) and d)").Equal(t, test("(a b or c) and d"))
 }
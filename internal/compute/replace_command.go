@@ -3,6 +3,9 @@ package compute
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/sourcegraph/sourcegraph/internal/comby"
@@ -24,6 +27,8 @@ func replace(ctx context.Context, content []byte, matchPattern MatchPattern, rep
 	switch match := matchPattern.(type) {
 	case *Regexp:
 		newContent = match.Value.ReplaceAllString(string(content), replacePattern)
+	case *Operator:
+		newContent = replaceOperator(string(content), match, replacePattern)
 	case *Comby:
 		replacements, err := comby.Replacements(ctx, comby.Args{
 			Input:           comby.FileContent(content),
@@ -44,10 +49,67 @@ func replace(ctx context.Context, content []byte, matchPattern MatchPattern, rep
 	return &Text{Value: newContent, Kind: "replace-in-place"}, nil
 }
 
-func (c *Replace) Run(ctx context.Context, fm *result.FileMatch) (Result, error) {
-	content, err := git.ReadFile(ctx, fm.Repo.Name, fm.CommitID, fm.Path, 0)
-	if err != nil {
-		return nil, err
+// leafMatch is the match of the i-th regexp in an 'and' or 'or' expression.
+type leafMatch struct {
+	leaf    int
+	regexp  *Regexp
+	indices []int
+}
+
+// replaceOperator replaces the matches of the regexps in p in content with
+// replacePattern, if the matches satisfy p. All regexps are matched against
+// the original content, and where matches overlap the one which starts first,
+// or the one of the earlier regexp in the expression, is replaced. As for the
+// output and count commands, the unnamed capture groups of the i-th regexp
+// are written $i.1, $i.2, and so on in replacePattern.
+func replaceOperator(content string, p *Operator, replacePattern string) string {
+	lines := []line{{value: content}}
+	if !satisfied(p, leafMatches(lines, p)) {
+		return content
+	}
+
+	var matches []leafMatch
+	for i, r := range p.leaves() {
+		for _, indices := range r.Value.FindAllStringSubmatchIndex(content, -1) {
+			matches = append(matches, leafMatch{leaf: i, regexp: r, indices: indices})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].indices[0] != matches[j].indices[0] {
+			return matches[i].indices[0] < matches[j].indices[0]
+		}
+		return matches[i].leaf < matches[j].leaf
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m.indices[0], m.indices[1]
+		if start < last {
+			// Overlaps a match which was already replaced.
+			continue
+		}
+		prefix := strconv.Itoa(m.leaf+1) + "."
+		env := fromRegexpMatches([][]int{m.indices}, m.regexp.Value.SubexpNames(), content, 0, prefix).Environment
+		b.WriteString(content[last:start])
+		b.WriteString(substitute(replacePattern, env))
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+func (c *Replace) Run(ctx context.Context, r result.Match) (Result, error) {
+	switch m := r.(type) {
+	case *result.FileMatch:
+		content, err := git.ReadFile(ctx, m.Repo.Name, m.CommitID, m.Path, 0)
+		if err != nil {
+			return nil, err
+		}
+		return replace(ctx, content, c.MatchPattern, c.ReplacePattern)
+	case *result.CommitMatch:
+		return replace(ctx, []byte(commitContent(m)), c.MatchPattern, c.ReplacePattern)
+	default:
+		return nil, errors.Errorf("compute commands do not support %T results", r)
 	}
-	return replace(ctx, content, c.MatchPattern, c.ReplacePattern)
 }
@@ -27,6 +27,39 @@ func Test_replace(t *testing.T) {
 			ReplacePattern: "a bit more $1",
 		}))
 
+	autogold.Want(
+		"regexp and search replace",
+		"<foo> <bar> <foo>").
+		Equal(t, test("foo bar foo", &Replace{
+			MatchPattern: &Operator{Kind: And, Operands: []MatchPattern{
+				&Regexp{Value: regexp.MustCompile(`(foo)`)},
+				&Regexp{Value: regexp.MustCompile(`(bar)`)},
+			}},
+			ReplacePattern: "<$1.1$2.1>",
+		}))
+
+	autogold.Want(
+		"regexp and search replace not satisfied",
+		"foo bar").
+		Equal(t, test("foo bar", &Replace{
+			MatchPattern: &Operator{Kind: And, Operands: []MatchPattern{
+				&Regexp{Value: regexp.MustCompile(`foo`)},
+				&Regexp{Value: regexp.MustCompile(`baz`)},
+			}},
+			ReplacePattern: "qux",
+		}))
+
+	autogold.Want(
+		"regexp or search replace against original content",
+		"b bb").
+		Equal(t, test("a b", &Replace{
+			MatchPattern: &Operator{Kind: Or, Operands: []MatchPattern{
+				&Regexp{Value: regexp.MustCompile(`(a)`)},
+				&Regexp{Value: regexp.MustCompile(`(b)`)},
+			}},
+			ReplacePattern: "b$2.1",
+		}))
+
 	// If we are not on CI skip the test if comby is not installed.
 	if os.Getenv("CI") == "" && !comby.Exists() {
 		t.Skip("comby is not installed on the PATH. Try running 'bash <(curl -sL get.comby.dev)'.")
@@ -294,6 +294,10 @@ func APIDocsSearchIndexingEnabled() bool {
 	return val == "enabled"
 }
 
+func GlobalSymbolIndexEnabled() bool {
+	return ExperimentalFeatures().SymbolsGlobalIndex == "enabled"
+}
+
 func StructuralSearchEnabled() bool {
 	val := ExperimentalFeatures().StructuralSearch
 	if val == "" {
@@ -198,6 +198,11 @@ SELECT
        last_error,
        last_fetched,
        last_changed,
+       last_integrity_check_at,
+       last_integrity_check_error,
+       corrupted_at,
+       corruption_count,
+       evicted_at,
        updated_at
 FROM gitserver_repos
 WHERE repo_id = %s
@@ -217,6 +222,11 @@ WHERE repo_id = %s
 		&dbutil.NullString{S: &gr.LastError},
 		&dbutil.NullTime{Time: &gr.LastFetched},
 		&dbutil.NullTime{Time: &gr.LastChanged},
+		&dbutil.NullTime{Time: &gr.LastIntegrityCheckAt},
+		&dbutil.NullString{S: &gr.LastIntegrityCheckError},
+		&dbutil.NullTime{Time: &gr.CorruptedAt},
+		&gr.CorruptionCount,
+		&dbutil.NullTime{Time: &gr.EvictedAt},
 		&gr.UpdatedAt,
 	)
 	if err != nil {
@@ -294,6 +304,71 @@ SET (last_fetched, last_changed, shard_id, updated_at) =
 	return errors.Wrap(err, "setting last fetched")
 }
 
+// GitserverIntegrityCheckData is the outcome of an integrity check of a
+// repository on gitserver.
+type GitserverIntegrityCheckData struct {
+	// CheckedAt was the time the check completed (gitserver_repos.last_integrity_check_at).
+	CheckedAt time.Time
+	// Error is the output of the check if it found the repository to be corrupt,
+	// or empty if the repository is intact (gitserver_repos.last_integrity_check_error).
+	Error string
+	// ShardID is the name of the gitserver the check ran on (gitserver.shard_id).
+	ShardID string
+}
+
+// SetLastIntegrityCheck will attempt to update ONLY the integrity check data of
+// a GitServerRepo. A failed check also updates the time the repository was last
+// found to be corrupt and increments its corruption count. If a matching row
+// does not yet exist a new one will be created.
+func (s *GitserverRepoStore) SetLastIntegrityCheck(ctx context.Context, name api.RepoName, data GitserverIntegrityCheckData) error {
+	var (
+		corruptedAt     *time.Time
+		corruptionCount int
+	)
+	if data.Error != "" {
+		corruptedAt = &data.CheckedAt
+		corruptionCount = 1
+	}
+
+	err := s.Exec(ctx, sqlf.Sprintf(`
+-- source: internal/database/gitserver_repos.go:GitserverRepoStore.SetLastIntegrityCheck
+INSERT INTO gitserver_repos(repo_id, last_integrity_check_at, last_integrity_check_error, corrupted_at, corruption_count, shard_id, updated_at)
+SELECT id, %s, %s, %s, %s, %s, now()
+FROM repo WHERE name = %s
+ON CONFLICT (repo_id) DO UPDATE
+SET (last_integrity_check_at, last_integrity_check_error, corrupted_at, corruption_count, shard_id, updated_at) =
+    (EXCLUDED.last_integrity_check_at, EXCLUDED.last_integrity_check_error,
+     COALESCE(EXCLUDED.corrupted_at, gitserver_repos.corrupted_at),
+     gitserver_repos.corruption_count + EXCLUDED.corruption_count,
+     EXCLUDED.shard_id, now())
+`, data.CheckedAt, dbutil.NewNullString(sanitizeToUTF8(data.Error)), corruptedAt, corruptionCount, data.ShardID, name))
+
+	return errors.Wrap(err, "setting last integrity check")
+}
+
+// SetEvictedAt will attempt to update ONLY the time a GitServerRepo was
+// evicted from gitserver to free up disk space. A zero evictedAt clears it. If
+// a matching row does not yet exist a new one will be created.
+func (s *GitserverRepoStore) SetEvictedAt(ctx context.Context, name api.RepoName, evictedAt time.Time, shardID string) error {
+	var at *time.Time
+	if !evictedAt.IsZero() {
+		at = &evictedAt
+	}
+
+	err := s.Exec(ctx, sqlf.Sprintf(`
+-- source: internal/database/gitserver_repos.go:GitserverRepoStore.SetEvictedAt
+INSERT INTO gitserver_repos(repo_id, evicted_at, shard_id, updated_at)
+SELECT id, %s, %s, now()
+FROM repo WHERE name = %s
+ON CONFLICT (repo_id) DO UPDATE
+SET (evicted_at, shard_id, updated_at) =
+    (EXCLUDED.evicted_at, EXCLUDED.shard_id, now())
+WHERE gitserver_repos.evicted_at IS DISTINCT FROM EXCLUDED.evicted_at
+`, at, shardID, name))
+
+	return errors.Wrap(err, "setting evicted at")
+}
+
 // sanitizeToUTF8 will remove any null character terminated string. The null character can be
 // represented in one of the following ways in Go:
 //
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -342,6 +343,137 @@ func TestSetLastError(t *testing.T) {
 	}
 }
 
+func TestSetLastIntegrityCheck(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	db := dbtest.NewDB(t)
+	ctx := context.Background()
+	const shardID = "test"
+
+	repo1 := &types.Repo{
+		Name:         "github.com/sourcegraph/repo1",
+		URI:          "github.com/sourcegraph/repo1",
+		ExternalRepo: api.ExternalRepoSpec{},
+	}
+
+	// Create one test repo
+	err := Repos(db).Create(ctx, repo1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitserverRepo := &types.GitserverRepo{
+		RepoID:      repo1.ID,
+		ShardID:     shardID,
+		CloneStatus: types.CloneStatusCloned,
+	}
+
+	// Create GitServerRepo
+	if err := GitserverRepos(db).Upsert(ctx, gitserverRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(checkedAt time.Time, error string) {
+		t.Helper()
+		err := GitserverRepos(db).SetLastIntegrityCheck(ctx, repo1.Name, GitserverIntegrityCheckData{
+			CheckedAt: checkedAt,
+			Error:     error,
+			ShardID:   shardID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fromDB, err := GitserverRepos(db).GetByID(ctx, gitserverRepo.RepoID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(gitserverRepo, fromDB, cmpopts.IgnoreFields(types.GitserverRepo{}, "UpdatedAt")); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+
+	// A failed check records the corruption.
+	corrupted := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	gitserverRepo.LastIntegrityCheckAt = corrupted
+	gitserverRepo.LastIntegrityCheckError = "missing blob"
+	gitserverRepo.CorruptedAt = corrupted
+	gitserverRepo.CorruptionCount = 1
+	check(corrupted, "missing blob")
+
+	// A passed check clears the error, but keeps the corruption history.
+	repaired := corrupted.Add(time.Hour)
+	gitserverRepo.LastIntegrityCheckAt = repaired
+	gitserverRepo.LastIntegrityCheckError = ""
+	check(repaired, "")
+
+	// Another failed check increments the corruption count.
+	corruptedAgain := repaired.Add(time.Hour)
+	gitserverRepo.LastIntegrityCheckAt = corruptedAgain
+	gitserverRepo.LastIntegrityCheckError = "broken link"
+	gitserverRepo.CorruptedAt = corruptedAgain
+	gitserverRepo.CorruptionCount = 2
+	check(corruptedAgain, "broken link")
+}
+
+func TestSetEvictedAt(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	db := dbtest.NewDB(t)
+	ctx := context.Background()
+	const shardID = "test"
+
+	repo1 := &types.Repo{
+		Name:         "github.com/sourcegraph/repo1",
+		URI:          "github.com/sourcegraph/repo1",
+		ExternalRepo: api.ExternalRepoSpec{},
+	}
+
+	// Create one test repo
+	err := Repos(db).Create(ctx, repo1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitserverRepo := &types.GitserverRepo{
+		RepoID:      repo1.ID,
+		ShardID:     shardID,
+		CloneStatus: types.CloneStatusNotCloned,
+	}
+
+	// Create GitServerRepo
+	if err := GitserverRepos(db).Upsert(ctx, gitserverRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(evictedAt time.Time) {
+		t.Helper()
+		if err := GitserverRepos(db).SetEvictedAt(ctx, repo1.Name, evictedAt, shardID); err != nil {
+			t.Fatal(err)
+		}
+
+		fromDB, err := GitserverRepos(db).GetByID(ctx, gitserverRepo.RepoID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(gitserverRepo, fromDB, cmpopts.IgnoreFields(types.GitserverRepo{}, "UpdatedAt")); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+
+	evicted := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	gitserverRepo.EvictedAt = evicted
+	check(evicted)
+
+	// Evicted repos which are requested again are no longer evicted.
+	gitserverRepo.EvictedAt = time.Time{}
+	check(time.Time{})
+}
+
 func TestGitserverRepoUpsertNullShard(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -0,0 +1,292 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/batch"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func GlobalSymbols(db dbutil.DB) GlobalSymbolsStore {
+	store := basestore.NewWithDB(db, sql.TxOptions{})
+	return &globalSymbolsStore{store}
+}
+
+// GlobalSymbolsStore stores the symbols on the default branch of every
+// repository, so that symbol searches across the whole instance can be
+// answered without querying the symbols service for each repository.
+type GlobalSymbolsStore interface {
+	basestore.ShareableStore
+
+	// ListStale returns up to limit cloned repositories whose default branch
+	// changed since their symbols were last indexed, or which were never
+	// indexed. Repositories which were never indexed come first.
+	ListStale(ctx context.Context, limit int) ([]StaleGlobalSymbolsRepo, error)
+
+	// Replace replaces the symbols of the given repository with the symbols of
+	// commitID. If truncated is set, the repository has more symbols than the
+	// indexer stores, and searches must not rely on the index for it.
+	Replace(ctx context.Context, repoID api.RepoID, commitID api.CommitID, symbols []result.Symbol, truncated bool) error
+
+	// MarkIndexed records that the symbols of the given repository are up to
+	// date without changing them.
+	MarkIndexed(ctx context.Context, repoID api.RepoID) error
+
+	// IndexedCommits returns the indexed commit of each of the given
+	// repositories whose symbols are all stored in the index. Truncated
+	// repositories are left out.
+	IndexedCommits(ctx context.Context, repoIDs []api.RepoID) (map[api.RepoID]api.CommitID, error)
+
+	// Search returns the indexed symbols matching opts.
+	Search(ctx context.Context, opts GlobalSymbolsSearchOptions) ([]GlobalSymbol, error)
+}
+
+// StaleGlobalSymbolsRepo is a repository whose symbols need to be indexed.
+type StaleGlobalSymbolsRepo struct {
+	Repo types.RepoName
+
+	// CommitID is the currently indexed commit, or empty if the repository
+	// was never indexed.
+	CommitID api.CommitID
+}
+
+// GlobalSymbol is a symbol in the global symbols index.
+type GlobalSymbol struct {
+	Repo     types.RepoName
+	CommitID api.CommitID
+	result.Symbol
+}
+
+// GlobalSymbolsSearchOptions restricts the symbols returned by
+// GlobalSymbolsStore.Search. Patterns use the regular expression syntax of
+// Postgres (AREs), which differs from the syntax of Go in the details, so Go
+// patterns must be translated by the caller.
+type GlobalSymbolsSearchOptions struct {
+	// RepoIDs is the set of repositories to search.
+	RepoIDs []api.RepoID
+
+	// Pattern matches symbol names.
+	Pattern string
+
+	// IsCaseSensitive applies to Pattern, IncludePatterns and ExcludePattern.
+	IsCaseSensitive bool
+
+	// IncludePatterns are patterns that all paths must match.
+	IncludePatterns []string
+
+	// ExcludePattern is a pattern that paths must not match.
+	ExcludePattern string
+
+	// Kinds, if non-empty, restricts the symbol kinds. It is matched case
+	// insensitively.
+	Kinds []string
+
+	// Container, if non-empty, is a pattern that the parent of symbols must
+	// match.
+	Container string
+
+	Limit int
+}
+
+type globalSymbolsStore struct {
+	*basestore.Store
+}
+
+func (s *globalSymbolsStore) ListStale(ctx context.Context, limit int) ([]StaleGlobalSymbolsRepo, error) {
+	rows, err := s.Query(ctx, sqlf.Sprintf(listStaleGlobalSymbolsReposQuery, limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repos []StaleGlobalSymbolsRepo
+	for rows.Next() {
+		var r StaleGlobalSymbolsRepo
+		if err := rows.Scan(&r.Repo.ID, &r.Repo.Name, &r.CommitID); err != nil {
+			return nil, err
+		}
+		repos = append(repos, r)
+	}
+	return repos, rows.Err()
+}
+
+const listStaleGlobalSymbolsReposQuery = `
+-- source: internal/database/global_symbols.go:ListStale
+SELECT repo.id, repo.name, COALESCE(gsr.commit_id, '')
+FROM repo
+JOIN gitserver_repos gr ON gr.repo_id = repo.id
+LEFT JOIN global_symbols_repos gsr ON gsr.repo_id = repo.id
+WHERE
+	repo.deleted_at IS NULL AND
+	gr.clone_status = 'cloned' AND
+	(gsr.repo_id IS NULL OR gsr.indexed_at < gr.last_changed)
+ORDER BY gsr.indexed_at NULLS FIRST, repo.id
+LIMIT %s
+`
+
+func (s *globalSymbolsStore) Replace(ctx context.Context, repoID api.RepoID, commitID api.CommitID, symbols []result.Symbol, truncated bool) (err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = tx.Done(err) }()
+
+	if err := tx.Exec(ctx, sqlf.Sprintf(upsertGlobalSymbolsRepoQuery, repoID, commitID, time.Now(), truncated)); err != nil {
+		return err
+	}
+	if err := tx.Exec(ctx, sqlf.Sprintf(`DELETE FROM global_symbols WHERE repo_id = %s`, repoID)); err != nil {
+		return err
+	}
+
+	rowValues := make(chan []interface{}, len(symbols))
+	for _, symbol := range symbols {
+		rowValues <- []interface{}{
+			repoID,
+			symbol.Path,
+			symbol.Name,
+			symbol.Kind,
+			symbol.Language,
+			symbol.Parent,
+			symbol.Line,
+		}
+	}
+	close(rowValues)
+
+	return batch.InsertValues(
+		ctx,
+		tx.Handle().DB(),
+		"global_symbols",
+		[]string{"repo_id", "path", "name", "kind", "language", "parent", "line"},
+		rowValues,
+	)
+}
+
+const upsertGlobalSymbolsRepoQuery = `
+-- source: internal/database/global_symbols.go:Replace
+INSERT INTO global_symbols_repos (repo_id, commit_id, indexed_at, truncated)
+VALUES (%s, %s, %s, %s)
+ON CONFLICT (repo_id) DO UPDATE SET commit_id = EXCLUDED.commit_id, indexed_at = EXCLUDED.indexed_at, truncated = EXCLUDED.truncated
+`
+
+func (s *globalSymbolsStore) MarkIndexed(ctx context.Context, repoID api.RepoID) error {
+	return s.Exec(ctx, sqlf.Sprintf(`UPDATE global_symbols_repos SET indexed_at = %s WHERE repo_id = %s`, time.Now(), repoID))
+}
+
+func (s *globalSymbolsStore) IndexedCommits(ctx context.Context, repoIDs []api.RepoID) (map[api.RepoID]api.CommitID, error) {
+	if mock := Mocks.GlobalSymbols.IndexedCommits; mock != nil {
+		return mock(ctx, repoIDs)
+	}
+
+	rows, err := s.Query(ctx, sqlf.Sprintf(
+		`SELECT repo_id, commit_id FROM global_symbols_repos WHERE repo_id = ANY(%s) AND NOT truncated`,
+		pq.Array(repoIDs),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commits := make(map[api.RepoID]api.CommitID, len(repoIDs))
+	for rows.Next() {
+		var (
+			repoID   api.RepoID
+			commitID api.CommitID
+		)
+		if err := rows.Scan(&repoID, &commitID); err != nil {
+			return nil, err
+		}
+		commits[repoID] = commitID
+	}
+	return commits, rows.Err()
+}
+
+func (s *globalSymbolsStore) Search(ctx context.Context, opts GlobalSymbolsSearchOptions) ([]GlobalSymbol, error) {
+	if mock := Mocks.GlobalSymbols.Search; mock != nil {
+		return mock(ctx, opts)
+	}
+
+	conds := []*sqlf.Query{sqlf.Sprintf("gs.repo_id = ANY(%s)", pq.Array(opts.RepoIDs))}
+	if opts.Pattern != "" {
+		if opts.IsCaseSensitive {
+			conds = append(conds, sqlf.Sprintf("gs.name ~ %s", opts.Pattern))
+		} else {
+			// Matches the expression of global_symbols_name_trgm.
+			conds = append(conds, sqlf.Sprintf("lower(gs.name) ~* %s", opts.Pattern))
+		}
+	}
+	matchPath, notMatchPath := "gs.path ~* %s", "gs.path !~* %s"
+	if opts.IsCaseSensitive {
+		matchPath, notMatchPath = "gs.path ~ %s", "gs.path !~ %s"
+	}
+	for _, p := range opts.IncludePatterns {
+		conds = append(conds, sqlf.Sprintf(matchPath, p))
+	}
+	if opts.ExcludePattern != "" {
+		conds = append(conds, sqlf.Sprintf(notMatchPath, opts.ExcludePattern))
+	}
+	if len(opts.Kinds) > 0 {
+		conds = append(conds, sqlf.Sprintf("lower(gs.kind) = ANY(%s)", pq.Array(lowerAll(opts.Kinds))))
+	}
+	if opts.Container != "" {
+		if opts.IsCaseSensitive {
+			conds = append(conds, sqlf.Sprintf("gs.parent ~ %s", opts.Container))
+		} else {
+			conds = append(conds, sqlf.Sprintf("gs.parent ~* %s", opts.Container))
+		}
+	}
+
+	rows, err := s.Query(ctx, sqlf.Sprintf(searchGlobalSymbolsQuery, sqlf.Join(conds, "AND"), opts.Limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var symbols []GlobalSymbol
+	for rows.Next() {
+		var s GlobalSymbol
+		if err := rows.Scan(
+			&s.Repo.ID,
+			&s.Repo.Name,
+			&s.CommitID,
+			&s.Path,
+			&s.Name,
+			&s.Kind,
+			&s.Language,
+			&s.Parent,
+			&s.Line,
+		); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, s)
+	}
+	return symbols, rows.Err()
+}
+
+const searchGlobalSymbolsQuery = `
+-- source: internal/database/global_symbols.go:Search
+SELECT repo.id, repo.name, gsr.commit_id, gs.path, gs.name, gs.kind, gs.language, gs.parent, gs.line
+FROM global_symbols gs
+JOIN global_symbols_repos gsr ON gsr.repo_id = gs.repo_id
+JOIN repo ON repo.id = gs.repo_id
+WHERE %s
+ORDER BY repo.name, gs.path, gs.line
+LIMIT %s
+`
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, 0, len(values))
+	for _, v := range values {
+		lowered = append(lowered, strings.ToLower(v))
+	}
+	return lowered
+}
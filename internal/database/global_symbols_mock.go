@@ -0,0 +1,12 @@
+package database
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+type MockGlobalSymbols struct {
+	IndexedCommits func(ctx context.Context, repoIDs []api.RepoID) (map[api.RepoID]api.CommitID, error)
+	Search         func(ctx context.Context, opts GlobalSymbolsSearchOptions) ([]GlobalSymbol, error)
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestGlobalSymbols(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	db := dbtest.NewDB(t)
+	ctx := context.Background()
+
+	repo := mustCreateGitserverRepo(ctx, t, db, &types.Repo{Name: "a"}, types.GitserverRepo{
+		CloneStatus: types.CloneStatusCloned,
+	})[0]
+	mustCreate(ctx, t, db, &types.Repo{Name: "not-cloned"})
+
+	store := GlobalSymbols(db)
+	want := []StaleGlobalSymbolsRepo{{Repo: types.RepoName{ID: repo.ID, Name: "a"}}}
+	if stale, err := store.ListStale(ctx, 10); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(want, stale); diff != "" {
+		t.Fatalf("unexpected stale repos (-want +got):\n%s", diff)
+	}
+
+	symbols := []result.Symbol{
+		{Name: "Server", Kind: "struct", Language: "Go", Path: "server.go", Line: 3},
+		{Name: "Start", Kind: "func", Language: "Go", Parent: "Server", Path: "server.go", Line: 7},
+		{Name: "server", Kind: "function", Language: "TypeScript", Path: "web/server.ts", Line: 1},
+	}
+	if err := store.Replace(ctx, repo.ID, "c1", symbols, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if stale, err := store.ListStale(ctx, 10); err != nil {
+		t.Fatal(err)
+	} else if len(stale) != 0 {
+		t.Fatalf("expected no stale repos, got %v", stale)
+	}
+
+	// A fetch which changes the repository makes it stale again.
+	if err := GitserverRepos(db).SetLastFetched(ctx, "a", GitserverFetchData{
+		LastFetched: time.Now().Add(time.Hour),
+		LastChanged: time.Now().Add(time.Hour),
+		ShardID:     "test",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want[0].CommitID = "c1"
+	if stale, err := store.ListStale(ctx, 10); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(want, stale); diff != "" {
+		t.Fatalf("unexpected stale repos (-want +got):\n%s", diff)
+	}
+
+	if commits, err := store.IndexedCommits(ctx, []api.RepoID{repo.ID, repo.ID + 1}); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(map[api.RepoID]api.CommitID{repo.ID: "c1"}, commits); diff != "" {
+		t.Fatalf("unexpected indexed commits (-want +got):\n%s", diff)
+	}
+
+	// Truncated repositories are searched with the symbols service instead.
+	truncated := mustCreateGitserverRepo(ctx, t, db, &types.Repo{Name: "truncated"}, types.GitserverRepo{
+		CloneStatus: types.CloneStatusCloned,
+	})[0]
+	if err := store.Replace(ctx, truncated.ID, "c2", nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if commits, err := store.IndexedCommits(ctx, []api.RepoID{repo.ID, truncated.ID}); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(map[api.RepoID]api.CommitID{repo.ID: "c1"}, commits); diff != "" {
+		t.Fatalf("unexpected indexed commits (-want +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		opts GlobalSymbolsSearchOptions
+		want []string
+	}{
+		{opts: GlobalSymbolsSearchOptions{Pattern: "^server$"}, want: []string{"Server", "server"}},
+		{opts: GlobalSymbolsSearchOptions{Pattern: "^server$", IsCaseSensitive: true}, want: []string{"server"}},
+		{opts: GlobalSymbolsSearchOptions{Pattern: "s", Kinds: []string{"FUNC"}}, want: []string{"Start"}},
+		{opts: GlobalSymbolsSearchOptions{Pattern: "s", IncludePatterns: []string{`\.ts$`}}, want: []string{"server"}},
+		{opts: GlobalSymbolsSearchOptions{Pattern: "s", ExcludePattern: `\.ts$`}, want: []string{"Server", "Start"}},
+		{opts: GlobalSymbolsSearchOptions{Pattern: "s", Limit: 1}, want: []string{"Server"}},
+	} {
+		tc.opts.RepoIDs = []api.RepoID{repo.ID}
+		if tc.opts.Limit == 0 {
+			tc.opts.Limit = 10
+		}
+		got, err := store.Search(ctx, tc.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, s := range got {
+			names = append(names, s.Name)
+		}
+		if diff := cmp.Diff(tc.want, names); diff != "" {
+			t.Errorf("%+v: unexpected symbols (-want +got):\n%s", tc.opts, diff)
+		}
+	}
+}
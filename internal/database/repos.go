@@ -599,6 +599,14 @@ type ReposListOptions struct {
 	// OnlyCloned excludes non-cloned repositories from the list.
 	OnlyCloned bool
 
+	// NoEvicted excludes repositories which gitserver evicted to free up disk
+	// space, and which were not requested since, from the list.
+	NoEvicted bool
+
+	// OnlyEvicted excludes repositories which gitserver did not evict to free
+	// up disk space, or which were requested since, from the list.
+	OnlyEvicted bool
+
 	// NoPrivate excludes private repositories from the list.
 	NoPrivate bool
 
@@ -941,6 +949,12 @@ func (s *repoStore) listSQL(ctx context.Context, opt ReposListOptions) (*sqlf.Qu
 	if opt.OnlyCloned {
 		where = append(where, sqlf.Sprintf("gr.clone_status = 'cloned'"))
 	}
+	if opt.NoEvicted {
+		where = append(where, sqlf.Sprintf("gr.evicted_at IS NULL"))
+	}
+	if opt.OnlyEvicted {
+		where = append(where, sqlf.Sprintf("gr.evicted_at IS NOT NULL"))
+	}
 	if opt.FailedFetch {
 		where = append(where, sqlf.Sprintf("gr.last_error IS NOT NULL"))
 	}
@@ -1010,7 +1024,7 @@ func (s *repoStore) listSQL(ctx context.Context, opt ReposListOptions) (*sqlf.Qu
 		where = append(where, sqlf.Sprintf("dscr.search_context_id = %d", opt.SearchContextID))
 	}
 
-	if opt.NoCloned || opt.OnlyCloned || opt.FailedFetch || !opt.MinLastChanged.IsZero() || opt.joinGitserverRepos {
+	if opt.NoCloned || opt.OnlyCloned || opt.NoEvicted || opt.OnlyEvicted || opt.FailedFetch || !opt.MinLastChanged.IsZero() || opt.joinGitserverRepos {
 		from = append(from, sqlf.Sprintf("LEFT JOIN gitserver_repos gr ON gr.repo_id = repo.id"))
 	}
 
@@ -1073,6 +1087,9 @@ SELECT repo_id as id FROM user_public_repos WHERE user_id = %d
 type ListIndexableReposOptions struct {
 	// If true, will only include uncloned indexable repos
 	OnlyUncloned bool
+	// If true, will exclude repos which gitserver evicted to free up disk space
+	// and which were not requested since
+	NoEvicted bool
 	// If true, we include user added private repos
 	IncludePrivate bool
 
@@ -1097,16 +1114,23 @@ func (s *repoStore) ListIndexableRepos(ctx context.Context, opts ListIndexableRe
 
 	var where, joins []*sqlf.Query
 
-	if opts.OnlyUncloned {
+	if opts.OnlyUncloned || opts.NoEvicted {
 		joins = append(joins, sqlf.Sprintf(
 			"LEFT JOIN gitserver_repos gr ON gr.repo_id = repo.id",
 		))
+	}
+
+	if opts.OnlyUncloned {
 		where = append(where, sqlf.Sprintf(
 			"(gr.clone_status IS NULL OR gr.clone_status = %s)",
 			types.CloneStatusNotCloned,
 		))
 	}
 
+	if opts.NoEvicted {
+		where = append(where, sqlf.Sprintf("gr.evicted_at IS NULL"))
+	}
+
 	if !opts.IncludePrivate {
 		where = append(where, sqlf.Sprintf("NOT repo.private"))
 	}
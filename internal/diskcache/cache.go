@@ -139,6 +139,21 @@ func (s *Store) OpenWithPath(ctx context.Context, key string, fetcher FetcherWit
 	}
 }
 
+// Peek opens the file for key if it is already in the cache. Unlike Open it
+// never fetches a missing item, so it is cheap to use when probing for related
+// keys. ok is false if key is not in the cache.
+func (s *Store) Peek(key string) (file *File, ok bool) {
+	if s.Dir == "" {
+		return nil, false
+	}
+	path := s.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	return &File{File: f, Path: path}, true
+}
+
 // path returns the path for key.
 func (s *Store) path(key string) string {
 	// path uses a sha256 hash of the key since we want to use it for the
@@ -60,3 +60,41 @@ func TestOpen(t *testing.T) {
 		t.Fatal("Item was not properly evicted")
 	}
 }
+
+func TestPeek(t *testing.T) {
+	dir, err := os.MkdirTemp("", "diskcache_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := &Store{
+		Dir:       dir,
+		Component: "test",
+	}
+
+	if _, ok := store.Peek("key"); ok {
+		t.Fatal("expected Peek to miss on empty cache")
+	}
+
+	f, err := store.Open(context.Background(), "key", func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte("foobar"))), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	f, ok := store.Peek("key")
+	if !ok {
+		t.Fatal("expected Peek to hit after Open")
+	}
+	defer f.Close()
+	got, err := io.ReadAll(f.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "foobar" {
+		t.Fatalf("got %q, want %q", string(got), "foobar")
+	}
+}
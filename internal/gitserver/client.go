@@ -155,7 +155,18 @@ func addrForKey(key string, addrs []string) string {
 type ArchiveOptions struct {
 	Treeish string   // the tree or commit to produce an archive for
 	Format  string   // format of the resulting archive (usually "tar" or "zip")
-	Paths   []string // if nonempty, only include these paths
+	Paths   []string // if nonempty, only include these paths (git pathspecs, see LiteralPathspecs)
+}
+
+// LiteralPathspecs returns pathspecs matching exactly the given paths. Plain paths passed as
+// ArchiveOptions.Paths are interpreted as globs, so a path containing *, ? or [ would also match
+// other files.
+func LiteralPathspecs(paths []string) []string {
+	pathspecs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		pathspecs = append(pathspecs, ":(literal)"+path)
+	}
+	return pathspecs
 }
 
 // archiveReader wraps the StdoutReader yielded by gitserver's
@@ -372,6 +383,61 @@ func (c *Client) Search(ctx context.Context, args *protocol.SearchRequest, onMat
 	return eventDone.LimitHit, eventDone.Err()
 }
 
+// StreamBlame streams the blame hunks of a file, calling onHunks with batches
+// of hunks as gitserver produces them. Hunks are not sent in file order.
+// Canceling ctx stops the blame on gitserver.
+func (c *Client) StreamBlame(ctx context.Context, req *protocol.BlameRequest, onHunks func([]protocol.BlameHunk)) (err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "GitserverClient.StreamBlame")
+	span.SetTag("repo", string(req.Repo))
+	span.SetTag("commit", string(req.Commit))
+	span.SetTag("path", req.Path)
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("err", err.Error())
+		}
+		span.Finish()
+	}()
+
+	repoName := protocol.NormalizeRepo(req.Repo)
+	resp, err := c.httpPost(ctx, repoName, "blame", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("unexpected status code: %d - %s", resp.StatusCode, body)
+	}
+
+	var (
+		decodeErr error
+		eventDone protocol.BlameEventDone
+	)
+	dec := StreamBlameDecoder{
+		OnHunks: func(e protocol.BlameEventHunks) {
+			onHunks(e)
+		},
+		OnDone: func(e protocol.BlameEventDone) {
+			eventDone = e
+		},
+		OnUnknown: func(event, _ []byte) {
+			decodeErr = errors.Errorf("unknown event %s", event)
+		},
+	}
+
+	if err := dec.ReadAll(resp.Body); err != nil {
+		return err
+	}
+
+	if decodeErr != nil {
+		return decodeErr
+	}
+
+	return eventDone.Err()
+}
+
 // P4Exec sends a p4 command with given arguments and returns an io.ReadCloser for the output.
 func (c *Client) P4Exec(ctx context.Context, host, user, password string, args ...string) (_ io.ReadCloser, _ http.Header, errRes error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "Client.P4Exec")
@@ -955,6 +1021,37 @@ func (c *Client) doReposStats(ctx context.Context, addr string) (*protocol.Repos
 	return &stats, nil
 }
 
+// RepoStats returns the language breakdown, top contributors and file churn
+// of a repository at a commit (or HEAD if commit is empty). Gitserver caches
+// the statistics of each commit.
+func (c *Client) RepoStats(ctx context.Context, repo api.RepoName, commit api.CommitID) (*protocol.RepoStats, error) {
+	req := &protocol.RepoStatsRequest{
+		Repo:   repo,
+		Commit: commit,
+	}
+	resp, err := c.httpPost(ctx, repo, "repo-stats", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, &gitdomain.RepoNotExistError{Repo: repo}
+	default:
+		// best-effort inclusion of body in error message
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, &url.Error{URL: resp.Request.URL.String(), Op: "RepoStats", Err: errors.Errorf("RepoStats: http status %d: %s", resp.StatusCode, string(body))}
+	}
+
+	var stats protocol.RepoStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 // Remove removes the repository clone from gitserver.
 func (c *Client) Remove(ctx context.Context, repo api.RepoName) error {
 	req := &protocol.RepoDeleteRequest{
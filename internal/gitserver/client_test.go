@@ -100,6 +100,7 @@ func TestClient_Archive(t *testing.T) {
 
 	tests := map[api.RepoName]struct {
 		remote string
+		paths  []string
 		want   map[string]string
 		err    error
 	}{
@@ -115,6 +116,11 @@ func TestClient_Archive(t *testing.T) {
 			remote: createRepoWithDotGitDir(t, root),
 			want:   map[string]string{"file1": "hello\n", ".git/mydir/file2": "milton\n", ".git/mydir/": "", ".git/": ""},
 		},
+		"literal-paths": {
+			remote: createRepoWithGlobFilenames(t, root),
+			paths:  gitserver.LiteralPathspecs([]string{"a[b]", "c*"}),
+			want:   map[string]string{"a[b]": "x", "c*": "z"},
+		},
 		"not-found": {
 			err: errors.New("repository does not exist: not-found"),
 		},
@@ -150,7 +156,7 @@ func TestClient_Archive(t *testing.T) {
 				}
 			}
 
-			rc, err := cli.Archive(ctx, name, gitserver.ArchiveOptions{Treeish: "HEAD", Format: "zip"})
+			rc, err := cli.Archive(ctx, name, gitserver.ArchiveOptions{Treeish: "HEAD", Format: "zip", Paths: test.paths})
 			if have, want := fmt.Sprint(err), fmt.Sprint(test.err); have != want {
 				t.Errorf("archive: have err %v, want %v", have, want)
 			}
@@ -285,6 +291,32 @@ func createSimpleGitRepo(t *testing.T, root string) string {
 	return dir
 }
 
+// createRepoWithGlobFilenames creates a repository containing files whose names are glob
+// patterns matching other files of the repository.
+func createRepoWithGlobFilenames(t *testing.T, root string) string {
+	t.Helper()
+	dir := filepath.Join(root, "remotes", "literal-paths")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cmd := range []string{
+		"git init",
+		"echo -n x > 'a[b]' && echo -n y > ab && echo -n z > 'c*' && echo -n w > cd",
+		"git add -A",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m commit1 --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+	} {
+		c := exec.Command("bash", "-c", cmd)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command %q failed. Output was:\n\n%s", cmd, out)
+		}
+	}
+
+	return dir
+}
+
 func TestAddrForRepo(t *testing.T) {
 	addrs := []string{"gitserver-1", "gitserver-2", "gitserver-3"}
 
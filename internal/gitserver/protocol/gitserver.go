@@ -65,6 +65,59 @@ func NewSearchEventDone(limitHit bool, err error) SearchEventDone {
 	return event
 }
 
+// BlameRequest is a request to stream the blame of a file.
+type BlameRequest struct {
+	Repo   api.RepoName
+	Commit api.CommitID `json:",omitempty"` // or "" for HEAD
+	Path   string
+
+	StartLine int `json:",omitempty"` // 1-indexed start line (or 0 for beginning of file)
+	EndLine   int `json:",omitempty"` // 1-indexed end line (or 0 for end of file)
+}
+
+// BlameHunk is a contiguous portion of a file associated with a commit.
+type BlameHunk struct {
+	StartLine int // 1-indexed start line number
+	EndLine   int // 1-indexed end line number (exclusive)
+	StartByte int // 0-indexed start byte position (inclusive)
+	EndByte   int // 0-indexed end byte position (exclusive)
+	CommitID  api.CommitID
+	Author    Signature
+	Message   string
+	Filename  string
+}
+
+// BlameEventHunks is a batch of hunks streamed by the blame endpoint. Hunks
+// are sent as soon as git produces them, which is not in file order.
+type BlameEventHunks []BlameHunk
+
+type BlameEventDone struct {
+	Error string
+}
+
+func (b BlameEventDone) Err() error {
+	if b.Error != "" {
+		var e gitdomain.RepoNotExistError
+		if err := json.Unmarshal([]byte(b.Error), &e); err == nil && e.Repo != "" {
+			return &e
+		}
+		return errors.New(b.Error)
+	}
+	return nil
+}
+
+func NewBlameEventDone(err error) BlameEventDone {
+	var event BlameEventDone
+	var notExistError *gitdomain.RepoNotExistError
+	if errors.As(err, &notExistError) {
+		b, _ := json.Marshal(notExistError)
+		event.Error = string(b)
+	} else if err != nil {
+		event.Error = err.Error()
+	}
+	return event
+}
+
 type CommitMatch struct {
 	Oid        api.CommitID
 	Author     Signature      `json:",omitempty"`
@@ -231,6 +284,55 @@ type ReposStats struct {
 	GitDirBytes int64
 }
 
+// RepoStatsRequest is a request for the statistics of a repository at a
+// commit.
+type RepoStatsRequest struct {
+	Repo   api.RepoName
+	Commit api.CommitID `json:",omitempty"` // or "" for HEAD
+}
+
+// RepoStats are statistics about the contents and history of a repository at
+// a commit.
+type RepoStats struct {
+	// Commit is the commit the statistics were computed for.
+	Commit api.CommitID
+
+	// Languages is the breakdown of the files in the tree of the commit by
+	// language, sorted by descending size. Vendored files are not counted.
+	Languages []RepoStatsLanguage
+
+	// Contributors are the authors of the commits reachable from the commit,
+	// sorted by descending number of commits.
+	Contributors []RepoStatsContributor
+
+	// Churn lists the files changed most often by the most recent commits
+	// reachable from the commit, sorted by descending number of commits.
+	Churn []RepoStatsFileChurn
+}
+
+// RepoStatsLanguage is the number and size of the files of a language.
+type RepoStatsLanguage struct {
+	Name       string
+	TotalFiles int
+	TotalBytes int64
+}
+
+// RepoStatsContributor is the number of commits of an author.
+type RepoStatsContributor struct {
+	Name    string
+	Email   string
+	Commits int
+}
+
+// RepoStatsFileChurn is the number of commits which changed a file, and the
+// number of lines they added and deleted.
+type RepoStatsFileChurn struct {
+	Path         string
+	Commits      int
+	LinesAdded   int
+	LinesDeleted int
+}
+
 // RepoCloneProgressRequest is a request for information about the clone progress of multiple
 // repositories on gitserver.
 type RepoCloneProgressRequest struct {
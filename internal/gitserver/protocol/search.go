@@ -85,6 +85,32 @@ func (d *DiffModifiesFile) String() string {
 	return fmt.Sprintf("%T(%s)", d, d.Expr)
 }
 
+// DiffMatchesInFiles is a predicate that matches if any of the lines changed
+// in the files that satisfy the path filters match the given regex pattern.
+// Lines changed in other files are neither matched nor highlighted.
+type DiffMatchesInFiles struct {
+	Expr       string
+	IgnoreCase bool
+
+	// IncludeFiles are patterns which the path of a file must all match.
+	IncludeFiles []DiffModifiesFile
+
+	// ExcludeFiles are patterns which the path of a file must not match.
+	ExcludeFiles []DiffModifiesFile
+}
+
+func (d *DiffMatchesInFiles) String() string {
+	include := make([]string, 0, len(d.IncludeFiles))
+	for _, f := range d.IncludeFiles {
+		include = append(include, f.Expr)
+	}
+	exclude := make([]string, 0, len(d.ExcludeFiles))
+	for _, f := range d.ExcludeFiles {
+		exclude = append(exclude, f.Expr)
+	}
+	return fmt.Sprintf("%T(%s, include=[%s], exclude=[%s])", d, d.Expr, strings.Join(include, ", "), strings.Join(exclude, ", "))
+}
+
 // Boolean is a predicate that will either always match or never match
 type Boolean struct {
 	Value bool
@@ -219,6 +245,7 @@ func RegisterGob() {
 		gob.Register(&MessageMatches{})
 		gob.Register(&DiffMatches{})
 		gob.Register(&DiffModifiesFile{})
+		gob.Register(&DiffMatchesInFiles{})
 		gob.Register(&Boolean{})
 		gob.Register(&Operator{})
 	})
@@ -250,7 +250,7 @@ func estimatedNodeCost(n Node) float64 {
 		return 10
 	case *DiffModifiesFile:
 		return 1000
-	case *DiffMatches:
+	case *DiffMatches, *DiffMatchesInFiles:
 		return 10000
 	default:
 		return 1
@@ -77,9 +77,17 @@ func (l *LazyCommit) ParentIDs() []api.CommitID {
 }
 
 func (l *LazyCommit) RefNames() []string {
-	return strings.Split(string(l.RawCommit.RefNames), ", ")
+	return splitRefs(l.RawCommit.RefNames)
 }
 
 func (l *LazyCommit) SourceRefs() []string {
-	return strings.Split(string(l.RawCommit.SourceRefs), ", ")
+	return splitRefs(l.RawCommit.SourceRefs)
+}
+
+// splitRefs splits a comma-separated list of refs as output by git log.
+func splitRefs(refs []byte) []string {
+	if len(refs) == 0 {
+		return nil
+	}
+	return strings.Split(string(refs), ", ")
 }
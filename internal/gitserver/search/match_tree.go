@@ -5,6 +5,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/cockroachdb/errors"
+	"github.com/sourcegraph/go-diff/diff"
 
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/search/casetransform"
@@ -35,6 +36,8 @@ func ToMatchTree(q protocol.Node) (MatchTree, error) {
 	case *protocol.DiffModifiesFile:
 		re, err := casetransform.CompileRegexp(v.Expr, v.IgnoreCase)
 		return &DiffModifiesFile{re}, err
+	case *protocol.DiffMatchesInFiles:
+		return toDiffMatchesInFiles(v)
 	case *protocol.Boolean:
 		return &Constant{v.Value}, nil
 	case *protocol.Operator:
@@ -135,6 +138,12 @@ type DiffMatches struct {
 }
 
 func (dm *DiffMatches) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit, error) {
+	return matchDiff(lc, dm.Regexp, func(*diff.FileDiff) bool { return true })
+}
+
+// matchDiff matches re against the lines changed in the file diffs of the
+// commit for which includeFile returns true.
+func matchDiff(lc *LazyCommit, re *casetransform.Regexp, includeFile func(*diff.FileDiff) bool) (CommitFilterResult, MatchedCommit, error) {
 	diff, err := lc.Diff()
 	if err != nil {
 		return filterResult(false), MatchedCommit{}, err
@@ -143,6 +152,10 @@ func (dm *DiffMatches) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit,
 	var fileDiffHighlights map[int]MatchedFileDiff
 	matchedFileDiffs := make(map[int]struct{})
 	for fileIdx, fileDiff := range diff {
+		if !includeFile(fileDiff) {
+			continue
+		}
+
 		var hunkHighlights map[int]MatchedHunk
 		for hunkIdx, hunk := range fileDiff.Hunks {
 			var lineHighlights map[int]result.Ranges
@@ -158,7 +171,7 @@ func (dm *DiffMatches) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit,
 					continue
 				}
 
-				matches := dm.FindAllIndex(lineWithoutPrefix, -1, &lc.LowerBuf)
+				matches := re.FindAllIndex(lineWithoutPrefix, -1, &lc.LowerBuf)
 				if matches != nil {
 					if lineHighlights == nil {
 						lineHighlights = make(map[int]result.Ranges, 1)
@@ -186,6 +199,62 @@ func (dm *DiffMatches) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit,
 	return CommitFilterResult{MatchedFileDiffs: matchedFileDiffs}, MatchedCommit{Diff: fileDiffHighlights}, nil
 }
 
+// DiffMatchesInFiles is a predicate that matches if any of the lines changed
+// in the files that satisfy the path filters match the given regex pattern.
+type DiffMatchesInFiles struct {
+	*casetransform.Regexp
+	IncludeFiles []*casetransform.Regexp
+	ExcludeFiles []*casetransform.Regexp
+}
+
+func toDiffMatchesInFiles(v *protocol.DiffMatchesInFiles) (*DiffMatchesInFiles, error) {
+	re, err := casetransform.CompileRegexp(v.Expr, v.IgnoreCase)
+	if err != nil {
+		return nil, err
+	}
+	compile := func(files []protocol.DiffModifiesFile) ([]*casetransform.Regexp, error) {
+		res := make([]*casetransform.Regexp, 0, len(files))
+		for _, f := range files {
+			fileRe, err := casetransform.CompileRegexp(f.Expr, f.IgnoreCase)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, fileRe)
+		}
+		return res, nil
+	}
+	include, err := compile(v.IncludeFiles)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compile(v.ExcludeFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &DiffMatchesInFiles{Regexp: re, IncludeFiles: include, ExcludeFiles: exclude}, nil
+}
+
+func (dm *DiffMatchesInFiles) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit, error) {
+	return matchDiff(lc, dm.Regexp, func(fileDiff *diff.FileDiff) bool {
+		// Like DiffModifiesFile, a file matches a pattern if either its old
+		// or its new name does.
+		matches := func(re *casetransform.Regexp) bool {
+			return re.Match([]byte(fileDiff.OrigName), &lc.LowerBuf) || re.Match([]byte(fileDiff.NewName), &lc.LowerBuf)
+		}
+		for _, re := range dm.IncludeFiles {
+			if !matches(re) {
+				return false
+			}
+		}
+		for _, re := range dm.ExcludeFiles {
+			if matches(re) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
 // DiffModifiesFile is a predicate that matches if the commit modifies any files
 // that match the given regex pattern.
 type DiffModifiesFile struct {
@@ -0,0 +1,176 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"math/bits"
+	"os/exec"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// refGlobs returns the ref globs among revs in order, or nil if revs contains
+// no ref globs.
+func refGlobs(revs []protocol.RevisionSpecifier) []gitdomain.RefGlob {
+	var globs []gitdomain.RefGlob
+	for _, rev := range revs {
+		switch {
+		case rev.RefGlob != "":
+			globs = append(globs, gitdomain.RefGlob{Include: rev.RefGlob})
+		case rev.ExcludeRefGlob != "":
+			globs = append(globs, gitdomain.RefGlob{Exclude: rev.ExcludeRefGlob})
+		}
+	}
+	return globs
+}
+
+// gitRef is a ref along with the commit it points to. For annotated tags,
+// Commit is the tagged commit.
+type gitRef struct {
+	Name   string
+	Commit string
+}
+
+// expandRefGlobs returns the refs in the repository at repoDir that match
+// globs. See gitdomain.CompileRefGlobs for how include and exclude globs are
+// combined.
+func expandRefGlobs(ctx context.Context, repoDir string, globs []gitdomain.RefGlob) ([]gitRef, error) {
+	rg, err := gitdomain.CompileRefGlobs(globs)
+	if err != nil {
+		return nil, err
+	}
+
+	allRefs, err := forEachRef(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []gitRef
+	for _, ref := range allRefs {
+		if rg.Match(ref.Name) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// forEachRef returns all refs listed by git for-each-ref.
+func forEachRef(ctx context.Context, repoDir string) ([]gitRef, error) {
+	cmd := exec.CommandContext(ctx, "git", "for-each-ref", "--format=%(refname) %(objectname) %(*objectname)")
+	cmd.Dir = repoDir
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "git for-each-ref failed with stderr %q", stderrBuf.String())
+	}
+
+	var refs []gitRef
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// Peeled tags list the tagged object last.
+		refs = append(refs, gitRef{Name: fields[0], Commit: fields[len(fields)-1]})
+	}
+	return refs, nil
+}
+
+// refContainment computes the refs which contain each commit listed by
+// git log --topo-order, which lists every commit before its parents. A commit
+// is contained in the refs pointing to it and in the refs containing any of
+// its children, so the refs of each commit are known by the time it is
+// listed. This replaces a git for-each-ref --contains per matched commit with
+// a single pass over the log.
+type refContainment struct {
+	refs []gitRef
+
+	// tips maps commits to the indexes of the refs pointing to them.
+	tips map[string][]int
+
+	// pending maps commits which have not been listed yet to the refs
+	// containing their listed children. Entries are removed once the commit
+	// is listed, so its size is bounded by the width of the commit graph.
+	pending map[string]refSet
+}
+
+func newRefContainment(refs []gitRef) *refContainment {
+	tips := make(map[string][]int, len(refs))
+	for i, ref := range refs {
+		tips[ref.Commit] = append(tips[ref.Commit], i)
+	}
+	return &refContainment{
+		refs:    refs,
+		tips:    tips,
+		pending: map[string]refSet{},
+	}
+}
+
+// visit records that the given commit was listed and returns the names of the
+// refs containing it, in the order of the refs. parents is the space
+// separated list of parent hashes of the commit.
+func (c *refContainment) visit(commit, parents []byte) []string {
+	set, ok := c.pending[string(commit)]
+	if ok {
+		delete(c.pending, string(commit))
+	} else {
+		set = newRefSet(len(c.refs))
+	}
+	for _, i := range c.tips[string(commit)] {
+		set.add(i)
+	}
+
+	var names []string
+	set.each(func(i int) {
+		names = append(names, c.refs[i].Name)
+	})
+
+	for i, parent := range bytes.Fields(parents) {
+		if parentSet, ok := c.pending[string(parent)]; ok {
+			parentSet.union(set)
+		} else if i == 0 {
+			// The set of this commit is not needed anymore.
+			c.pending[string(parent)] = set
+		} else {
+			c.pending[string(parent)] = set.clone()
+		}
+	}
+
+	return names
+}
+
+// refSet is a set of ref indexes.
+type refSet []uint64
+
+func newRefSet(n int) refSet {
+	return make(refSet, (n+63)/64)
+}
+
+func (s refSet) add(i int) {
+	s[i/64] |= 1 << (i % 64)
+}
+
+func (s refSet) union(other refSet) {
+	for i := range s {
+		s[i] |= other[i]
+	}
+}
+
+func (s refSet) clone() refSet {
+	return append(refSet(nil), s...)
+}
+
+// each calls f with each index in the set in increasing order.
+func (s refSet) each(f func(int)) {
+	for i, word := range s {
+		for word != 0 {
+			f(i*64 + bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+}
@@ -54,7 +54,6 @@ var (
 		"log",
 		"--decorate=full",
 		"-z",
-		"--no-merges",
 		"--format=format:" + strings.Join(commitFields, "%x00") + "%x00",
 	}
 
@@ -77,6 +76,10 @@ type CommitSearcher struct {
 	Query       MatchTree
 	Revisions   []protocol.RevisionSpecifier
 	IncludeDiff bool
+
+	// refs are the refs the ref globs in Revisions expanded to. Matches are
+	// annotated with the refs among them that contain the matched commit.
+	refs []gitRef
 }
 
 // Search runs a search for commits matching the given predicate across the revisions passed in as revisionArgs.
@@ -87,7 +90,25 @@ type CommitSearcher struct {
 // that job should be sent down. We then read from the result channels in the same order that the jobs were sent.
 // This allows our worker pool to run the jobs in parallel, but we still emit matches in the same order that
 // git log outputs them.
+//
+// Ref globs in the revisions are expanded on our side rather than by git log so
+// that we know which of the refs contain each matched commit. A commit
+// reachable from many refs is still only visited (and matched) once.
 func (cs *CommitSearcher) Search(ctx context.Context, onMatch func(*protocol.CommitMatch)) error {
+	if globs := refGlobs(cs.Revisions); len(globs) > 0 {
+		refs, err := expandRefGlobs(ctx, cs.RepoDir, globs)
+		if err != nil {
+			return err
+		}
+		cs.refs = refs
+
+		if len(revsToGitArgs(cs.Revisions, cs.refs)) == 0 {
+			// No refs matched, and without revisions git log would
+			// search HEAD instead.
+			return nil
+		}
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
 
 	jobs := make(chan job, 128)
@@ -123,8 +144,20 @@ func (cs *CommitSearcher) Search(ctx context.Context, onMatch func(*protocol.Com
 }
 
 func (cs *CommitSearcher) feedBatches(ctx context.Context, jobs chan job, resultChans chan chan *protocol.CommitMatch) error {
-	revArgs := revsToGitArgs(cs.Revisions)
-	cmd := exec.CommandContext(ctx, "git", append(logArgs, revArgs...)...)
+	args := append([]string(nil), logArgs...)
+
+	// The refs containing each commit are computed while reading the log,
+	// which requires listing children before parents and listing merge
+	// commits. Merge commits are not matched either way.
+	var containment *refContainment
+	if len(cs.refs) > 0 {
+		containment = newRefContainment(cs.refs)
+		args = append(args, "--topo-order")
+	} else {
+		args = append(args, "--no-merges")
+	}
+
+	cmd := exec.CommandContext(ctx, "git", append(args, revsToGitArgs(cs.Revisions, cs.refs)...)...)
 	cmd.Dir = cs.RepoDir
 	stdoutReader, err := cmd.StdoutPipe()
 	if err != nil {
@@ -154,6 +187,13 @@ func (cs *CommitSearcher) feedBatches(ctx context.Context, jobs chan job, result
 			return ctx.Err()
 		}
 		cv := scanner.NextRawCommit()
+		if containment != nil {
+			cv.SourceRefs = []byte(strings.Join(containment.visit(cv.Hash, cv.ParentHashes), ", "))
+			if bytes.IndexByte(cv.ParentHashes, ' ') >= 0 {
+				// Merge commit
+				continue
+			}
+		}
 		batch = append(batch, cv)
 		if len(batch) == batchSize {
 			sendBatch()
@@ -229,19 +269,20 @@ func (cs *CommitSearcher) runJobs(ctx context.Context, jobs chan job) error {
 	return errors
 }
 
-func revsToGitArgs(revs []protocol.RevisionSpecifier) []string {
-	revArgs := make([]string, 0, len(revs))
+// revsToGitArgs returns the git log arguments for revs. Ref globs are not
+// passed to git log, the refs they expanded to are passed instead.
+func revsToGitArgs(revs []protocol.RevisionSpecifier, refs []gitRef) []string {
+	revArgs := make([]string, 0, len(revs)+len(refs))
 	for _, rev := range revs {
 		if rev.RevSpec != "" {
 			revArgs = append(revArgs, rev.RevSpec)
-		} else if rev.RefGlob != "" {
-			revArgs = append(revArgs, "--glob="+rev.RefGlob)
-		} else if rev.ExcludeRefGlob != "" {
-			revArgs = append(revArgs, "--exclude="+rev.RefGlob)
-		} else {
+		} else if rev.RefGlob == "" && rev.ExcludeRefGlob == "" {
 			revArgs = append(revArgs, "HEAD")
 		}
 	}
+	for _, ref := range refs {
+		revArgs = append(revArgs, ref.Name)
+	}
 	return revArgs
 }
 
@@ -167,6 +167,30 @@ func TestSearch(t *testing.T) {
 		require.Equal(t, matches[0].Author.Name, "camden1")
 	})
 
+	t.Run("diff matches in files", func(t *testing.T) {
+		// Both diffs contain an "i", but only the second one modifies file2.
+		query := &protocol.DiffMatchesInFiles{
+			Expr:         "i",
+			IncludeFiles: []protocol.DiffModifiesFile{{Expr: "file"}},
+			ExcludeFiles: []protocol.DiffModifiesFile{{Expr: "file1"}},
+		}
+		tree, err := ToMatchTree(query)
+		require.NoError(t, err)
+		searcher := &CommitSearcher{
+			RepoDir:     dir,
+			Query:       tree,
+			IncludeDiff: true,
+		}
+		var matches []*protocol.CommitMatch
+		err = searcher.Search(context.Background(), func(match *protocol.CommitMatch) {
+			matches = append(matches, match)
+		})
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		require.Equal(t, matches[0].Author.Name, "camden2")
+		require.Contains(t, matches[0].Diff.Content, "file2")
+	})
+
 	t.Run("and match", func(t *testing.T) {
 		query := protocol.NewAnd(
 			&protocol.DiffMatches{Expr: "lorem"},
@@ -190,6 +214,96 @@ func TestSearch(t *testing.T) {
 	})
 }
 
+func TestSearchRefGlobs(t *testing.T) {
+	cmds := []string{
+		"echo lorem > file1",
+		"git add -A",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z GIT_AUTHOR_NAME=a GIT_AUTHOR_EMAIL=a@a.com git commit -m commit1",
+		"git branch release/1",
+		"git branch release/old",
+		"echo ipsum > file2",
+		"git add -A",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-03T15:04:05Z GIT_AUTHOR_NAME=a GIT_AUTHOR_EMAIL=a@a.com git commit -m commit2",
+		"git branch release/2",
+		"git branch other",
+	}
+	dir := initGitRepository(t, cmds...)
+
+	search := func(t *testing.T, revs ...protocol.RevisionSpecifier) []*protocol.CommitMatch {
+		tree, err := ToMatchTree(&protocol.MessageMatches{Expr: "commit"})
+		require.NoError(t, err)
+		searcher := &CommitSearcher{
+			RepoDir:   dir,
+			Query:     tree,
+			Revisions: revs,
+		}
+		var matches []*protocol.CommitMatch
+		err = searcher.Search(context.Background(), func(match *protocol.CommitMatch) {
+			matches = append(matches, match)
+		})
+		require.NoError(t, err)
+		return matches
+	}
+
+	t.Run("matches each commit once with containing refs", func(t *testing.T) {
+		matches := search(t,
+			protocol.RevisionSpecifier{RefGlob: "refs/heads/release/*"},
+			protocol.RevisionSpecifier{ExcludeRefGlob: "refs/heads/release/old"},
+		)
+		require.Len(t, matches, 2)
+		require.Equal(t, "commit2", matches[0].Message.Content)
+		require.Equal(t, []string{"refs/heads/release/2"}, matches[0].SourceRefs)
+		require.Equal(t, "commit1", matches[1].Message.Content)
+		require.Equal(t, []string{"refs/heads/release/1", "refs/heads/release/2"}, matches[1].SourceRefs)
+	})
+
+	t.Run("no matching refs", func(t *testing.T) {
+		matches := search(t, protocol.RevisionSpecifier{RefGlob: "refs/heads/missing/*"})
+		require.Empty(t, matches)
+	})
+}
+
+func TestSearchRefGlobsThroughMerges(t *testing.T) {
+	const env = "GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_AUTHOR_NAME=a GIT_AUTHOR_EMAIL=a@a.com "
+	cmds := []string{
+		"git checkout -b main",
+		"echo lorem > file1",
+		"git add -A",
+		env + "GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m commit1",
+		env + "git tag -a v1 -m v1",
+		"git checkout -b feature",
+		"echo ipsum > file2",
+		"git add -A",
+		env + "GIT_COMMITTER_DATE=2006-01-03T15:04:05Z git commit -m commit2",
+		"git checkout main",
+		"echo dolor > file3",
+		"git add -A",
+		env + "GIT_COMMITTER_DATE=2006-01-04T15:04:05Z git commit -m commit3",
+		env + "GIT_COMMITTER_DATE=2006-01-05T15:04:05Z git merge --no-ff -m merge feature",
+	}
+	dir := initGitRepository(t, cmds...)
+
+	tree, err := ToMatchTree(&protocol.MessageMatches{Expr: "commit|merge"})
+	require.NoError(t, err)
+	searcher := &CommitSearcher{
+		RepoDir:   dir,
+		Query:     tree,
+		Revisions: []protocol.RevisionSpecifier{{RefGlob: "refs/heads/*"}, {RefGlob: "refs/tags/*"}},
+	}
+	sourceRefs := map[string][]string{}
+	err = searcher.Search(context.Background(), func(match *protocol.CommitMatch) {
+		sourceRefs[match.Message.Content] = match.SourceRefs
+	})
+	require.NoError(t, err)
+
+	// Merge commits are not matched, but refs containing them contain their parents.
+	require.Equal(t, map[string][]string{
+		"commit1": {"refs/heads/feature", "refs/heads/main", "refs/tags/v1"},
+		"commit2": {"refs/heads/feature", "refs/heads/main"},
+		"commit3": {"refs/heads/main"},
+	}, sourceRefs)
+}
+
 func TestCommitScanner(t *testing.T) {
 	cases := []struct {
 		input    []byte
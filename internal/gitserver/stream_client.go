@@ -44,3 +44,39 @@ func (s StreamSearchDecoder) ReadAll(r io.Reader) error {
 
 	return dec.Err()
 }
+
+type StreamBlameDecoder struct {
+	OnHunks   func(protocol.BlameEventHunks)
+	OnDone    func(protocol.BlameEventDone)
+	OnUnknown func(event, data []byte)
+}
+
+func (s StreamBlameDecoder) ReadAll(r io.Reader) error {
+	dec := http.NewDecoder(r)
+
+	for dec.Scan() {
+		event := dec.Event()
+		data := dec.Data()
+
+		if bytes.Equal(event, []byte("hunks")) {
+			if s.OnHunks == nil {
+				continue
+			}
+			var e protocol.BlameEventHunks
+			if err := json.Unmarshal(data, &e); err != nil {
+				return errors.Errorf("failed to decode hunks payload: %w", err)
+			}
+			s.OnHunks(e)
+		} else if bytes.Equal(event, []byte("done")) {
+			var e protocol.BlameEventDone
+			if err := json.Unmarshal(data, &e); err != nil {
+				return errors.Errorf("failed to decode done payload: %w", err)
+			}
+			s.OnDone(e)
+		} else if s.OnUnknown != nil {
+			s.OnUnknown(event, data)
+		}
+	}
+
+	return dec.Err()
+}
@@ -106,6 +106,12 @@ const (
 type updateScheduler struct {
 	updateQueue *updateQueue
 	schedule    *schedule
+
+	// evicted is the set of repos which gitserver evicted to free up disk
+	// space, which are not updated until they are requested again. Requesting
+	// an update of an evicted repo would clone it straight back.
+	evictedMu sync.Mutex
+	evicted   map[api.RepoID]struct{}
 }
 
 // A configuredRepo represents the configuration data for a given repo from
@@ -193,6 +199,13 @@ func (s *updateScheduler) runUpdateLoop(ctx context.Context) {
 				break
 			}
 
+			if s.isEvicted(repo.ID) {
+				log15.Debug("scheduler.updateQueue.skipped evicted repo", "repo", repo.Name)
+				s.updateQueue.remove(repo, true)
+				cancel()
+				continue
+			}
+
 			go func(ctx context.Context, repo configuredRepo, cancel context.CancelFunc) {
 				defer cancel()
 				defer s.updateQueue.remove(repo, true)
@@ -310,6 +323,30 @@ func (s *updateScheduler) EnsureScheduled(repos []types.RepoName) {
 	s.schedule.insertNew(repos)
 }
 
+// SetEvicted replaces the set of repos which gitserver evicted to free up disk
+// space. Updates of evicted repos are skipped, so that they are not cloned
+// again until a search or another request clones them on demand.
+//
+// This method should be called periodically with the list of all repositories
+// managed by the scheduler that are evicted.
+func (s *updateScheduler) SetEvicted(ids []api.RepoID) {
+	evicted := make(map[api.RepoID]struct{}, len(ids))
+	for _, id := range ids {
+		evicted[id] = struct{}{}
+	}
+
+	s.evictedMu.Lock()
+	s.evicted = evicted
+	s.evictedMu.Unlock()
+}
+
+func (s *updateScheduler) isEvicted(id api.RepoID) bool {
+	s.evictedMu.Lock()
+	defer s.evictedMu.Unlock()
+	_, ok := s.evicted[id]
+	return ok
+}
+
 // ListRepos list all repos managed by the scheduler
 func (s *updateScheduler) ListRepos() []string {
 	s.schedule.mu.Lock()
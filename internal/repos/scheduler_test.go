@@ -1317,6 +1317,7 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 		gitMaxConcurrentClones int
 		initialSchedule        []*scheduledRepoUpdate
 		initialQueue           []*repoUpdate
+		evicted                []api.RepoID
 		mockRequestRepoUpdates []*mockRequestRepoUpdate
 		finalSchedule          []*scheduledRepoUpdate
 		finalQueue             []*repoUpdate
@@ -1349,6 +1350,20 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 				{repo: c},
 			},
 		},
+		{
+			name:                   "evicted repos skipped",
+			gitMaxConcurrentClones: 1,
+			initialQueue: []*repoUpdate{
+				{Repo: a, Seq: 1},
+				{Repo: b, Seq: 2},
+				{Repo: c, Seq: 3},
+			},
+			evicted: []api.RepoID{b.ID},
+			mockRequestRepoUpdates: []*mockRequestRepoUpdate{
+				{repo: a},
+				{repo: c},
+			},
+		},
 		{
 			name:                   "schedule updated",
 			gitMaxConcurrentClones: 1,
@@ -1426,6 +1441,7 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 
 			setupInitialSchedule(s, test.initialSchedule)
 			setupInitialQueue(s, test.initialQueue)
+			s.SetEvicted(test.evicted)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -134,7 +134,7 @@ func NewSearchJob(q query.Q, diff bool, limit int) (*CommitSearch, error) {
 }
 
 func queryToGitQuery(q query.Q, diff bool) gitprotocol.Node {
-	return gitprotocol.Reduce(gitprotocol.NewAnd(queryNodesToPredicates(q, q.IsCaseSensitive(), diff)...))
+	return gitprotocol.Reduce(gitprotocol.NewAnd(andNodesToPredicates(q, q.IsCaseSensitive(), diff)...))
 }
 
 func searchRevsToGitserverRevs(in []search.RevisionSpecifier) []gitprotocol.RevisionSpecifier {
@@ -168,10 +168,88 @@ func queryNodesToPredicates(nodes []query.Node, caseSensitive, diff bool) []gitp
 	return res
 }
 
+// andNodesToPredicates converts the operands of an and operator. When searching
+// diffs, the file: and lang: filters among the operands restrict the diff
+// patterns to the files they match, so that a query like `file:a b` only
+// matches and highlights b in the files matching a.
+func andNodesToPredicates(nodes []query.Node, caseSensitive, diff bool) []gitprotocol.Node {
+	preds := queryNodesToPredicates(nodes, caseSensitive, diff)
+	if !diff {
+		return preds
+	}
+
+	include, exclude := diffPathFilters(nodes, caseSensitive)
+	if len(include) == 0 && len(exclude) == 0 {
+		return preds
+	}
+	for i, pred := range preds {
+		preds[i] = scopeDiffMatches(pred, include, exclude)
+	}
+	return preds
+}
+
+// diffPathFilters returns the path patterns of the file: and lang: parameters
+// in nodes, split by whether they are negated.
+func diffPathFilters(nodes []query.Node, caseSensitive bool) (include, exclude []gitprotocol.DiffModifiesFile) {
+	for _, node := range nodes {
+		parameter, ok := node.(query.Parameter)
+		if !ok {
+			continue
+		}
+
+		var filter gitprotocol.DiffModifiesFile
+		switch parameter.Field {
+		case query.FieldFile:
+			filter = gitprotocol.DiffModifiesFile{Expr: parameter.Value, IgnoreCase: !caseSensitive}
+		case query.FieldLang:
+			filter = gitprotocol.DiffModifiesFile{Expr: search.LangToFileRegexp(parameter.Value), IgnoreCase: true}
+		default:
+			continue
+		}
+
+		if parameter.Negated {
+			exclude = append(exclude, filter)
+		} else {
+			include = append(include, filter)
+		}
+	}
+	return include, exclude
+}
+
+// scopeDiffMatches replaces the diff patterns in n with patterns which only
+// match in the files satisfying the given path filters.
+func scopeDiffMatches(n gitprotocol.Node, include, exclude []gitprotocol.DiffModifiesFile) gitprotocol.Node {
+	switch v := n.(type) {
+	case *gitprotocol.DiffMatches:
+		return &gitprotocol.DiffMatchesInFiles{
+			Expr:         v.Expr,
+			IgnoreCase:   v.IgnoreCase,
+			IncludeFiles: include,
+			ExcludeFiles: exclude,
+		}
+	case *gitprotocol.DiffMatchesInFiles:
+		// Already scoped by the filters of a nested and operator.
+		return &gitprotocol.DiffMatchesInFiles{
+			Expr:         v.Expr,
+			IgnoreCase:   v.IgnoreCase,
+			IncludeFiles: append(append([]gitprotocol.DiffModifiesFile{}, include...), v.IncludeFiles...),
+			ExcludeFiles: append(append([]gitprotocol.DiffModifiesFile{}, exclude...), v.ExcludeFiles...),
+		}
+	case *gitprotocol.Operator:
+		operands := make([]gitprotocol.Node, 0, len(v.Operands))
+		for _, operand := range v.Operands {
+			operands = append(operands, scopeDiffMatches(operand, include, exclude))
+		}
+		return &gitprotocol.Operator{Kind: v.Kind, Operands: operands}
+	default:
+		return n
+	}
+}
+
 func queryOperatorToPredicate(op query.Operator, caseSensitive, diff bool) gitprotocol.Node {
 	switch op.Kind {
 	case query.And:
-		return gitprotocol.NewAnd(queryNodesToPredicates(op.Operands, caseSensitive, diff)...)
+		return gitprotocol.NewAnd(andNodesToPredicates(op.Operands, caseSensitive, diff)...)
 	case query.Or:
 		return gitprotocol.NewOr(queryNodesToPredicates(op.Operands, caseSensitive, diff)...)
 	default:
@@ -274,6 +352,7 @@ func protocolMatchToCommitMatch(repo types.RepoName, diff bool, in protocol.Comm
 			Parents: in.Parents,
 		},
 		Repo:           repo,
+		SourceRefs:     in.SourceRefs,
 		MessagePreview: messagePreview,
 		DiffPreview:    diffPreview,
 		Body: result.HighlightedString{
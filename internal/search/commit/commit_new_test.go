@@ -130,6 +130,41 @@ func TestQueryToGitQuery(t *testing.T) {
 			&protocol.MessageMatches{Expr: "message2", IgnoreCase: true},
 			&protocol.DiffModifiesFile{Expr: "file", IgnoreCase: true},
 		),
+	}, {
+		name: "file and lang filters scope diff patterns",
+		input: []query.Node{
+			query.Parameter{Field: query.FieldLang, Value: "go"},
+			query.Operator{
+				Kind: query.Or,
+				Operands: []query.Node{
+					query.Operator{
+						Kind: query.And,
+						Operands: []query.Node{
+							query.Parameter{Field: query.FieldFile, Value: "vendor/", Negated: true},
+							query.Pattern{Value: "b"},
+						},
+					},
+					query.Parameter{Field: query.FieldMessage, Value: "c"},
+				},
+			},
+		},
+		diff: true,
+		output: protocol.NewAnd(
+			&protocol.DiffModifiesFile{Expr: `\.go$`, IgnoreCase: true},
+			protocol.NewOr(
+				&protocol.MessageMatches{Expr: "c", IgnoreCase: true},
+				protocol.NewNot(&protocol.DiffModifiesFile{Expr: "vendor/", IgnoreCase: true}),
+			),
+			protocol.NewOr(
+				&protocol.MessageMatches{Expr: "c", IgnoreCase: true},
+				&protocol.DiffMatchesInFiles{
+					Expr:         "b",
+					IgnoreCase:   true,
+					IncludeFiles: []protocol.DiffModifiesFile{{Expr: `\.go$`, IgnoreCase: true}},
+					ExcludeFiles: []protocol.DiffModifiesFile{{Expr: "vendor/", IgnoreCase: true}},
+				},
+			),
+		),
 	}}
 
 	for _, tc := range cases {
@@ -34,6 +34,9 @@ const (
 	FieldTimeout   = "timeout"
 	FieldCombyRule = "rule"
 	FieldSelect    = "select"
+
+	// For symbol search only:
+	FieldContainer = "container"
 )
 
 var allFields = map[string]struct{}{
@@ -72,6 +75,8 @@ var allFields = map[string]struct{}{
 	FieldRev:                empty,
 	"revision":              empty,
 	FieldSelect:             empty,
+	FieldContainer:          empty,
+	"kind":                  empty,
 }
 
 var aliases = map[string]string{
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/filter"
 )
 
 type Predicate interface {
@@ -21,7 +23,10 @@ type Predicate interface {
 	ParseParams(string) error
 
 	// Plan generates a plan of (possibly multiple) queries to execute the
-	// behavior of a predicate in a query Q.
+	// behavior of a predicate in a query Q. The plan finds the results that
+	// satisfy the predicate. When the predicate is negated, e.g.
+	// `-repo:contains.file(...)`, the same plan is used and its results are
+	// excluded from the parent query instead.
 	Plan(parent Basic) (Plan, error)
 }
 
@@ -31,10 +36,12 @@ var DefaultPredicateRegistry = PredicateRegistry{
 		"contains.file":         func() Predicate { return &RepoContainsFilePredicate{} },
 		"contains.content":      func() Predicate { return &RepoContainsContentPredicate{} },
 		"contains.commit.after": func() Predicate { return &RepoContainsCommitAfterPredicate{} },
+		"contains.symbol":       func() Predicate { return &RepoContainsSymbolPredicate{} },
 	},
 	FieldFile: {
 		"contains.content": func() Predicate { return &FileContainsContentPredicate{} },
 		"contains":         func() Predicate { return &FileContainsContentPredicate{} },
+		"contains.symbol":  func() Predicate { return &FileContainsSymbolPredicate{} },
 	},
 }
 
@@ -296,6 +303,147 @@ func (f *FileContainsContentPredicate) Plan(parent Basic) (Plan, error) {
 	return ToPlan(Dnf(nodes))
 }
 
+/* contains.symbol(pattern kind:k) */
+
+// SymbolPredicateParams are the parameters shared by the `contains.symbol`
+// predicates. Pattern is a regular expression matched against symbol names,
+// and Kind optionally restricts matches to a symbol kind as understood by
+// `select:symbol.<kind>`.
+type SymbolPredicateParams struct {
+	Pattern string
+	Kind    string
+}
+
+func (s *SymbolPredicateParams) parse(predicate, params string) error {
+	nodes, err := Parse(params, SearchTypeRegex)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		if err := s.parseNode(predicate, node); err != nil {
+			return err
+		}
+	}
+
+	if s.Pattern == "" {
+		return errors.Errorf("%s argument should contain a symbol pattern", predicate)
+	}
+	return nil
+}
+
+func (s *SymbolPredicateParams) parseNode(predicate string, n Node) error {
+	switch v := n.(type) {
+	case Pattern:
+		if v.Negated {
+			return errors.New("predicates do not currently support negated values")
+		}
+		if kind := strings.TrimPrefix(v.Value, "kind:"); kind != v.Value {
+			return s.parseKind(predicate, kind)
+		}
+		if s.Pattern != "" {
+			return errors.Errorf("%s only supports one symbol pattern", predicate)
+		}
+		if _, err := regexp.Compile(v.Value); err != nil {
+			return errors.Errorf("%s argument: %w", predicate, err)
+		}
+		s.Pattern = v.Value
+	case Parameter:
+		// `kind:` is a recognized field, so it parses as a parameter.
+		if strings.ToLower(v.Field) == "kind" && !v.Negated {
+			return s.parseKind(predicate, v.Value)
+		}
+		return errors.Errorf("unsupported option %q", v.Field)
+	case Operator:
+		if v.Kind == Or {
+			return errors.New("predicates do not currently support 'or' queries")
+		}
+		for _, operand := range v.Operands {
+			if err := s.parseNode(predicate, operand); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.Errorf("unsupported node type %T", n)
+	}
+	return nil
+}
+
+func (s *SymbolPredicateParams) parseKind(predicate, kind string) error {
+	if s.Kind != "" {
+		return errors.New("cannot specify kind multiple times")
+	}
+	if _, err := filter.SelectPathFromString(filter.Symbol + "." + strings.ToLower(kind)); err != nil {
+		return errors.Errorf("%s has invalid `kind` argument %q", predicate, kind)
+	}
+	s.Kind = strings.ToLower(kind)
+	return nil
+}
+
+// plan returns a symbol search for the predicate parameters, scoped to the
+// repositories of the parent query. Results are file matches containing the
+// matching symbols.
+func (s *SymbolPredicateParams) plan(parent Basic) (Plan, error) {
+	selectPath := filter.Symbol
+	if s.Kind != "" {
+		selectPath += "." + s.Kind
+	}
+
+	nodes := make([]Node, 0, 4)
+	nodes = append(nodes, Parameter{
+		Field: FieldCount,
+		Value: "99999",
+	}, Parameter{
+		Field: FieldType,
+		Value: "symbol",
+	}, Parameter{
+		Field: FieldSelect,
+		Value: selectPath,
+	}, Pattern{
+		Value:      s.Pattern,
+		Annotation: Annotation{Labels: Regexp},
+	})
+
+	nodes = append(nodes, nonPredicateRepos(parent)...)
+	return ToPlan(Dnf(nodes))
+}
+
+/* repo:contains.symbol(pattern) */
+
+// RepoContainsSymbolPredicate represents the `repo:contains.symbol()`
+// predicate, which filters to repos that define a matching symbol.
+type RepoContainsSymbolPredicate struct {
+	SymbolPredicateParams
+}
+
+func (f *RepoContainsSymbolPredicate) ParseParams(params string) error {
+	return f.parse("repo:contains.symbol", params)
+}
+
+func (f *RepoContainsSymbolPredicate) Field() string { return FieldRepo }
+func (f *RepoContainsSymbolPredicate) Name() string  { return "contains.symbol" }
+func (f *RepoContainsSymbolPredicate) Plan(parent Basic) (Plan, error) {
+	return f.plan(parent)
+}
+
+/* file:contains.symbol(pattern) */
+
+// FileContainsSymbolPredicate represents the `file:contains.symbol()`
+// predicate, which filters to files that define a matching symbol.
+type FileContainsSymbolPredicate struct {
+	SymbolPredicateParams
+}
+
+func (f *FileContainsSymbolPredicate) ParseParams(params string) error {
+	return f.parse("file:contains.symbol", params)
+}
+
+func (f *FileContainsSymbolPredicate) Field() string { return FieldFile }
+func (f *FileContainsSymbolPredicate) Name() string  { return "contains.symbol" }
+func (f *FileContainsSymbolPredicate) Plan(parent Basic) (Plan, error) {
+	return f.plan(parent)
+}
+
 // nonPredicateRepos returns the repo nodes in a query that aren't predicates,
 // respecting parameters that determine repo results.
 func nonPredicateRepos(q Basic) []Node {
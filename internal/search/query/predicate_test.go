@@ -56,6 +56,84 @@ func TestRepoContainsPredicate(t *testing.T) {
 	})
 }
 
+func TestSymbolPredicate(t *testing.T) {
+	t.Run("ParseParams", func(t *testing.T) {
+		type test struct {
+			name     string
+			params   string
+			expected SymbolPredicateParams
+		}
+
+		valid := []test{
+			{`name`, `NewServer`, SymbolPredicateParams{Pattern: "NewServer"}},
+			{`regex`, `^New.*Server$`, SymbolPredicateParams{Pattern: "^New.*Server$"}},
+			{`kind`, `Server kind:struct`, SymbolPredicateParams{Pattern: "Server", Kind: "struct"}},
+			{`kind first`, `kind:Function serve`, SymbolPredicateParams{Pattern: "serve", Kind: "function"}},
+		}
+
+		for _, tc := range valid {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &FileContainsSymbolPredicate{}
+				if err := p.ParseParams(tc.params); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if !reflect.DeepEqual(tc.expected, p.SymbolPredicateParams) {
+					t.Fatalf("expected %#v, got %#v", tc.expected, p.SymbolPredicateParams)
+				}
+			})
+		}
+
+		invalid := []test{
+			{`empty`, ``, SymbolPredicateParams{}},
+			{`only kind`, `kind:function`, SymbolPredicateParams{}},
+			{`unknown kind`, `foo kind:gadget`, SymbolPredicateParams{}},
+			{`multiple kinds`, `foo kind:function kind:method`, SymbolPredicateParams{}},
+			{`invalid regexp`, `([)`, SymbolPredicateParams{}},
+			{`unsupported field`, `file:foo bar`, SymbolPredicateParams{}},
+			{`negated`, `NOT foo`, SymbolPredicateParams{}},
+			{`or`, `foo or bar`, SymbolPredicateParams{}},
+		}
+
+		for _, tc := range invalid {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &RepoContainsSymbolPredicate{}
+				if err := p.ParseParams(tc.params); err == nil {
+					t.Fatal("expected error but got none")
+				}
+			})
+		}
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		test := func(predicate Predicate, params, parent string) string {
+			if err := predicate.ParseParams(params); err != nil {
+				t.Fatal(err)
+			}
+			parentPlan, err := Pipeline(InitRegexp(parent))
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan, err := predicate.Plan(parentPlan[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return plan[0].StringHuman()
+		}
+
+		got := test(&FileContainsSymbolPredicate{}, `Server kind:struct`, `repo:foo -repo:bar file:contains.symbol(Server kind:struct) baz`)
+		want := `count:99999 type:symbol select:symbol.struct repo:foo -repo:bar Server`
+		if got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		got = test(&RepoContainsSymbolPredicate{}, `Server`, `repo:contains.symbol(Server)`)
+		want = `count:99999 type:symbol select:symbol Server`
+		if got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	})
+}
+
 func TestParseAsPredicate(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -11,7 +11,8 @@ import (
 )
 
 // SubstituteAliases substitutes field name aliases for their canonical names,
-// and substitutes `content:` for pattern nodes.
+// substitutes `content:` for pattern nodes, and substitutes `kind:X` for
+// `select:symbol.X`.
 func SubstituteAliases(searchType SearchType) func(nodes []Node) []Node {
 	mapper := func(nodes []Node) []Node {
 		return MapParameter(nodes, func(field, value string, negated bool, annotation Annotation) Node {
@@ -24,6 +25,10 @@ func SubstituteAliases(searchType SearchType) func(nodes []Node) []Node {
 				annotation.Labels.set(IsAlias)
 				return Pattern{Value: value, Negated: negated, Annotation: annotation}
 			}
+			if field == "kind" {
+				annotation.Labels.set(IsAlias)
+				return Parameter{Field: FieldSelect, Value: "symbol." + value, Negated: negated, Annotation: annotation}
+			}
 			if canonical, ok := aliases[field]; ok {
 				annotation.Labels.set(IsAlias)
 				field = canonical
@@ -97,6 +97,11 @@ func TestSubstituteAliases(t *testing.T) {
 		"substitution honors literal search pattern",
 		`[{"and":[{"field":"repo","value":"repo","negated":false,"labels":["IsAlias"]},{"value":"^not-actually-a-regexp:tbf$","negated":false,"labels":["IsAlias","Literal"]}]}]`).
 		Equal(t, test("r:repo content:^not-actually-a-regexp:tbf$", SearchTypeLiteral))
+
+	autogold.Want(
+		"kind substitution",
+		`[{"and":[{"field":"type","value":"symbol","negated":false,"labels":["None"]},{"field":"select","value":"symbol.function","negated":false,"labels":["IsAlias"]}]}]`).
+		Equal(t, test("type:symbol kind:function", SearchTypeRegex))
 }
 
 func TestLowercaseFieldNames(t *testing.T) {
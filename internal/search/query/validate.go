@@ -352,6 +352,9 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 	case
 		FieldSelect:
 		return satisfies(isSingular, isNotNegated, isValidSelect)
+	case
+		FieldContainer:
+		return satisfies(isSingular, isNotNegated, isValidRegexp)
 	default:
 		return isUnrecognizedField()
 	}
@@ -451,10 +454,9 @@ func validateRefGlobs(nodes []Node) error {
 }
 
 // validatePredicates validates predicate parameters with respect to their validation logic.
-func validatePredicate(field, value string, negated bool) error {
-	if negated {
-		return errors.New("predicates do not currently support negation")
-	}
+// A negated predicate is valid whenever its non-negated form is valid: it
+// excludes what the predicate matches from the query's scope.
+func validatePredicate(field, value string) error {
 	name, params := ParseAsPredicate(value)                // guaranteed to succeed
 	predicate := DefaultPredicateRegistry.Get(field, name) // guaranteed to succeed
 	if err := predicate.ParseParams(params); err != nil {
@@ -513,7 +515,7 @@ func validateParameters(nodes []Node) error {
 			return
 		}
 		if annotation.Labels.IsSet(IsPredicate) {
-			err = validatePredicate(field, value, negated)
+			err = validatePredicate(field, value)
 			seen[field] = struct{}{}
 			return
 		}
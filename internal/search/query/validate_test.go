@@ -95,10 +95,26 @@ func TestValidation(t *testing.T) {
 			input: "-context:a",
 			want:  `field "context" does not support negation`,
 		},
+		{
+			input: "-repo:contains.file([)",
+			want:  "invalid predicate value: contains.file argument: error parsing regexp: missing closing ]: `[`",
+		},
 		{
 			input: "type:symbol select:symbol.timelime",
 			want:  `invalid field "timelime" on select path "symbol.timelime"`,
 		},
+		{
+			input: "type:symbol kind:timelime",
+			want:  `invalid field "timelime" on select path "symbol.timelime"`,
+		},
+		{
+			input: "type:symbol container:[",
+			want:  "error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			input: "type:symbol -container:Server",
+			want:  `field "container" does not support negation`,
+		},
 		{
 			input:      "nice try type:repo",
 			want:       "this structural search query specifies `type:` and is not supported. Structural search syntax only applies to searching file contents",
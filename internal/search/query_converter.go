@@ -171,6 +171,7 @@ func ToTextPatternInfo(q query.Basic, p Protocol, transform query.BasicPass) *Te
 		CombyRule:                    q.FindValue(query.FieldCombyRule),
 		Index:                        q.Index(),
 		Select:                       selector,
+		SymbolContainer:              q.FindValue(query.FieldContainer),
 	}
 }
 
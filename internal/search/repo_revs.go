@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
@@ -208,7 +209,7 @@ func (r *RepositoryRevisions) ExpandedRevSpecs(ctx context.Context) ([]string, e
 
 // expandedRevSpecs evaluates all of r's ref glob expressions and returns the full, current list of
 // refs matched or resolved by them, plus the explicitly listed Git revspecs. See
-// git.CompileRefGlobs for information on how ref include/exclude globs are handled.
+// gitdomain.CompileRefGlobs for information on how ref include/exclude globs are handled.
 func expandedRevSpec(ctx context.Context, r *RepositoryRevisions) ([]string, error) {
 	listRefs := r.ListRefs
 	if listRefs == nil {
@@ -217,14 +218,14 @@ func expandedRevSpec(ctx context.Context, r *RepositoryRevisions) ([]string, err
 
 	var (
 		revSpecs = map[string]struct{}{}
-		globs    []git.RefGlob
+		globs    []gitdomain.RefGlob
 	)
 	for _, rev := range r.Revs {
 		switch {
 		case rev.RefGlob != "":
-			globs = append(globs, git.RefGlob{Include: rev.RefGlob})
+			globs = append(globs, gitdomain.RefGlob{Include: rev.RefGlob})
 		case rev.ExcludeRefGlob != "":
-			globs = append(globs, git.RefGlob{Exclude: rev.ExcludeRefGlob})
+			globs = append(globs, gitdomain.RefGlob{Exclude: rev.ExcludeRefGlob})
 		default:
 			revSpecs[rev.RevSpec] = struct{}{}
 		}
@@ -235,7 +236,7 @@ func expandedRevSpec(ctx context.Context, r *RepositoryRevisions) ([]string, err
 			return nil, err
 		}
 
-		rg, err := git.CompileRefGlobs(globs)
+		rg, err := gitdomain.CompileRefGlobs(globs)
 		if err != nil {
 			return nil, err
 		}
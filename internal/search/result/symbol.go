@@ -3,6 +3,7 @@ package result
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -305,6 +306,21 @@ func pick(symbols []*SymbolMatch, satisfy func(*SymbolMatch) bool) []*SymbolMatc
 	return result
 }
 
+// CtagsKinds returns the internal symbol kinds (cf. ctagsKind) which
+// correspond to the symbol selector kind value, in sorted order. It is the
+// inverse of the mapping used by SelectSymbolKind, so that selecting a kind can
+// be pushed down to the symbols service.
+func CtagsKinds(selectKind string) []string {
+	var kinds []string
+	for kind, s := range toSelectKind {
+		if s == selectKind {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
 func SelectSymbolKind(symbols []*SymbolMatch, field string) []*SymbolMatch {
 	return pick(symbols, func(s *SymbolMatch) bool {
 		return field == toSelectKind[strings.ToLower(s.Symbol.Kind)]
@@ -67,3 +67,15 @@ func TestSymbolURL(t *testing.T) {
 		})
 	}
 }
+
+func TestCtagsKinds(t *testing.T) {
+	require.Equal(t, []string{"struct"}, CtagsKinds("struct"))
+	require.Equal(t, []string{"method", "methodspec"}, CtagsKinds("method"))
+	require.Empty(t, CtagsKinds("timelime"))
+
+	// Every kind returned must be selected by SelectSymbolKind.
+	for _, kind := range CtagsKinds("function") {
+		symbols := []*SymbolMatch{{Symbol: Symbol{Kind: kind}}}
+		require.Len(t, SelectSymbolKind(symbols, "function"), 1, kind)
+	}
+}
@@ -103,7 +103,7 @@ func (a *Aggregator) DoSymbolSearch(ctx context.Context, args *search.TextParame
 		tr.Finish()
 	}()
 
-	err = symbol.Search(ctx, args, notSearcherOnly, globalSearch, limit, a)
+	err = symbol.Search(ctx, a.db, args, notSearcherOnly, globalSearch, limit, a)
 	return errors.Wrap(err, "symbol search failed")
 }
 
@@ -0,0 +1,165 @@
+package searcher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+)
+
+const (
+	// maxSearchAttempts is the number of attempts at a search, each on the
+	// next searcher returned by searchEndpoints, before giving up on
+	// temporary errors.
+	maxSearchAttempts = 3
+
+	// cachedCommitsTTL is how long the commits advertised by a searcher are
+	// used before they are fetched again.
+	cachedCommitsTTL = 30 * time.Second
+)
+
+// warmSearchers tracks which searchers hold the archives of which commits.
+var warmSearchers = newCachedCommits()
+
+// searchEndpoints returns the searchers to attempt a search on, in order.
+// hashed lists all searchers in consistent hashing order, so starts with the
+// owner of the commit, and warm lists the searchers which hold the archive of
+// the commit. Warm searchers come first since they don't need to fetch the
+// archive, then the remaining searchers in hashing order. At most
+// maxSearchAttempts searchers are returned.
+func searchEndpoints(hashed, warm []string) []string {
+	endpoints := make([]string, 0, maxSearchAttempts)
+	seen := make(map[string]struct{}, maxSearchAttempts)
+	for _, list := range [][]string{warm, hashed} {
+		for _, e := range list {
+			if len(endpoints) == maxSearchAttempts {
+				return endpoints
+			}
+			if _, ok := seen[e]; ok {
+				continue
+			}
+			seen[e] = struct{}{}
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// cachedCommits is the set of commits whose archives are cached by each
+// searcher, as advertised by the /cached-commits endpoint of searcher. It is
+// refreshed in the background, so lookups never wait on the network.
+type cachedCommits struct {
+	mu sync.Mutex
+
+	// byEndpoint maps each searcher to the set of repo@commit keys it holds.
+	byEndpoint map[string]map[string]struct{}
+
+	// refreshedAt is when the commits of each searcher were last fetched.
+	refreshedAt map[string]time.Time
+
+	// refreshing is the set of searchers whose commits are being fetched.
+	refreshing map[string]struct{}
+
+	// fetch returns the commits cached by the searcher at endpoint.
+	fetch func(ctx context.Context, endpoint string) (*protocol.CachedCommits, error)
+}
+
+func newCachedCommits() *cachedCommits {
+	return &cachedCommits{
+		byEndpoint:  make(map[string]map[string]struct{}),
+		refreshedAt: make(map[string]time.Time),
+		refreshing:  make(map[string]struct{}),
+		fetch:       fetchCachedCommits,
+	}
+}
+
+// Warm returns the searchers among endpoints which hold the archive of key, in
+// the order of endpoints. It starts refreshing the commits of searchers whose
+// commits are stale.
+func (c *cachedCommits) Warm(endpoints []string, key string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var warm []string
+	now := time.Now()
+	for _, e := range endpoints {
+		if _, ok := c.refreshing[e]; !ok && now.Sub(c.refreshedAt[e]) > cachedCommitsTTL {
+			c.refreshing[e] = struct{}{}
+			go c.refresh(e)
+		}
+		if _, ok := c.byEndpoint[e][key]; ok {
+			warm = append(warm, e)
+		}
+	}
+	return warm
+}
+
+// Add records that the searcher at endpoint holds the archive of key, which is
+// the case after it served a search of key.
+func (c *cachedCommits) Add(endpoint, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys, ok := c.byEndpoint[endpoint]
+	if !ok {
+		keys = make(map[string]struct{})
+		c.byEndpoint[endpoint] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+func (c *cachedCommits) refresh(endpoint string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := c.fetch(ctx, endpoint)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.refreshing, endpoint)
+	// Also record failed refreshes, so that we don't retry on every search.
+	c.refreshedAt[endpoint] = time.Now()
+	if err != nil {
+		log15.Debug("failed to fetch commits cached by searcher", "endpoint", endpoint, "error", err)
+		delete(c.byEndpoint, endpoint)
+		return
+	}
+
+	keys := make(map[string]struct{}, len(resp.Commits))
+	for _, rc := range resp.Commits {
+		keys[string(rc.Repo)+"@"+string(rc.Commit)] = struct{}{}
+	}
+	c.byEndpoint[endpoint] = keys
+}
+
+// fetchCachedCommits returns the commits whose archives are cached by the
+// searcher at endpoint.
+func fetchCachedCommits(ctx context.Context, endpoint string) (*protocol.CachedCommits, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(endpoint, "/")+"/cached-commits", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := searchDoer.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var commits protocol.CachedCommits
+	if err := json.NewDecoder(resp.Body).Decode(&commits); err != nil {
+		return nil, err
+	}
+	return &commits, nil
+}
@@ -0,0 +1,83 @@
+package searcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+)
+
+func TestSearchEndpoints(t *testing.T) {
+	hashed := []string{"a", "b", "c", "d"}
+	cases := []struct {
+		name string
+		warm []string
+		want []string
+	}{
+		{name: "cold", want: []string{"a", "b", "c"}},
+		{name: "owner warm", warm: []string{"a"}, want: []string{"a", "b", "c"}},
+		{name: "replica warm", warm: []string{"c"}, want: []string{"c", "a", "b"}},
+		{name: "many warm", warm: []string{"b", "c", "d"}, want: []string{"b", "c", "d"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, searchEndpoints(hashed, tc.warm)); diff != "" {
+				t.Errorf("unexpected endpoints (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if diff := cmp.Diff([]string{"a"}, searchEndpoints([]string{"a"}, nil)); diff != "" {
+		t.Errorf("unexpected endpoints (-want +got):\n%s", diff)
+	}
+}
+
+func TestCachedCommits(t *testing.T) {
+	fetched := make(chan string, 2)
+	c := newCachedCommits()
+	c.fetch = func(ctx context.Context, endpoint string) (*protocol.CachedCommits, error) {
+		defer func() { fetched <- endpoint }()
+		if endpoint != "b" {
+			return &protocol.CachedCommits{}, nil
+		}
+		return &protocol.CachedCommits{Commits: []protocol.RepoCommit{{Repo: "r", Commit: "c"}}}, nil
+	}
+
+	// The first lookup doesn't know any commits yet, but starts refreshing.
+	if warm := c.Warm([]string{"a", "b"}, "r@c"); len(warm) != 0 {
+		t.Fatalf("got warm searchers %v before refresh", warm)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-fetched:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for refresh")
+		}
+	}
+
+	// Wait for the refreshes to be recorded.
+	for i := 0; ; i++ {
+		c.mu.Lock()
+		refreshing := len(c.refreshing)
+		c.mu.Unlock()
+		if refreshing == 0 {
+			break
+		}
+		if i == 500 {
+			t.Fatal("timed out waiting for refresh")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if diff := cmp.Diff([]string{"b"}, c.Warm([]string{"a", "b"}, "r@c")); diff != "" {
+		t.Errorf("unexpected warm searchers (-want +got):\n%s", diff)
+	}
+
+	c.Add("a", "r@c")
+	if diff := cmp.Diff([]string{"a", "b"}, c.Warm([]string{"a", "b"}, "r@c")); diff != "" {
+		t.Errorf("unexpected warm searchers (-want +got):\n%s", diff)
+	}
+}
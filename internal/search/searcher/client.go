@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -94,8 +95,9 @@ func Search(
 	}
 
 	// Searcher caches the file contents for repo@commit since it is
-	// relatively expensive to fetch from gitserver. So we use consistent
-	// hashing to increase cache hits.
+	// relatively expensive to fetch from gitserver. So we prefer searchers
+	// which advertise that they hold repo@commit, and otherwise use
+	// consistent hashing to increase cache hits.
 	consistentHashKey := string(repo) + "@" + string(commit)
 	tr.LazyPrintf("%s", consistentHashKey)
 
@@ -108,19 +110,23 @@ func Search(
 	if err != nil {
 		return false, err
 	}
+	urls = searchEndpoints(urls, warmSearchers.Warm(urls, consistentHashKey))
 
-	for attempt := 0; attempt < 2; attempt++ {
+	for attempt := 0; attempt < maxSearchAttempts; attempt++ {
 		url := urls[attempt%len(urls)]
 
 		tr.LazyPrintf("attempt %d: %s", attempt, url)
 		limitHit, err = textSearchStream(ctx, url, body, onMatches)
+		if err == nil {
+			warmSearchers.Add(url, consistentHashKey)
+		}
 		if err == nil || errcode.IsTimeout(err) {
 			return limitHit, err
 		}
 
 		// If we are canceled, return that error.
-		if err = ctx.Err(); err != nil {
-			return false, err
+		if ctx.Err() != nil {
+			return false, ctx.Err()
 		}
 
 		// If not temporary or our last attempt then don't try again.
@@ -134,6 +140,41 @@ func Search(
 	return false, err
 }
 
+// Prewarm asks the searcher which owns repo@commit to prepare its archive in
+// the background, so that later searches don't need to wait for the fetch.
+func Prewarm(ctx context.Context, searcherURLs *endpoint.Map, repo api.RepoName, commit api.CommitID) (err error) {
+	tr, ctx := trace.New(ctx, "searcher.prewarm", fmt.Sprintf("%s@%s", repo, commit))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	url, err := searcherURLs.Get(string(repo) + "@" + string(commit))
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(protocol.PrewarmRequest{Repo: repo, Commit: commit})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(url, "/")+"/prewarm", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	resp, err := searchDoer.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return errors.WithStack(&searcherError{StatusCode: resp.StatusCode, Message: string(body)})
+	}
+	return nil
+}
+
 func textSearchStream(ctx context.Context, url string, body []byte, cb func([]*protocol.FileMatch)) (bool, error) {
 	req, err := http.NewRequest("GET", url, bytes.NewReader(body))
 	if err != nil {
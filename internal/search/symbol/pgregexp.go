@@ -0,0 +1,192 @@
+package symbol
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// postgresRegexp translates a Go regular expression into an equivalent
+// Postgres regular expression (ARE), which is how the global symbols index is
+// searched. The two syntaxes agree on most patterns but differ in the details,
+// e.g. `\b` is a backspace in Postgres and `\y` a word boundary with a
+// different notion of word characters. Rather than risk returning different
+// results, ok is false for patterns whose meaning cannot be expressed exactly,
+// in which case the symbols service must be queried instead.
+//
+// Only whether a pattern matches is preserved, not which part of the input it
+// matches, so greediness is dropped.
+func postgresRegexp(pattern string) (_ string, ok bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if !writePostgresRegexp(&b, re) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// postgresMaxRepeat is the largest repetition count Postgres supports.
+const postgresMaxRepeat = 255
+
+func writePostgresRegexp(b *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return true
+
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && unicode.SimpleFold(r) != r {
+				b.WriteByte('[')
+				for f := r; ; {
+					writePostgresRune(b, f)
+					if f = unicode.SimpleFold(f); f == r {
+						break
+					}
+				}
+				b.WriteByte(']')
+			} else {
+				writePostgresLiteral(b, r)
+			}
+		}
+		return true
+
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		b.WriteByte('[')
+		for i := 0; i < len(re.Rune); i += 2 {
+			lo, hi := re.Rune[i], re.Rune[i+1]
+			if lo == 0 {
+				// Postgres text cannot contain NUL.
+				if hi == 0 {
+					continue
+				}
+				lo = 1
+			}
+			writePostgresRune(b, lo)
+			if hi != lo {
+				b.WriteByte('-')
+				writePostgresRune(b, hi)
+			}
+		}
+		b.WriteByte(']')
+		return true
+
+	case syntax.OpAnyCharNotNL:
+		b.WriteString(`[^\n]`)
+		return true
+
+	case syntax.OpAnyChar:
+		// Without the n flag, `.` matches newlines in Postgres.
+		b.WriteByte('.')
+		return true
+
+	case syntax.OpBeginText:
+		b.WriteByte('^')
+		return true
+
+	case syntax.OpEndText:
+		b.WriteByte('$')
+		return true
+
+	case syntax.OpCapture:
+		return writePostgresGroup(b, re.Sub[0])
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		if !writePostgresGroup(b, re.Sub[0]) {
+			return false
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteByte('*')
+		case syntax.OpPlus:
+			b.WriteByte('+')
+		case syntax.OpQuest:
+			b.WriteByte('?')
+		}
+		return true
+
+	case syntax.OpRepeat:
+		if re.Min > postgresMaxRepeat || re.Max > postgresMaxRepeat {
+			return false
+		}
+		if !writePostgresGroup(b, re.Sub[0]) {
+			return false
+		}
+		switch {
+		case re.Max == -1:
+			fmt.Fprintf(b, "{%d,}", re.Min)
+		case re.Min == re.Max:
+			fmt.Fprintf(b, "{%d}", re.Min)
+		default:
+			fmt.Fprintf(b, "{%d,%d}", re.Min, re.Max)
+		}
+		return true
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writePostgresRegexp(b, sub) {
+				return false
+			}
+		}
+		return true
+
+	case syntax.OpAlternate:
+		b.WriteString("(?:")
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			if !writePostgresRegexp(b, sub) {
+				return false
+			}
+		}
+		b.WriteByte(')')
+		return true
+
+	default:
+		// Multi-line anchors, word boundaries and patterns which never
+		// match have no exact equivalent.
+		return false
+	}
+}
+
+func writePostgresGroup(b *strings.Builder, re *syntax.Regexp) bool {
+	b.WriteString("(?:")
+	if !writePostgresRegexp(b, re) {
+		return false
+	}
+	b.WriteByte(')')
+	return true
+}
+
+// writePostgresLiteral writes r so that it matches itself outside of a
+// bracket expression.
+func writePostgresLiteral(b *strings.Builder, r rune) {
+	switch {
+	case r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9':
+		b.WriteRune(r)
+	case ' ' <= r && r <= '~':
+		// A backslash followed by a non-alphanumeric character is that
+		// character.
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	default:
+		writePostgresRune(b, r)
+	}
+}
+
+// writePostgresRune writes r as an escape, which has the same meaning inside
+// and outside of bracket expressions.
+func writePostgresRune(b *strings.Builder, r rune) {
+	if r <= 0xFFFF {
+		fmt.Fprintf(b, `\u%04x`, r)
+	} else {
+		fmt.Fprintf(b, `\U%08x`, r)
+	}
+}
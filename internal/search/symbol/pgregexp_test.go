@@ -0,0 +1,31 @@
+package symbol
+
+import "testing"
+
+func TestPostgresRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		ok      bool
+	}{
+		{pattern: `^Server$`, want: `^Server$`, ok: true},
+		{pattern: `a\.b`, want: `a\.b`, ok: true},
+		{pattern: `(Get|Set)Value`, want: `(?:(?:Get|Set))Value`, ok: true},
+		{pattern: `^get.*?x+$`, want: `^get(?:[^\n])*(?:x)+$`, ok: true},
+		{pattern: `a{2,3}b{4,}`, want: `(?:a){2,3}(?:b){4,}`, ok: true},
+		{pattern: `[a-c_]\d`, want: `[\u005f\u0061-\u0063][\u0030-\u0039]`, ok: true},
+		{pattern: `[^a]`, want: `[\u0001-\u0060\u0062-\U0010ffff]`, ok: true},
+		{pattern: `(?i)k`, want: `[\u004b\u006b\u212a]`, ok: true},
+		{pattern: `é`, want: `\u00e9`, ok: true},
+		{pattern: `\bServe`, ok: false},
+		{pattern: `(?m)^Serve`, ok: false},
+		{pattern: `a{256}`, ok: false},
+		{pattern: `[`, ok: false},
+	}
+	for _, tc := range tests {
+		got, ok := postgresRegexp(tc.pattern)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("postgresRegexp(%q) = %q, %t, want %q, %t", tc.pattern, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"regexp"
 	"regexp/syntax"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/RoaringBitmap/roaring"
 	"github.com/cockroachdb/errors"
+	"github.com/go-enry/go-enry/v2"
 	"github.com/google/zoekt"
 	zoektquery "github.com/google/zoekt/query"
 	"github.com/neelance/parallel"
@@ -19,8 +21,11 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/goroutine"
 	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/filter"
 	searchrepos "github.com/sourcegraph/sourcegraph/internal/search/repos"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
@@ -39,7 +44,7 @@ var MockSearchSymbols func(ctx context.Context, args *search.TextParameters, lim
 // it can be used for both search suggestions and search results
 //
 // May return partial results and an error
-func Search(ctx context.Context, args *search.TextParameters, notSearcherOnly, globalSearch bool, limit int, stream streaming.Sender) (err error) {
+func Search(ctx context.Context, db dbutil.DB, args *search.TextParameters, notSearcherOnly, globalSearch bool, limit int, stream streaming.Sender) (err error) {
 	if MockSearchSymbols != nil {
 		results, stats, err := MockSearchSymbols(ctx, args, limit)
 		stream.Send(streaming.SearchEvent{
@@ -66,10 +71,14 @@ func Search(ctx context.Context, args *search.TextParameters, notSearcherOnly, g
 	run := parallel.NewRun(conf.SearchSymbolsParallelism())
 
 	if notSearcherOnly {
+		zoektStream, err := withContainerFilter(stream, args.PatternInfo)
+		if err != nil {
+			return err
+		}
 		run.Acquire()
 		goroutine.Go(func() {
 			defer run.Release()
-			err := request.Search(ctx, stream)
+			err := request.Search(ctx, zoektStream)
 			if err != nil {
 				tr.LogFields(otlog.Error(err))
 				// Only record error if we haven't timed out.
@@ -81,7 +90,20 @@ func Search(ctx context.Context, args *search.TextParameters, notSearcherOnly, g
 		})
 	}
 
-	for _, repoRevs := range request.UnindexedRepos() {
+	unindexed := request.UnindexedRepos()
+	if args.Mode == search.ZoektGlobalSearch && conf.GlobalSymbolIndexEnabled() {
+		// Queries without repository filters search the default branch of
+		// every repository, which the global symbols index covers.
+		remaining, err := searchGlobalIndex(ctx, db, unindexed, args.PatternInfo, limit, stream)
+		if err != nil {
+			// Fall back to querying the symbols service for every repository.
+			tr.LogFields(otlog.String("globalIndex", "failed"), otlog.Error(err))
+		} else {
+			unindexed = remaining
+		}
+	}
+
+	for _, repoRevs := range unindexed {
 		repoRevs := repoRevs
 		if ctx.Err() != nil {
 			break
@@ -113,6 +135,219 @@ func Search(ctx context.Context, args *search.TextParameters, notSearcherOnly, g
 	return run.Wait()
 }
 
+// selectKinds returns the symbol kinds to push down to the symbols service for
+// a `select:symbol.<kind>` value. It returns nil if the select value does not
+// restrict the kind of symbols.
+func selectKinds(selectPath filter.SelectPath) []string {
+	if selectPath.Root() != filter.Symbol || len(selectPath) < 2 {
+		return nil
+	}
+	return result.CtagsKinds(selectPath[1])
+}
+
+// withContainerFilter returns a stream which drops the symbols whose parent
+// does not match the `container:` filter of patternInfo. Zoekt cannot filter
+// symbols by their parent, so the filter is applied to its results instead.
+func withContainerFilter(stream streaming.Sender, patternInfo *search.TextPatternInfo) (streaming.Sender, error) {
+	if patternInfo.SymbolContainer == "" {
+		return stream, nil
+	}
+	expr := patternInfo.SymbolContainer
+	if !patternInfo.IsCaseSensitive {
+		expr = "(?i:" + expr + ")"
+	}
+	container, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return streaming.StreamFunc(func(event streaming.SearchEvent) {
+		matches := make([]result.Match, 0, len(event.Results))
+		for _, match := range event.Results {
+			fm, ok := match.(*result.FileMatch)
+			if !ok {
+				matches = append(matches, match)
+				continue
+			}
+			symbols := make([]*result.SymbolMatch, 0, len(fm.Symbols))
+			for _, symbol := range fm.Symbols {
+				if container.MatchString(symbol.Symbol.Parent) {
+					symbols = append(symbols, symbol)
+				}
+			}
+			if len(symbols) > 0 {
+				fm.Symbols = symbols
+				matches = append(matches, fm)
+			}
+		}
+		event.Results = matches
+		stream.Send(event)
+	}), nil
+}
+
+// ctagsLanguages returns the languages to push down to the symbols service for
+// the values of `lang:` filters. Languages are named as reported by ctags,
+// which mostly agrees with the canonical names of go-enry.
+func ctagsLanguages(langs []string) []string {
+	if len(langs) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(langs))
+	for _, lang := range langs {
+		lang, _ = enry.GetLanguageByAlias(lang) // Invariant: lang is valid.
+		if ctagsLang, ok := enryToCtagsLanguage[lang]; ok {
+			lang = ctagsLang
+		}
+		res = append(res, lang)
+	}
+	return res
+}
+
+// enryToCtagsLanguage maps go-enry language names to the ctags language name
+// where the two differ.
+var enryToCtagsLanguage = map[string]string{
+	"Emacs Lisp": "EmacsLisp",
+	"Shell":      "Sh",
+	"Vim script": "Vim",
+}
+
+// exactSymbolName returns the symbol name matched by a pattern of the form
+// `^name$`, where name contains no regular expression metacharacters. Such
+// patterns are pushed down to the symbols service as an exact name match.
+func exactSymbolName(pattern string) (string, bool) {
+	if len(pattern) < 3 || !strings.HasPrefix(pattern, "^") || !strings.HasSuffix(pattern, "$") {
+		return "", false
+	}
+	name := pattern[1 : len(pattern)-1]
+	if regexp.QuoteMeta(name) != name {
+		return "", false
+	}
+	return name, true
+}
+
+// searchGlobalIndex searches the repositories in the global symbols index. It
+// returns the repositories which are not in the index and must be searched
+// with the symbols service.
+func searchGlobalIndex(ctx context.Context, db dbutil.DB, repos []*search.RepositoryRevisions, patternInfo *search.TextPatternInfo, limit int, stream streaming.Sender) (remaining []*search.RepositoryRevisions, err error) {
+	opts, ok := globalSymbolsSearchOptions(patternInfo)
+	if !ok {
+		// The patterns have no equivalent in Postgres, so the symbols
+		// service has to search every repository.
+		return repos, nil
+	}
+
+	defaultBranchRepos := make(map[api.RepoID]*search.RepositoryRevisions, len(repos))
+	repoIDs := make([]api.RepoID, 0, len(repos))
+	for _, repoRevs := range repos {
+		// The index only contains the default branch.
+		if revs := repoRevs.RevSpecs(); len(revs) == 1 && (revs[0] == "" || revs[0] == "HEAD") {
+			defaultBranchRepos[repoRevs.Repo.ID] = repoRevs
+			repoIDs = append(repoIDs, repoRevs.Repo.ID)
+		}
+	}
+	if len(repoIDs) == 0 {
+		return repos, nil
+	}
+
+	store := database.GlobalSymbols(db)
+	commits, err := store.IndexedCommits(ctx, repoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	indexedIDs := make([]api.RepoID, 0, len(commits))
+	for _, repoRevs := range repos {
+		if _, ok := commits[repoRevs.Repo.ID]; ok && defaultBranchRepos[repoRevs.Repo.ID] != nil {
+			indexedIDs = append(indexedIDs, repoRevs.Repo.ID)
+		} else {
+			remaining = append(remaining, repoRevs)
+		}
+	}
+	if len(indexedIDs) == 0 {
+		return remaining, nil
+	}
+
+	opts.RepoIDs = indexedIDs
+	// Ask for limit + 1 so we can detect whether there are more results than the limit.
+	opts.Limit = limit + 1
+	symbols, err := store.Search(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	limitHit := len(symbols) > limit
+	if limitHit {
+		symbols = symbols[:limit]
+	}
+
+	type fileKey struct {
+		repo api.RepoID
+		path string
+	}
+	fileMatches := make(map[fileKey]*result.FileMatch)
+	matches := make([]result.Match, 0, len(symbols))
+	for _, symbol := range symbols {
+		key := fileKey{repo: symbol.Repo.ID, path: symbol.Path}
+		fm, ok := fileMatches[key]
+		if !ok {
+			repoRevs := defaultBranchRepos[symbol.Repo.ID]
+			inputRev := repoRevs.RevSpecs()[0]
+			fm = &result.FileMatch{
+				File: result.File{
+					Path:     symbol.Path,
+					Repo:     repoRevs.Repo,
+					CommitID: symbol.CommitID,
+					InputRev: &inputRev,
+				},
+			}
+			fileMatches[key] = fm
+			matches = append(matches, fm)
+		}
+		fm.Symbols = append(fm.Symbols, &result.SymbolMatch{
+			File:   &fm.File,
+			Symbol: symbol.Symbol,
+		})
+	}
+
+	// Make the results deterministic
+	sort.Sort(result.Matches(matches))
+	stream.Send(streaming.SearchEvent{
+		Results: matches,
+		Stats:   streaming.Stats{IsLimitHit: limitHit},
+	})
+	return remaining, nil
+}
+
+// globalSymbolsSearchOptions returns the options to search the global symbols
+// index for patternInfo. ok is false if a pattern cannot be translated to the
+// regular expression syntax of Postgres.
+func globalSymbolsSearchOptions(patternInfo *search.TextPatternInfo) (opts database.GlobalSymbolsSearchOptions, ok bool) {
+	translate := func(pattern string) string {
+		if pattern == "" || !ok {
+			return ""
+		}
+		pattern, ok = postgresRegexp(pattern)
+		return pattern
+	}
+
+	ok = true
+	pattern := patternInfo.Pattern
+	if !patternInfo.IsRegExp {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	opts = database.GlobalSymbolsSearchOptions{
+		Pattern:         translate(pattern),
+		IsCaseSensitive: patternInfo.IsCaseSensitive,
+		ExcludePattern:  translate(patternInfo.ExcludePattern),
+		Kinds:           selectKinds(patternInfo.Select),
+		Container:       translate(patternInfo.SymbolContainer),
+	}
+	for _, p := range patternInfo.IncludePatterns {
+		opts.IncludePatterns = append(opts.IncludePatterns, translate(p))
+	}
+	return opts, ok
+}
+
 func searchInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions, patternInfo *search.TextPatternInfo, limit int) (res []result.Match, err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "Search symbols in repo")
 	defer func() {
@@ -136,14 +371,27 @@ func searchInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions, pat
 	}
 	span.SetTag("commit", string(commitID))
 
+	pattern := patternInfo.Pattern
+	exactName, isExactName := "", false
+	if patternInfo.IsRegExp {
+		exactName, isExactName = exactSymbolName(pattern)
+	}
+	if isExactName {
+		pattern = exactName
+	}
+
 	symbols, err := backend.Symbols.ListTags(ctx, search.SymbolsParameters{
 		Repo:            repoRevs.Repo.Name,
 		CommitID:        commitID,
-		Query:           patternInfo.Pattern,
+		Query:           pattern,
 		IsCaseSensitive: patternInfo.IsCaseSensitive,
 		IsRegExp:        patternInfo.IsRegExp,
 		IncludePatterns: patternInfo.IncludePatterns,
 		ExcludePattern:  patternInfo.ExcludePattern,
+		IsExactName:     isExactName,
+		Kinds:           selectKinds(patternInfo.Select),
+		Languages:       ctagsLanguages(patternInfo.Languages),
+		Container:       patternInfo.SymbolContainer,
 		// Ask for limit + 1 so we can detect whether there are more results than the limit.
 		First: limit + 1,
 	})
@@ -0,0 +1,260 @@
+package symbol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neelance/parallel"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/filter"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/internal/symbols"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestSearchGlobalIndex(t *testing.T) {
+	repoRevs := func(id api.RepoID, revs ...string) *search.RepositoryRevisions {
+		rr := &search.RepositoryRevisions{Repo: types.RepoName{ID: id, Name: api.RepoName(fmt.Sprintf("r%d", id))}}
+		for _, rev := range revs {
+			rr.Revs = append(rr.Revs, search.RevisionSpecifier{RevSpec: rev})
+		}
+		return rr
+	}
+	repos := []*search.RepositoryRevisions{
+		repoRevs(1, ""),       // indexed
+		repoRevs(2, ""),       // not indexed
+		repoRevs(3, "branch"), // indexed, but not searched at the default branch
+	}
+
+	database.Mocks.GlobalSymbols.IndexedCommits = func(ctx context.Context, repoIDs []api.RepoID) (map[api.RepoID]api.CommitID, error) {
+		if diff := cmp.Diff([]api.RepoID{1, 2}, repoIDs); diff != "" {
+			t.Errorf("unexpected repo IDs (-want +got):\n%s", diff)
+		}
+		return map[api.RepoID]api.CommitID{1: "c1"}, nil
+	}
+	database.Mocks.GlobalSymbols.Search = func(ctx context.Context, opts database.GlobalSymbolsSearchOptions) ([]database.GlobalSymbol, error) {
+		want := database.GlobalSymbolsSearchOptions{
+			RepoIDs: []api.RepoID{1},
+			Pattern: `a\.b`,
+			Kinds:   result.CtagsKinds("function"),
+			Limit:   3,
+		}
+		if diff := cmp.Diff(want, opts); diff != "" {
+			t.Errorf("unexpected options (-want +got):\n%s", diff)
+		}
+		repo := types.RepoName{ID: 1, Name: "r1"}
+		return []database.GlobalSymbol{
+			{Repo: repo, CommitID: "c1", Symbol: result.Symbol{Name: "a.b1", Path: "x.go"}},
+			{Repo: repo, CommitID: "c1", Symbol: result.Symbol{Name: "a.b2", Path: "x.go"}},
+			{Repo: repo, CommitID: "c1", Symbol: result.Symbol{Name: "a.b3", Path: "y.go"}},
+		}, nil
+	}
+	t.Cleanup(func() { database.Mocks.GlobalSymbols = database.MockGlobalSymbols{} })
+
+	var events []streaming.SearchEvent
+	stream := streaming.StreamFunc(func(e streaming.SearchEvent) { events = append(events, e) })
+
+	remaining, err := searchGlobalIndex(context.Background(), nil, repos, &search.TextPatternInfo{
+		Pattern: "a.b",
+		Select:  filter.SelectPath{filter.Symbol, "function"},
+	}, 2, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]*search.RepositoryRevisions{repos[1], repos[2]}, remaining); diff != "" {
+		t.Errorf("unexpected remaining repos (-want +got):\n%s", diff)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if !events[0].Stats.IsLimitHit {
+		t.Error("expected limit to be hit")
+	}
+	var got []string
+	for _, m := range events[0].Results {
+		fm := m.(*result.FileMatch)
+		for _, s := range fm.Symbols {
+			got = append(got, string(fm.Repo.Name)+"@"+string(fm.CommitID)+"/"+fm.Path+":"+s.Symbol.Name)
+		}
+	}
+	if diff := cmp.Diff([]string{"r1@c1/x.go:a.b1", "r1@c1/x.go:a.b2"}, got); diff != "" {
+		t.Errorf("unexpected results (-want +got):\n%s", diff)
+	}
+}
+
+func TestSearchGlobalIndexUntranslatablePattern(t *testing.T) {
+	database.Mocks.GlobalSymbols.IndexedCommits = func(ctx context.Context, repoIDs []api.RepoID) (map[api.RepoID]api.CommitID, error) {
+		t.Error("unexpected call to IndexedCommits")
+		return nil, nil
+	}
+	database.Mocks.GlobalSymbols.Search = func(ctx context.Context, opts database.GlobalSymbolsSearchOptions) ([]database.GlobalSymbol, error) {
+		t.Error("unexpected call to Search")
+		return nil, nil
+	}
+	t.Cleanup(func() { database.Mocks.GlobalSymbols = database.MockGlobalSymbols{} })
+
+	repos := []*search.RepositoryRevisions{{
+		Repo: types.RepoName{ID: 1, Name: "r1"},
+		Revs: []search.RevisionSpecifier{{RevSpec: ""}},
+	}}
+	stream := streaming.StreamFunc(func(e streaming.SearchEvent) { t.Errorf("unexpected event %+v", e) })
+
+	// Postgres has no word boundary with the semantics of Go.
+	remaining, err := searchGlobalIndex(context.Background(), nil, repos, &search.TextPatternInfo{
+		Pattern:  `\bserve\b`,
+		IsRegExp: true,
+	}, 10, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(repos, remaining); diff != "" {
+		t.Errorf("unexpected remaining repos (-want +got):\n%s", diff)
+	}
+}
+
+func TestSearchInRepoFilters(t *testing.T) {
+	git.Mocks.ResolveRevision = func(spec string, opt git.ResolveRevisionOptions) (api.CommitID, error) {
+		return "c1", nil
+	}
+	t.Cleanup(func() { git.Mocks.ResolveRevision = nil })
+
+	var got search.SymbolsParameters
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		_ = json.NewEncoder(w).Encode(result.Symbols{})
+	}))
+	defer srv.Close()
+
+	orig := symbols.DefaultClient
+	symbols.DefaultClient = &symbols.Client{URL: srv.URL, HTTPClient: http.DefaultClient, HTTPLimiter: parallel.NewRun(1)}
+	t.Cleanup(func() { symbols.DefaultClient = orig })
+
+	tests := []struct {
+		name        string
+		patternInfo *search.TextPatternInfo
+		want        search.SymbolsParameters
+	}{{
+		name: "exact name with kind and language",
+		patternInfo: &search.TextPatternInfo{
+			Pattern:   "^Server$",
+			IsRegExp:  true,
+			Select:    filter.SelectPath{filter.Symbol, "struct"},
+			Languages: []string{"golang"},
+		},
+		want: search.SymbolsParameters{
+			Query:       "Server",
+			IsRegExp:    true,
+			IsExactName: true,
+			Kinds:       result.CtagsKinds("struct"),
+			Languages:   []string{"Go"},
+		},
+	}, {
+		name: "anchored regexp is not an exact name",
+		patternInfo: &search.TextPatternInfo{
+			Pattern:   "^Serve.*$",
+			IsRegExp:  true,
+			Languages: []string{"shell"},
+		},
+		want: search.SymbolsParameters{
+			Query:     "^Serve.*$",
+			IsRegExp:  true,
+			Languages: []string{"Sh"},
+		},
+	}, {
+		name: "container",
+		patternInfo: &search.TextPatternInfo{
+			Pattern:         "serve",
+			IsRegExp:        true,
+			SymbolContainer: "^Server$",
+		},
+		want: search.SymbolsParameters{
+			Query:     "serve",
+			IsRegExp:  true,
+			Container: "^Server$",
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repoRevs := &search.RepositoryRevisions{
+				Repo: types.RepoName{ID: 1, Name: "r1"},
+				Revs: []search.RevisionSpecifier{{RevSpec: ""}},
+			}
+			if _, err := searchInRepo(context.Background(), repoRevs, tc.patternInfo, 10); err != nil {
+				t.Fatal(err)
+			}
+
+			tc.want.Repo = "r1"
+			tc.want.CommitID = "c1"
+			tc.want.First = 11
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected symbols parameters (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithContainerFilter(t *testing.T) {
+	file := result.File{Path: "server.go"}
+	symbol := func(name, parent string) *result.SymbolMatch {
+		return &result.SymbolMatch{File: &file, Symbol: result.Symbol{Name: name, Parent: parent}}
+	}
+
+	tests := []struct {
+		name        string
+		patternInfo *search.TextPatternInfo
+		want        []string
+	}{
+		{
+			name:        "case insensitive",
+			patternInfo: &search.TextPatternInfo{SymbolContainer: "^server$"},
+			want:        []string{"Server.Serve", "server.Serve"},
+		},
+		{
+			name:        "case sensitive",
+			patternInfo: &search.TextPatternInfo{SymbolContainer: "^server$", IsCaseSensitive: true},
+			want:        []string{"server.Serve"},
+		},
+		{
+			name:        "no match",
+			patternInfo: &search.TextPatternInfo{SymbolContainer: "^Client$"},
+			want:        nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			stream, err := withContainerFilter(streaming.StreamFunc(func(event streaming.SearchEvent) {
+				for _, match := range event.Results {
+					for _, s := range match.(*result.FileMatch).Symbols {
+						got = append(got, s.Symbol.Parent+"."+s.Symbol.Name)
+					}
+				}
+			}), tc.patternInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream.Send(streaming.SearchEvent{Results: []result.Match{
+				&result.FileMatch{File: file, Symbols: []*result.SymbolMatch{symbol("Serve", "Server"), symbol("Serve", "server")}},
+				&result.FileMatch{File: file, Symbols: []*result.SymbolMatch{symbol("Close", "Conn")}},
+			}})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected symbols (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -86,6 +86,25 @@ type SymbolsParameters struct {
 	// need to match to get included in the result
 	ExcludePattern string
 
+	// IsExactName if true will match Query against symbol names literally
+	// and in full, instead of as a regular expression.
+	IsExactName bool
+
+	// Kinds is an optional list of symbol kinds (as reported by ctags, e.g.
+	// "func" or "struct"). If non-empty, only symbols of one of these kinds
+	// are returned. Kinds are compared case insensitively.
+	Kinds []string
+
+	// Languages is an optional list of languages (as reported by ctags, e.g.
+	// "Go"). If non-empty, only symbols of one of these languages are
+	// returned. Languages are compared case insensitively.
+	Languages []string
+
+	// Container is an optional regex that the name of the symbol's parent
+	// (e.g. the type of a method) needs to match to get included in the
+	// result.
+	Container string
+
 	// First indicates that only the first n symbols should be returned.
 	First int
 }
@@ -235,6 +254,10 @@ type TextPatternInfo struct {
 	PatternMatchesPath    bool
 
 	Languages []string
+
+	// SymbolContainer is the value of the `container:` filter, a regex that
+	// the parent of a symbol needs to match in symbol search.
+	SymbolContainer string
 }
 
 func (p *TextPatternInfo) String() string {
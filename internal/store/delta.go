@@ -0,0 +1,145 @@
+package store
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"io"
+
+	"github.com/google/zoekt/ignore"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/diskcache"
+)
+
+const (
+	// deltaMaxAncestors is the number of ancestors of a commit which are
+	// checked for a cached zip.
+	deltaMaxAncestors = 20
+
+	// deltaMaxChangedFiles is the maximum number of files changed since the
+	// nearest cached ancestor for which we fetch only the changed files.
+	// Beyond it we fetch the whole archive, which is cheaper than asking
+	// gitserver for many paths.
+	deltaMaxChangedFiles = 1000
+)
+
+// delta is the input for building the zip of a commit from the cached zip of
+// an ancestor commit.
+type delta struct {
+	// base is the ancestor commit.
+	base api.CommitID
+
+	// baseZip is the cached zip of base.
+	baseZip *diskcache.File
+
+	// skip is the set of files in baseZip which were changed or deleted
+	// since base.
+	skip map[string]struct{}
+
+	// changed is a tar archive of the files changed since base.
+	changed io.ReadCloser
+}
+
+// fetchDelta looks for the cached zip of the nearest ancestor of commit and
+// fetches the files changed since. ok is false if the store does not support
+// delta fetches, if no ancestor zip is cached or if anything fails, in which
+// case the whole archive should be fetched instead.
+func (s *Store) fetchDelta(ctx context.Context, repo api.RepoName, commit api.CommitID, largeFilePatterns []string) (_ *delta, ok bool) {
+	if s.FetchTarPaths == nil || s.Ancestors == nil || s.ChangedFiles == nil {
+		return nil, false
+	}
+
+	ancestors, err := s.Ancestors(ctx, repo, commit, deltaMaxAncestors)
+	if err != nil {
+		log15.Warn("failed to list ancestors for delta fetch", "repo", repo, "commit", commit, "error", err)
+		return nil, false
+	}
+
+	d := &delta{}
+	for _, ancestor := range ancestors {
+		if f, ok := s.cache.Peek(cacheKey(repo, ancestor, largeFilePatterns)); ok {
+			d.base, d.baseZip = ancestor, f
+			break
+		}
+	}
+	if d.baseZip == nil {
+		return nil, false
+	}
+
+	changed, deleted, err := s.ChangedFiles(ctx, repo, d.base, commit)
+	if err != nil {
+		log15.Warn("failed to list changed files for delta fetch", "repo", repo, "base", d.base, "commit", commit, "error", err)
+		d.Close()
+		return nil, false
+	}
+
+	d.skip = make(map[string]struct{}, len(changed)+len(deleted))
+	for _, paths := range [][]string{changed, deleted} {
+		for _, path := range paths {
+			// A change to the ignore file changes which of the unchanged
+			// files are filtered out, so the zip of base can't be reused.
+			if path == ignore.IgnoreFile {
+				d.Close()
+				return nil, false
+			}
+			d.skip[path] = struct{}{}
+		}
+	}
+	if len(d.skip) > deltaMaxChangedFiles {
+		d.Close()
+		return nil, false
+	}
+
+	if len(changed) > 0 {
+		d.changed, err = s.FetchTarPaths(ctx, repo, commit, changed)
+		if err != nil {
+			log15.Warn("failed to fetch changed files for delta fetch", "repo", repo, "commit", commit, "error", err)
+			d.Close()
+			return nil, false
+		}
+	}
+
+	return d, true
+}
+
+// writeZip writes the zip of the commit to zw. It copies the files of the zip
+// of base which did not change and then the changed files.
+func (d *delta) writeZip(zw *zip.Writer, largeFilePatterns []string, filter FilterFunc) error {
+	fi, err := d.baseZip.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(d.baseZip, fi.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range zr.File {
+		if _, ok := d.skip[f.Name]; ok {
+			continue
+		}
+		// FilterTar is computed per commit, so apply the filter of the
+		// commit to the files of base as well.
+		if filter(&tar.Header{Name: f.Name, Size: int64(f.UncompressedSize64), Typeflag: tar.TypeReg}) {
+			continue
+		}
+		if err := zw.Copy(f); err != nil {
+			return err
+		}
+	}
+
+	if d.changed == nil {
+		return nil
+	}
+	return copySearchable(tar.NewReader(d.changed), zw, largeFilePatterns, filter)
+}
+
+// Close releases the zip of base and the archive of changed files.
+func (d *delta) Close() error {
+	if d.changed != nil {
+		d.changed.Close()
+	}
+	return d.baseZip.Close()
+}
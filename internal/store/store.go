@@ -61,6 +61,21 @@ type Store struct {
 	// FilterTar returns a FilterFunc that filters out files we don't want to write to disk
 	FilterTar func(ctx context.Context, repo api.RepoName, commit api.CommitID) (FilterFunc, error)
 
+	// FetchTarPaths returns an io.ReadCloser to a tar archive of only the given paths of a
+	// repository at the specified commit ID.
+	//
+	// If FetchTarPaths, Ancestors and ChangedFiles are all set, the zip of a commit is built
+	// from the cached zip of its nearest ancestor and the files changed since, instead of
+	// fetching the whole archive with FetchTar.
+	FetchTarPaths func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error)
+
+	// Ancestors returns up to n ancestors of commit, nearest first.
+	Ancestors func(ctx context.Context, repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error)
+
+	// ChangedFiles returns the paths of the files which were added or modified (changed) and
+	// the paths of the files which were deleted between base and head.
+	ChangedFiles func(ctx context.Context, repo api.RepoName, base, head api.CommitID) (changed, deleted []string, err error)
+
 	// Path is the directory to store the cache
 	Path string
 
@@ -79,10 +94,24 @@ type Store struct {
 	// fetchLimiter limits concurrent calls to FetchTar.
 	fetchLimiter *mutablelimiter.Limiter
 
+	// cachedMu protects cached.
+	cachedMu sync.Mutex
+
+	// cached maps the path of each zip prepared since Start to the commit it
+	// holds. Zips left on disk by a previous process are not included, since
+	// their paths are hashes of the commits.
+	cached map[string]CachedCommit
+
 	// ZipCache provides efficient access to repo zip files.
 	ZipCache ZipCache
 }
 
+// CachedCommit is a commit of a repository whose zip is in the cache.
+type CachedCommit struct {
+	Repo   api.RepoName
+	Commit api.CommitID
+}
+
 // FilterFunc filters tar files based on their header.
 // Tar files for which FilterFunc evaluates to true
 // are not stored in the target zip.
@@ -94,11 +123,12 @@ type FilterFunc func(hdr *tar.Header) bool
 func (s *Store) Start() {
 	s.once.Do(func() {
 		s.fetchLimiter = mutablelimiter.New(15)
+		s.cached = make(map[string]CachedCommit)
 		s.cache = &diskcache.Store{
 			Dir:               s.Path,
 			Component:         "store",
 			BackgroundTimeout: 10 * time.Minute,
-			BeforeEvict:       s.ZipCache.delete,
+			BeforeEvict:       s.beforeEvict,
 		}
 		_ = os.MkdirAll(s.Path, 0700)
 		metrics.MustRegisterDiskMonitor(s.Path)
@@ -131,9 +161,7 @@ func (s *Store) PrepareZip(ctx context.Context, repo api.RepoName, commit api.Co
 
 	largeFilePatterns := conf.Get().SearchLargeFiles
 
-	// key is a sha256 hash since we want to use it for the disk name
-	h := sha256.Sum256([]byte(fmt.Sprintf("%q %q %q", repo, commit, largeFilePatterns)))
-	key := hex.EncodeToString(h[:])
+	key := cacheKey(repo, commit, largeFilePatterns)
 	span.LogKV("key", key)
 
 	// Our fetch can take a long time, and the frontend aggressively cancels
@@ -148,15 +176,25 @@ func (s *Store) PrepareZip(ctx context.Context, repo api.RepoName, commit api.Co
 		// TODO: consider adding a cache method that doesn't actually bother opening the file,
 		// since we're just going to close it again immediately.
 		bgctx := opentracing.ContextWithSpan(context.Background(), opentracing.SpanFromContext(ctx))
+		fetched := false
 		f, err := s.cache.Open(bgctx, key, func(ctx context.Context) (io.ReadCloser, error) {
+			fetched = true
 			return s.fetch(ctx, repo, commit, largeFilePatterns)
 		})
+		if fetched {
+			cacheMisses.Inc()
+		} else if err == nil {
+			cacheHits.Inc()
+		}
 		var path string
 		if f != nil {
 			path = f.Path
 			if f.File != nil {
 				f.File.Close()
 			}
+			s.cachedMu.Lock()
+			s.cached[path] = CachedCommit{Repo: repo, Commit: commit}
+			s.cachedMu.Unlock()
 		}
 		if err != nil {
 			log15.Error("failed to fetch archive", "repo", repo, "commit", commit, "duration", time.Since(start), "error", err)
@@ -176,6 +214,13 @@ func (s *Store) PrepareZip(ctx context.Context, repo api.RepoName, commit api.Co
 	}
 }
 
+// cacheKey returns the key of the zip of repo at commit in the disk cache. It
+// is a sha256 hash since we want to use it for the disk name.
+func cacheKey(repo api.RepoName, commit api.CommitID, largeFilePatterns []string) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%q %q %q", repo, commit, largeFilePatterns)))
+	return hex.EncodeToString(h[:])
+}
+
 // fetch fetches an archive from the network and stores it on disk. It does
 // not populate the in-memory cache. You should probably be calling
 // prepareZip.
@@ -222,11 +267,6 @@ func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitI
 		}
 	}()
 
-	r, err := s.FetchTar(ctx, repo, commit)
-	if err != nil {
-		return nil, err
-	}
-
 	filter := func(hdr *tar.Header) bool { return false } // default: don't filter
 	if s.FilterTar != nil {
 		filter, err = s.FilterTar(ctx, repo, commit)
@@ -235,19 +275,38 @@ func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitI
 		}
 	}
 
+	// Prefer building the zip from the cached zip of an ancestor, since
+	// that only transfers the files which changed since.
+	var writeZip func(zw *zip.Writer) error
+	if delta, ok := s.fetchDelta(ctx, repo, commit, largeFilePatterns); ok {
+		deltaFetches.Inc()
+		span.SetTag("base", delta.base)
+		writeZip = func(zw *zip.Writer) error {
+			defer delta.Close()
+			return delta.writeZip(zw, largeFilePatterns, filter)
+		}
+	} else {
+		r, err := s.FetchTar(ctx, repo, commit)
+		if err != nil {
+			return nil, err
+		}
+		writeZip = func(zw *zip.Writer) error {
+			defer r.Close()
+			return copySearchable(tar.NewReader(r), zw, largeFilePatterns, filter)
+		}
+	}
+
 	pr, pw := io.Pipe()
 
 	// After this point we are not allowed to return an error. Instead we can
 	// return an error via the reader we return. If you do want to update this
 	// code please ensure we still always call done once.
 
-	// Write tr to zw. Return the first error encountered, but clean up if
-	// we encounter an error.
+	// Write the zip to pw. Return the first error encountered, but clean up
+	// if we encounter an error.
 	go func() {
-		defer r.Close()
-		tr := tar.NewReader(r)
 		zw := zip.NewWriter(pw)
-		err := copySearchable(tr, zw, largeFilePatterns, filter)
+		err := writeZip(zw)
 		if err1 := zw.Close(); err == nil {
 			err = err1
 		}
@@ -339,6 +398,29 @@ func copySearchable(tr *tar.Reader, zw *zip.Writer, largeFilePatterns []string,
 	}
 }
 
+// CachedCommits returns the commits whose zips were prepared since Start and
+// are still in the cache.
+func (s *Store) CachedCommits() []CachedCommit {
+	s.Start()
+
+	s.cachedMu.Lock()
+	defer s.cachedMu.Unlock()
+	commits := make([]CachedCommit, 0, len(s.cached))
+	for _, c := range s.cached {
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// beforeEvict is called by the disk cache before it deletes the zip at path.
+func (s *Store) beforeEvict(path string) {
+	s.cachedMu.Lock()
+	delete(s.cached, path)
+	s.cachedMu.Unlock()
+
+	s.ZipCache.delete(path)
+}
+
 func (s *Store) String() string {
 	return "Store(" + s.Path + ")"
 }
@@ -410,6 +492,18 @@ var (
 		Name: "searcher_store_fetch_failed",
 		Help: "The total number of archive fetches that failed.",
 	})
+	cacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_store_cache_hits",
+		Help: "The total number of archives found in the on disk cache.",
+	})
+	cacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_store_cache_misses",
+		Help: "The total number of archives missing from the on disk cache.",
+	})
+	deltaFetches = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_store_delta_fetches",
+		Help: "The total number of archives built from the cached archive of an ancestor commit.",
+	})
 )
 
 // temporaryError wraps an error but adds the Temporary method. It does not
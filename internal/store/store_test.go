@@ -2,15 +2,18 @@ package store
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"context"
 	"io"
 	"os"
+	"sort"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
@@ -117,6 +120,81 @@ func TestPrepareZip_errHeader(t *testing.T) {
 	}
 }
 
+func TestPrepareZip_delta(t *testing.T) {
+	s, cleanup := tmpStore(t)
+	defer cleanup()
+
+	base := api.CommitID("deadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+	head := api.CommitID("cafebabecafebabecafebabecafebabecafebabe")
+
+	var fetchTarCalled int64
+	s.FetchTar = func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+		atomic.AddInt64(&fetchTarCalled, 1)
+		if commit != base {
+			t.Errorf("fetched whole archive of %s", commit)
+		}
+		return tarArchive(t, map[string]string{"a": "a", "b": "b", "c": "c"}), nil
+	}
+	s.Ancestors = func(ctx context.Context, repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error) {
+		return []api.CommitID{"0000000000000000000000000000000000000000", base}, nil
+	}
+	s.ChangedFiles = func(ctx context.Context, repo api.RepoName, b, h api.CommitID) (changed, deleted []string, err error) {
+		if b != base || h != head {
+			t.Errorf("got changed files of %s..%s", b, h)
+		}
+		return []string{"b", "d"}, []string{"c"}, nil
+	}
+	var gotPaths []string
+	s.FetchTarPaths = func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+		gotPaths = paths
+		return tarArchive(t, map[string]string{"b": "b2", "d": "d"}), nil
+	}
+
+	if _, err := s.PrepareZip(context.Background(), "foo", base); err != nil {
+		t.Fatal(err)
+	}
+	path, err := s.PrepareZip(context.Background(), "foo", head)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fetchTarCalled := atomic.LoadInt64(&fetchTarCalled); fetchTarCalled != 1 {
+		t.Errorf("got %d whole archive fetches, want 1", fetchTarCalled)
+	}
+	if diff := cmp.Diff([]string{"b", "d"}, gotPaths); diff != "" {
+		t.Errorf("unexpected fetched paths (-want +got):\n%s", diff)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	got := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[f.Name] = string(b)
+	}
+	if diff := cmp.Diff(map[string]string{"a": "a", "b": "b2", "d": "d"}, got); diff != "" {
+		t.Errorf("unexpected zip contents (-want +got):\n%s", diff)
+	}
+
+	cached := s.CachedCommits()
+	sort.Slice(cached, func(i, j int) bool { return cached[i].Commit < cached[j].Commit })
+	wantCached := []CachedCommit{{Repo: "foo", Commit: head}, {Repo: "foo", Commit: base}}
+	if diff := cmp.Diff(wantCached, cached); diff != "" {
+		t.Errorf("unexpected cached commits (-want +got):\n%s", diff)
+	}
+}
+
 func TestIngoreSizeMax(t *testing.T) {
 	patterns := []string{
 		"foo",
@@ -158,6 +236,30 @@ func tmpStore(t *testing.T) (*Store, func()) {
 	}, func() { os.RemoveAll(d) }
 }
 
+func tarArchive(t *testing.T, files map[string]string) io.ReadCloser {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	w := tar.NewWriter(buf)
+	for _, name := range names {
+		body := files[name]
+		if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return io.NopCloser(bytes.NewReader(buf.Bytes()))
+}
+
 func emptyTar(t *testing.T) io.ReadCloser {
 	buf := new(bytes.Buffer)
 	w := tar.NewWriter(buf)
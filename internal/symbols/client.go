@@ -107,6 +107,41 @@ func (c *Client) Search(ctx context.Context, args search.SymbolsParameters) (res
 	return result, err
 }
 
+// Prewarm asks the symbols service to build the symbols of repo@commitID in
+// the background, so that later searches don't need to wait for them.
+func (c *Client) Prewarm(ctx context.Context, repo api.RepoName, commitID api.CommitID) (err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "symbols.Client.Prewarm")
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+	span.SetTag("Repo", string(repo))
+	span.SetTag("CommitID", string(commitID))
+
+	resp, err := c.httpPost(ctx, "prewarm", key{repo: repo, commitID: commitID}, search.SymbolsParameters{
+		Repo:     repo,
+		CommitID: commitID,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		// best-effort inclusion of body in error message
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return errors.Errorf(
+			"Symbol.Prewarm http status %d: %s",
+			resp.StatusCode,
+			string(body),
+		)
+	}
+	return nil
+}
+
 func (c *Client) httpPost(
 	ctx context.Context,
 	method string,
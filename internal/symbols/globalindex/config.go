@@ -0,0 +1,23 @@
+package globalindex
+
+import (
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+type config struct {
+	env.BaseConfig
+
+	Interval          time.Duration
+	BatchSize         int
+	MaxSymbolsPerRepo int
+}
+
+var configInst = &config{}
+
+func (c *config) Load() {
+	c.Interval = c.GetInterval("GLOBAL_SYMBOLS_INDEXING_INTERVAL", "1m", "How frequently to look for repositories whose symbols need to be indexed.")
+	c.BatchSize = c.GetInt("GLOBAL_SYMBOLS_INDEXING_BATCH_SIZE", "50", "The maximum number of repositories to index per interval.")
+	c.MaxSymbolsPerRepo = c.GetInt("GLOBAL_SYMBOLS_MAX_PER_REPO", "100000", "The maximum number of symbols to index per repository. Repositories with more symbols are searched with the symbols service instead.")
+}
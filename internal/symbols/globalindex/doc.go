@@ -0,0 +1,8 @@
+// Package globalindex keeps the global symbols index up to date.
+//
+// The index stores the symbols on the default branch of every cloned
+// repository in Postgres (see database.GlobalSymbolsStore), so that symbol
+// searches without repository filters do not need to query the symbols service
+// once per repository. A repository is reindexed after gitserver records a
+// change to it, which is tracked by gitserver_repos.last_changed.
+package globalindex
@@ -0,0 +1,99 @@
+package globalindex
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+var indexedRepos = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_global_symbols_indexed_repos_total",
+	Help: "The number of repositories processed by the global symbols indexer, by result.",
+}, []string{"result"})
+
+// indexer reindexes the symbols of repositories whose default branch changed
+// since they were last indexed.
+type indexer struct {
+	store       database.GlobalSymbolsStore
+	resolveHEAD func(ctx context.Context, repo api.RepoName) (api.CommitID, error)
+	listSymbols func(ctx context.Context, repo api.RepoName, commitID api.CommitID, limit int) ([]result.Symbol, error)
+
+	batchSize         int
+	maxSymbolsPerRepo int
+}
+
+var _ goroutine.Handler = &indexer{}
+
+func (i *indexer) Handle(ctx context.Context) error {
+	if !conf.GlobalSymbolIndexEnabled() {
+		return nil
+	}
+
+	repos, err := i.store.ListStale(ctx, i.batchSize)
+	if err != nil {
+		return errors.Wrap(err, "ListStale")
+	}
+
+	var errs *multierror.Error
+	for _, repo := range repos {
+		if err := i.index(ctx, repo); err != nil {
+			indexedRepos.WithLabelValues("error").Inc()
+			errs = multierror.Append(errs, errors.Wrapf(err, "indexing symbols of %s", repo.Repo.Name))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+func (i *indexer) index(ctx context.Context, repo database.StaleGlobalSymbolsRepo) error {
+	commitID, err := i.resolveHEAD(ctx, repo.Repo.Name)
+	if err != nil {
+		if !errcode.IsNotFound(err) {
+			return err
+		}
+		// The repository is empty, so it has no symbols.
+		commitID = ""
+	}
+
+	if commitID == repo.CommitID && repo.CommitID != "" {
+		indexedRepos.WithLabelValues("unchanged").Inc()
+		return i.store.MarkIndexed(ctx, repo.Repo.ID)
+	}
+
+	var symbols []result.Symbol
+	if commitID != "" {
+		// Ask for one more symbol than we store to detect whether the
+		// repository has more symbols than that.
+		symbols, err = i.listSymbols(ctx, repo.Repo.Name, commitID, i.maxSymbolsPerRepo+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	// An incomplete set of symbols would make searches silently miss
+	// results, so truncated repositories are recorded without symbols and
+	// searched with the symbols service instead.
+	truncated := len(symbols) > i.maxSymbolsPerRepo
+	if truncated {
+		symbols = nil
+	}
+
+	if err := i.store.Replace(ctx, repo.Repo.ID, commitID, symbols, truncated); err != nil {
+		return err
+	}
+	if truncated {
+		indexedRepos.WithLabelValues("truncated").Inc()
+	} else {
+		indexedRepos.WithLabelValues("indexed").Inc()
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package globalindex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+type fakeStore struct {
+	database.GlobalSymbolsStore
+
+	stale     []database.StaleGlobalSymbolsRepo
+	replaced  map[api.RepoName]api.CommitID
+	symbols   map[api.RepoName][]result.Symbol
+	truncated []api.RepoName
+	marked    []api.RepoName
+}
+
+func (s *fakeStore) ListStale(ctx context.Context, limit int) ([]database.StaleGlobalSymbolsRepo, error) {
+	if len(s.stale) > limit {
+		return s.stale[:limit], nil
+	}
+	return s.stale, nil
+}
+
+func (s *fakeStore) Replace(ctx context.Context, repoID api.RepoID, commitID api.CommitID, symbols []result.Symbol, truncated bool) error {
+	for _, r := range s.stale {
+		if r.Repo.ID == repoID {
+			s.replaced[r.Repo.Name] = commitID
+			s.symbols[r.Repo.Name] = symbols
+			if truncated {
+				s.truncated = append(s.truncated, r.Repo.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) MarkIndexed(ctx context.Context, repoID api.RepoID) error {
+	for _, r := range s.stale {
+		if r.Repo.ID == repoID {
+			s.marked = append(s.marked, r.Repo.Name)
+		}
+	}
+	return nil
+}
+
+func TestIndexer(t *testing.T) {
+	store := &fakeStore{
+		stale: []database.StaleGlobalSymbolsRepo{
+			{Repo: types.RepoName{ID: 1, Name: "new"}},
+			{Repo: types.RepoName{ID: 2, Name: "changed"}, CommitID: "old"},
+			{Repo: types.RepoName{ID: 3, Name: "unchanged"}, CommitID: "c3"},
+			{Repo: types.RepoName{ID: 4, Name: "empty"}},
+			{Repo: types.RepoName{ID: 5, Name: "large"}},
+		},
+		replaced: map[api.RepoName]api.CommitID{},
+		symbols:  map[api.RepoName][]result.Symbol{},
+	}
+
+	heads := map[api.RepoName]api.CommitID{"new": "c1", "changed": "c2", "unchanged": "c3", "large": "c5"}
+	i := &indexer{
+		store: store,
+		resolveHEAD: func(ctx context.Context, repo api.RepoName) (api.CommitID, error) {
+			if head, ok := heads[repo]; ok {
+				return head, nil
+			}
+			return "", &gitdomain.RevisionNotFoundError{Repo: repo, Spec: "HEAD"}
+		},
+		listSymbols: func(ctx context.Context, repo api.RepoName, commitID api.CommitID, limit int) ([]result.Symbol, error) {
+			// One more than maxSymbolsPerRepo to detect truncation.
+			if limit != 3 {
+				t.Errorf("got limit %d, want 3", limit)
+			}
+			if repo == "large" {
+				return []result.Symbol{{Name: "a"}, {Name: "b"}, {Name: "c"}}, nil
+			}
+			return []result.Symbol{{Name: string(repo) + "@" + string(commitID)}}, nil
+		},
+		batchSize:         10,
+		maxSymbolsPerRepo: 2,
+	}
+
+	// Nothing is indexed while the feature is disabled.
+	if err := i.Handle(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.replaced) != 0 || len(store.marked) != 0 {
+		t.Fatalf("indexed while disabled: %v %v", store.replaced, store.marked)
+	}
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{SymbolsGlobalIndex: "enabled"},
+	}})
+	defer conf.Mock(nil)
+
+	if err := i.Handle(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	wantReplaced := map[api.RepoName]api.CommitID{"new": "c1", "changed": "c2", "empty": "", "large": "c5"}
+	if diff := cmp.Diff(wantReplaced, store.replaced); diff != "" {
+		t.Errorf("unexpected replaced commits (-want +got):\n%s", diff)
+	}
+	wantSymbols := map[api.RepoName][]result.Symbol{
+		"new":     {{Name: "new@c1"}},
+		"changed": {{Name: "changed@c2"}},
+		"empty":   nil,
+		"large":   nil,
+	}
+	if diff := cmp.Diff(wantSymbols, store.symbols); diff != "" {
+		t.Errorf("unexpected symbols (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]api.RepoName{"large"}, store.truncated); diff != "" {
+		t.Errorf("unexpected truncated repos (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]api.RepoName{"unchanged"}, store.marked); diff != "" {
+		t.Errorf("unexpected marked repos (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,60 @@
+package globalindex
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/worker/shared"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/symbols"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// NewIndexingJob returns the worker job which keeps the global symbols index
+// up to date. It does nothing unless the experimental feature
+// "symbols.globalIndex" is enabled.
+func NewIndexingJob() shared.Job {
+	return &indexingJob{}
+}
+
+type indexingJob struct{}
+
+func (j *indexingJob) Config() []env.Config {
+	return []env.Config{configInst}
+}
+
+func (j *indexingJob) Routines(_ context.Context) ([]goroutine.BackgroundRoutine, error) {
+	db, err := shared.InitDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	indexer := &indexer{
+		store: database.GlobalSymbols(db),
+		resolveHEAD: func(ctx context.Context, repo api.RepoName) (api.CommitID, error) {
+			return git.ResolveRevision(ctx, repo, "HEAD", git.ResolveRevisionOptions{NoEnsureRevision: true})
+		},
+		listSymbols: func(ctx context.Context, repo api.RepoName, commitID api.CommitID, limit int) ([]result.Symbol, error) {
+			res, err := symbols.DefaultClient.Search(ctx, search.SymbolsParameters{
+				Repo:     repo,
+				CommitID: commitID,
+				First:    limit,
+			})
+			if res == nil {
+				return nil, err
+			}
+			return *res, err
+		},
+		batchSize:         configInst.BatchSize,
+		maxSymbolsPerRepo: configInst.MaxSymbolsPerRepo,
+	}
+
+	return []goroutine.BackgroundRoutine{
+		// Pass a fresh context, see docs for shared.Job
+		goroutine.NewPeriodicGoroutine(context.Background(), configInst.Interval, indexer),
+	}, nil
+}
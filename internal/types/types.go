@@ -460,7 +460,19 @@ type GitserverRepo struct {
 	LastFetched time.Time
 	// The last time a fetch updated the repository.
 	LastChanged time.Time
-	UpdatedAt   time.Time
+	// The last time the integrity of the repository was checked.
+	LastIntegrityCheckAt time.Time
+	// The output of the last integrity check if it failed, or empty if the
+	// repository was intact.
+	LastIntegrityCheckError string
+	// The last time an integrity check found the repository to be corrupt.
+	CorruptedAt time.Time
+	// The number of integrity checks which found the repository to be corrupt.
+	CorruptionCount int
+	// The time the repository was removed to free up disk space, or zero if it
+	// was requested since.
+	EvictedAt time.Time
+	UpdatedAt time.Time
 }
 
 // ExternalService is a connection to an external service.
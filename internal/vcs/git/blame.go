@@ -11,6 +11,8 @@ import (
 	"github.com/cockroachdb/errors"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
 )
@@ -46,6 +48,55 @@ func BlameFile(ctx context.Context, repo api.RepoName, path string, opt *BlameOp
 	return blameFileCmd(ctx, gitserverCmdFunc(repo), path, opt)
 }
 
+// StreamBlameFile streams Git blame information about a file, calling onHunks
+// with batches of hunks as they are computed. Unlike BlameFile, the hunks are
+// not sorted by line. Canceling ctx stops the blame.
+func StreamBlameFile(ctx context.Context, repo api.RepoName, path string, opt *BlameOptions, onHunks func([]*Hunk)) error {
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: StreamBlameFile")
+	span.SetTag("repo", repo)
+	span.SetTag("path", path)
+	span.SetTag("opt", opt)
+	defer span.Finish()
+
+	if opt == nil {
+		opt = &BlameOptions{}
+	}
+	if opt.OldestCommit != "" {
+		return errors.Errorf("OldestCommit not implemented")
+	}
+	if err := checkSpecArgSafety(string(opt.NewestCommit)); err != nil {
+		return err
+	}
+
+	req := &protocol.BlameRequest{
+		Repo:      repo,
+		Commit:    opt.NewestCommit,
+		Path:      filepath.ToSlash(path),
+		StartLine: opt.StartLine,
+		EndLine:   opt.EndLine,
+	}
+	return gitserver.DefaultClient.StreamBlame(ctx, req, func(protocolHunks []protocol.BlameHunk) {
+		hunks := make([]*Hunk, 0, len(protocolHunks))
+		for _, h := range protocolHunks {
+			hunks = append(hunks, &Hunk{
+				StartLine: h.StartLine,
+				EndLine:   h.EndLine,
+				StartByte: h.StartByte,
+				EndByte:   h.EndByte,
+				CommitID:  h.CommitID,
+				Author: gitapi.Signature{
+					Name:  h.Author.Name,
+					Email: h.Author.Email,
+					Date:  h.Author.Date,
+				},
+				Message:  h.Message,
+				Filename: h.Filename,
+			})
+		}
+		onHunks(hunks)
+	})
+}
+
 func blameFileCmd(ctx context.Context, command cmdFunc, path string, opt *BlameOptions) ([]*Hunk, error) {
 	if opt == nil {
 		opt = &BlameOptions{}
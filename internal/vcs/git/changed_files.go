@@ -0,0 +1,99 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+// Ancestors returns up to n ancestors of commit in the order of git rev-list,
+// which lists the most recent commits first. commit itself is not included.
+func Ancestors(ctx context.Context, repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error) {
+	if Mocks.Ancestors != nil {
+		return Mocks.Ancestors(repo, commit, n)
+	}
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: Ancestors")
+	span.SetTag("Commit", commit)
+	defer span.Finish()
+
+	if err := checkSpecArgSafety(string(commit)); err != nil {
+		return nil, err
+	}
+
+	cmd := gitserver.DefaultClient.Command("git", "rev-list", "--max-count="+strconv.Itoa(n+1), string(commit), "--")
+	cmd.Repo = repo
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("git command %v failed (output: %q)", cmd.Args, out))
+	}
+
+	lines := bytes.Fields(out)
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	ancestors := make([]api.CommitID, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		ancestors = append(ancestors, api.CommitID(line))
+	}
+	return ancestors, nil
+}
+
+// ChangedFiles returns the paths of the files which differ between the trees
+// of base and head. changed lists the files which were added or modified in
+// head, and deleted lists the files which exist in base but not in head.
+// Renames are reported as a deletion and an addition.
+func ChangedFiles(ctx context.Context, repo api.RepoName, base, head api.CommitID) (changed, deleted []string, err error) {
+	if Mocks.ChangedFiles != nil {
+		return Mocks.ChangedFiles(repo, base, head)
+	}
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: ChangedFiles")
+	span.SetTag("Base", base)
+	span.SetTag("Head", head)
+	defer span.Finish()
+
+	if err := checkSpecArgSafety(string(base)); err != nil {
+		return nil, nil, err
+	}
+	if err := checkSpecArgSafety(string(head)); err != nil {
+		return nil, nil, err
+	}
+
+	cmd := gitserver.DefaultClient.Command("git", "diff-tree", "-r", "--no-renames", "--name-status", "-z", string(base), string(head), "--")
+	cmd.Repo = repo
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, fmt.Sprintf("git command %v failed (output: %q)", cmd.Args, out))
+	}
+	return parseChangedFiles(out)
+}
+
+// parseChangedFiles parses the output of git diff-tree --name-status -z,
+// which is a sequence of NUL-terminated status and path pairs.
+func parseChangedFiles(out []byte) (changed, deleted []string, err error) {
+	fields := bytes.Split(bytes.TrimSuffix(out, []byte{0}), []byte{0})
+	if len(fields) == 1 && len(fields[0]) == 0 {
+		return nil, nil, nil
+	}
+	if len(fields)%2 != 0 {
+		return nil, nil, errors.Errorf("invalid git diff-tree output %q", out)
+	}
+	for i := 0; i < len(fields); i += 2 {
+		status, path := fields[i], string(fields[i+1])
+		if len(status) == 0 {
+			return nil, nil, errors.Errorf("invalid git diff-tree output %q", out)
+		}
+		if status[0] == 'D' {
+			deleted = append(deleted, path)
+		} else {
+			changed = append(changed, path)
+		}
+	}
+	return changed, deleted, nil
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestChangedFiles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	repo := MakeGitRepository(t,
+		"echo a > a && echo b > b && mkdir dir && echo c > dir/c",
+		"git add -A",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m first --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+		"echo changed > a && git rm -q dir/c && git mv b d && echo e > e",
+		"git add -A",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:06Z git commit -m second --author='a <a@a.com>' --date 2006-01-02T15:04:06Z",
+	)
+
+	resolve := func(spec string) api.CommitID {
+		t.Helper()
+		commit, err := ResolveRevision(ctx, repo, spec, ResolveRevisionOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return commit
+	}
+	base, head := resolve("HEAD~1"), resolve("HEAD")
+
+	changed, deleted, err := ChangedFiles(ctx, repo, base, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a", "d", "e"}, changed); diff != "" {
+		t.Errorf("unexpected changed files (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"b", "dir/c"}, deleted); diff != "" {
+		t.Errorf("unexpected deleted files (-want +got):\n%s", diff)
+	}
+
+	ancestors, err := Ancestors(ctx, repo, head, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]api.CommitID{base}, ancestors); diff != "" {
+		t.Errorf("unexpected ancestors (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseChangedFiles(t *testing.T) {
+	tests := []struct {
+		output      string
+		wantChanged []string
+		wantDeleted []string
+		wantErr     bool
+	}{
+		{output: ""},
+		{
+			output:      "A\x00added.go\x00M\x00dir/modified.go\x00D\x00deleted.go\x00T\x00link\x00",
+			wantChanged: []string{"added.go", "dir/modified.go", "link"},
+			wantDeleted: []string{"deleted.go"},
+		},
+		{output: "A\x00", wantErr: true},
+	}
+
+	for _, test := range tests {
+		changed, deleted, err := parseChangedFiles([]byte(test.output))
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseChangedFiles(%q): expected error", test.output)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.wantChanged, changed); diff != "" {
+			t.Errorf("parseChangedFiles(%q): unexpected changed files (-want +got):\n%s", test.output, diff)
+		}
+		if diff := cmp.Diff(test.wantDeleted, deleted); diff != "" {
+			t.Errorf("parseChangedFiles(%q): unexpected deleted files (-want +got):\n%s", test.output, diff)
+		}
+	}
+}
@@ -26,6 +26,8 @@ var Mocks, emptyMocks struct {
 	Commits          func(repo api.RepoName, opt CommitsOptions) ([]*gitapi.Commit, error)
 	MergeBase        func(repo api.RepoName, a, b api.CommitID) (api.CommitID, error)
 	GetDefaultBranch func(repo api.RepoName) (refName string, commit api.CommitID, err error)
+	Ancestors        func(repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error)
+	ChangedFiles     func(repo api.RepoName, base, head api.CommitID) (changed, deleted []string, err error)
 }
 
 // ResetMocks clears the mock functions set on Mocks (so that subsequent tests don't inadvertently
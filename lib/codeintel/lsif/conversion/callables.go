@@ -0,0 +1,87 @@
+package conversion
+
+import (
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+// callableSymbolKinds are the document symbol kinds of range tags that denote a callable.
+var callableSymbolKinds = map[protocol.SymbolKind]struct{}{
+	protocol.Function:    {},
+	protocol.Method:      {},
+	protocol.Constructor: {},
+}
+
+// callableDocumentationTags are the documentation tags that denote a callable.
+var callableDocumentationTags = map[protocol.Tag]struct{}{
+	protocol.TagFunction:    {},
+	protocol.TagMethod:      {},
+	protocol.TagConstructor: {},
+}
+
+// gatherCallables returns the extent of each function, method, and constructor defined in the
+// given document, keyed by the identifier of the range defining the callable's name.
+//
+// A range is considered to define a callable if it is a definition of its own symbol and either
+// its range tag or its documentation result identifies it as a function, method, or constructor.
+// The extent of a callable is the full range of its range tag. The body of a callable is unknown
+// for indexers that do not emit a full range, so its extent is only its name: it can be the callee
+// of a call, but does not enclose any call sites. Guessing the body from the positions of other
+// definitions would attribute calls outside of any callable to the preceding one. The call
+// hierarchy of such a callable is reported as unknown rather than empty.
+func gatherCallables(state *State, documentID int) map[int]precise.CallableData {
+	var callables map[int]precise.CallableData
+	state.Contains.SetEach(documentID, func(rangeID int) {
+		if !isCallableDefinition(state, documentID, rangeID) {
+			return
+		}
+		if callables == nil {
+			callables = map[int]precise.CallableData{}
+		}
+
+		r := state.RangeData[rangeID]
+
+		start, end := r.Start, r.End
+		if r.Tag != nil && r.Tag.FullRange != nil {
+			start, end = r.Tag.FullRange.Start, r.Tag.FullRange.End
+		}
+
+		callables[rangeID] = precise.CallableData{
+			StartLine:      start.Line,
+			StartCharacter: start.Character,
+			EndLine:        end.Line,
+			EndCharacter:   end.Character,
+		}
+	})
+
+	return callables
+}
+
+// isCallableDefinition returns true if the given range defines a function, method, or constructor.
+func isCallableDefinition(state *State, documentID, rangeID int) bool {
+	r := state.RangeData[rangeID]
+
+	if r.Tag != nil {
+		if r.Tag.Type != "definition" {
+			return false
+		}
+		if _, ok := callableSymbolKinds[r.Tag.Kind]; ok {
+			return true
+		}
+	} else {
+		definitions, ok := state.DefinitionData[r.DefinitionResultID]
+		if !ok || !definitions.SetContains(documentID, rangeID) {
+			return false
+		}
+	}
+
+	if documentation, ok := state.DocumentationResultsData[r.DocumentationResultID]; ok {
+		for _, tag := range documentation.Tags {
+			if _, ok := callableDocumentationTags[tag]; ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package conversion
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion/datastructures"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/reader"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+func TestGatherCallables(t *testing.T) {
+	newRange := func(startLine, startCharacter, endLine, endCharacter int, tag *protocol.RangeTag) reader.Range {
+		return reader.Range{
+			RangeData: protocol.RangeData{
+				Start: protocol.Pos{Line: startLine, Character: startCharacter},
+				End:   protocol.Pos{Line: endLine, Character: endCharacter},
+			},
+			Tag: tag,
+		}
+	}
+
+	state := &State{
+		DocumentData: map[int]string{
+			1001: "foo.go",
+		},
+		RangeData: map[int]Range{
+			// function with a full range
+			2001: {Range: newRange(0, 5, 0, 8, &protocol.RangeTag{
+				Type:      "definition",
+				Kind:      protocol.Function,
+				FullRange: &protocol.RangeData{Start: protocol.Pos{Line: 0, Character: 0}, End: protocol.Pos{Line: 4, Character: 1}},
+			})},
+			// call site within the function above
+			2002: {Range: newRange(2, 1, 2, 4, &protocol.RangeTag{Type: "reference"}), DefinitionResultID: 3002},
+			// method identified by documentation
+			2003: {Range: newRange(6, 5, 6, 8, nil), DefinitionResultID: 3001, DocumentationResultID: 4001},
+			// function identified by documentation
+			2004: {Range: newRange(10, 5, 10, 8, nil), DefinitionResultID: 3002, DocumentationResultID: 4002},
+			// variable identified by documentation
+			2005: {Range: newRange(14, 2, 14, 9, nil), DefinitionResultID: 3003, DocumentationResultID: 4003},
+			// variable identified by range tag
+			2006: {Range: newRange(12, 2, 12, 9, &protocol.RangeTag{Type: "definition", Kind: protocol.Variable})},
+		},
+		DefinitionData: map[int]*datastructures.DefaultIDSetMap{
+			3001: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{1001: datastructures.IDSetWith(2003)}),
+			3002: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{1001: datastructures.IDSetWith(2004)}),
+			3003: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{1001: datastructures.IDSetWith(2005)}),
+		},
+		DocumentationResultsData: map[int]protocol.Documentation{
+			4001: {Tags: []protocol.Tag{protocol.TagMethod}},
+			4002: {Tags: []protocol.Tag{protocol.TagPrivate, protocol.TagFunction}},
+			4003: {Tags: []protocol.Tag{protocol.TagVariable}},
+		},
+		Contains: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{
+			1001: datastructures.IDSetWith(2001, 2002, 2003, 2004, 2005, 2006),
+		}),
+	}
+
+	expectedCallables := map[int]precise.CallableData{
+		2001: {StartLine: 0, StartCharacter: 0, EndLine: 4, EndCharacter: 1},
+		// callables without a full range enclose only their name
+		2003: {StartLine: 6, StartCharacter: 5, EndLine: 6, EndCharacter: 8},
+		2004: {StartLine: 10, StartCharacter: 5, EndLine: 10, EndCharacter: 8},
+	}
+	if diff := cmp.Diff(expectedCallables, gatherCallables(state, 1001)); diff != "" {
+		t.Errorf("unexpected callables (-want +got):\n%s", diff)
+	}
+}
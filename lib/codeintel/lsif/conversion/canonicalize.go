@@ -44,7 +44,9 @@ func canonicalizeDocuments(state *State) {
 			state.Diagnostics.SetUnion(canonicalID, state.Diagnostics.Get(documentID))
 
 			canonicalizeDocumentsInDefinitionReferences(state, state.DefinitionData, documentID, canonicalID)
+			canonicalizeDocumentsInDefinitionReferences(state, state.TypeDefinitionData, documentID, canonicalID)
 			canonicalizeDocumentsInDefinitionReferences(state, state.ReferenceData, documentID, canonicalID)
+			canonicalizeDocumentsInDefinitionReferences(state, state.ImplementationData, documentID, canonicalID)
 
 			// Remove non-canonical document
 			delete(state.DocumentData, documentID)
@@ -152,16 +154,22 @@ func canonicalizeResultSetData(state *State, id int, item ResultSet) ResultSet {
 	return item
 }
 
-// mergeNextResultSetData merges the definition, reference, and hover result identifiers from
-// nextItem into item when not already defined. The moniker identifiers of nextItem are unioned
-// into the moniker identifiers of item.
+// mergeNextResultSetData merges the definition, type definition, reference, implementation, and
+// hover result identifiers from nextItem into item when not already defined. The moniker identifiers
+// of nextItem are unioned into the moniker identifiers of item.
 func mergeNextResultSetData(state *State, itemID int, item ResultSet, nextID int, nextItem ResultSet) ResultSet {
 	if item.DefinitionResultID == 0 {
 		item = item.SetDefinitionResultID(nextItem.DefinitionResultID)
 	}
+	if item.TypeDefinitionResultID == 0 {
+		item = item.SetTypeDefinitionResultID(nextItem.TypeDefinitionResultID)
+	}
 	if item.ReferenceResultID == 0 {
 		item = item.SetReferenceResultID(nextItem.ReferenceResultID)
 	}
+	if item.ImplementationResultID == 0 {
+		item = item.SetImplementationResultID(nextItem.ImplementationResultID)
+	}
 	if item.HoverResultID == 0 {
 		item = item.SetHoverResultID(nextItem.HoverResultID)
 	}
@@ -173,16 +181,22 @@ func mergeNextResultSetData(state *State, itemID int, item ResultSet, nextID int
 	return item
 }
 
-// mergeNextRangeData merges the definition, reference, and hover result identifiers from nextItem
-// into item when not already defined. The moniker identifiers of nextItem are unioned into the
-// moniker identifiers of item.
+// mergeNextRangeData merges the definition, type definition, reference, implementation, and hover
+// result identifiers from nextItem into item when not already defined. The moniker identifiers of
+// nextItem are unioned into the moniker identifiers of item.
 func mergeNextRangeData(state *State, itemID int, item Range, nextID int, nextItem ResultSet) Range {
 	if item.DefinitionResultID == 0 {
 		item = item.SetDefinitionResultID(nextItem.DefinitionResultID)
 	}
+	if item.TypeDefinitionResultID == 0 {
+		item = item.SetTypeDefinitionResultID(nextItem.TypeDefinitionResultID)
+	}
 	if item.ReferenceResultID == 0 {
 		item = item.SetReferenceResultID(nextItem.ReferenceResultID)
 	}
+	if item.ImplementationResultID == 0 {
+		item = item.SetImplementationResultID(nextItem.ImplementationResultID)
+	}
 	if item.HoverResultID == 0 {
 		item = item.SetHoverResultID(nextItem.HoverResultID)
 	}
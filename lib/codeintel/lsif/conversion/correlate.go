@@ -18,13 +18,40 @@ import (
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
 )
 
+// CorrelateOptions configures the correlation of an LSIF upload.
+type CorrelateOptions struct {
+	// HoverSpillThreshold is the heap size (in bytes) above which hover text is moved out of memory
+	// and into a temporary file while the upload is read. Zero disables spilling to disk.
+	//
+	// This is a trigger rather than a ceiling on memory usage: only hover text is spilled, and the
+	// remainder of the correlation state (ranges, result sets, results, and monikers) is held in
+	// memory regardless of the size of the heap.
+	HoverSpillThreshold int64
+
+	// TempDir is the directory in which spill files are created. The default directory for
+	// temporary files is used if empty.
+	TempDir string
+}
+
 // Correlate reads LSIF data from the given reader and returns a correlation state object with
 // the same data canonicalized and pruned for storage.
 //
+// The entire graph is held in memory until the upload has been read, as result sets, results, and
+// monikers may be attached to a range by any later element of the upload. Documents are therefore
+// not serialized until every document has been read. Only hover text can be moved to disk (see
+// CorrelateOptions), so the memory required to correlate an upload still grows with its size.
+//
 // If getChildren == nil, no pruning of irrelevant data is performed.
 func Correlate(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc) (*precise.GroupedBundleDataChans, error) {
+	return CorrelateWithOptions(ctx, r, root, getChildren, CorrelateOptions{})
+}
+
+// CorrelateWithOptions is like Correlate, but moves hover text to disk while reading the upload
+// as described by the given options. Spill files are removed once the documents channel of the
+// returned bundle data has been drained or the given context is canceled.
+func CorrelateWithOptions(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc, options CorrelateOptions) (*precise.GroupedBundleDataChans, error) {
 	// Read raw upload stream and return a correlation state
-	state, err := correlateFromReader(ctx, r, root)
+	state, err := correlateFromReader(ctx, r, root, options)
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +62,7 @@ func Correlate(ctx context.Context, r io.Reader, root string, getChildren pathex
 	if getChildren != nil {
 		// Remove elements we don't need to store
 		if err := prune(ctx, state, root, getChildren); err != nil {
+			_ = state.HoverSpill.Close()
 			return nil, err
 		}
 	}
@@ -42,6 +70,7 @@ func Correlate(ctx context.Context, r io.Reader, root string, getChildren pathex
 	// Convert data to the format we send to the writer
 	groupedBundleData, err := groupBundleData(ctx, state)
 	if err != nil {
+		_ = state.HoverSpill.Close()
 		return nil, err
 	}
 
@@ -118,7 +147,10 @@ func gitRoot(path string) (string, error) {
 
 // correlateFromReader reads the given upload stream and returns a correlation state object.
 // The data in the correlation state is neither canonicalized nor pruned.
-func correlateFromReader(ctx context.Context, r io.Reader, root string) (*State, error) {
+//
+// If the heap grows beyond the hover spill threshold of the given options while the upload is read,
+// the hover text of the correlation state is spilled to disk.
+func correlateFromReader(ctx context.Context, r io.Reader, root string, options CorrelateOptions) (_ *State, err error) {
 	ctx, cancel := context.WithCancel(ctx)
 	ch := Read(ctx, r)
 	defer func() {
@@ -131,6 +163,12 @@ func correlateFromReader(ctx context.Context, r io.Reader, root string) (*State,
 	}()
 
 	wrappedState := newWrappedState(root)
+	defer func() {
+		if err != nil {
+			// Remove spill file of the partial correlation state
+			_ = wrappedState.HoverSpill.Close()
+		}
+	}()
 
 	i := 0
 	for pair := range ch {
@@ -143,12 +181,28 @@ func correlateFromReader(ctx context.Context, r io.Reader, root string) (*State,
 		if err := correlateElement(wrappedState, pair.Element); err != nil {
 			return nil, errors.Errorf("dump malformed on element %d: %s", i, err)
 		}
+
+		if options.HoverSpillThreshold > 0 && wrappedState.HoverSpill == nil && (i-1)%memoryCheckInterval == 0 {
+			if size := heapSize(); size > options.HoverSpillThreshold {
+				log15.Info("Spilling hover text to disk", "heapSize", size, "hoverSpillThreshold", options.HoverSpillThreshold, "element", i)
+
+				if err := spillHoverData(wrappedState.State, options.TempDir); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 
 	if wrappedState.LSIFVersion == "" {
 		return nil, ErrMissingMetaData
 	}
 
+	if wrappedState.HoverSpill != nil {
+		if err := wrappedState.HoverSpill.Flush(); err != nil {
+			return nil, err
+		}
+	}
+
 	return wrappedState.State, nil
 }
 
@@ -179,16 +233,18 @@ func correlateElement(state *wrappedState, element Element) error {
 }
 
 var vertexHandlers = map[string]func(state *wrappedState, element Element) error{
-	"metaData":           correlateMetaData,
-	"document":           correlateDocument,
-	"range":              correlateRange,
-	"resultSet":          correlateResultSet,
-	"definitionResult":   correlateDefinitionResult,
-	"referenceResult":    correlateReferenceResult,
-	"hoverResult":        correlateHoverResult,
-	"moniker":            correlateMoniker,
-	"packageInformation": correlatePackageInformation,
-	"diagnosticResult":   correlateDiagnosticResult,
+	"metaData":             correlateMetaData,
+	"document":             correlateDocument,
+	"range":                correlateRange,
+	"resultSet":            correlateResultSet,
+	"definitionResult":     correlateDefinitionResult,
+	"typeDefinitionResult": correlateTypeDefinitionResult,
+	"referenceResult":      correlateReferenceResult,
+	"implementationResult": correlateImplementationResult,
+	"hoverResult":          correlateHoverResult,
+	"moniker":              correlateMoniker,
+	"packageInformation":   correlatePackageInformation,
+	"diagnosticResult":     correlateDiagnosticResult,
 
 	// Sourcegraph extensions
 	string(protocol.VertexSourcegraphDocumentationResult): correlateDocumentationResult,
@@ -212,16 +268,18 @@ func correlateVertex(state *wrappedState, element Element) error {
 }
 
 var edgeHandlers = map[string]func(state *wrappedState, id int, edge Edge) error{
-	"contains":                correlateContainsEdge,
-	"next":                    correlateNextEdge,
-	"item":                    correlateItemEdge,
-	"textDocument/definition": correlateTextDocumentDefinitionEdge,
-	"textDocument/references": correlateTextDocumentReferencesEdge,
-	"textDocument/hover":      correlateTextDocumentHoverEdge,
-	"moniker":                 correlateMonikerEdge,
-	"nextMoniker":             correlateNextMonikerEdge,
-	"packageInformation":      correlatePackageInformationEdge,
-	"textDocument/diagnostic": correlateDiagnosticEdge,
+	"contains":                    correlateContainsEdge,
+	"next":                        correlateNextEdge,
+	"item":                        correlateItemEdge,
+	"textDocument/definition":     correlateTextDocumentDefinitionEdge,
+	"textDocument/typeDefinition": correlateTextDocumentTypeDefinitionEdge,
+	"textDocument/references":     correlateTextDocumentReferencesEdge,
+	"textDocument/implementation": correlateTextDocumentImplementationEdge,
+	"textDocument/hover":          correlateTextDocumentHoverEdge,
+	"moniker":                     correlateMonikerEdge,
+	"nextMoniker":                 correlateNextMonikerEdge,
+	"packageInformation":          correlatePackageInformationEdge,
+	"textDocument/diagnostic":     correlateDiagnosticEdge,
 
 	// Sourcegraph extensions
 	string(protocol.EdgeSourcegraphDocumentationResult):   correlateDocumentationResultEdge,
@@ -312,19 +370,28 @@ func correlateDefinitionResult(state *wrappedState, element Element) error {
 	return nil
 }
 
+func correlateTypeDefinitionResult(state *wrappedState, element Element) error {
+	state.TypeDefinitionData[element.ID] = datastructures.NewDefaultIDSetMap()
+	return nil
+}
+
 func correlateReferenceResult(state *wrappedState, element Element) error {
 	state.ReferenceData[element.ID] = datastructures.NewDefaultIDSetMap()
 	return nil
 }
 
+func correlateImplementationResult(state *wrappedState, element Element) error {
+	state.ImplementationData[element.ID] = datastructures.NewDefaultIDSetMap()
+	return nil
+}
+
 func correlateHoverResult(state *wrappedState, element Element) error {
 	payload, ok := element.Payload.(string)
 	if !ok {
 		return ErrUnexpectedPayload
 	}
 
-	state.HoverData[element.ID] = payload
-	return nil
+	return state.setHoverData(element.ID, payload)
 }
 
 func correlateMoniker(state *wrappedState, element Element) error {
@@ -405,6 +472,19 @@ func correlateItemEdge(state *wrappedState, id int, edge Edge) error {
 		return nil
 	}
 
+	if documentMap, ok := state.TypeDefinitionData[edge.OutV]; ok {
+		for _, inV := range edge.InVs {
+			if _, ok := state.RangeData[inV]; !ok {
+				return malformedDump(id, inV, "range")
+			}
+
+			// Link type definition data to defining range
+			documentMap.SetAdd(edge.Document, inV)
+		}
+
+		return nil
+	}
+
 	if documentMap, ok := state.ReferenceData[edge.OutV]; ok {
 		for _, inV := range edge.InVs {
 			if _, ok := state.ReferenceData[inV]; ok {
@@ -423,6 +503,19 @@ func correlateItemEdge(state *wrappedState, id int, edge Edge) error {
 		return nil
 	}
 
+	if documentMap, ok := state.ImplementationData[edge.OutV]; ok {
+		for _, inV := range edge.InVs {
+			if _, ok := state.RangeData[inV]; !ok {
+				return malformedDump(id, inV, "range")
+			}
+
+			// Link implementation data to implementing range
+			documentMap.SetAdd(edge.Document, inV)
+		}
+
+		return nil
+	}
+
 	if !state.unsupportedVertices.Contains(edge.OutV) {
 		return malformedDump(id, edge.OutV, "vertex")
 	}
@@ -446,6 +539,21 @@ func correlateTextDocumentDefinitionEdge(state *wrappedState, id int, edge Edge)
 	return nil
 }
 
+func correlateTextDocumentTypeDefinitionEdge(state *wrappedState, id int, edge Edge) error {
+	if _, ok := state.TypeDefinitionData[edge.InV]; !ok {
+		return malformedDump(id, edge.InV, "typeDefinitionResult")
+	}
+
+	if source, ok := state.RangeData[edge.OutV]; ok {
+		state.RangeData[edge.OutV] = source.SetTypeDefinitionResultID(edge.InV)
+	} else if source, ok := state.ResultSetData[edge.OutV]; ok {
+		state.ResultSetData[edge.OutV] = source.SetTypeDefinitionResultID(edge.InV)
+	} else {
+		return malformedDump(id, edge.OutV, "range", "resultSet")
+	}
+	return nil
+}
+
 func correlateTextDocumentReferencesEdge(state *wrappedState, id int, edge Edge) error {
 	if _, ok := state.ReferenceData[edge.InV]; !ok {
 		return malformedDump(id, edge.InV, "referenceResult")
@@ -461,8 +569,23 @@ func correlateTextDocumentReferencesEdge(state *wrappedState, id int, edge Edge)
 	return nil
 }
 
+func correlateTextDocumentImplementationEdge(state *wrappedState, id int, edge Edge) error {
+	if _, ok := state.ImplementationData[edge.InV]; !ok {
+		return malformedDump(id, edge.InV, "implementationResult")
+	}
+
+	if source, ok := state.RangeData[edge.OutV]; ok {
+		state.RangeData[edge.OutV] = source.SetImplementationResultID(edge.InV)
+	} else if source, ok := state.ResultSetData[edge.OutV]; ok {
+		state.ResultSetData[edge.OutV] = source.SetImplementationResultID(edge.InV)
+	} else {
+		return malformedDump(id, edge.OutV, "range", "resultSet")
+	}
+	return nil
+}
+
 func correlateTextDocumentHoverEdge(state *wrappedState, id int, edge Edge) error {
-	if _, ok := state.HoverData[edge.InV]; !ok {
+	if !state.hasHoverData(edge.InV) {
 		return malformedDump(id, edge.InV, "hoverResult")
 	}
 
@@ -19,7 +19,7 @@ func TestCorrelate(t *testing.T) {
 		t.Fatalf("unexpected error reading test file: %s", err)
 	}
 
-	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root")
+	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root", CorrelateOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error correlating input: %s", err)
 	}
@@ -39,7 +39,8 @@ func TestCorrelate(t *testing.T) {
 						End:   protocol.Pos{Line: 3, Character: 4},
 					},
 				},
-				DefinitionResultID: 13,
+				DefinitionResultID:     13,
+				TypeDefinitionResultID: 54,
 			},
 			5: {
 				Range: reader.Range{
@@ -89,8 +90,9 @@ func TestCorrelate(t *testing.T) {
 		},
 		ResultSetData: map[int]ResultSet{
 			10: {
-				DefinitionResultID: 12,
-				ReferenceResultID:  14,
+				DefinitionResultID:     12,
+				ReferenceResultID:      14,
+				ImplementationResultID: 51,
 			},
 			11: {
 				HoverResultID: 16,
@@ -100,10 +102,16 @@ func TestCorrelate(t *testing.T) {
 			12: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{3: datastructures.IDSetWith(7)}),
 			13: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{3: datastructures.IDSetWith(8)}),
 		},
+		TypeDefinitionData: map[int]*datastructures.DefaultIDSetMap{
+			54: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{3: datastructures.IDSetWith(8)}),
+		},
 		ReferenceData: map[int]*datastructures.DefaultIDSetMap{
 			14: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{2: datastructures.IDSetWith(4, 5)}),
 			15: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{}),
 		},
+		ImplementationData: map[int]*datastructures.DefaultIDSetMap{
+			51: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{2: datastructures.IDSetWith(6)}),
+		},
 		HoverData: map[int]string{
 			16: "```go\ntext A\n```",
 			17: "```go\ntext B\n```",
@@ -202,7 +210,7 @@ func TestCorrelateMetaDataRoot(t *testing.T) {
 		t.Fatalf("unexpected error reading test file: %s", err)
 	}
 
-	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root/")
+	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root/", CorrelateOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error correlating input: %s", err)
 	}
@@ -216,7 +224,9 @@ func TestCorrelateMetaDataRoot(t *testing.T) {
 		RangeData:              map[int]Range{},
 		ResultSetData:          map[int]ResultSet{},
 		DefinitionData:         map[int]*datastructures.DefaultIDSetMap{},
+		TypeDefinitionData:     map[int]*datastructures.DefaultIDSetMap{},
 		ReferenceData:          map[int]*datastructures.DefaultIDSetMap{},
+		ImplementationData:     map[int]*datastructures.DefaultIDSetMap{},
 		HoverData:              map[int]string{},
 		MonikerData:            map[int]Moniker{},
 		PackageInformationData: map[int]PackageInformation{},
@@ -250,7 +260,7 @@ func TestCorrelateMetaDataRootX(t *testing.T) {
 		t.Fatalf("unexpected error reading test file: %s", err)
 	}
 
-	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "")
+	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "", CorrelateOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error correlating input: %s", err)
 	}
@@ -264,7 +274,9 @@ func TestCorrelateMetaDataRootX(t *testing.T) {
 		RangeData:              map[int]Range{},
 		ResultSetData:          map[int]ResultSet{},
 		DefinitionData:         map[int]*datastructures.DefaultIDSetMap{},
+		TypeDefinitionData:     map[int]*datastructures.DefaultIDSetMap{},
 		ReferenceData:          map[int]*datastructures.DefaultIDSetMap{},
+		ImplementationData:     map[int]*datastructures.DefaultIDSetMap{},
 		HoverData:              map[int]string{},
 		MonikerData:            map[int]Moniker{},
 		PackageInformationData: map[int]PackageInformation{},
@@ -0,0 +1,356 @@
+package conversion
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/reader"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsiftyped"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/pathexistence"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+// CorrelateTyped reads a protobuf-encoded typed index (see lib/codeintel/lsiftyped) from the given
+// reader and returns the same grouped bundle data as Correlate, pruned for storage.
+//
+// Unlike Correlate, the index is not expanded into an LSIF graph. Documents are read one at a time
+// and reduced to a flat list of ranges, each pointing to the symbol it references. Definition,
+// reference, implementation, and type definition results are formed per symbol once the entire
+// index has been read.
+//
+// If getChildren == nil, no pruning of irrelevant data is performed.
+func CorrelateTyped(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc) (*precise.GroupedBundleDataChans, error) {
+	return CorrelateTypedWithOptions(ctx, r, root, getChildren, CorrelateOptions{})
+}
+
+// CorrelateTypedWithOptions is like CorrelateTyped, but moves hover text to disk while reading the
+// index as described by the given options. Spill files are removed once the documents channel of
+// the returned bundle data has been drained or the given context is canceled.
+func CorrelateTypedWithOptions(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc, options CorrelateOptions) (*precise.GroupedBundleDataChans, error) {
+	// Read raw upload stream and return a typed correlation state
+	state, err := correlateTypedFromReader(r, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if getChildren != nil {
+		// Remove documents we don't need to store
+		if err := pruneTyped(ctx, state, root, getChildren); err != nil {
+			_ = state.hoverSpill.Close()
+			return nil, err
+		}
+	}
+
+	// Form result sets and convert data to the format we send to the writer
+	groupedBundleData, err := groupTypedBundleData(ctx, state)
+	if err != nil {
+		_ = state.hoverSpill.Close()
+		return nil, err
+	}
+
+	return groupedBundleData, nil
+}
+
+// typedState is an in-memory representation of an uploaded typed index. Only the data required
+// to form result sets is retained for each document.
+type typedState struct {
+	hasMetadata bool
+	documents   []*typedDocument // indexed by document identifier - 1
+	documentIDs map[string]int   // maps document paths to their identifier
+	symbols     []*typedSymbol   // indexed by symbol identifier - 1
+	symbolIDs   map[typedSymbolKey]int
+	lastID      int // last identifier assigned to a range or result
+
+	numOccurrences      int         // number of occurrences read so far
+	nextMemoryCheck     int         // number of occurrences after which the heap size is checked next
+	hoverSpillThreshold int64       // see CorrelateOptions
+	hoverSpillDir       string      // see CorrelateOptions
+	hoverSpill          *spillStore // hover text moved out of memory (possibly nil)
+	lastHoverSpillID    int         // last identifier assigned to hover text in the spill store
+}
+
+// typedSymbolKey identifies a symbol within an index. Local symbols are namespaced by the
+// document in which they occur, global symbols have a zero-valued document identifier.
+type typedSymbolKey struct {
+	documentID int
+	symbol     string
+}
+
+type typedDocument struct {
+	path        string
+	pruned      bool
+	ranges      []typedRange
+	diagnostics []precise.DiagnosticData
+}
+
+type typedRange struct {
+	lsiftyped.Range
+	id           int
+	symbolID     int              // possibly zero
+	isDefinition bool             // whether the range defines its symbol
+	hover        typedHover       // overrides the hover text of the symbol
+	extent       *lsiftyped.Range // possibly nil
+}
+
+// typedLocation identifies a range within a document of a typed correlation state.
+type typedLocation struct {
+	documentID int
+	rangeIndex int
+}
+
+type typedSymbol struct {
+	symbol        lsiftyped.Symbol // zero-valued for local symbols
+	isLocal       bool
+	hover         typedHover
+	definitions   []typedLocation
+	occurrences   []typedLocation
+	referenceOf   []int // symbols whose occurrences are included in the references of this symbol
+	implements    []int // symbols implemented by this symbol
+	typeDefinedBy []int // symbols whose definitions are the type definitions of this symbol
+
+	// populated by formTypedResults
+	definitionResultID     int
+	typeDefinitionResultID int
+	referenceResultID      int
+	implementationResultID int
+	hoverResultID          int
+	monikerID              int
+	monikerKind            string
+	packageInformationID   int
+}
+
+// typedHover is hover text of a typed correlation state. The text is held in memory unless it was
+// moved to the spill store of the state, in which case spillID identifies the text within it.
+type typedHover struct {
+	text    string
+	spillID int
+}
+
+// exists returns true if there is hover text.
+func (h typedHover) exists() bool {
+	return h.text != "" || h.spillID != 0
+}
+
+// correlateTypedFromReader reads the given upload stream and returns a typed correlation state.
+//
+// If the heap grows beyond the hover spill threshold of the given options while the index is read,
+// the hover text of the correlation state is spilled to disk.
+func correlateTypedFromReader(r io.Reader, options CorrelateOptions) (_ *typedState, err error) {
+	state := &typedState{
+		documentIDs:         map[string]int{},
+		symbolIDs:           map[typedSymbolKey]int{},
+		hoverSpillThreshold: options.HoverSpillThreshold,
+		hoverSpillDir:       options.TempDir,
+	}
+	defer func() {
+		if err != nil {
+			// Remove spill file of the partial correlation state
+			_ = state.hoverSpill.Close()
+		}
+	}()
+
+	if err := lsiftyped.ReadIndex(r, lsiftyped.Visitor{
+		VisitMetadata:       state.addMetadata,
+		VisitDocument:       state.addDocument,
+		VisitExternalSymbol: func(symbol lsiftyped.SymbolInformation) error { return state.addSymbolInformation(0, symbol) },
+	}); err != nil {
+		return nil, errors.Wrap(err, "index malformed")
+	}
+
+	if !state.hasMetadata {
+		return nil, ErrMissingMetaData
+	}
+
+	if state.hoverSpill != nil {
+		if err := state.hoverSpill.Flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+func (s *typedState) addMetadata(metadata lsiftyped.Metadata) error {
+	s.hasMetadata = true
+	return nil
+}
+
+// addDocument adds the ranges, diagnostics, and symbols of the given document to the state. An
+// index may contain several document messages with the same path; their contents are merged.
+func (s *typedState) addDocument(document lsiftyped.Document) error {
+	documentID, ok := s.documentIDs[document.RelativePath]
+	if !ok {
+		s.documents = append(s.documents, &typedDocument{path: document.RelativePath})
+		documentID = len(s.documents)
+		s.documentIDs[document.RelativePath] = documentID
+	}
+	data := s.documents[documentID-1]
+
+	for _, symbol := range document.Symbols {
+		if err := s.addSymbolInformation(documentID, symbol); err != nil {
+			return err
+		}
+	}
+
+	for _, occurrence := range document.Occurrences {
+		r, ok := lsiftyped.NewRange(occurrence.Range)
+		if !ok {
+			return errors.Errorf("malformed range %v in document %q", occurrence.Range, document.RelativePath)
+		}
+
+		typedRange := typedRange{
+			Range: r,
+			id:    s.nextID(),
+		}
+		if err := s.setHover(&typedRange.hover, strings.Join(occurrence.OverrideDocumentation, reader.HoverPartSeparator)); err != nil {
+			return err
+		}
+		if extent, ok := lsiftyped.NewRange(occurrence.EnclosingRange); ok {
+			typedRange.extent = &extent
+		}
+
+		if occurrence.Symbol != "" {
+			symbolID, err := s.symbolID(documentID, occurrence.Symbol)
+			if err != nil {
+				return errors.Wrapf(err, "document %q", document.RelativePath)
+			}
+			symbol := s.symbols[symbolID-1]
+			location := typedLocation{documentID: documentID, rangeIndex: len(data.ranges)}
+
+			typedRange.symbolID = symbolID
+			symbol.occurrences = append(symbol.occurrences, location)
+			if occurrence.HasRole(lsiftyped.SymbolRoleDefinition) {
+				typedRange.isDefinition = true
+				symbol.definitions = append(symbol.definitions, location)
+			}
+		}
+
+		for _, diagnostic := range occurrence.Diagnostics {
+			data.diagnostics = append(data.diagnostics, precise.DiagnosticData{
+				Severity:       int(diagnostic.Severity),
+				Code:           diagnostic.Code,
+				Message:        diagnostic.Message,
+				Source:         diagnostic.Source,
+				StartLine:      r.StartLine,
+				StartCharacter: r.StartCharacter,
+				EndLine:        r.EndLine,
+				EndCharacter:   r.EndCharacter,
+			})
+		}
+
+		data.ranges = append(data.ranges, typedRange)
+	}
+
+	s.numOccurrences += len(document.Occurrences)
+	if s.hoverSpillThreshold > 0 && s.hoverSpill == nil && s.numOccurrences >= s.nextMemoryCheck {
+		s.nextMemoryCheck = s.numOccurrences + memoryCheckInterval
+
+		if size := heapSize(); size > s.hoverSpillThreshold {
+			log15.Info("Spilling hover text to disk", "heapSize", size, "hoverSpillThreshold", s.hoverSpillThreshold, "occurrence", s.numOccurrences)
+
+			if err := spillTypedHovers(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// addSymbolInformation records the hover text and relationships of the given symbol. Local symbols
+// are resolved relative to the given document.
+func (s *typedState) addSymbolInformation(documentID int, information lsiftyped.SymbolInformation) error {
+	symbolID, err := s.symbolID(documentID, information.Symbol)
+	if err != nil {
+		return err
+	}
+	symbol := s.symbols[symbolID-1]
+
+	if len(information.Documentation) > 0 {
+		if err := s.setHover(&symbol.hover, strings.Join(information.Documentation, reader.HoverPartSeparator)); err != nil {
+			return err
+		}
+	}
+
+	for _, relationship := range information.Relationships {
+		relatedID, err := s.symbolID(documentID, relationship.Symbol)
+		if err != nil {
+			return err
+		}
+
+		if relationship.IsReference {
+			symbol.referenceOf = append(symbol.referenceOf, relatedID)
+		}
+		if relationship.IsImplementation {
+			symbol.implements = append(symbol.implements, relatedID)
+		}
+		if relationship.IsTypeDefinition {
+			symbol.typeDefinedBy = append(symbol.typeDefinedBy, relatedID)
+		}
+	}
+
+	return nil
+}
+
+// symbolID returns the identifier of the given symbol occurring in the given document, creating
+// a new symbol if one does not already exist.
+func (s *typedState) symbolID(documentID int, symbol string) (int, error) {
+	isLocal := lsiftyped.IsLocalSymbol(symbol)
+
+	key := typedSymbolKey{symbol: symbol}
+	if isLocal {
+		if documentID == 0 {
+			return 0, errors.Errorf("unexpected local symbol %q outside of a document", symbol)
+		}
+		key.documentID = documentID
+	}
+
+	if symbolID, ok := s.symbolIDs[key]; ok {
+		return symbolID, nil
+	}
+
+	data := &typedSymbol{isLocal: isLocal}
+	if !isLocal {
+		parsed, err := lsiftyped.ParseSymbol(symbol)
+		if err != nil {
+			return 0, err
+		}
+		data.symbol = parsed
+	}
+
+	s.symbols = append(s.symbols, data)
+	s.symbolIDs[key] = len(s.symbols)
+	return len(s.symbols), nil
+}
+
+func (s *typedState) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+// pruneTyped marks the documents in the given typed correlation state that do not exist in the
+// git clone at the target commit. Pruned documents are not stored and are omitted from results.
+func pruneTyped(ctx context.Context, state *typedState, root string, getChildren pathexistence.GetChildrenFunc) error {
+	paths := make([]string, 0, len(state.documents))
+	for _, document := range state.documents {
+		paths = append(paths, document.path)
+	}
+
+	checker, err := pathexistence.NewExistenceChecker(ctx, root, paths, getChildren)
+	if err != nil {
+		return err
+	}
+
+	for _, document := range state.documents {
+		if !checker.Exists(document.path) {
+			// Document does not exist in git
+			document.pruned = true
+		}
+	}
+
+	return nil
+}
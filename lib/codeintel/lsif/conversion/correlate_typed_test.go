@@ -0,0 +1,239 @@
+package conversion
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/bloomfilter"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsiftyped"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+const (
+	typedFooSymbol   = "gomod . example.com/foo v1.0.0 foo/Foo()."
+	typedFooerSymbol = "gomod . example.com/foo v1.0.0 foo/Fooer#Foo()."
+	typedBarSymbol   = "gomod . example.com/bar v2.0.0 bar/Bar()."
+)
+
+func TestCorrelateTyped(t *testing.T) {
+	index := lsiftyped.Index{
+		Metadata: lsiftyped.Metadata{
+			ToolInfo:    lsiftyped.ToolInfo{Name: "lsif-test"},
+			ProjectRoot: "file:///test/root",
+		},
+		Documents: []lsiftyped.Document{
+			{
+				RelativePath: "foo.go",
+				Occurrences: []lsiftyped.Occurrence{
+					{Range: []int32{1, 5, 8}, Symbol: typedFooSymbol, SymbolRoles: lsiftyped.SymbolRoleDefinition, EnclosingRange: []int32{1, 0, 3, 1}},
+					{Range: []int32{2, 1, 4}, Symbol: typedBarSymbol},
+					{Range: []int32{2, 8, 9}, Symbol: "local 0", SymbolRoles: lsiftyped.SymbolRoleDefinition},
+					{Range: []int32{3, 0, 1}, Symbol: "local 0", OverrideDocumentation: []string{"overridden"}, Diagnostics: []lsiftyped.Diagnostic{{Severity: 1, Message: "unused"}}},
+				},
+				Symbols: []lsiftyped.SymbolInformation{
+					{
+						Symbol:        typedFooSymbol,
+						Documentation: []string{"Foo does things."},
+						Relationships: []lsiftyped.Relationship{{Symbol: typedFooerSymbol, IsImplementation: true}},
+					},
+				},
+			},
+			{
+				RelativePath: "bar.go",
+				Occurrences: []lsiftyped.Occurrence{
+					{Range: []int32{0, 5, 10}, Symbol: typedFooerSymbol, SymbolRoles: lsiftyped.SymbolRoleDefinition},
+					{Range: []int32{4, 1, 4}, Symbol: typedFooSymbol},
+				},
+			},
+			{
+				RelativePath: "vendor/baz.go",
+				Occurrences: []lsiftyped.Occurrence{
+					{Range: []int32{0, 0, 3}, Symbol: typedFooSymbol},
+				},
+			},
+		},
+		ExternalSymbols: []lsiftyped.SymbolInformation{
+			{Symbol: typedBarSymbol, Documentation: []string{"Bar does other things."}},
+		},
+	}
+
+	getChildren := func(ctx context.Context, dirnames []string) (map[string][]string, error) {
+		return map[string][]string{"": {"foo.go", "bar.go"}}, nil
+	}
+
+	chans, err := CorrelateTyped(context.Background(), bytes.NewReader(lsiftyped.MarshalIndex(index)), "", getChildren)
+	if err != nil {
+		t.Fatalf("unexpected error correlating typed index: %s", err)
+	}
+	actualBundleData := precise.GroupedBundleDataChansToMaps(chans)
+
+	expectedFilter, err := bloomfilter.CreateFilter([]string{"bar/Bar()."})
+	if err != nil {
+		t.Fatalf("unexpected error creating bloom filter: %s", err)
+	}
+
+	// Ranges are numbered 1-7 in the order in which they are read. Results, hover texts,
+	// monikers, and package information are numbered per symbol in the order symbols were
+	// first encountered: Foo (8-12), Fooer#Foo (13-17), Bar (18-21), local 0 (22-23).
+	expectedBundleData := &precise.GroupedBundleDataMaps{
+		Meta: precise.MetaData{NumResultChunks: 1},
+		Documents: map[string]precise.DocumentData{
+			"foo.go": {
+				Ranges: map[precise.ID]precise.RangeData{
+					"1": {
+						StartLine:          1,
+						StartCharacter:     5,
+						EndLine:            1,
+						EndCharacter:       8,
+						DefinitionResultID: "8",
+						ReferenceResultID:  "9",
+						HoverResultID:      "10",
+						MonikerIDs:         []precise.ID{"11"},
+						Callable:           &precise.CallableData{StartLine: 1, StartCharacter: 0, EndLine: 3, EndCharacter: 1},
+					},
+					"2": {
+						StartLine:         2,
+						StartCharacter:    1,
+						EndLine:           2,
+						EndCharacter:      4,
+						ReferenceResultID: "18",
+						HoverResultID:     "19",
+						MonikerIDs:        []precise.ID{"20"},
+					},
+					"3": {
+						StartLine:          2,
+						StartCharacter:     8,
+						EndLine:            2,
+						EndCharacter:       9,
+						DefinitionResultID: "22",
+						ReferenceResultID:  "23",
+					},
+					"4": {
+						StartLine:          3,
+						StartCharacter:     0,
+						EndLine:            3,
+						EndCharacter:       1,
+						DefinitionResultID: "22",
+						ReferenceResultID:  "23",
+						HoverResultID:      "4",
+					},
+				},
+				HoverResults: map[precise.ID]string{
+					"4":  "overridden",
+					"10": "Foo does things.",
+					"19": "Bar does other things.",
+				},
+				Monikers: map[precise.ID]precise.MonikerData{
+					"11": {Kind: "export", Scheme: "gomod", Identifier: "foo/Foo().", PackageInformationID: "12"},
+					"20": {Kind: "import", Scheme: "gomod", Identifier: "bar/Bar().", PackageInformationID: "21"},
+				},
+				PackageInformation: map[precise.ID]precise.PackageInformationData{
+					"12": {Name: "example.com/foo", Version: "v1.0.0"},
+					"21": {Name: "example.com/bar", Version: "v2.0.0"},
+				},
+				Diagnostics: []precise.DiagnosticData{
+					{Severity: 1, Message: "unused", StartLine: 3, StartCharacter: 0, EndLine: 3, EndCharacter: 1},
+				},
+			},
+			"bar.go": {
+				Ranges: map[precise.ID]precise.RangeData{
+					"5": {
+						StartLine:              0,
+						StartCharacter:         5,
+						EndLine:                0,
+						EndCharacter:           10,
+						DefinitionResultID:     "13",
+						ReferenceResultID:      "14",
+						ImplementationResultID: "15",
+						MonikerIDs:             []precise.ID{"16"},
+						Callable:               &precise.CallableData{StartLine: 0, StartCharacter: 5, EndLine: 0, EndCharacter: 10},
+					},
+					"6": {
+						StartLine:          4,
+						StartCharacter:     1,
+						EndLine:            4,
+						EndCharacter:       4,
+						DefinitionResultID: "8",
+						ReferenceResultID:  "9",
+						HoverResultID:      "10",
+						MonikerIDs:         []precise.ID{"11"},
+					},
+				},
+				HoverResults: map[precise.ID]string{
+					"10": "Foo does things.",
+				},
+				Monikers: map[precise.ID]precise.MonikerData{
+					"11": {Kind: "export", Scheme: "gomod", Identifier: "foo/Foo().", PackageInformationID: "12"},
+					"16": {Kind: "export", Scheme: "gomod", Identifier: "foo/Fooer#Foo().", PackageInformationID: "17"},
+				},
+				PackageInformation: map[precise.ID]precise.PackageInformationData{
+					"12": {Name: "example.com/foo", Version: "v1.0.0"},
+					"17": {Name: "example.com/foo", Version: "v1.0.0"},
+				},
+				Diagnostics: []precise.DiagnosticData{},
+			},
+		},
+		ResultChunks: map[int]precise.ResultChunkData{
+			0: {
+				DocumentPaths: map[precise.ID]string{
+					"1": "foo.go",
+					"2": "bar.go",
+				},
+				DocumentIDRangeIDs: map[precise.ID][]precise.DocumentIDRangeID{
+					"8":  {{DocumentID: "1", RangeID: "1"}},
+					"9":  {{DocumentID: "2", RangeID: "6"}, {DocumentID: "1", RangeID: "1"}},
+					"13": {{DocumentID: "2", RangeID: "5"}},
+					"14": {{DocumentID: "2", RangeID: "5"}},
+					"15": {{DocumentID: "1", RangeID: "1"}},
+					"18": {{DocumentID: "1", RangeID: "2"}},
+					"22": {{DocumentID: "1", RangeID: "3"}},
+					"23": {{DocumentID: "1", RangeID: "3"}, {DocumentID: "1", RangeID: "4"}},
+				},
+			},
+		},
+		Definitions: map[string]map[string][]precise.LocationData{
+			"gomod": {
+				"foo/Foo().":       {{URI: "foo.go", StartLine: 1, StartCharacter: 5, EndLine: 1, EndCharacter: 8}},
+				"foo/Fooer#Foo().": {{URI: "bar.go", StartLine: 0, StartCharacter: 5, EndLine: 0, EndCharacter: 10}},
+			},
+		},
+		TypeDefinitions: map[string]map[string][]precise.LocationData{},
+		References: map[string]map[string][]precise.LocationData{
+			"gomod": {
+				"foo/Foo().": {
+					{URI: "bar.go", StartLine: 4, StartCharacter: 1, EndLine: 4, EndCharacter: 4},
+					{URI: "foo.go", StartLine: 1, StartCharacter: 5, EndLine: 1, EndCharacter: 8},
+				},
+				"foo/Fooer#Foo().": {{URI: "bar.go", StartLine: 0, StartCharacter: 5, EndLine: 0, EndCharacter: 10}},
+				"bar/Bar().":       {{URI: "foo.go", StartLine: 2, StartCharacter: 1, EndLine: 2, EndCharacter: 4}},
+			},
+		},
+		Implementations: map[string]map[string][]precise.LocationData{
+			"gomod": {
+				"foo/Fooer#Foo().": {{URI: "foo.go", StartLine: 1, StartCharacter: 5, EndLine: 1, EndCharacter: 8}},
+			},
+		},
+		Packages: []precise.Package{
+			{Scheme: "gomod", Name: "example.com/foo", Version: "v1.0.0"},
+		},
+		PackageReferences: []precise.PackageReference{
+			{Package: precise.Package{Scheme: "gomod", Name: "example.com/bar", Version: "v2.0.0"}, Filter: expectedFilter},
+		},
+	}
+	if diff := cmp.Diff(expectedBundleData, actualBundleData); diff != "" {
+		t.Errorf("unexpected bundle data (-want +got):\n%s", diff)
+	}
+}
+
+func TestCorrelateTypedMissingMetadata(t *testing.T) {
+	index := lsiftyped.Index{
+		Documents: []lsiftyped.Document{{RelativePath: "foo.go"}},
+	}
+
+	if _, err := CorrelateTyped(context.Background(), bytes.NewReader(lsiftyped.MarshalIndex(index)), "", nil); err != ErrMissingMetaData {
+		t.Fatalf("unexpected error. want=%q have=%q", ErrMissingMetaData, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
 
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/bloomfilter"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion/datastructures"
@@ -24,14 +25,16 @@ const resultsPerResultChunk = 512
 
 // groupBundleData converts a raw (but canonicalized) correlation State into a GroupedBundleData.
 func groupBundleData(ctx context.Context, state *State) (*precise.GroupedBundleDataChans, error) {
-	numResults := len(state.DefinitionData) + len(state.ReferenceData)
+	numResults := len(state.DefinitionData) + len(state.TypeDefinitionData) + len(state.ReferenceData) + len(state.ImplementationData)
 	numResultChunks := int(math.Max(1, math.Floor(float64(numResults)/resultsPerResultChunk)))
 
 	meta := precise.MetaData{NumResultChunks: numResultChunks}
-	documents := serializeBundleDocuments(ctx, state)
+	documents, documentsErr := serializeBundleDocuments(ctx, state)
 	resultChunks := serializeResultChunks(ctx, state, numResultChunks)
 	definitionRows := gatherMonikersLocations(ctx, state, state.DefinitionData, func(r Range) int { return r.DefinitionResultID })
+	typeDefinitionRows := gatherMonikersLocations(ctx, state, state.TypeDefinitionData, func(r Range) int { return r.TypeDefinitionResultID })
 	referenceRows := gatherMonikersLocations(ctx, state, state.ReferenceData, func(r Range) int { return r.ReferenceResultID })
+	implementationRows := gatherMonikersLocations(ctx, state, state.ImplementationData, func(r Range) int { return r.ImplementationResultID })
 	documentation := collectDocumentation(ctx, state)
 	packages := gatherPackages(state)
 	packageReferences, err := gatherPackageReferences(state, packages)
@@ -44,29 +47,49 @@ func groupBundleData(ctx context.Context, state *State) (*precise.GroupedBundleD
 		Documents:             documents,
 		ResultChunks:          resultChunks,
 		Definitions:           definitionRows,
+		TypeDefinitions:       typeDefinitionRows,
 		References:            referenceRows,
+		Implementations:       implementationRows,
 		DocumentationPages:    documentation.pages,
 		DocumentationPathInfo: documentation.pathInfo,
 		DocumentationMappings: documentation.mappings,
 		Packages:              packages,
 		PackageReferences:     packageReferences,
+		Err:                   documentsErr,
 	}, nil
 }
 
-func serializeBundleDocuments(ctx context.Context, state *State) chan precise.KeyedDocumentData {
+// serializeBundleDocuments returns a channel of the serialized documents of the given state, and
+// a function returning the error that stopped serialization early, if any. Hover text that was
+// spilled to disk is read back as each document is serialized.
+func serializeBundleDocuments(ctx context.Context, state *State) (chan precise.KeyedDocumentData, func() error) {
 	ch := make(chan precise.KeyedDocumentData)
+	var serializeErr error
 
 	go func() {
 		defer close(ch)
 
+		defer func() {
+			// Documents are the only consumers of hover text
+			if err := state.HoverSpill.Close(); err != nil {
+				log15.Warn("Failed to remove spill file", "err", err)
+			}
+		}()
+
 		for documentID, uri := range state.DocumentData {
 			if strings.HasPrefix(uri, "..") {
 				continue
 			}
 
+			document, err := serializeDocument(state, documentID)
+			if err != nil {
+				serializeErr = errors.Wrapf(err, "serializing document %q", uri)
+				return
+			}
+
 			data := precise.KeyedDocumentData{
 				Path:     uri,
-				Document: serializeDocument(state, documentID),
+				Document: document,
 			}
 
 			select {
@@ -77,10 +100,10 @@ func serializeBundleDocuments(ctx context.Context, state *State) chan precise.Ke
 		}
 	}()
 
-	return ch
+	return ch, func() error { return serializeErr }
 }
 
-func serializeDocument(state *State, documentID int) precise.DocumentData {
+func serializeDocument(state *State, documentID int) (precise.DocumentData, error) {
 	document := precise.DocumentData{
 		Ranges:             make(map[precise.ID]precise.RangeData, state.Contains.SetLen(documentID)),
 		HoverResults:       map[precise.ID]string{},
@@ -89,6 +112,9 @@ func serializeDocument(state *State, documentID int) precise.DocumentData {
 		Diagnostics:        make([]precise.DiagnosticData, 0, state.Diagnostics.SetLen(documentID)),
 	}
 
+	callables := gatherCallables(state, documentID)
+
+	var hoverErr error
 	state.Contains.SetEach(documentID, func(rangeID int) {
 		rangeData := state.RangeData[rangeID]
 
@@ -113,23 +139,38 @@ func serializeDocument(state *State, documentID int) precise.DocumentData {
 			}
 		})
 
+		var callable *precise.CallableData
+		if extent, ok := callables[rangeID]; ok {
+			callable = &extent
+		}
+
 		document.Ranges[toID(rangeID)] = precise.RangeData{
-			StartLine:             rangeData.Start.Line,
-			StartCharacter:        rangeData.Start.Character,
-			EndLine:               rangeData.End.Line,
-			EndCharacter:          rangeData.End.Character,
-			DefinitionResultID:    toID(rangeData.DefinitionResultID),
-			ReferenceResultID:     toID(rangeData.ReferenceResultID),
-			HoverResultID:         toID(rangeData.HoverResultID),
-			DocumentationResultID: toID(rangeData.DocumentationResultID),
-			MonikerIDs:            monikerIDs,
+			StartLine:              rangeData.Start.Line,
+			StartCharacter:         rangeData.Start.Character,
+			EndLine:                rangeData.End.Line,
+			EndCharacter:           rangeData.End.Character,
+			DefinitionResultID:     toID(rangeData.DefinitionResultID),
+			TypeDefinitionResultID: toID(rangeData.TypeDefinitionResultID),
+			ReferenceResultID:      toID(rangeData.ReferenceResultID),
+			ImplementationResultID: toID(rangeData.ImplementationResultID),
+			HoverResultID:          toID(rangeData.HoverResultID),
+			DocumentationResultID:  toID(rangeData.DocumentationResultID),
+			MonikerIDs:             monikerIDs,
+			Callable:               callable,
 		}
 
-		if rangeData.HoverResultID != 0 {
-			hoverData := state.HoverData[rangeData.HoverResultID]
+		if rangeData.HoverResultID != 0 && hoverErr == nil {
+			hoverData, err := state.hoverData(rangeData.HoverResultID)
+			if err != nil {
+				hoverErr = err
+				return
+			}
 			document.HoverResults[toID(rangeData.HoverResultID)] = hoverData
 		}
 	})
+	if hoverErr != nil {
+		return precise.DocumentData{}, hoverErr
+	}
 
 	state.Diagnostics.SetEach(documentID, func(diagnosticID int) {
 		for _, diagnostic := range state.DiagnosticResults[diagnosticID] {
@@ -146,7 +187,7 @@ func serializeDocument(state *State, documentID int) precise.DocumentData {
 		}
 	})
 
-	return document
+	return document, nil
 }
 
 func serializeResultChunks(ctx context.Context, state *State, numResultChunks int) chan precise.IndexedResultChunkData {
@@ -155,10 +196,18 @@ func serializeResultChunks(ctx context.Context, state *State, numResultChunks in
 		index := precise.HashKey(toID(id), numResultChunks)
 		chunkAssignments[index] = append(chunkAssignments[index], id)
 	}
+	for id := range state.TypeDefinitionData {
+		index := precise.HashKey(toID(id), numResultChunks)
+		chunkAssignments[index] = append(chunkAssignments[index], id)
+	}
 	for id := range state.ReferenceData {
 		index := precise.HashKey(toID(id), numResultChunks)
 		chunkAssignments[index] = append(chunkAssignments[index], id)
 	}
+	for id := range state.ImplementationData {
+		index := precise.HashKey(toID(id), numResultChunks)
+		chunkAssignments[index] = append(chunkAssignments[index], id)
+	}
 
 	ch := make(chan precise.IndexedResultChunkData)
 
@@ -176,7 +225,13 @@ func serializeResultChunks(ctx context.Context, state *State, numResultChunks in
 			for _, resultID := range resultIDs {
 				documentRanges, ok := state.DefinitionData[resultID]
 				if !ok {
-					documentRanges = state.ReferenceData[resultID]
+					documentRanges, ok = state.TypeDefinitionData[resultID]
+				}
+				if !ok {
+					documentRanges, ok = state.ReferenceData[resultID]
+				}
+				if !ok {
+					documentRanges = state.ImplementationData[resultID]
 				}
 
 				rangeIDMap := map[precise.ID]int{}
@@ -40,8 +40,10 @@ func TestGroupBundleData(t *testing.T) {
 						End:   protocol.Pos{Line: 4, Character: 5},
 					},
 				},
-				DefinitionResultID: 0,
-				ReferenceResultID:  3006,
+				DefinitionResultID:     0,
+				TypeDefinitionResultID: 3011,
+				ReferenceResultID:      3006,
+				ImplementationResultID: 3010,
 			},
 			2003: {
 				Range: reader.Range{
@@ -141,6 +143,11 @@ func TestGroupBundleData(t *testing.T) {
 				1003: datastructures.IDSetWith(2008),
 			}),
 		},
+		TypeDefinitionData: map[int]*datastructures.DefaultIDSetMap{
+			3011: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{
+				1001: datastructures.IDSetWith(2001),
+			}),
+		},
 		ReferenceData: map[int]*datastructures.DefaultIDSetMap{
 			3006: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{
 				1001: datastructures.IDSetWith(2003),
@@ -151,6 +158,12 @@ func TestGroupBundleData(t *testing.T) {
 				1003: datastructures.IDSetWith(2007, 2009),
 			}),
 		},
+		ImplementationData: map[int]*datastructures.DefaultIDSetMap{
+			3010: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{
+				1002: datastructures.IDSetWith(2005),
+				1003: datastructures.IDSetWith(2009),
+			}),
+		},
 		HoverData: map[int]string{
 			3008: "foo",
 			3009: "bar",
@@ -350,14 +363,16 @@ func TestGroupBundleData(t *testing.T) {
 					MonikerIDs:         []precise.ID{"4001", "4002"},
 				},
 				"2002": {
-					StartLine:          2,
-					StartCharacter:     3,
-					EndLine:            4,
-					EndCharacter:       5,
-					DefinitionResultID: "",
-					ReferenceResultID:  "3006",
-					HoverResultID:      "",
-					MonikerIDs:         []precise.ID{"4003", "4004"},
+					StartLine:              2,
+					StartCharacter:         3,
+					EndLine:                4,
+					EndCharacter:           5,
+					DefinitionResultID:     "",
+					TypeDefinitionResultID: "3011",
+					ReferenceResultID:      "3006",
+					ImplementationResultID: "3010",
+					HoverResultID:          "",
+					MonikerIDs:             []precise.ID{"4003", "4004"},
 				},
 				"2003": {
 					StartLine:          3,
@@ -585,6 +600,13 @@ func TestGroupBundleData(t *testing.T) {
 					{DocumentID: "1003", RangeID: "2007"},
 					{DocumentID: "1003", RangeID: "2009"},
 				},
+				"3010": {
+					{DocumentID: "1002", RangeID: "2005"},
+					{DocumentID: "1003", RangeID: "2009"},
+				},
+				"3011": {
+					{DocumentID: "1001", RangeID: "2001"},
+				},
 			},
 		},
 	}
@@ -622,6 +644,32 @@ func TestGroupBundleData(t *testing.T) {
 		t.Errorf("unexpected definitions (-want +got):\n%s", diff)
 	}
 
+	var typeDefinitions []precise.MonikerLocations
+	for v := range actualBundleData.TypeDefinitions {
+		typeDefinitions = append(typeDefinitions, v)
+	}
+	sortMonikerLocations(typeDefinitions)
+
+	expectedTypeDefinitions := []precise.MonikerLocations{
+		{
+			Scheme:     "scheme C",
+			Identifier: "ident C",
+			Locations: []precise.LocationData{
+				{URI: "foo.go", StartLine: 1, StartCharacter: 2, EndLine: 3, EndCharacter: 4},
+			},
+		},
+		{
+			Scheme:     "scheme D",
+			Identifier: "ident D",
+			Locations: []precise.LocationData{
+				{URI: "foo.go", StartLine: 1, StartCharacter: 2, EndLine: 3, EndCharacter: 4},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedTypeDefinitions, typeDefinitions); diff != "" {
+		t.Errorf("unexpected type definitions (-want +got):\n%s", diff)
+	}
+
 	var references []precise.MonikerLocations
 	for v := range actualBundleData.References {
 		references = append(references, v)
@@ -651,6 +699,34 @@ func TestGroupBundleData(t *testing.T) {
 	if diff := cmp.Diff(expectedReferences, references); diff != "" {
 		t.Errorf("unexpected references (-want +got):\n%s", diff)
 	}
+
+	var implementations []precise.MonikerLocations
+	for v := range actualBundleData.Implementations {
+		implementations = append(implementations, v)
+	}
+	sortMonikerLocations(implementations)
+
+	expectedImplementations := []precise.MonikerLocations{
+		{
+			Scheme:     "scheme C",
+			Identifier: "ident C",
+			Locations: []precise.LocationData{
+				{URI: "bar.go", StartLine: 5, StartCharacter: 6, EndLine: 7, EndCharacter: 8},
+				{URI: "baz.go", StartLine: 9, StartCharacter: 0, EndLine: 1, EndCharacter: 2},
+			},
+		},
+		{
+			Scheme:     "scheme D",
+			Identifier: "ident D",
+			Locations: []precise.LocationData{
+				{URI: "bar.go", StartLine: 5, StartCharacter: 6, EndLine: 7, EndCharacter: 8},
+				{URI: "baz.go", StartLine: 9, StartCharacter: 0, EndLine: 1, EndCharacter: 2},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedImplementations, implementations); diff != "" {
+		t.Errorf("unexpected implementations (-want +got):\n%s", diff)
+	}
 }
 
 //
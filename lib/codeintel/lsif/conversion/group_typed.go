@@ -0,0 +1,497 @@
+package conversion
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/bloomfilter"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+// groupTypedBundleData converts a typed correlation state into a GroupedBundleData.
+func groupTypedBundleData(ctx context.Context, state *typedState) (*precise.GroupedBundleDataChans, error) {
+	results := formTypedResults(state)
+	numResultChunks := int(math.Max(1, math.Floor(float64(len(results))/resultsPerResultChunk)))
+
+	meta := precise.MetaData{NumResultChunks: numResultChunks}
+	documents, documentsErr := serializeTypedBundleDocuments(ctx, state)
+	resultChunks := serializeTypedResultChunks(ctx, state, results, numResultChunks)
+	definitionRows := gatherTypedMonikersLocations(ctx, state, results, func(s *typedSymbol) int { return s.definitionResultID })
+	typeDefinitionRows := gatherTypedMonikersLocations(ctx, state, results, func(s *typedSymbol) int { return s.typeDefinitionResultID })
+	referenceRows := gatherTypedMonikersLocations(ctx, state, results, func(s *typedSymbol) int { return s.referenceResultID })
+	implementationRows := gatherTypedMonikersLocations(ctx, state, results, func(s *typedSymbol) int { return s.implementationResultID })
+	packages := gatherTypedPackages(state)
+	packageReferences, err := gatherTypedPackageReferences(state, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	// Typed indexes do not carry API documentation
+	documentationPages := make(chan *precise.DocumentationPageData)
+	documentationPathInfo := make(chan *precise.DocumentationPathInfoData)
+	documentationMappings := make(chan precise.DocumentationMapping)
+	close(documentationPages)
+	close(documentationPathInfo)
+	close(documentationMappings)
+
+	return &precise.GroupedBundleDataChans{
+		Meta:                  meta,
+		Documents:             documents,
+		ResultChunks:          resultChunks,
+		Definitions:           definitionRows,
+		TypeDefinitions:       typeDefinitionRows,
+		References:            referenceRows,
+		Implementations:       implementationRows,
+		DocumentationPages:    documentationPages,
+		DocumentationPathInfo: documentationPathInfo,
+		DocumentationMappings: documentationMappings,
+		Packages:              packages,
+		PackageReferences:     packageReferences,
+		Err:                   documentsErr,
+	}, nil
+}
+
+// formTypedResults assigns result, hover, and moniker identifiers to each symbol of the given state
+// and returns the locations of each result. Locations within a result are sorted by document path
+// and then by offset within the document. Results without a location are not assigned an identifier.
+//
+// The definitions of a symbol are the ranges that define it, and its references are all of its
+// occurrences along with the occurrences of each symbol it is a reference of. The implementations
+// of a symbol are the definitions of each symbol implementing it, and its type definitions are the
+// definitions of each symbol it is type-defined by.
+func formTypedResults(state *typedState) map[int][]typedLocation {
+	implementations := map[int][]typedLocation{}
+	for _, symbol := range state.symbols {
+		for _, symbolID := range symbol.implements {
+			implementations[symbolID] = append(implementations[symbolID], symbol.definitions...)
+		}
+	}
+
+	results := map[int][]typedLocation{}
+	addResult := func(locations []typedLocation) int {
+		if locations = normalizeTypedLocations(state, locations); len(locations) == 0 {
+			return 0
+		}
+
+		id := state.nextID()
+		results[id] = locations
+		return id
+	}
+
+	for i, symbol := range state.symbols {
+		references := append([]typedLocation(nil), symbol.occurrences...)
+		for _, symbolID := range symbol.referenceOf {
+			references = append(references, state.symbols[symbolID-1].occurrences...)
+		}
+
+		var typeDefinitions []typedLocation
+		for _, symbolID := range symbol.typeDefinedBy {
+			typeDefinitions = append(typeDefinitions, state.symbols[symbolID-1].definitions...)
+		}
+
+		symbol.definitionResultID = addResult(symbol.definitions)
+		symbol.typeDefinitionResultID = addResult(typeDefinitions)
+		symbol.referenceResultID = addResult(references)
+		symbol.implementationResultID = addResult(implementations[i+1])
+
+		if symbol.hover.exists() {
+			symbol.hoverResultID = state.nextID()
+		}
+
+		if !symbol.isLocal && symbol.symbol.Package.Name != "" {
+			// Symbols defined in this index are exported, all others are imported from a dependency
+			symbol.monikerKind = "import"
+			if len(symbol.definitions) > 0 {
+				symbol.monikerKind = "export"
+			}
+
+			symbol.monikerID = state.nextID()
+			symbol.packageInformationID = state.nextID()
+		}
+	}
+
+	return results
+}
+
+// normalizeTypedLocations removes locations within pruned documents from the given slice, then
+// sorts and deduplicates the remaining locations.
+func normalizeTypedLocations(state *typedState, locations []typedLocation) []typedLocation {
+	filtered := make([]typedLocation, 0, len(locations))
+	for _, location := range locations {
+		if !state.documents[location.documentID-1].pruned {
+			filtered = append(filtered, location)
+		}
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		return compareTypedLocations(state, filtered[i], filtered[j])
+	})
+
+	deduplicated := filtered[:0]
+	for i, location := range filtered {
+		if i == 0 || location != filtered[i-1] {
+			deduplicated = append(deduplicated, location)
+		}
+	}
+
+	return deduplicated
+}
+
+// compareTypedLocations returns true if location a occurs before location b in reading order.
+func compareTypedLocations(state *typedState, a, b typedLocation) bool {
+	if a.documentID != b.documentID {
+		return state.documents[a.documentID-1].path < state.documents[b.documentID-1].path
+	}
+
+	return compareTypedRanges(state.documents[a.documentID-1], a.rangeIndex, b.rangeIndex)
+}
+
+// compareTypedRanges returns true if the range at index i of the given document starts before the
+// range at index j.
+func compareTypedRanges(document *typedDocument, i, j int) bool {
+	ri, rj := document.ranges[i], document.ranges[j]
+	if ri.StartLine != rj.StartLine {
+		return ri.StartLine < rj.StartLine
+	}
+	if ri.StartCharacter != rj.StartCharacter {
+		return ri.StartCharacter < rj.StartCharacter
+	}
+
+	return i < j
+}
+
+// serializeTypedBundleDocuments returns a channel of the serialized documents of the given state,
+// and a function returning the error that stopped serialization early, if any.
+func serializeTypedBundleDocuments(ctx context.Context, state *typedState) (chan precise.KeyedDocumentData, func() error) {
+	ch := make(chan precise.KeyedDocumentData)
+	var serializeErr error
+
+	go func() {
+		defer close(ch)
+
+		defer func() {
+			// Documents are the only consumers of hover text
+			if err := state.hoverSpill.Close(); err != nil {
+				log15.Warn("Failed to remove spill file", "err", err)
+			}
+		}()
+
+		for _, document := range state.documents {
+			if document.pruned || strings.HasPrefix(document.path, "..") {
+				continue
+			}
+
+			serialized, err := serializeTypedDocument(state, document)
+			if err != nil {
+				serializeErr = errors.Wrapf(err, "serializing document %q", document.path)
+				return
+			}
+
+			data := precise.KeyedDocumentData{
+				Path:     document.path,
+				Document: serialized,
+			}
+
+			select {
+			case ch <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, func() error { return serializeErr }
+}
+
+func serializeTypedDocument(state *typedState, document *typedDocument) (precise.DocumentData, error) {
+	data := precise.DocumentData{
+		Ranges:             make(map[precise.ID]precise.RangeData, len(document.ranges)),
+		HoverResults:       map[precise.ID]string{},
+		Monikers:           map[precise.ID]precise.MonikerData{},
+		PackageInformation: map[precise.ID]precise.PackageInformationData{},
+		Diagnostics:        append([]precise.DiagnosticData{}, document.diagnostics...),
+	}
+
+	callables := gatherTypedCallables(state, document)
+
+	for i, r := range document.ranges {
+		rangeData := precise.RangeData{
+			StartLine:      r.StartLine,
+			StartCharacter: r.StartCharacter,
+			EndLine:        r.EndLine,
+			EndCharacter:   r.EndCharacter,
+			Callable:       callables[i],
+		}
+
+		if r.symbolID != 0 {
+			symbol := state.symbols[r.symbolID-1]
+			rangeData.DefinitionResultID = toID(symbol.definitionResultID)
+			rangeData.TypeDefinitionResultID = toID(symbol.typeDefinitionResultID)
+			rangeData.ReferenceResultID = toID(symbol.referenceResultID)
+			rangeData.ImplementationResultID = toID(symbol.implementationResultID)
+
+			if symbol.hoverResultID != 0 {
+				hover, err := state.hoverText(symbol.hover)
+				if err != nil {
+					return precise.DocumentData{}, err
+				}
+
+				rangeData.HoverResultID = toID(symbol.hoverResultID)
+				data.HoverResults[toID(symbol.hoverResultID)] = hover
+			}
+
+			if symbol.monikerID != 0 {
+				rangeData.MonikerIDs = []precise.ID{toID(symbol.monikerID)}
+
+				data.Monikers[toID(symbol.monikerID)] = precise.MonikerData{
+					Kind:                 symbol.monikerKind,
+					Scheme:               symbol.symbol.Scheme,
+					Identifier:           symbol.symbol.Descriptors,
+					PackageInformationID: toID(symbol.packageInformationID),
+				}
+				data.PackageInformation[toID(symbol.packageInformationID)] = precise.PackageInformationData{
+					Name:    symbol.symbol.Package.Name,
+					Version: symbol.symbol.Package.Version,
+				}
+			}
+		}
+
+		if r.hover.exists() {
+			hover, err := state.hoverText(r.hover)
+			if err != nil {
+				return precise.DocumentData{}, err
+			}
+
+			// Override documentation is unique to this range, so we key it by the range identifier
+			rangeData.HoverResultID = toID(r.id)
+			data.HoverResults[toID(r.id)] = hover
+		}
+
+		data.Ranges[toID(r.id)] = rangeData
+	}
+
+	return data, nil
+}
+
+// gatherTypedCallables returns the extent of each function, method, and constructor defined in the
+// given document, keyed by the index of the range defining the callable's name.
+//
+// The extent of a callable is the enclosing range of its defining occurrence. Callables of indexers
+// that do not emit an enclosing range do not enclose any call sites, as described by gatherCallables.
+func gatherTypedCallables(state *typedState, document *typedDocument) map[int]*precise.CallableData {
+	var callables map[int]*precise.CallableData
+	for i, r := range document.ranges {
+		if r.symbolID == 0 || !r.isDefinition {
+			continue
+		}
+		if symbol := state.symbols[r.symbolID-1]; symbol.isLocal || !symbol.symbol.IsCallable() {
+			continue
+		}
+		if callables == nil {
+			callables = map[int]*precise.CallableData{}
+		}
+
+		extent := r.Range
+		if r.extent != nil {
+			extent = *r.extent
+		}
+
+		callables[i] = &precise.CallableData{
+			StartLine:      extent.StartLine,
+			StartCharacter: extent.StartCharacter,
+			EndLine:        extent.EndLine,
+			EndCharacter:   extent.EndCharacter,
+		}
+	}
+
+	return callables
+}
+
+func serializeTypedResultChunks(ctx context.Context, state *typedState, results map[int][]typedLocation, numResultChunks int) chan precise.IndexedResultChunkData {
+	chunkAssignments := make(map[int][]int, numResultChunks)
+	for id := range results {
+		index := precise.HashKey(toID(id), numResultChunks)
+		chunkAssignments[index] = append(chunkAssignments[index], id)
+	}
+
+	ch := make(chan precise.IndexedResultChunkData)
+
+	go func() {
+		defer close(ch)
+
+		for index, resultIDs := range chunkAssignments {
+			documentPaths := map[precise.ID]string{}
+			rangeIDsByResultID := make(map[precise.ID][]precise.DocumentIDRangeID, len(resultIDs))
+
+			for _, resultID := range resultIDs {
+				// Locations are already sorted in reading order by formTypedResults
+				locations := results[resultID]
+				documentIDRangeIDs := make([]precise.DocumentIDRangeID, 0, len(locations))
+
+				for _, location := range locations {
+					document := state.documents[location.documentID-1]
+					documentPaths[toID(location.documentID)] = document.path
+
+					documentIDRangeIDs = append(documentIDRangeIDs, precise.DocumentIDRangeID{
+						DocumentID: toID(location.documentID),
+						RangeID:    toID(document.ranges[location.rangeIndex].id),
+					})
+				}
+
+				rangeIDsByResultID[toID(resultID)] = documentIDRangeIDs
+			}
+
+			data := precise.IndexedResultChunkData{
+				Index: index,
+				ResultChunk: precise.ResultChunkData{
+					DocumentPaths:      documentPaths,
+					DocumentIDRangeIDs: rangeIDsByResultID,
+				},
+			}
+
+			select {
+			case ch <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+func gatherTypedMonikersLocations(ctx context.Context, state *typedState, results map[int][]typedLocation, getResultID func(s *typedSymbol) int) chan precise.MonikerLocations {
+	idsBySchemeByIdentifier := map[string]map[string][]int{}
+	for _, symbol := range state.symbols {
+		resultID := getResultID(symbol)
+		if symbol.monikerID == 0 || resultID == 0 {
+			continue
+		}
+
+		idsByIdentifier, ok := idsBySchemeByIdentifier[symbol.symbol.Scheme]
+		if !ok {
+			idsByIdentifier = map[string][]int{}
+			idsBySchemeByIdentifier[symbol.symbol.Scheme] = idsByIdentifier
+		}
+		idsByIdentifier[symbol.symbol.Descriptors] = append(idsByIdentifier[symbol.symbol.Descriptors], resultID)
+	}
+
+	ch := make(chan precise.MonikerLocations)
+
+	go func() {
+		defer close(ch)
+
+		for scheme, idsByIdentifier := range idsBySchemeByIdentifier {
+			for identifier, ids := range idsByIdentifier {
+				var locations []precise.LocationData
+				for _, id := range ids {
+					for _, location := range results[id] {
+						document := state.documents[location.documentID-1]
+						if strings.HasPrefix(document.path, "..") {
+							continue
+						}
+
+						r := document.ranges[location.rangeIndex]
+						locations = append(locations, precise.LocationData{
+							URI:            document.path,
+							StartLine:      r.StartLine,
+							StartCharacter: r.StartCharacter,
+							EndLine:        r.EndLine,
+							EndCharacter:   r.EndCharacter,
+						})
+					}
+				}
+
+				if len(locations) == 0 {
+					continue
+				}
+
+				sort.Sort(sortableLocations(locations))
+
+				data := precise.MonikerLocations{
+					Scheme:     scheme,
+					Identifier: identifier,
+					Locations:  locations,
+				}
+
+				select {
+				case ch <- data:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+func gatherTypedPackages(state *typedState) []precise.Package {
+	uniques := map[string]precise.Package{}
+	for _, symbol := range state.symbols {
+		if symbol.monikerKind != "export" {
+			continue
+		}
+
+		uniques[makeKey(symbol.symbol.Scheme, symbol.symbol.Package.Name, symbol.symbol.Package.Version)] = precise.Package{
+			Scheme:  symbol.symbol.Scheme,
+			Name:    symbol.symbol.Package.Name,
+			Version: symbol.symbol.Package.Version,
+		}
+	}
+
+	packages := make([]precise.Package, 0, len(uniques))
+	for _, v := range uniques {
+		packages = append(packages, v)
+	}
+
+	return packages
+}
+
+func gatherTypedPackageReferences(state *typedState, packageDefinitions []precise.Package) ([]precise.PackageReference, error) {
+	packageDefinitionKeySet := make(map[string]struct{}, len(packageDefinitions))
+	for _, pkg := range packageDefinitions {
+		packageDefinitionKeySet[makeKey(pkg.Scheme, pkg.Name, pkg.Version)] = struct{}{}
+	}
+
+	packagesByKey := map[string]precise.Package{}
+	identifiersByKey := map[string][]string{}
+	for _, symbol := range state.symbols {
+		if symbol.monikerKind != "import" {
+			continue
+		}
+
+		key := makeKey(symbol.symbol.Scheme, symbol.symbol.Package.Name, symbol.symbol.Package.Version)
+		if _, ok := packageDefinitionKeySet[key]; ok {
+			// Do not store self-references (see gatherPackageReferences)
+			continue
+		}
+
+		packagesByKey[key] = precise.Package{
+			Scheme:  symbol.symbol.Scheme,
+			Name:    symbol.symbol.Package.Name,
+			Version: symbol.symbol.Package.Version,
+		}
+		identifiersByKey[key] = append(identifiersByKey[key], symbol.symbol.Descriptors)
+	}
+
+	packageReferences := make([]precise.PackageReference, 0, len(packagesByKey))
+	for key, pkg := range packagesByKey {
+		filter, err := bloomfilter.CreateFilter(identifiersByKey[key])
+		if err != nil {
+			return nil, errors.Wrap(err, "bloomfilter.CreateFilter")
+		}
+
+		packageReferences = append(packageReferences, precise.PackageReference{
+			Package: pkg,
+			Filter:  filter,
+		})
+	}
+
+	return packageReferences, nil
+}
@@ -30,7 +30,9 @@ func prune(ctx context.Context, state *State, root string, getChildren pathexist
 	}
 
 	pruneFromDefinitionReferences(state, state.DefinitionData)
+	pruneFromDefinitionReferences(state, state.TypeDefinitionData)
 	pruneFromDefinitionReferences(state, state.ReferenceData)
+	pruneFromDefinitionReferences(state, state.ImplementationData)
 	return nil
 }
 
@@ -0,0 +1,226 @@
+package conversion
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+
+	"github.com/cockroachdb/errors"
+)
+
+// memoryCheckInterval is the number of elements (or occurrences of a typed index) read between
+// checks of the heap size against the hover spill threshold of a correlation.
+const memoryCheckInterval = 100000
+
+// spillStore holds strings that were moved out of the correlation state and into a temporary
+// file to reduce memory usage. Values can be written until Flush is called, after which they
+// can be read concurrently.
+type spillStore struct {
+	file    *os.File
+	writer  *bufio.Writer
+	size    int64
+	offsets map[int]spillLocation
+}
+
+// spillLocation is the position of a single value within a spill file.
+type spillLocation struct {
+	offset int64
+	length int
+}
+
+// newSpillStore creates a spill store backed by a new temporary file in the given directory.
+// The default directory for temporary files is used if dir is empty.
+func newSpillStore(dir string) (*spillStore, error) {
+	file, err := os.CreateTemp(dir, "lsif-spill-*")
+	if err != nil {
+		return nil, errors.Wrap(err, "creating spill file")
+	}
+
+	return &spillStore{
+		file:    file,
+		writer:  bufio.NewWriter(file),
+		offsets: map[int]spillLocation{},
+	}, nil
+}
+
+// Set writes the given value to the spill file.
+func (s *spillStore) Set(id int, value string) error {
+	if _, err := s.writer.WriteString(value); err != nil {
+		return errors.Wrap(err, "writing spill file")
+	}
+
+	s.offsets[id] = spillLocation{offset: s.size, length: len(value)}
+	s.size += int64(len(value))
+	return nil
+}
+
+// Has returns true if a value with the given identifier has been written to the spill file.
+func (s *spillStore) Has(id int) bool {
+	_, ok := s.offsets[id]
+	return ok
+}
+
+// Get reads the value with the given identifier from the spill file. A false-valued flag is
+// returned if no such value was written.
+func (s *spillStore) Get(id int) (string, bool, error) {
+	location, ok := s.offsets[id]
+	if !ok {
+		return "", false, nil
+	}
+
+	buf := make([]byte, location.length)
+	if _, err := s.file.ReadAt(buf, location.offset); err != nil {
+		return "", false, errors.Wrap(err, "reading spill file")
+	}
+
+	return string(buf), true, nil
+}
+
+// Flush writes any buffered values to the spill file.
+func (s *spillStore) Flush() error {
+	return errors.Wrap(s.writer.Flush(), "writing spill file")
+}
+
+// Close closes and removes the spill file. It is safe to call Close on a nil spill store.
+func (s *spillStore) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	closeErr := s.file.Close()
+	if err := os.Remove(s.file.Name()); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "removing spill file")
+	}
+
+	return closeErr
+}
+
+// spillHoverData moves all hover text of the given correlation state into a new spill store.
+// Hover text added to the state afterwards is written directly to the spill store.
+//
+// Hover text is often the bulk of the correlation state for large indexes, and is not needed
+// until documents are serialized one at a time when the state is grouped.
+func spillHoverData(state *State, dir string) error {
+	store, err := newSpillStore(dir)
+	if err != nil {
+		return err
+	}
+
+	for id, text := range state.HoverData {
+		if err := store.Set(id, text); err != nil {
+			_ = store.Close()
+			return err
+		}
+	}
+
+	state.HoverData = map[int]string{}
+	state.HoverSpill = store
+	return nil
+}
+
+// setHoverData adds the given hover text to the correlation state.
+func (s *State) setHoverData(id int, text string) error {
+	if s.HoverSpill != nil {
+		return s.HoverSpill.Set(id, text)
+	}
+
+	s.HoverData[id] = text
+	return nil
+}
+
+// hasHoverData returns true if the correlation state has hover text with the given identifier.
+func (s *State) hasHoverData(id int) bool {
+	if _, ok := s.HoverData[id]; ok {
+		return true
+	}
+
+	return s.HoverSpill != nil && s.HoverSpill.Has(id)
+}
+
+// hoverData returns the hover text with the given identifier, reading it from the spill file if
+// it was moved out of memory.
+func (s *State) hoverData(id int) (string, error) {
+	if text, ok := s.HoverData[id]; ok || s.HoverSpill == nil {
+		return text, nil
+	}
+
+	text, _, err := s.HoverSpill.Get(id)
+	return text, err
+}
+
+// heapSize returns the number of bytes of allocated heap objects.
+func heapSize() int64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc)
+}
+
+// spillTypedHovers moves all hover text of the given typed correlation state into a new spill store.
+// Hover text added to the state afterwards is written directly to the spill store.
+func spillTypedHovers(state *typedState) error {
+	store, err := newSpillStore(state.hoverSpillDir)
+	if err != nil {
+		return err
+	}
+
+	spill := func(hover *typedHover) error {
+		if hover.text == "" {
+			return nil
+		}
+
+		state.lastHoverSpillID++
+		id := state.lastHoverSpillID
+		if err := store.Set(id, hover.text); err != nil {
+			return err
+		}
+
+		*hover = typedHover{spillID: id}
+		return nil
+	}
+
+	for _, symbol := range state.symbols {
+		if err := spill(&symbol.hover); err != nil {
+			_ = store.Close()
+			return err
+		}
+	}
+	for _, document := range state.documents {
+		for i := range document.ranges {
+			if err := spill(&document.ranges[i].hover); err != nil {
+				_ = store.Close()
+				return err
+			}
+		}
+	}
+
+	state.hoverSpill = store
+	return nil
+}
+
+// setHover sets the given hover text of the typed correlation state.
+func (s *typedState) setHover(hover *typedHover, text string) error {
+	if s.hoverSpill == nil || text == "" {
+		*hover = typedHover{text: text}
+		return nil
+	}
+
+	s.lastHoverSpillID++
+	id := s.lastHoverSpillID
+	if err := s.hoverSpill.Set(id, text); err != nil {
+		return err
+	}
+
+	*hover = typedHover{spillID: id}
+	return nil
+}
+
+// hoverText returns the given hover text of the typed correlation state, reading it from the spill
+// file if it was moved out of memory.
+func (s *typedState) hoverText(hover typedHover) (string, error) {
+	if hover.spillID == 0 {
+		return hover.text, nil
+	}
+
+	text, _, err := s.hoverSpill.Get(hover.spillID)
+	return text, err
+}
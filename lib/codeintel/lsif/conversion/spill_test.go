@@ -0,0 +1,191 @@
+package conversion
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsiftyped"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+func TestSpillHoverData(t *testing.T) {
+	state := newState()
+	state.HoverData[16] = "text A"
+	state.HoverData[17] = "text B"
+
+	if err := spillHoverData(state, t.TempDir()); err != nil {
+		t.Fatalf("unexpected error spilling hover data: %s", err)
+	}
+	defer state.HoverSpill.Close()
+
+	if len(state.HoverData) != 0 {
+		t.Errorf("expected hover data to be moved out of memory")
+	}
+
+	// Hover text added after spilling goes directly to disk
+	if err := state.setHoverData(18, "text C"); err != nil {
+		t.Fatalf("unexpected error setting hover data: %s", err)
+	}
+	if err := state.HoverSpill.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing spill file: %s", err)
+	}
+
+	for id, expected := range map[int]string{16: "text A", 17: "text B", 18: "text C", 19: ""} {
+		if has := state.hasHoverData(id); has != (expected != "") {
+			t.Errorf("unexpected presence of hover data %d. want=%v have=%v", id, expected != "", has)
+		}
+
+		text, err := state.hoverData(id)
+		if err != nil {
+			t.Fatalf("unexpected error reading hover data %d: %s", id, err)
+		}
+		if text != expected {
+			t.Errorf("unexpected hover data %d. want=%q have=%q", id, expected, text)
+		}
+	}
+}
+
+func TestCorrelateWithHoverSpillThreshold(t *testing.T) {
+	input, err := os.ReadFile("../testdata/dump1.lsif")
+	if err != nil {
+		t.Fatalf("unexpected error reading test file: %s", err)
+	}
+
+	expected, err := Correlate(context.Background(), bytes.NewReader(input), "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error correlating input: %s", err)
+	}
+
+	tempDir := t.TempDir()
+	options := CorrelateOptions{
+		HoverSpillThreshold: 1, // spill as early as possible
+		TempDir:             tempDir,
+	}
+	actual, err := CorrelateWithOptions(context.Background(), bytes.NewReader(input), "", nil, options)
+	if err != nil {
+		t.Fatalf("unexpected error correlating input: %s", err)
+	}
+
+	expectedBundleData := precise.GroupedBundleDataChansToMaps(expected)
+	actualBundleData := precise.GroupedBundleDataChansToMaps(actual)
+	if diff := cmp.Diff(expectedBundleData, actualBundleData); diff != "" {
+		t.Errorf("unexpected bundle data (-want +got):\n%s", diff)
+	}
+	if len(actualBundleData.Documents["root/bar.go"].HoverResults) == 0 {
+		t.Errorf("expected hover results to be read from the spill file")
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("unexpected error reading temp dir: %s", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected spill file to be removed. have=%d entries", len(entries))
+	}
+}
+
+func TestCorrelateTypedWithHoverSpillThreshold(t *testing.T) {
+	index := lsiftyped.Index{
+		Metadata: lsiftyped.Metadata{
+			ToolInfo:    lsiftyped.ToolInfo{Name: "lsif-test"},
+			ProjectRoot: "file:///test/root",
+		},
+		Documents: []lsiftyped.Document{
+			{
+				RelativePath: "foo.go",
+				Occurrences: []lsiftyped.Occurrence{
+					{Range: []int32{1, 5, 8}, Symbol: typedFooSymbol, SymbolRoles: lsiftyped.SymbolRoleDefinition},
+					{Range: []int32{2, 1, 4}, Symbol: typedBarSymbol, OverrideDocumentation: []string{"overridden"}},
+				},
+				Symbols: []lsiftyped.SymbolInformation{
+					{Symbol: typedFooSymbol, Documentation: []string{"Foo does things."}},
+				},
+			},
+			{
+				// Read after hover text has been spilled
+				RelativePath: "bar.go",
+				Occurrences: []lsiftyped.Occurrence{
+					{Range: []int32{0, 5, 10}, Symbol: typedFooerSymbol, SymbolRoles: lsiftyped.SymbolRoleDefinition},
+					{Range: []int32{4, 1, 4}, Symbol: typedFooSymbol, OverrideDocumentation: []string{"also overridden"}},
+				},
+				Symbols: []lsiftyped.SymbolInformation{
+					{Symbol: typedFooerSymbol, Documentation: []string{"Fooer#Foo does things."}},
+				},
+			},
+		},
+		ExternalSymbols: []lsiftyped.SymbolInformation{
+			{Symbol: typedBarSymbol, Documentation: []string{"Bar does other things."}},
+		},
+	}
+	input := lsiftyped.MarshalIndex(index)
+
+	expected, err := CorrelateTyped(context.Background(), bytes.NewReader(input), "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error correlating typed index: %s", err)
+	}
+
+	tempDir := t.TempDir()
+	options := CorrelateOptions{
+		HoverSpillThreshold: 1, // spill as early as possible
+		TempDir:             tempDir,
+	}
+	actual, err := CorrelateTypedWithOptions(context.Background(), bytes.NewReader(input), "", nil, options)
+	if err != nil {
+		t.Fatalf("unexpected error correlating typed index: %s", err)
+	}
+
+	expectedBundleData := precise.GroupedBundleDataChansToMaps(expected)
+	actualBundleData := precise.GroupedBundleDataChansToMaps(actual)
+	if err := actual.Err(); err != nil {
+		t.Fatalf("unexpected error serializing documents: %s", err)
+	}
+	if diff := cmp.Diff(expectedBundleData, actualBundleData); diff != "" {
+		t.Errorf("unexpected bundle data (-want +got):\n%s", diff)
+	}
+	if len(actualBundleData.Documents["bar.go"].HoverResults) != 3 {
+		t.Errorf("unexpected number of hover results. want=%d have=%d", 3, len(actualBundleData.Documents["bar.go"].HoverResults))
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("unexpected error reading temp dir: %s", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected spill file to be removed. have=%d entries", len(entries))
+	}
+}
+
+func TestGroupBundleDataSpillReadError(t *testing.T) {
+	state := newState()
+	state.DocumentData[1] = "foo.go"
+	state.RangeData[2] = Range{HoverResultID: 3}
+	state.Contains.SetAdd(1, 2)
+	state.HoverData[3] = "text A"
+
+	if err := spillHoverData(state, t.TempDir()); err != nil {
+		t.Fatalf("unexpected error spilling hover data: %s", err)
+	}
+	if err := state.HoverSpill.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing spill file: %s", err)
+	}
+
+	// Make reads of the spill file fail
+	_ = state.HoverSpill.file.Close()
+
+	chans, err := groupBundleData(context.Background(), state)
+	if err != nil {
+		t.Fatalf("unexpected error grouping bundle data: %s", err)
+	}
+
+	bundleData := precise.GroupedBundleDataChansToMaps(chans)
+	if len(bundleData.Documents) != 0 {
+		t.Errorf("unexpected documents. want=%d have=%d", 0, len(bundleData.Documents))
+	}
+	if err := chans.Err(); err == nil {
+		t.Fatalf("expected an error reading spilled hover text")
+	}
+}
@@ -13,8 +13,11 @@ type State struct {
 	RangeData              map[int]Range
 	ResultSetData          map[int]ResultSet
 	DefinitionData         map[int]*datastructures.DefaultIDSetMap
+	TypeDefinitionData     map[int]*datastructures.DefaultIDSetMap
 	ReferenceData          map[int]*datastructures.DefaultIDSetMap
+	ImplementationData     map[int]*datastructures.DefaultIDSetMap
 	HoverData              map[int]string
+	HoverSpill             *spillStore // hover text moved out of HoverData under memory pressure (possibly nil)
 	MonikerData            map[int]Moniker
 	PackageInformationData map[int]PackageInformation
 	DiagnosticResults      map[int][]Diagnostic
@@ -43,7 +46,9 @@ func newState() *State {
 		RangeData:              map[int]Range{},
 		ResultSetData:          map[int]ResultSet{},
 		DefinitionData:         map[int]*datastructures.DefaultIDSetMap{},
+		TypeDefinitionData:     map[int]*datastructures.DefaultIDSetMap{},
 		ReferenceData:          map[int]*datastructures.DefaultIDSetMap{},
+		ImplementationData:     map[int]*datastructures.DefaultIDSetMap{},
 		HoverData:              map[int]string{},
 		MonikerData:            map[int]Moniker{},
 		PackageInformationData: map[int]PackageInformation{},
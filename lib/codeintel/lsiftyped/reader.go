@@ -0,0 +1,343 @@
+package lsiftyped
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/cockroachdb/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MaxMessageSize is the maximum size of a single top-level field of an index (e.g., a single
+// document). Larger fields are rejected rather than buffered in memory.
+const MaxMessageSize = 64 << 20
+
+// Visitor receives the top-level fields of an index as they are decoded by ReadIndex. Nil
+// functions are skipped.
+type Visitor struct {
+	VisitMetadata       func(metadata Metadata) error
+	VisitDocument       func(document Document) error
+	VisitExternalSymbol func(symbol SymbolInformation) error
+}
+
+// IsIndex returns true if the given prefix of an upload is the start of a protobuf-encoded index
+// rather than of newline-delimited LSIF JSON. The first byte of an encoded index is the tag of one
+// of the top-level fields of the Index message, none of which can start a JSON object.
+func IsIndex(prefix []byte) bool {
+	if len(prefix) == 0 {
+		return false
+	}
+
+	num, typ := protowire.DecodeTag(uint64(prefix[0]))
+	return typ == protowire.BytesType && num >= 1 && num <= 3
+}
+
+// ReadIndex decodes a protobuf-encoded Index message from the given reader. The top-level fields
+// of the index are decoded one at a time and passed to the given visitor, so that only a single
+// document needs to be held in memory at once.
+func ReadIndex(r io.Reader, visitor Visitor) error {
+	br := bufio.NewReader(r)
+
+	var buf bytes.Buffer
+	for {
+		tag, err := binary.ReadUvarint(br)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.Wrap(err, "reading field tag")
+		}
+
+		num, typ := protowire.DecodeTag(tag)
+		if typ != protowire.BytesType {
+			if err := skipField(br, typ); err != nil {
+				return err
+			}
+			continue
+		}
+
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return errors.Wrap(err, "reading field length")
+		}
+		if size > MaxMessageSize {
+			return errors.Errorf("field %d exceeds maximum message size (%d > %d bytes)", num, size, MaxMessageSize)
+		}
+
+		// The buffer grows with the bytes actually read rather than to the declared size of the
+		// field, so that a malformed length prefix cannot force a large allocation.
+		buf.Reset()
+		if n, err := io.CopyN(&buf, br, int64(size)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return errors.Wrapf(err, "reading field (%d of %d bytes)", n, size)
+		}
+
+		switch {
+		case num == 1 && visitor.VisitMetadata != nil:
+			var metadata Metadata
+			if err := decodeMessage(buf.Bytes(), metadata.decodeField); err != nil {
+				return errors.Wrap(err, "decoding metadata")
+			}
+			if err := visitor.VisitMetadata(metadata); err != nil {
+				return err
+			}
+
+		case num == 2 && visitor.VisitDocument != nil:
+			var document Document
+			if err := decodeMessage(buf.Bytes(), document.decodeField); err != nil {
+				return errors.Wrap(err, "decoding document")
+			}
+			if err := visitor.VisitDocument(document); err != nil {
+				return err
+			}
+
+		case num == 3 && visitor.VisitExternalSymbol != nil:
+			var symbol SymbolInformation
+			if err := decodeMessage(buf.Bytes(), symbol.decodeField); err != nil {
+				return errors.Wrap(err, "decoding external symbol")
+			}
+			if err := visitor.VisitExternalSymbol(symbol); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// skipField discards the value of a top-level field with a non-length-delimited wire type.
+func skipField(br *bufio.Reader, typ protowire.Type) error {
+	var err error
+	switch typ {
+	case protowire.VarintType:
+		_, err = binary.ReadUvarint(br)
+	case protowire.Fixed32Type:
+		_, err = br.Discard(4)
+	case protowire.Fixed64Type:
+		_, err = br.Discard(8)
+	default:
+		return errors.Errorf("unsupported wire type %d", typ)
+	}
+
+	return errors.Wrap(err, "skipping field")
+}
+
+// fieldDecoder decodes the value of a single field from the given buffer and returns the number of
+// bytes consumed. A negative return value denotes a protowire parse error.
+type fieldDecoder func(num protowire.Number, typ protowire.Type, b []byte) int
+
+// decodeMessage invokes the given field decoder for each field of the given encoded message.
+func decodeMessage(b []byte, decodeField fieldDecoder) error {
+	if n := decodeFields(b, decodeField); n < 0 {
+		return protowire.ParseError(n)
+	}
+
+	return nil
+}
+
+// decodeFields invokes the given field decoder for each field of the given encoded message. A
+// negative return value denotes a protowire parse error.
+func decodeFields(b []byte, decodeField fieldDecoder) int {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return n
+		}
+		b = b[n:]
+
+		if n = decodeField(num, typ, b); n < 0 {
+			return n
+		}
+		b = b[n:]
+	}
+
+	return 0
+}
+
+func (m *Metadata) decodeField(num protowire.Number, typ protowire.Type, b []byte) int {
+	switch {
+	case num == 1 && typ == protowire.BytesType:
+		return consumeMessage(b, m.ToolInfo.decodeField)
+	case num == 2 && typ == protowire.BytesType:
+		return consumeString(b, &m.ProjectRoot)
+	}
+
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
+
+func (t *ToolInfo) decodeField(num protowire.Number, typ protowire.Type, b []byte) int {
+	switch {
+	case num == 1 && typ == protowire.BytesType:
+		return consumeString(b, &t.Name)
+	case num == 2 && typ == protowire.BytesType:
+		return consumeString(b, &t.Version)
+	case num == 3 && typ == protowire.BytesType:
+		return consumeRepeatedString(b, &t.Arguments)
+	}
+
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
+
+func (d *Document) decodeField(num protowire.Number, typ protowire.Type, b []byte) int {
+	switch {
+	case num == 1 && typ == protowire.BytesType:
+		return consumeString(b, &d.RelativePath)
+
+	case num == 2 && typ == protowire.BytesType:
+		var occurrence Occurrence
+		n := consumeMessage(b, occurrence.decodeField)
+		d.Occurrences = append(d.Occurrences, occurrence)
+		return n
+
+	case num == 3 && typ == protowire.BytesType:
+		var symbol SymbolInformation
+		n := consumeMessage(b, symbol.decodeField)
+		d.Symbols = append(d.Symbols, symbol)
+		return n
+	}
+
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
+
+func (s *SymbolInformation) decodeField(num protowire.Number, typ protowire.Type, b []byte) int {
+	switch {
+	case num == 1 && typ == protowire.BytesType:
+		return consumeString(b, &s.Symbol)
+
+	case num == 2 && typ == protowire.BytesType:
+		return consumeRepeatedString(b, &s.Documentation)
+
+	case num == 3 && typ == protowire.BytesType:
+		var relationship Relationship
+		n := consumeMessage(b, relationship.decodeField)
+		s.Relationships = append(s.Relationships, relationship)
+		return n
+	}
+
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
+
+func (r *Relationship) decodeField(num protowire.Number, typ protowire.Type, b []byte) int {
+	switch {
+	case num == 1 && typ == protowire.BytesType:
+		return consumeString(b, &r.Symbol)
+	case num == 2 && typ == protowire.VarintType:
+		return consumeBool(b, &r.IsReference)
+	case num == 3 && typ == protowire.VarintType:
+		return consumeBool(b, &r.IsImplementation)
+	case num == 4 && typ == protowire.VarintType:
+		return consumeBool(b, &r.IsTypeDefinition)
+	}
+
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
+
+func (o *Occurrence) decodeField(num protowire.Number, typ protowire.Type, b []byte) int {
+	switch {
+	case num == 1:
+		return consumeInt32s(num, typ, b, &o.Range)
+	case num == 2 && typ == protowire.BytesType:
+		return consumeString(b, &o.Symbol)
+	case num == 3 && typ == protowire.VarintType:
+		v, n := protowire.ConsumeVarint(b)
+		o.SymbolRoles = SymbolRole(int32(v))
+		return n
+	case num == 4 && typ == protowire.BytesType:
+		return consumeRepeatedString(b, &o.OverrideDocumentation)
+
+	case num == 5 && typ == protowire.BytesType:
+		var diagnostic Diagnostic
+		n := consumeMessage(b, diagnostic.decodeField)
+		o.Diagnostics = append(o.Diagnostics, diagnostic)
+		return n
+
+	case num == 6:
+		return consumeInt32s(num, typ, b, &o.EnclosingRange)
+	}
+
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
+
+func (d *Diagnostic) decodeField(num protowire.Number, typ protowire.Type, b []byte) int {
+	switch {
+	case num == 1 && typ == protowire.VarintType:
+		v, n := protowire.ConsumeVarint(b)
+		d.Severity = int32(v)
+		return n
+	case num == 2 && typ == protowire.BytesType:
+		return consumeString(b, &d.Code)
+	case num == 3 && typ == protowire.BytesType:
+		return consumeString(b, &d.Message)
+	case num == 4 && typ == protowire.BytesType:
+		return consumeString(b, &d.Source)
+	}
+
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
+
+// consumeMessage decodes the length-delimited message at the head of the given buffer.
+func consumeMessage(b []byte, decodeField fieldDecoder) int {
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return n
+	}
+	if m := decodeFields(v, decodeField); m < 0 {
+		return m
+	}
+
+	return n
+}
+
+func consumeString(b []byte, dst *string) int {
+	v, n := protowire.ConsumeString(b)
+	*dst = v
+	return n
+}
+
+func consumeRepeatedString(b []byte, dst *[]string) int {
+	v, n := protowire.ConsumeString(b)
+	if n >= 0 {
+		*dst = append(*dst, v)
+	}
+	return n
+}
+
+func consumeBool(b []byte, dst *bool) int {
+	v, n := protowire.ConsumeVarint(b)
+	*dst = protowire.DecodeBool(v)
+	return n
+}
+
+// consumeInt32s decodes a repeated int32 field, which may be encoded either packed (as a single
+// length-delimited field) or unpacked (as one varint field per element).
+func consumeInt32s(num protowire.Number, typ protowire.Type, b []byte, dst *[]int32) int {
+	switch typ {
+	case protowire.VarintType:
+		v, n := protowire.ConsumeVarint(b)
+		if n >= 0 {
+			*dst = append(*dst, int32(v))
+		}
+		return n
+
+	case protowire.BytesType:
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return n
+		}
+
+		for len(v) > 0 {
+			x, m := protowire.ConsumeVarint(v)
+			if m < 0 {
+				return m
+			}
+			*dst = append(*dst, int32(x))
+			v = v[m:]
+		}
+
+		return n
+	}
+
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
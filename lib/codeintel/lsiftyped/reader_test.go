@@ -0,0 +1,174 @@
+package lsiftyped
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestReadIndex(t *testing.T) {
+	metadata := Metadata{
+		ToolInfo:    ToolInfo{Name: "lsif-test", Version: "0.1.0", Arguments: []string{"--verbose"}},
+		ProjectRoot: "file:///src/project",
+	}
+	documents := []Document{
+		{
+			RelativePath: "foo.go",
+			Occurrences: []Occurrence{
+				{Range: []int32{1, 5, 8}, Symbol: "gomod example.com/foo v1.0.0 foo/Foo().", SymbolRoles: SymbolRoleDefinition, EnclosingRange: []int32{1, 0, 3, 1}},
+				{Range: []int32{2, 1, 2, 4}, Symbol: "local 1", SymbolRoles: SymbolRoleWriteAccess | SymbolRoleDefinition},
+				{Range: []int32{2, 8, 12}, Symbol: "gomod example.com/bar v2.0.0 bar/Bar().", Diagnostics: []Diagnostic{{Severity: 2, Code: "SA1019", Message: "deprecated", Source: "staticcheck"}}},
+			},
+			Symbols: []SymbolInformation{
+				{
+					Symbol:        "gomod example.com/foo v1.0.0 foo/Foo().",
+					Documentation: []string{"```go\nfunc Foo()\n```", "Foo does things."},
+					Relationships: []Relationship{{Symbol: "gomod example.com/foo v1.0.0 foo/Fooer#Foo().", IsImplementation: true}},
+				},
+			},
+		},
+		{
+			RelativePath: "bar.go",
+			Occurrences: []Occurrence{
+				{Range: []int32{-1, 0, 0}, OverrideDocumentation: []string{"negative"}},
+			},
+		},
+	}
+	externalSymbols := []SymbolInformation{
+		{Symbol: "gomod example.com/bar v2.0.0 bar/Bar().", Documentation: []string{"Bar does other things."}},
+	}
+
+	// Write each top-level field as a separate message
+	var buf bytes.Buffer
+	buf.Write(MarshalIndex(Index{Metadata: metadata}))
+	for _, document := range documents {
+		buf.Write(MarshalIndex(Index{Documents: []Document{document}}))
+	}
+	buf.Write(MarshalIndex(Index{ExternalSymbols: externalSymbols}))
+
+	if !IsIndex(buf.Bytes()) {
+		t.Fatalf("expected encoded index to be detected")
+	}
+
+	index, err := readIndex(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading index: %s", err)
+	}
+
+	expectedIndex := Index{
+		Metadata:        metadata,
+		Documents:       documents,
+		ExternalSymbols: externalSymbols,
+	}
+	if diff := cmp.Diff(expectedIndex, index); diff != "" {
+		t.Errorf("unexpected index (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadIndexUnpackedAndUnknownFields(t *testing.T) {
+	var occurrence []byte
+	for _, v := range []uint64{3, 4, 7} {
+		occurrence = protowire.AppendTag(occurrence, 1, protowire.VarintType)
+		occurrence = protowire.AppendVarint(occurrence, v)
+	}
+	occurrence = protowire.AppendTag(occurrence, 99, protowire.Fixed64Type)
+	occurrence = protowire.AppendFixed64(occurrence, 42)
+
+	var document []byte
+	document = protowire.AppendTag(document, 1, protowire.BytesType)
+	document = protowire.AppendString(document, "baz.go")
+	document = protowire.AppendTag(document, 2, protowire.BytesType)
+	document = protowire.AppendBytes(document, occurrence)
+
+	var index []byte
+	index = protowire.AppendTag(index, 15, protowire.VarintType)
+	index = protowire.AppendVarint(index, 1)
+	index = protowire.AppendTag(index, 2, protowire.BytesType)
+	index = protowire.AppendBytes(index, document)
+
+	decoded, err := readIndex(bytes.NewReader(index))
+	if err != nil {
+		t.Fatalf("unexpected error reading index: %s", err)
+	}
+
+	expectedDocuments := []Document{{RelativePath: "baz.go", Occurrences: []Occurrence{{Range: []int32{3, 4, 7}}}}}
+	if diff := cmp.Diff(expectedDocuments, decoded.Documents); diff != "" {
+		t.Errorf("unexpected documents (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadIndexTruncated(t *testing.T) {
+	encoded := MarshalIndex(Index{Documents: []Document{{RelativePath: "foo.go"}}})
+
+	if _, err := readIndex(bytes.NewReader(encoded[:len(encoded)-1])); err == nil {
+		t.Fatalf("expected error reading truncated index")
+	}
+}
+
+func TestReadIndexFieldSize(t *testing.T) {
+	// A document declaring a size just under the maximum, followed by a few bytes
+	truncated := protowire.AppendTag(nil, 2, protowire.BytesType)
+	truncated = protowire.AppendVarint(truncated, MaxMessageSize-1)
+	truncated = append(truncated, 0x0a, 0x01, 'x')
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := readIndex(bytes.NewReader(truncated)); err == nil {
+		t.Fatalf("expected error reading truncated field")
+	}
+	runtime.ReadMemStats(&after)
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("unexpected allocation for a truncated field. want<=%d have=%d bytes", 1<<20, allocated)
+	}
+
+	oversized := protowire.AppendTag(nil, 2, protowire.BytesType)
+	oversized = protowire.AppendVarint(oversized, MaxMessageSize+1)
+
+	if _, err := readIndex(bytes.NewReader(oversized)); err == nil {
+		t.Fatalf("expected error reading oversized field")
+	}
+}
+
+func TestIsIndex(t *testing.T) {
+	testCases := []struct {
+		prefix   string
+		expected bool
+	}{
+		{"", false},
+		{`{"id":1,"type":"vertex","label":"metaData"}`, false},
+		{"\x0a\x00", true},
+		{"\x12\x00", true},
+		{"\x1a\x00", true},
+		{"\x08\x01", false},
+	}
+
+	for _, testCase := range testCases {
+		if actual := IsIndex([]byte(testCase.prefix)); actual != testCase.expected {
+			t.Errorf("unexpected result for %q. want=%v have=%v", testCase.prefix, testCase.expected, actual)
+		}
+	}
+}
+
+func readIndex(r io.Reader) (index Index, _ error) {
+	err := ReadIndex(r, Visitor{
+		VisitMetadata: func(metadata Metadata) error {
+			index.Metadata = metadata
+			return nil
+		},
+		VisitDocument: func(document Document) error {
+			index.Documents = append(index.Documents, document)
+			return nil
+		},
+		VisitExternalSymbol: func(symbol SymbolInformation) error {
+			index.ExternalSymbols = append(index.ExternalSymbols, symbol)
+			return nil
+		},
+	})
+
+	return index, err
+}
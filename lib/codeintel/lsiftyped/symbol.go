@@ -0,0 +1,63 @@
+package lsiftyped
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Symbol is a parsed global symbol of the form `<scheme> <manager> <package-name> <version> <descriptors>`.
+type Symbol struct {
+	Scheme      string
+	Package     Package
+	Descriptors string
+}
+
+// Package identifies the package that defines a global symbol. Empty fields are omitted by the
+// indexer (encoded as `.` within the symbol).
+type Package struct {
+	Manager string
+	Name    string
+	Version string
+}
+
+// IsLocalSymbol returns true if the given symbol is visible only within the document in which it
+// occurs.
+func IsLocalSymbol(symbol string) bool {
+	return strings.HasPrefix(symbol, "local ")
+}
+
+// ParseSymbol parses the given global symbol.
+func ParseSymbol(symbol string) (Symbol, error) {
+	if IsLocalSymbol(symbol) {
+		return Symbol{}, errors.Errorf("unexpected local symbol %q", symbol)
+	}
+
+	parts := strings.SplitN(symbol, " ", 5)
+	if len(parts) != 5 || parts[0] == "" || parts[4] == "" {
+		return Symbol{}, errors.Errorf("malformed symbol %q", symbol)
+	}
+
+	return Symbol{
+		Scheme: parts[0],
+		Package: Package{
+			Manager: unplaceholder(parts[1]),
+			Name:    unplaceholder(parts[2]),
+			Version: unplaceholder(parts[3]),
+		},
+		Descriptors: parts[4],
+	}, nil
+}
+
+// IsCallable returns true if the symbol denotes a function, method, or constructor.
+func (s Symbol) IsCallable() bool {
+	return strings.HasSuffix(s.Descriptors, ").")
+}
+
+func unplaceholder(s string) string {
+	if s == "." {
+		return ""
+	}
+
+	return s
+}
@@ -0,0 +1,34 @@
+package lsiftyped
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSymbol(t *testing.T) {
+	symbol, err := ParseSymbol("gomod . example.com/foo v1.0.0 foo/Bar#Baz().")
+	if err != nil {
+		t.Fatalf("unexpected error parsing symbol: %s", err)
+	}
+
+	expected := Symbol{
+		Scheme:      "gomod",
+		Package:     Package{Name: "example.com/foo", Version: "v1.0.0"},
+		Descriptors: "foo/Bar#Baz().",
+	}
+	if diff := cmp.Diff(expected, symbol); diff != "" {
+		t.Errorf("unexpected symbol (-want +got):\n%s", diff)
+	}
+	if !symbol.IsCallable() {
+		t.Errorf("expected symbol to be callable")
+	}
+}
+
+func TestParseSymbolMalformed(t *testing.T) {
+	for _, symbol := range []string{"", "local 1", "gomod . example.com/foo v1.0.0"} {
+		if _, err := ParseSymbol(symbol); err == nil {
+			t.Errorf("expected error parsing %q", symbol)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package lsiftyped
+
+// Index is the decoded form of the Index message. Consumers of large indexes should prefer
+// ReadIndex, which does not hold all documents in memory at once.
+type Index struct {
+	Metadata        Metadata
+	Documents       []Document
+	ExternalSymbols []SymbolInformation
+}
+
+// Metadata describes the tool that produced an index.
+type Metadata struct {
+	ToolInfo    ToolInfo
+	ProjectRoot string
+}
+
+// ToolInfo identifies the indexer that produced an index.
+type ToolInfo struct {
+	Name      string
+	Version   string
+	Arguments []string
+}
+
+// Document holds the occurrences within and the symbols defined by a single source file.
+type Document struct {
+	RelativePath string
+	Occurrences  []Occurrence
+	Symbols      []SymbolInformation
+}
+
+// SymbolInformation holds the hover text and relationships of a symbol.
+type SymbolInformation struct {
+	Symbol        string
+	Documentation []string
+	Relationships []Relationship
+}
+
+// Relationship relates a symbol to another symbol.
+type Relationship struct {
+	Symbol           string
+	IsReference      bool
+	IsImplementation bool
+	IsTypeDefinition bool
+}
+
+// SymbolRole is a bitset describing how a symbol is used by an occurrence.
+type SymbolRole int32
+
+const (
+	SymbolRoleDefinition  SymbolRole = 1
+	SymbolRoleImport      SymbolRole = 2
+	SymbolRoleWriteAccess SymbolRole = 4
+	SymbolRoleReadAccess  SymbolRole = 8
+)
+
+// Occurrence associates a range within a document with a symbol.
+type Occurrence struct {
+	Range                 []int32
+	Symbol                string
+	SymbolRoles           SymbolRole
+	OverrideDocumentation []string
+	Diagnostics           []Diagnostic
+	EnclosingRange        []int32
+}
+
+// Diagnostic is a compiler or linter message attached to an occurrence.
+type Diagnostic struct {
+	Severity int32
+	Code     string
+	Message  string
+	Source   string
+}
+
+// Range is a decoded occurrence range. All values are zero-based and the end position is
+// exclusive.
+type Range struct {
+	StartLine      int
+	StartCharacter int
+	EndLine        int
+	EndCharacter   int
+}
+
+// NewRange decodes the range encoding used by occurrences, which is either of the form
+// [startLine, startCharacter, endLine, endCharacter] or, for ranges that span a single line,
+// [startLine, startCharacter, endCharacter]. A false-valued flag is returned if the given
+// slice is neither.
+func NewRange(r []int32) (Range, bool) {
+	switch len(r) {
+	case 3:
+		return Range{StartLine: int(r[0]), StartCharacter: int(r[1]), EndLine: int(r[0]), EndCharacter: int(r[2])}, true
+	case 4:
+		return Range{StartLine: int(r[0]), StartCharacter: int(r[1]), EndLine: int(r[2]), EndCharacter: int(r[3])}, true
+	}
+
+	return Range{}, false
+}
+
+// HasRole returns true if the occurrence has all of the given symbol roles.
+func (o Occurrence) HasRole(role SymbolRole) bool {
+	return o.SymbolRoles&role == role
+}
@@ -0,0 +1,144 @@
+package lsiftyped
+
+import "google.golang.org/protobuf/encoding/protowire"
+
+// MarshalIndex encodes the given index as a protobuf Index message.
+//
+// Encoded indexes can be concatenated: indexers that cannot hold an entire index in memory can
+// marshal an index containing a single document at a time and write the results one after the
+// other.
+func MarshalIndex(index Index) []byte {
+	var b []byte
+	if index.Metadata.ProjectRoot != "" || index.Metadata.ToolInfo.Name != "" {
+		b = appendMessage(b, 1, index.Metadata.appendFields)
+	}
+	for i := range index.Documents {
+		b = appendMessage(b, 2, index.Documents[i].appendFields)
+	}
+	for i := range index.ExternalSymbols {
+		b = appendMessage(b, 3, index.ExternalSymbols[i].appendFields)
+	}
+
+	return b
+}
+
+func (m *Metadata) appendFields(b []byte) []byte {
+	b = appendMessage(b, 1, m.ToolInfo.appendFields)
+	b = appendString(b, 2, m.ProjectRoot)
+	return b
+}
+
+func (t *ToolInfo) appendFields(b []byte) []byte {
+	b = appendString(b, 1, t.Name)
+	b = appendString(b, 2, t.Version)
+	b = appendStrings(b, 3, t.Arguments)
+	return b
+}
+
+func (d *Document) appendFields(b []byte) []byte {
+	b = appendString(b, 1, d.RelativePath)
+	for i := range d.Occurrences {
+		b = appendMessage(b, 2, d.Occurrences[i].appendFields)
+	}
+	for i := range d.Symbols {
+		b = appendMessage(b, 3, d.Symbols[i].appendFields)
+	}
+
+	return b
+}
+
+func (s *SymbolInformation) appendFields(b []byte) []byte {
+	b = appendString(b, 1, s.Symbol)
+	b = appendStrings(b, 2, s.Documentation)
+	for i := range s.Relationships {
+		b = appendMessage(b, 3, s.Relationships[i].appendFields)
+	}
+
+	return b
+}
+
+func (r *Relationship) appendFields(b []byte) []byte {
+	b = appendString(b, 1, r.Symbol)
+	b = appendBool(b, 2, r.IsReference)
+	b = appendBool(b, 3, r.IsImplementation)
+	b = appendBool(b, 4, r.IsTypeDefinition)
+	return b
+}
+
+func (o *Occurrence) appendFields(b []byte) []byte {
+	b = appendInt32s(b, 1, o.Range)
+	b = appendString(b, 2, o.Symbol)
+	b = appendInt32(b, 3, int32(o.SymbolRoles))
+	b = appendStrings(b, 4, o.OverrideDocumentation)
+	for i := range o.Diagnostics {
+		b = appendMessage(b, 5, o.Diagnostics[i].appendFields)
+	}
+	b = appendInt32s(b, 6, o.EnclosingRange)
+	return b
+}
+
+func (d *Diagnostic) appendFields(b []byte) []byte {
+	b = appendInt32(b, 1, d.Severity)
+	b = appendString(b, 2, d.Code)
+	b = appendString(b, 3, d.Message)
+	b = appendString(b, 4, d.Source)
+	return b
+}
+
+// appendMessage appends the given message field. The message's fields are appended by the given
+// function.
+func appendMessage(b []byte, num protowire.Number, appendFields func(b []byte) []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, appendFields(nil))
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendStrings(b []byte, num protowire.Number, vs []string) []byte {
+	for _, v := range vs {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	}
+
+	return b
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	if !v {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, protowire.EncodeBool(v))
+}
+
+func appendInt32(b []byte, num protowire.Number, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// appendInt32s appends the given values as a packed repeated field.
+func appendInt32s(b []byte, num protowire.Number, vs []int32) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+
+	var packed []byte
+	for _, v := range vs {
+		packed = protowire.AppendVarint(packed, uint64(v))
+	}
+
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, packed)
+}
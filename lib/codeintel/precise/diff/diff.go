@@ -111,6 +111,13 @@ func Diff(old, new *precise.GroupedBundleDataMaps) string {
 				fmt.Sprintf("Definition: %v -> ", locationString(location)),
 			)
 
+			diffLocations(
+				&builder,
+				oldResult.TypeDefinitions,
+				newResult.TypeDefinitions,
+				fmt.Sprintf("TypeDefinition: %v -> ", locationString(location)),
+			)
+
 			diffLocations(
 				&builder,
 				oldResult.References,
@@ -118,6 +125,13 @@ func Diff(old, new *precise.GroupedBundleDataMaps) string {
 				fmt.Sprintf("Reference: %v -> ", locationString(location)),
 			)
 
+			diffLocations(
+				&builder,
+				oldResult.Implementations,
+				newResult.Implementations,
+				fmt.Sprintf("Implementation: %v -> ", locationString(location)),
+			)
+
 			diffQualifiedMonikers(
 				&builder,
 				oldResult.Monikers,
@@ -128,7 +142,9 @@ func Diff(old, new *precise.GroupedBundleDataMaps) string {
 	}
 
 	diffMonikers(&builder, old.Definitions, new.Definitions, "MonikerDefinition: ")
+	diffMonikers(&builder, old.TypeDefinitions, new.TypeDefinitions, "MonikerTypeDefinition: ")
 	diffMonikers(&builder, old.References, new.References, "MonikerReference: ")
+	diffMonikers(&builder, old.Implementations, new.Implementations, "MonikerImplementation: ")
 
 	return builder.String()
 }
@@ -25,15 +25,30 @@ type DocumentData struct {
 // that was reachable via a result set has been collapsed into this object during
 // conversion.
 type RangeData struct {
-	StartLine             int  // 0-indexed, inclusive
-	StartCharacter        int  // 0-indexed, inclusive
-	EndLine               int  // 0-indexed, inclusive
-	EndCharacter          int  // 0-indexed, inclusive
-	DefinitionResultID    ID   // possibly empty
-	ReferenceResultID     ID   // possibly empty
-	HoverResultID         ID   // possibly empty
-	DocumentationResultID ID   // possibly empty
-	MonikerIDs            []ID // possibly empty
+	StartLine              int  // 0-indexed, inclusive
+	StartCharacter         int  // 0-indexed, inclusive
+	EndLine                int  // 0-indexed, inclusive
+	EndCharacter           int  // 0-indexed, inclusive
+	DefinitionResultID     ID   // possibly empty
+	TypeDefinitionResultID ID   // possibly empty
+	ReferenceResultID      ID   // possibly empty
+	ImplementationResultID ID   // possibly empty
+	HoverResultID          ID   // possibly empty
+	DocumentationResultID  ID   // possibly empty
+	MonikerIDs             []ID // possibly empty
+
+	// Callable is non-nil when the range is the definition of a function, method, or
+	// constructor. It holds the extent of the callable's declaration (including its body).
+	Callable *CallableData
+}
+
+// CallableData describes the extent of the declaration of a function, method, or constructor.
+// This is used to determine which callable encloses a given call site.
+type CallableData struct {
+	StartLine      int // 0-indexed, inclusive
+	StartCharacter int // 0-indexed, inclusive
+	EndLine        int // 0-indexed, inclusive
+	EndCharacter   int // 0-indexed, inclusive
 }
 
 // MonikerData represent a unique name (eventually) attached to a range.
@@ -209,12 +224,19 @@ type GroupedBundleDataChans struct {
 	Documents             chan KeyedDocumentData
 	ResultChunks          chan IndexedResultChunkData
 	Definitions           chan MonikerLocations
+	TypeDefinitions       chan MonikerLocations
 	References            chan MonikerLocations
+	Implementations       chan MonikerLocations
 	Packages              []Package
 	PackageReferences     []PackageReference
 	DocumentationPages    chan *DocumentationPageData
 	DocumentationPathInfo chan *DocumentationPathInfoData
 	DocumentationMappings chan DocumentationMapping
+
+	// Err returns the error that caused the producer of the channels above to stop sending values
+	// early, if any. It must be called only once the channels have been drained. Err is nil if the
+	// producer cannot fail.
+	Err func() error
 }
 
 type GroupedBundleDataMaps struct {
@@ -222,7 +244,9 @@ type GroupedBundleDataMaps struct {
 	Documents         map[string]DocumentData
 	ResultChunks      map[int]ResultChunkData
 	Definitions       map[string]map[string][]LocationData
+	TypeDefinitions   map[string]map[string][]LocationData
 	References        map[string]map[string][]LocationData
+	Implementations   map[string]map[string][]LocationData
 	Packages          []Package
 	PackageReferences []PackageReference
 }
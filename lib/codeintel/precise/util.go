@@ -215,12 +215,52 @@ func GroupedBundleDataMapsToChans(ctx context.Context, maps *GroupedBundleDataMa
 		}
 	}()
 
+	monikerTypeDefsChan := make(chan MonikerLocations)
+	go func() {
+		defer close(monikerTypeDefsChan)
+
+		for scheme, identMap := range maps.TypeDefinitions {
+			for ident, locations := range identMap {
+				select {
+				case monikerTypeDefsChan <- MonikerLocations{
+					Scheme:     scheme,
+					Identifier: ident,
+					Locations:  locations,
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	monikerImplsChan := make(chan MonikerLocations)
+	go func() {
+		defer close(monikerImplsChan)
+
+		for scheme, identMap := range maps.Implementations {
+			for ident, locations := range identMap {
+				select {
+				case monikerImplsChan <- MonikerLocations{
+					Scheme:     scheme,
+					Identifier: ident,
+					Locations:  locations,
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
 	return &GroupedBundleDataChans{
 		Meta:              maps.Meta,
 		Documents:         documentChan,
 		ResultChunks:      resultChunkChan,
 		Definitions:       monikerDefsChan,
+		TypeDefinitions:   monikerTypeDefsChan,
 		References:        monikerRefsChan,
+		Implementations:   monikerImplsChan,
 		Packages:          maps.Packages,
 		PackageReferences: maps.PackageReferences,
 	}
@@ -251,12 +291,30 @@ func GroupedBundleDataChansToMaps(chans *GroupedBundleDataChans) *GroupedBundleD
 		monikerRefsMap[monikerRefs.Scheme][monikerRefs.Identifier] = monikerRefs.Locations
 	}
 
+	monikerTypeDefsMap := make(map[string]map[string][]LocationData)
+	for monikerTypeDefs := range chans.TypeDefinitions {
+		if _, exists := monikerTypeDefsMap[monikerTypeDefs.Scheme]; !exists {
+			monikerTypeDefsMap[monikerTypeDefs.Scheme] = make(map[string][]LocationData)
+		}
+		monikerTypeDefsMap[monikerTypeDefs.Scheme][monikerTypeDefs.Identifier] = monikerTypeDefs.Locations
+	}
+
+	monikerImplsMap := make(map[string]map[string][]LocationData)
+	for monikerImpls := range chans.Implementations {
+		if _, exists := monikerImplsMap[monikerImpls.Scheme]; !exists {
+			monikerImplsMap[monikerImpls.Scheme] = make(map[string][]LocationData)
+		}
+		monikerImplsMap[monikerImpls.Scheme][monikerImpls.Identifier] = monikerImpls.Locations
+	}
+
 	return &GroupedBundleDataMaps{
 		Meta:              chans.Meta,
 		Documents:         documentMap,
 		ResultChunks:      resultChunkMap,
 		Definitions:       monikerDefsMap,
+		TypeDefinitions:   monikerTypeDefsMap,
 		References:        monikerRefsMap,
+		Implementations:   monikerImplsMap,
 		Packages:          chans.Packages,
 		PackageReferences: chans.PackageReferences,
 	}
@@ -0,0 +1,61 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS lsif_data_implementations (
+    dump_id integer NOT NULL,
+    scheme text NOT NULL,
+    identifier text NOT NULL,
+    data bytea,
+    schema_version integer NOT NULL,
+    num_locations integer NOT NULL,
+    PRIMARY KEY (dump_id, scheme, identifier)
+);
+
+COMMENT ON TABLE lsif_data_implementations IS 'Associates (document, range) pairs with the implementation monikers attached to the range.';
+COMMENT ON COLUMN lsif_data_implementations.dump_id IS 'The identifier of the associated dump in the lsif_uploads table (state=completed).';
+COMMENT ON COLUMN lsif_data_implementations.scheme IS 'The moniker scheme.';
+COMMENT ON COLUMN lsif_data_implementations.identifier IS 'The moniker identifier.';
+COMMENT ON COLUMN lsif_data_implementations.data IS 'A gob-encoded payload conforming to an array of [LocationData](https://sourcegraph.com/github.com/sourcegraph/sourcegraph@3.26/-/blob/enterprise/lib/codeintel/semantic/types.go#L106:6) types.';
+COMMENT ON COLUMN lsif_data_implementations.schema_version IS 'The schema version of this row - used to determine presence and encoding of data.';
+COMMENT ON COLUMN lsif_data_implementations.num_locations IS 'The number of locations stored in the data field.';
+
+CREATE INDEX IF NOT EXISTS lsif_data_implementations_dump_id_schema_version ON lsif_data_implementations USING btree (dump_id, schema_version);
+
+CREATE TABLE IF NOT EXISTS lsif_data_implementations_schema_versions (
+    dump_id integer NOT NULL,
+    min_schema_version integer,
+    max_schema_version integer,
+    PRIMARY KEY (dump_id)
+);
+
+COMMENT ON TABLE lsif_data_implementations_schema_versions IS 'Tracks the range of schema_versions for each upload in the lsif_data_implementations table.';
+COMMENT ON COLUMN lsif_data_implementations_schema_versions.dump_id IS 'The identifier of the associated dump in the lsif_uploads table.';
+COMMENT ON COLUMN lsif_data_implementations_schema_versions.min_schema_version IS 'A lower-bound on the `lsif_data_implementations.schema_version` where `lsif_data_implementations.dump_id = dump_id`.';
+COMMENT ON COLUMN lsif_data_implementations_schema_versions.max_schema_version IS 'An upper-bound on the `lsif_data_implementations.schema_version` where `lsif_data_implementations.dump_id = dump_id`.';
+
+CREATE INDEX IF NOT EXISTS lsif_data_implementations_schema_versions_dump_id_bounds ON lsif_data_implementations_schema_versions USING btree (dump_id, min_schema_version, max_schema_version);
+
+CREATE OR REPLACE FUNCTION update_lsif_data_implementations_schema_versions_insert() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$ BEGIN
+    INSERT INTO
+        lsif_data_implementations_schema_versions
+    SELECT
+        dump_id,
+        MIN(schema_version) as min_schema_version,
+        MAX(schema_version) as max_schema_version
+    FROM
+        newtab
+    GROUP BY
+        dump_id
+    ON CONFLICT (dump_id) DO UPDATE SET
+        -- Update with min(old_min, new_min) and max(old_max, new_max)
+        min_schema_version = LEAST(lsif_data_implementations_schema_versions.min_schema_version, EXCLUDED.min_schema_version),
+        max_schema_version = GREATEST(lsif_data_implementations_schema_versions.max_schema_version, EXCLUDED.max_schema_version);
+
+    RETURN NULL;
+END $$;
+
+DROP TRIGGER IF EXISTS lsif_data_implementations_schema_versions_insert ON lsif_data_implementations;
+CREATE TRIGGER lsif_data_implementations_schema_versions_insert AFTER INSERT ON lsif_data_implementations REFERENCING NEW TABLE AS newtab FOR EACH STATEMENT EXECUTE FUNCTION update_lsif_data_implementations_schema_versions_insert();
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS global_symbols;
+DROP TABLE IF EXISTS global_symbols_repos;
+
+COMMIT;
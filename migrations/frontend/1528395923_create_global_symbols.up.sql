@@ -0,0 +1,26 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS global_symbols_repos (
+    repo_id integer PRIMARY KEY REFERENCES repo(id) ON DELETE CASCADE,
+    commit_id text NOT NULL,
+    indexed_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE global_symbols_repos IS 'Tracks the default branch commit of each repository whose symbols are stored in global_symbols.';
+
+CREATE TABLE IF NOT EXISTS global_symbols (
+    repo_id integer NOT NULL REFERENCES global_symbols_repos(repo_id) ON DELETE CASCADE,
+    path text NOT NULL,
+    name text NOT NULL,
+    kind text NOT NULL,
+    language text NOT NULL,
+    parent text NOT NULL,
+    line integer NOT NULL
+);
+
+COMMENT ON TABLE global_symbols IS 'Symbols on the default branch of every repository, used to answer symbol searches across the whole instance.';
+
+CREATE INDEX IF NOT EXISTS global_symbols_repo_id ON global_symbols(repo_id);
+CREATE INDEX IF NOT EXISTS global_symbols_name_trgm ON global_symbols USING gin (lower(name) gin_trgm_ops);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE gitserver_repos DROP COLUMN IF EXISTS last_integrity_check_at;
+ALTER TABLE gitserver_repos DROP COLUMN IF EXISTS last_integrity_check_error;
+ALTER TABLE gitserver_repos DROP COLUMN IF EXISTS corrupted_at;
+ALTER TABLE gitserver_repos DROP COLUMN IF EXISTS corruption_count;
+
+COMMIT;
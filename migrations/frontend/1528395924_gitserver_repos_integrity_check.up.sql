@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE gitserver_repos ADD COLUMN IF NOT EXISTS last_integrity_check_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE gitserver_repos ADD COLUMN IF NOT EXISTS last_integrity_check_error TEXT;
+ALTER TABLE gitserver_repos ADD COLUMN IF NOT EXISTS corrupted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE gitserver_repos ADD COLUMN IF NOT EXISTS corruption_count INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN gitserver_repos.last_integrity_check_error IS 'The output of the last integrity check of the repository if it failed, or NULL if the repository was intact.';
+COMMENT ON COLUMN gitserver_repos.corrupted_at IS 'The last time an integrity check found the repository to be corrupt.';
+COMMENT ON COLUMN gitserver_repos.corruption_count IS 'The number of integrity checks which found the repository to be corrupt.';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE gitserver_repos DROP COLUMN IF EXISTS evicted_at;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE gitserver_repos ADD COLUMN IF NOT EXISTS evicted_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN gitserver_repos.evicted_at IS 'The time the repository was removed from gitserver to free up disk space, or NULL if it was requested since.';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE global_symbols_repos DROP COLUMN IF EXISTS truncated;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE global_symbols_repos ADD COLUMN IF NOT EXISTS truncated boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN global_symbols_repos.truncated IS 'Whether the repository has more symbols than the indexer stores. The symbols of such repositories are not stored, and searches fall back to the symbols service.';
+
+COMMIT;
@@ -586,6 +586,8 @@ type ExperimentalFeatures struct {
 	SearchMultipleRevisionsPerRepository *bool `json:"searchMultipleRevisionsPerRepository,omitempty"`
 	// StructuralSearch description: Enables structural search.
 	StructuralSearch string `json:"structuralSearch,omitempty"`
+	// SymbolsGlobalIndex description: Maintain an index of the symbols on the default branch of every repository and answer symbol searches without repository filters from it, instead of querying the symbols service for each unindexed repository.
+	SymbolsGlobalIndex string `json:"symbols.globalIndex,omitempty"`
 	// TlsExternal description: Global TLS/SSL settings for Sourcegraph to use when communicating with code hosts.
 	TlsExternal *TlsExternal `json:"tls.external,omitempty"`
 	// VersionContexts description: DEPRECATED: Use search contexts instead.
@@ -1342,6 +1344,14 @@ type SearchLimits struct {
 	// MaxTimeoutSeconds description: The maximum value for "timeout:" that search will respect. "timeout:" values larger than maxTimeoutSeconds are capped at maxTimeoutSeconds. Note: You need to ensure your load balancer / reverse proxy in front of Sourcegraph won't timeout the request for larger values. Note: Too many large rearch requests may harm Soucregraph for other users. Defaults to 1 minute.
 	MaxTimeoutSeconds int `json:"maxTimeoutSeconds,omitempty"`
 }
+
+// SearchPrewarm description: Prepares the caches of searcher and the symbols service for the new HEAD of a repository after gitserver fetches it, so that the first unindexed search after a push does not wait for the repository archive. Only repositories which are not indexed by indexed search and which match one of the patterns in repos are pre-warmed.
+type SearchPrewarm struct {
+	// MaxPerMinute description: The maximum number of repositories each gitserver pre-warms per minute. Defaults to 60.
+	MaxPerMinute int `json:"maxPerMinute,omitempty"`
+	// Repos description: Regular expressions matching the names of the repositories to pre-warm.
+	Repos []string `json:"repos,omitempty"`
+}
 type SearchSavedQueries struct {
 	// Description description: Description of this saved query
 	Description string `json:"description"`
@@ -1584,6 +1594,10 @@ type SiteConfiguration struct {
 	ExternalURL string `json:"externalURL,omitempty"`
 	// GitCloneURLToRepositoryName description: JSON array of configuration that maps from Git clone URL to repository name. Sourcegraph automatically resolves remote clone URLs to their proper code host. However, there may be non-remote clone URLs (e.g., in submodule declarations) that Sourcegraph cannot automatically map to a code host. In this case, use this field to specify the mapping. The mappings are tried in the order they are specified and take precedence over automatic mappings.
 	GitCloneURLToRepositoryName []*CloneURLToRepositoryName `json:"git.cloneURLToRepositoryName,omitempty"`
+	// GitEvictionPinnedRepos description: Names of repositories which gitserver never removes when it runs low on disk space.
+	GitEvictionPinnedRepos []string `json:"gitEvictionPinnedRepos,omitempty"`
+	// GitEvictionPolicy description: The order in which gitserver removes repositories when it runs low on disk space. "last-fetched" removes the repositories which were fetched least recently first. "access" removes the repositories which are accessed (searched, archived, or otherwise read) least per byte of disk space first. Gitserver keeps the access history in memory, so after a restart "access" falls back to the order of "last-fetched" until repositories are accessed again.
+	GitEvictionPolicy string `json:"gitEvictionPolicy,omitempty"`
 	// GitLongCommandTimeout description: Maximum number of seconds that a long Git command (e.g. clone or remote update) is allowed to execute. The default is 3600 seconds, or 1 hour.
 	GitLongCommandTimeout int `json:"gitLongCommandTimeout,omitempty"`
 	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote code host git operations (e.g. clone or ls-remote) to be run per second per gitserver. Default is -1, which is unlimited.
@@ -1654,6 +1668,12 @@ type SiteConfiguration struct {
 	SearchLargeFiles []string `json:"search.largeFiles,omitempty"`
 	// SearchLimits description: Limits that search applies for number of repositories searched and timeouts.
 	SearchLimits *SearchLimits `json:"search.limits,omitempty"`
+	// SearchPrewarm description: Prepares the caches of searcher and the symbols service for the new HEAD of a repository after gitserver fetches it, so that the first unindexed search after a push does not wait for the repository archive. Only repositories which are not indexed by indexed search and which match one of the patterns in repos are pre-warmed.
+	SearchPrewarm *SearchPrewarm `json:"search.prewarm,omitempty"`
+	// SearchStructuralMatchers description: Custom language definitions for structural search, for languages which comby's built-in matchers do not cover. A definition is used for searches with a lang: filter matching one of its languages, and for files with one of its extensions. Definitions take precedence over built-in matchers.
+	SearchStructuralMatchers []*StructuralSearchMatcher `json:"search.structural.matchers,omitempty"`
+	// SymbolsParsers description: A map from lowercase language name to the parser the symbols service uses to extract symbols for that language. Languages which are not listed, or which the chosen parser does not support, are parsed with universal-ctags. The tree-sitter parser currently supports "go" and "typescript". It does not support "kotlin" yet because the tree-sitter bindings in use have no Kotlin grammar, so Kotlin is always parsed with universal-ctags.
+	SymbolsParsers map[string]string `json:"symbols.parsers,omitempty"`
 	// UpdateChannel description: The channel on which to automatically check for Sourcegraph updates.
 	UpdateChannel string `json:"update.channel,omitempty"`
 	// UseJaeger description: DEPRECATED. Use `"observability.tracing": { "sampling": "all" }`, instead. Enables Jaeger tracing.
@@ -1680,6 +1700,26 @@ type Step struct {
 	Run string `json:"run"`
 }
 
+// StructuralSearchMatcher description: A comby language definition, which describes the delimiters, comments and string literals of a language.
+type StructuralSearchMatcher struct {
+	// BlockComments description: Pairs of strings which start and end a multiline comment.
+	BlockComments [][]string `json:"blockComments,omitempty"`
+	// Delimiters description: Pairs of opening and closing delimiters which holes must balance, in addition to parentheses, brackets and braces.
+	Delimiters [][]string `json:"delimiters,omitempty"`
+	// EscapeCharacter description: The escape character of stringLiterals. Defaults to a backslash.
+	EscapeCharacter string `json:"escapeCharacter,omitempty"`
+	// Extensions description: File extensions the definition applies to, including the leading dot, for example ".dsl".
+	Extensions []string `json:"extensions,omitempty"`
+	// Languages description: Languages the definition applies to, compared case insensitively with the lang: filter of a search.
+	Languages []string `json:"languages,omitempty"`
+	// LineComments description: Strings which start a comment that extends to the end of the line.
+	LineComments []string `json:"lineComments,omitempty"`
+	// RawStringLiterals description: Pairs of strings which start and end a string literal without escapes.
+	RawStringLiterals [][]string `json:"rawStringLiterals,omitempty"`
+	// StringLiterals description: Delimiters of string literals in which escapeCharacter escapes the delimiter.
+	StringLiterals []string `json:"stringLiterals,omitempty"`
+}
+
 // TlsExternal description: Global TLS/SSL settings for Sourcegraph to use when communicating with code hosts.
 type TlsExternal struct {
 	// Certificates description: TLS certificates to accept. This is only necessary if you are using self-signed certificates or an internal CA. Can be an internal CA certificate or a self-signed certificate. To get the certificate of a webserver run `openssl s_client -connect HOST:443 -showcerts < /dev/null 2> /dev/null | openssl x509 -outform PEM`. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.